@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// DecodeBase58 godoc
+// @Summary Decode base58 value
+// @Description Validates base58 value and detects its type by prefix: addresses, public keys, signatures, chain ID, block, operation and protocol hashes, script expression hashes and others.
+// @Tags base58
+// @ID decode-base58
+// @Param value query string true "Base58 value"
+// @Accept json
+// @Produce json
+// @Success 200 {object} Base58Value
+// @Failure 400 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/base58/decode [get]
+func (ctx *Context) DecodeBase58(c *gin.Context) {
+	var req base58DecodeRequest
+	if err := c.BindQuery(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	value, err := encoding.ParseBase58(req.Value)
+	if ctx.handleError(c, errors.Wrap(err, "invalid base58 value"), http.StatusBadRequest) {
+		return
+	}
+	c.SecureJSON(http.StatusOK, Base58Value{
+		Value:  req.Value,
+		Prefix: value.Prefix,
+		Type:   value.DataType,
+		Hex:    hex.EncodeToString(value.Data),
+	})
+}
+
+// EncodeBase58 godoc
+// @Summary Encode hex to base58
+// @Description Encodes hex string to base58 value with prefix. Length of data should match the prefix.
+// @Tags base58
+// @ID encode-base58
+// @Param hex query string true "Hex string"
+// @Param prefix query string true "Base58 prefix (e.g. tz1, KT1, edpk, expr)"
+// @Accept json
+// @Produce json
+// @Success 200 {object} Base58Value
+// @Failure 400 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/base58/encode [get]
+func (ctx *Context) EncodeBase58(c *gin.Context) {
+	var req base58EncodeRequest
+	if err := c.BindQuery(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	data, err := hex.DecodeString(strings.TrimPrefix(req.Hex, "0x"))
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	value, err := encoding.EncodeBase58(data, []byte(req.Prefix))
+	if ctx.handleError(c, errors.Wrapf(err, "can't encode %d bytes with prefix %s", len(data), req.Prefix), http.StatusBadRequest) {
+		return
+	}
+	decoded, err := encoding.ParseBase58(value)
+	if ctx.handleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+	c.SecureJSON(http.StatusOK, Base58Value{
+		Value:  value,
+		Prefix: decoded.Prefix,
+		Type:   decoded.DataType,
+		Hex:    hex.EncodeToString(decoded.Data),
+	})
+}
+
+// GetAddressByPublicKey godoc
+// @Summary Derive address from public key
+// @Description Returns implicit address (tz1, tz2 or tz3) of public key
+// @Tags base58
+// @ID get-address-by-public-key
+// @Param public_key query string true "Public key"
+// @Accept json
+// @Produce json
+// @Success 200 {object} AddressResponse
+// @Failure 400 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/base58/address [get]
+func (ctx *Context) GetAddressByPublicKey(c *gin.Context) {
+	var req publicKeyRequest
+	if err := c.BindQuery(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	address, err := encoding.PublicKeyHash(req.PublicKey)
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	c.SecureJSON(http.StatusOK, AddressResponse{
+		Address: address,
+	})
+}
+
+// GetOriginatedAddress godoc
+// @Summary Compute address of originated contract
+// @Description Returns KT1 address of contract originated by operation group. `index` is a number of origination in the group including internal ones starting from 0.
+// @Tags base58
+// @ID get-originated-address
+// @Param hash query string true "Operation group hash" minlength(51) maxlength(51)
+// @Param index query integer false "Origination index" minimum(0)
+// @Accept json
+// @Produce json
+// @Success 200 {object} AddressResponse
+// @Failure 400 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/base58/originated_address [get]
+func (ctx *Context) GetOriginatedAddress(c *gin.Context) {
+	var req originatedAddressRequest
+	if err := c.BindQuery(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	address, err := encoding.OriginatedAddress(req.Hash, req.Index)
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	c.SecureJSON(http.StatusOK, AddressResponse{
+		Address: address,
+	})
+}
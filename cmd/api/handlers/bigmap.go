@@ -3,11 +3,13 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/baking-bad/bcdhub/internal/bcd"
 	"github.com/baking-bad/bcdhub/internal/bcd/ast"
 	"github.com/baking-bad/bcdhub/internal/bcd/consts"
 	"github.com/baking-bad/bcdhub/internal/bcd/formatter"
+	bcdTypes "github.com/baking-bad/bcdhub/internal/bcd/types"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapaction"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/types"
@@ -159,7 +161,8 @@ func (ctx *Context) GetBigMapHistory(c *gin.Context) {
 
 // GetBigMapKeys godoc
 // @Summary Get big map keys by pointer
-// @Description Get big map keys by pointer
+// @Description Get big map keys by pointer. If value predicates are set, keys are filtered by all of them on server side: not more than 100000 keys are scanned per request.
+// @Description If `X-Last-ID` header is returned, the page may be incomplete and next keys are requested with `last_id` set to its value.
 // @Tags bigmap
 // @ID get-bigmap-keys
 // @Param network path string true "Network"
@@ -169,9 +172,13 @@ func (ctx *Context) GetBigMapHistory(c *gin.Context) {
 // @Param size query integer false "Requested count" mininum(1) maximum(10)
 // @Param max_level query integer false "Max level filter" minimum(0)
 // @Param min_level query integer false "Min level filter" minimum(0)
+// @Param at_level query integer false "Return keys as they were at the end of the level" minimum(1)
+// @Param last_id query integer false "Return keys updated before the key with this ID: value of `X-Last-ID` header of previous page" minimum(1)
+// @Param value query []string false "Predicates on decoded value `path:operator:value`. Operators: eq, ne, gt, gte, lt, lte, has. Path is dot-separated field names, `*` is any field, empty path is the whole value" collectionFormat(multi)
 // @Accept json
 // @Produce json
 // @Success 200 {array} BigMapResponseItem
+// @Header 200 {integer} X-Last-ID "Cursor of the next page"
 // @Failure 400 {object} Error
 // @Failure 500 {object} Error
 // @Router /v1/bigmap/{network}/{ptr}/keys [get]
@@ -186,7 +193,12 @@ func (ctx *Context) GetBigMapKeys(c *gin.Context) {
 		return
 	}
 
-	states, err := ctx.BigMapDiffs.Keys(bigmapdiff.GetContext{
+	predicates, err := pageReq.Predicates()
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	getCtx := bigmapdiff.GetContext{
 		Ptr:      &req.Ptr,
 		Network:  req.NetworkID(),
 		Query:    pageReq.Search,
@@ -194,7 +206,23 @@ func (ctx *Context) GetBigMapKeys(c *gin.Context) {
 		Offset:   pageReq.Offset,
 		MaxLevel: pageReq.MaxLevel,
 		MinLevel: pageReq.MinLevel,
-	})
+		AtLevel:  pageReq.AtLevel,
+		LastID:   pageReq.LastID,
+	}
+
+	if len(predicates) > 0 {
+		response, lastID, err := ctx.filterBigMapKeys(getCtx, predicates)
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		if lastID > 0 {
+			c.Header(lastIDHeader, strconv.FormatInt(lastID, 10))
+		}
+		c.SecureJSON(http.StatusOK, response)
+		return
+	}
+
+	states, err := ctx.BigMapDiffs.Keys(getCtx)
 	if ctx.handleError(c, err, 0) {
 		return
 	}
@@ -204,6 +232,9 @@ func (ctx *Context) GetBigMapKeys(c *gin.Context) {
 		return
 	}
 
+	if len(states) > 0 && int64(len(states)) == ctx.pageSize(pageReq.Size) {
+		c.Header(lastIDHeader, strconv.FormatInt(states[len(states)-1].ID, 10))
+	}
 	c.SecureJSON(http.StatusOK, response)
 }
 
@@ -235,7 +266,61 @@ func (ctx *Context) GetBigMapByKeyHash(c *gin.Context) {
 		return
 	}
 
-	bm, total, err := ctx.BigMapDiffs.GetByPtrAndKeyHash(req.Ptr, types.NewNetwork(req.Network), req.KeyHash, pageReq.Size, pageReq.Offset)
+	ctx.writeBigMapItem(c, types.NewNetwork(req.Network), req.Ptr, req.KeyHash, pageReq)
+}
+
+// GetBigMapByKey godoc
+// @Summary Get big map diffs by pointer and typed key
+// @Description Get big map diffs by pointer and key value. Key is packed and hashed by key type of big map.
+// @Description Key can be set as Michelson or Micheline JSON in `key` field or as JSON schema form data in `data` field.
+// @Tags bigmap
+// @ID get-bigmap-key
+// @Param network path string true "Network"
+// @Param ptr path integer true "Big map pointer"
+// @Param body body getBigMapByKeyRequest true "Request body"
+// @Param offset query integer false "Offset"
+// @Param size query integer false "Requested count" mininum(1) maximum(10)
+// @Accept json
+// @Produce json
+// @Success 200 {object} BigMapDiffByKeyResponse
+// @Success 204 {object} gin.H
+// @Failure 400 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/bigmap/{network}/{ptr}/keys [post]
+func (ctx *Context) GetBigMapByKey(c *gin.Context) {
+	var req getBigMapRequest
+	if err := c.BindUri(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	var pageReq pageableRequest
+	if err := c.BindQuery(&pageReq); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	var reqKey getBigMapByKeyRequest
+	if err := c.BindJSON(&reqKey); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	if err := reqKey.Validate(); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	bigMapType, err := ctx.getBigMapType(req.NetworkID(), req.Ptr)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	keyHash, err := bigMapKeyHash(bigMapType, reqKey)
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	ctx.writeBigMapItem(c, req.NetworkID(), req.Ptr, keyHash, pageReq)
+}
+
+func (ctx *Context) writeBigMapItem(c *gin.Context, network types.Network, ptr int64, keyHash string, pageReq pageableRequest) {
+	bm, total, err := ctx.BigMapDiffs.GetByPtrAndKeyHash(ptr, network, keyHash, pageReq.Size, pageReq.Offset)
 	if ctx.handleError(c, err, 0) {
 		return
 	}
@@ -245,7 +330,7 @@ func (ctx *Context) GetBigMapByKeyHash(c *gin.Context) {
 		return
 	}
 
-	response, err := ctx.prepareBigMapItem(bm, req.KeyHash)
+	response, err := ctx.prepareBigMapItem(bm, keyHash)
 	if ctx.handleError(c, err, 0) {
 		return
 	}
@@ -285,6 +370,140 @@ func (ctx *Context) GetBigMapDiffCount(c *gin.Context) {
 	c.SecureJSON(http.StatusOK, CountResponse{count})
 }
 
+// GetBigMapChanges godoc
+// @Summary Get big map changes between two levels
+// @Description Get keys which were added, removed or updated after the end of `from_level` up to the end of `to_level`. `diff` contains big map changes in Miguel format.
+// @Description Keys touched in the range are ordered by key hash and paginated by `size` and `offset`; `total` is the count of such keys. Keys which got back their initial value are not returned, so a page may contain fewer changes than `size`.
+// @Tags bigmap
+// @ID get-bigmap-changes
+// @Param network path string true "Network"
+// @Param ptr path integer true "Big map pointer"
+// @Param from_level query integer true "Start level (exclusive)" minimum(0)
+// @Param to_level query integer true "End level (inclusive)" minimum(1)
+// @Param size query integer false "Requested count" mininum(1) maximum(10)
+// @Param offset query integer false "Offset" mininum(0)
+// @Accept json
+// @Produce json
+// @Success 200 {object} BigMapChangesResponse
+// @Failure 400 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/bigmap/{network}/{ptr}/changes [get]
+func (ctx *Context) GetBigMapChanges(c *gin.Context) {
+	var req getBigMapRequest
+	if err := c.BindUri(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	var changesReq bigMapChangesRequest
+	if err := c.BindQuery(&changesReq); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	before, after, total, err := ctx.BigMapDiffs.Changes(req.NetworkID(), req.Ptr, changesReq.FromLevel, changesReq.ToLevel, changesReq.Size, changesReq.Offset)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	response := BigMapChangesResponse{
+		Network:   req.Network,
+		Ptr:       req.Ptr,
+		FromLevel: changesReq.FromLevel,
+		ToLevel:   changesReq.ToLevel,
+		Total:     total,
+		Added:     make([]BigMapKeyChange, 0),
+		Removed:   make([]BigMapKeyChange, 0),
+		Updated:   make([]BigMapKeyChange, 0),
+	}
+
+	changes := bigmapdiff.NewKeyChanges(before, after)
+	if len(changes) == 0 {
+		c.SecureJSON(http.StatusOK, response)
+		return
+	}
+
+	bigMapType, err := ctx.getBigMapType(req.NetworkID(), req.Ptr)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	oldBigMap := newBigMapNode(bigMapType, req.Ptr)
+	newBigMap := newBigMapNode(bigMapType, req.Ptr)
+	oldDiffs := make([]*bcdTypes.BigMapDiff, 0)
+	newDiffs := make([]*bcdTypes.BigMapDiff, 0)
+
+	for i := range changes {
+		var oldValue, newValue types.Bytes
+		if changes[i].Before != nil {
+			oldValue = changes[i].Before.Value
+			oldDiffs = append(oldDiffs, &bcdTypes.BigMapDiff{Ptr: req.Ptr, Key: changes[i].Before.Key, Value: oldValue})
+		}
+		if !changes[i].After.Removed {
+			newValue = changes[i].After.Value
+		}
+		newDiffs = append(newDiffs, &bcdTypes.BigMapDiff{Ptr: req.Ptr, Key: changes[i].After.Key, Value: newValue})
+
+		key, oldMiguel, keyString, err := prepareItem(changes[i].After.Key, oldValue, bigMapType)
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		_, newMiguel, _, err := prepareItem(nil, newValue, bigMapType)
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+
+		item := BigMapKeyChange{
+			Key:       key,
+			KeyHash:   changes[i].KeyHash,
+			KeyString: keyString,
+			Level:     changes[i].After.LastUpdateLevel,
+			Timestamp: changes[i].After.LastUpdateTime,
+		}
+		if oldMiguel != nil {
+			item.OldValue = oldMiguel
+		}
+		if newMiguel != nil {
+			item.NewValue = newMiguel
+		}
+
+		switch changes[i].Kind {
+		case bigmapdiff.KeyAdded:
+			response.Added = append(response.Added, item)
+		case bigmapdiff.KeyRemoved:
+			response.Removed = append(response.Removed, item)
+		case bigmapdiff.KeyUpdated:
+			response.Updated = append(response.Updated, item)
+		}
+	}
+
+	if err := oldBigMap.EnrichBigMap(oldDiffs); ctx.handleError(c, err, 0) {
+		return
+	}
+	if err := newBigMap.EnrichBigMap(newDiffs); ctx.handleError(c, err, 0) {
+		return
+	}
+	response.Diff, err = newBigMap.Distinguish(oldBigMap)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	c.SecureJSON(http.StatusOK, response)
+}
+
+const (
+	bigMapScanBatchSize = 1000
+	bigMapScanLimit     = 100000
+
+	lastIDHeader = "X-Last-ID"
+)
+
+func newBigMapNode(bigMapType noderpc.BigMap, ptr int64) *ast.BigMap {
+	node := ast.NewBigMap(0)
+	node.KeyType = ast.Copy(bigMapType.KeyType.Nodes[0])
+	node.ValueType = ast.Copy(bigMapType.ValueType.Nodes[0])
+	node.Ptr = &ptr
+	return node
+}
+
 func (ctx *Context) prepareBigMapKeys(data []bigmapdiff.BigMapState) ([]BigMapResponseItem, error) {
 	if len(data) == 0 {
 		return []BigMapResponseItem{}, nil
@@ -297,24 +516,115 @@ func (ctx *Context) prepareBigMapKeys(data []bigmapdiff.BigMapState) ([]BigMapRe
 
 	res := make([]BigMapResponseItem, len(data))
 	for i := range data {
-		key, value, keyString, err := prepareItem(data[i].Key, data[i].Value, bigMapType)
+		res[i], err = newBigMapResponseItem(data[i], bigMapType)
 		if err != nil {
 			return nil, err
 		}
+	}
+	return res, nil
+}
+
+// filterBigMapKeys - scans keys from the latest one and returns page of keys which values match all predicates.
+// Keys are scanned by id cursor, not more than `bigMapScanLimit` per call. Returned id is the cursor of the next page
+// if scan was stopped before the end of big map, otherwise it's 0.
+func (ctx *Context) filterBigMapKeys(getCtx bigmapdiff.GetContext, predicates []ast.MiguelPredicate) ([]BigMapResponseItem, int64, error) {
+	bigMapType, err := ctx.getBigMapType(getCtx.Network, *getCtx.Ptr)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		res[i] = BigMapResponseItem{
-			Item: BigMapItem{
-				Key:       key,
-				KeyHash:   data[i].KeyHash,
-				KeyString: keyString,
-				Level:     data[i].LastUpdateLevel,
-				Value:     value,
-				Timestamp: data[i].LastUpdateTime,
-			},
-			Count: data[i].Count,
+	size := ctx.pageSize(getCtx.Size)
+	skip := getCtx.Offset
+
+	res := make([]BigMapResponseItem, 0)
+	getCtx.Size = bigMapScanBatchSize
+	getCtx.Offset = 0
+
+	var lastID int64
+	for scanned := 0; scanned < bigMapScanLimit; {
+		states, err := ctx.BigMapDiffs.Keys(getCtx)
+		if err != nil {
+			return nil, 0, err
 		}
+
+		for i := range states {
+			lastID = states[i].ID
+			item, err := newBigMapResponseItem(states[i], bigMapType)
+			if err != nil {
+				return nil, 0, err
+			}
+			value, _ := item.Item.Value.(*ast.MiguelNode)
+			if !matchPredicates(value, predicates) {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			res = append(res, item)
+			if int64(len(res)) == size {
+				return res, lastID, nil
+			}
+		}
+
+		if len(states) < bigMapScanBatchSize {
+			return res, 0, nil
+		}
+		scanned += len(states)
+		cursor := lastID
+		getCtx.LastID = &cursor
 	}
-	return res, nil
+	return res, lastID, nil
+}
+
+func (ctx *Context) pageSize(size int64) int64 {
+	if size == 0 {
+		return int64(ctx.Config.API.PageSize)
+	}
+	return size
+}
+
+func matchPredicates(value *ast.MiguelNode, predicates []ast.MiguelPredicate) bool {
+	if value == nil {
+		return false
+	}
+	for i := range predicates {
+		if !predicates[i].Match(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func newBigMapResponseItem(state bigmapdiff.BigMapState, bigMapType noderpc.BigMap) (BigMapResponseItem, error) {
+	key, value, keyString, err := prepareItem(state.Key, state.Value, bigMapType)
+	if err != nil {
+		return BigMapResponseItem{}, err
+	}
+
+	return BigMapResponseItem{
+		Item: BigMapItem{
+			Key:       key,
+			KeyHash:   state.KeyHash,
+			KeyString: keyString,
+			Level:     state.LastUpdateLevel,
+			Value:     value,
+			Timestamp: state.LastUpdateTime,
+		},
+		Count: state.Count,
+	}, nil
+}
+
+// bigMapKeyHash - computes hash of key set by request using key type of big map
+func bigMapKeyHash(bigMapType noderpc.BigMap, req getBigMapByKeyRequest) (string, error) {
+	keyType := &ast.TypedAst{
+		Nodes: []ast.Node{ast.Copy(bigMapType.KeyType.Nodes[0])},
+	}
+
+	if err := settleValue(keyType, req.Key, req.Data); err != nil {
+		return "", err
+	}
+	return ast.BigMapKeyHashFromNode(keyType.Nodes[0])
 }
 
 func (ctx *Context) prepareBigMapItem(data []bigmapdiff.BigMapDiff, keyHash string) (res BigMapDiffByKeyResponse, err error) {
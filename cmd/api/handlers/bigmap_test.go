@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	mock_bmd "github.com/baking-bad/bcdhub/internal/models/mock/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext_GetBigMapChanges(t *testing.T) {
+	aliceHash := "exprtgHvpVEPbFDDJRZg2JfXSiYjMRu5ck2TKzRxsxhDjGQmrbPBD5"
+	bobHash := "exprvBEwCMaJsvGaWmWVKBjLvymqNGQFY3d4tB8BRk2eEAP5rBQd1j"
+	carolHash := "exprtiRSZkLKYRess9GZ3ryb4cVQD36WLo2oysZBFxKTZ2jXqcHWGj"
+
+	tests := []struct {
+		name           string
+		query          string
+		size           int64
+		offset         int64
+		total          int64
+		before         []bigmapdiff.BigMapState
+		after          []bigmapdiff.BigMapState
+		wantCode       int
+		wantAdded      []string
+		wantRemoved    []string
+		wantUpdated    []string
+		withBigMapType bool
+	}{
+		{
+			name:     "nothing changed",
+			query:    "from_level=10&to_level=20",
+			wantCode: http.StatusOK,
+		}, {
+			name:  "first page",
+			query: "from_level=10&to_level=20&size=2",
+			size:  2,
+			total: 3,
+			before: []bigmapdiff.BigMapState{
+				{KeyHash: bobHash, Key: []byte(`{"string":"bob"}`), Value: []byte(`{"int":"1"}`)},
+			},
+			after: []bigmapdiff.BigMapState{
+				{KeyHash: aliceHash, Key: []byte(`{"string":"alice"}`), Value: []byte(`{"int":"2"}`), LastUpdateLevel: 15},
+				{KeyHash: bobHash, Key: []byte(`{"string":"bob"}`), Removed: true, LastUpdateLevel: 16},
+			},
+			wantCode:       http.StatusOK,
+			wantAdded:      []string{aliceHash},
+			wantRemoved:    []string{bobHash},
+			withBigMapType: true,
+		}, {
+			name:   "next page",
+			query:  "from_level=10&to_level=20&size=2&offset=2",
+			size:   2,
+			offset: 2,
+			total:  3,
+			before: []bigmapdiff.BigMapState{
+				{KeyHash: carolHash, Key: []byte(`{"string":"carol"}`), Value: []byte(`{"int":"1"}`)},
+			},
+			after: []bigmapdiff.BigMapState{
+				{KeyHash: carolHash, Key: []byte(`{"string":"carol"}`), Value: []byte(`{"int":"3"}`), LastUpdateLevel: 17},
+			},
+			wantCode:       http.StatusOK,
+			wantUpdated:    []string{carolHash},
+			withBigMapType: true,
+		}, {
+			name:     "too big size",
+			query:    "from_level=10&to_level=20&size=11",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			diffs := mock_bmd.NewMockRepository(ctrl)
+			rpc := noderpc.NewMockINode(ctrl)
+			ctx := &Context{
+				Context: &config.Context{
+					BigMapDiffs: diffs,
+					RPC: map[types.Network]noderpc.INode{
+						types.Mainnet: rpc,
+					},
+				},
+			}
+
+			if tt.wantCode == http.StatusOK {
+				diffs.EXPECT().Changes(types.Mainnet, int64(10), int64(10), int64(20), tt.size, tt.offset).Return(tt.before, tt.after, tt.total, nil).Times(1)
+			}
+			if tt.withBigMapType {
+				keyType, err := ast.NewTypedAstFromString(`{"prim":"string"}`)
+				require.NoError(t, err)
+				valueType, err := ast.NewTypedAstFromString(`{"prim":"nat"}`)
+				require.NoError(t, err)
+				rpc.EXPECT().GetBigMapType(int64(10), int64(0)).Return(noderpc.BigMap{
+					KeyType:   keyType,
+					ValueType: valueType,
+				}, nil).Times(1)
+			}
+
+			c, w := testRequest(t, http.MethodGet, "/v1/bigmap/mainnet/10/changes?"+tt.query, gin.Params{
+				{Key: "network", Value: types.Mainnet.String()},
+				{Key: "ptr", Value: "10"},
+			})
+			ctx.GetBigMapChanges(c)
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var response BigMapChangesResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.total, response.Total)
+
+			keyHashes := func(changes []BigMapKeyChange) []string {
+				var result []string
+				for i := range changes {
+					result = append(result, changes[i].KeyHash)
+				}
+				return result
+			}
+			assert.Equal(t, tt.wantAdded, keyHashes(response.Added))
+			assert.Equal(t, tt.wantRemoved, keyHashes(response.Removed))
+			assert.Equal(t, tt.wantUpdated, keyHashes(response.Updated))
+		})
+	}
+}
+
+func TestContext_filterBigMapKeys(t *testing.T) {
+	cursor := int64(5000)
+
+	// testStates - returns `count` states with ids decreasing from `from`, only states which ids are in `matched` have value 1
+	testStates := func(from int64, count int, matched ...int64) []bigmapdiff.BigMapState {
+		states := make([]bigmapdiff.BigMapState, count)
+		for i := range states {
+			id := from - int64(i)
+			states[i] = bigmapdiff.BigMapState{
+				ID:              id,
+				KeyHash:         aliceHash,
+				Key:             []byte(`{"string":"alice"}`),
+				Value:           []byte(`{"int":"0"}`),
+				LastUpdateLevel: id,
+			}
+			for _, m := range matched {
+				if m == id {
+					states[i].Value = []byte(`{"int":"1"}`)
+				}
+			}
+		}
+		return states
+	}
+
+	tests := []struct {
+		name       string
+		size       int64
+		offset     int64
+		lastID     *int64
+		batches    [][]bigmapdiff.BigMapState
+		wantIDs    []int64
+		wantLastID int64
+	}{
+		{
+			name: "end of big map",
+			size: 10,
+			batches: [][]bigmapdiff.BigMapState{
+				testStates(10, 10, 9, 3),
+			},
+			wantIDs: []int64{9, 3},
+		}, {
+			name:   "page is filled",
+			size:   2,
+			offset: 1,
+			batches: [][]bigmapdiff.BigMapState{
+				testStates(10, 10, 9, 7, 6, 2),
+			},
+			wantIDs:    []int64{7, 6},
+			wantLastID: 6,
+		}, {
+			name:   "next batch is requested by cursor",
+			size:   2,
+			lastID: &cursor,
+			batches: [][]bigmapdiff.BigMapState{
+				testStates(4000, bigMapScanBatchSize, 3500),
+				testStates(3000, 5, 2998),
+			},
+			wantIDs:    []int64{3500, 2998},
+			wantLastID: 2998,
+		}, {
+			name: "scan limit is reached",
+			size: 1,
+			batches: func() [][]bigmapdiff.BigMapState {
+				batches := make([][]bigmapdiff.BigMapState, 0)
+				for from := int64(bigMapScanLimit); from > 0; from -= bigMapScanBatchSize {
+					batches = append(batches, testStates(from, bigMapScanBatchSize))
+				}
+				return batches
+			}(),
+			wantIDs:    []int64{},
+			wantLastID: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			diffs := mock_bmd.NewMockRepository(ctrl)
+			rpc := noderpc.NewMockINode(ctrl)
+			ctx := &Context{
+				Context: &config.Context{
+					BigMapDiffs: diffs,
+					RPC: map[types.Network]noderpc.INode{
+						types.Mainnet: rpc,
+					},
+				},
+			}
+
+			keyType, err := ast.NewTypedAstFromString(`{"prim":"string"}`)
+			require.NoError(t, err)
+			valueType, err := ast.NewTypedAstFromString(`{"prim":"nat"}`)
+			require.NoError(t, err)
+			rpc.EXPECT().GetBigMapType(int64(10), int64(0)).Return(noderpc.BigMap{
+				KeyType:   keyType,
+				ValueType: valueType,
+			}, nil).Times(1)
+
+			call := 0
+			diffs.EXPECT().Keys(gomock.Any()).DoAndReturn(func(getCtx bigmapdiff.GetContext) ([]bigmapdiff.BigMapState, error) {
+				require.Less(t, call, len(tt.batches))
+				assert.EqualValues(t, bigMapScanBatchSize, getCtx.Size)
+				assert.Zero(t, getCtx.Offset)
+				if call == 0 {
+					assert.Equal(t, tt.lastID, getCtx.LastID)
+				} else {
+					previous := tt.batches[call-1]
+					require.NotNil(t, getCtx.LastID)
+					assert.Equal(t, previous[len(previous)-1].ID, *getCtx.LastID)
+				}
+				call++
+				return tt.batches[call-1], nil
+			}).Times(len(tt.batches))
+
+			predicate, err := ast.ParseMiguelPredicate(":eq:1")
+			require.NoError(t, err)
+
+			ptr := int64(10)
+			items, lastID, err := ctx.filterBigMapKeys(bigmapdiff.GetContext{
+				Network: types.Mainnet,
+				Ptr:     &ptr,
+				Size:    tt.size,
+				Offset:  tt.offset,
+				LastID:  tt.lastID,
+			}, []ast.MiguelPredicate{predicate})
+			require.NoError(t, err)
+
+			// level of test state is equal to its id
+			ids := make([]int64, 0)
+			for i := range items {
+				ids = append(ids, items[i].Item.Level)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, tt.wantLastID, lastID)
+		})
+	}
+}
+
+func TestContext_GetBigMapKeys_LastID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	diffs := mock_bmd.NewMockRepository(ctrl)
+	rpc := noderpc.NewMockINode(ctrl)
+	ctx := &Context{
+		Context: &config.Context{
+			BigMapDiffs: diffs,
+			RPC: map[types.Network]noderpc.INode{
+				types.Mainnet: rpc,
+			},
+		},
+	}
+
+	keyType, err := ast.NewTypedAstFromString(`{"prim":"string"}`)
+	require.NoError(t, err)
+	valueType, err := ast.NewTypedAstFromString(`{"prim":"nat"}`)
+	require.NoError(t, err)
+	rpc.EXPECT().GetBigMapType(int64(10), int64(0)).Return(noderpc.BigMap{
+		KeyType:   keyType,
+		ValueType: valueType,
+	}, nil).Times(1)
+	diffs.EXPECT().Keys(gomock.Any()).DoAndReturn(func(getCtx bigmapdiff.GetContext) ([]bigmapdiff.BigMapState, error) {
+		require.NotNil(t, getCtx.LastID)
+		assert.Equal(t, int64(100), *getCtx.LastID)
+		return []bigmapdiff.BigMapState{
+			{ID: 42, KeyHash: aliceHash, Key: []byte(`{"string":"alice"}`), Value: []byte(`{"int":"1"}`)},
+			{ID: 41, KeyHash: bobHash, Key: []byte(`{"string":"bob"}`), Value: []byte(`{"int":"1"}`)},
+		}, nil
+	}).Times(1)
+
+	c, w := testRequest(t, http.MethodGet, "/v1/bigmap/mainnet/10/keys?size=1&last_id=100&value=:eq:1", gin.Params{
+		{Key: "network", Value: types.Mainnet.String()},
+		{Key: "ptr", Value: "10"},
+	})
+	ctx.GetBigMapKeys(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "42", w.Header().Get(lastIDHeader))
+}
@@ -3,20 +3,39 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/gin-gonic/gin"
 )
 
 // GetConfig -
 func (ctx *Context) GetConfig(c *gin.Context) {
+	networks := make([]string, 0, len(ctx.Config.API.Networks))
+	rpcEndpoints := make(map[string]string)
 	tzktEndpoints := make(map[string]string)
-	for network, tzkt := range ctx.Config.TzKT {
-		tzktEndpoints[network] = tzkt.BaseURI
-		break
+
+	for network, uri := range ctx.Config.API.Frontend.RPC {
+		rpcEndpoints[network] = uri
+	}
+
+	for _, network := range ctx.Config.API.Networks {
+		if types.NewNetwork(network) == types.Empty {
+			continue
+		}
+		networks = append(networks, network)
+
+		if _, ok := rpcEndpoints[network]; !ok {
+			if networkCfg, ok := ctx.Config.Networks[network]; ok && networkCfg.RPC.URI != "" {
+				rpcEndpoints[network] = networkCfg.RPC.URI
+			}
+		}
+		if tzkt, ok := ctx.Config.TzKT[network]; ok && tzkt.BaseURI != "" {
+			tzktEndpoints[network] = tzkt.BaseURI
+		}
 	}
 
 	cfg := ConfigResponse{
-		Networks:       ctx.Config.API.Networks,
-		RPCEndpoints:   ctx.Config.API.Frontend.RPC,
+		Networks:       networks,
+		RPCEndpoints:   rpcEndpoints,
 		TzKTEndpoints:  tzktEndpoints,
 		GaEnabled:      ctx.Config.API.Frontend.GaEnabled,
 		MempoolEnabled: ctx.Config.API.Frontend.MempoolEnabled,
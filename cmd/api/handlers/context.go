@@ -10,20 +10,36 @@ var json = jsoniter.ConfigCompatibleWithStandardLibrary
 // Context -
 type Context struct {
 	*config.Context
+
+	Hub *Hub
 }
 
 // NewContext -
 func NewContext(cfg config.Config) (*Context, error) {
-	ctx := config.NewContext(
+	ctx, err := config.NewContext(
 		config.WithStorage(cfg.Storage, cfg.API.ProjectName, int64(cfg.API.PageSize), cfg.API.Connections.Open, cfg.API.Connections.Idle),
+		config.WithNetworks(cfg.Networks),
 		config.WithRPC(cfg.RPC),
 		config.WithSearch(cfg.Storage),
-		config.WithMempool(cfg.Services),
+		config.WithMempool(cfg.Services, cfg.RPC),
 		config.WithLoadErrorDescriptions(),
 		config.WithConfigCopy(cfg),
 	)
+	if err != nil {
+		return nil, err
+	}
 
-	return &Context{
+	handlersCtx := &Context{
 		Context: ctx,
-	}, nil
+	}
+	handlersCtx.Hub = NewHub(handlersCtx)
+	handlersCtx.Hub.Start()
+
+	return handlersCtx, nil
+}
+
+// Close -
+func (ctx *Context) Close() {
+	ctx.Hub.Close()
+	ctx.Context.Close()
 }
@@ -39,25 +39,11 @@ func (ctx *Context) GetEntrypoints(c *gin.Context) {
 		return
 	}
 
-	entrypoints, err := parameter.GetEntrypointsDocs()
+	resp, err := getEntrypointSchemas(parameter)
 	if ctx.handleError(c, err, 0) {
 		return
 	}
 
-	resp := make([]EntrypointSchema, len(entrypoints))
-	for i, entrypoint := range entrypoints {
-		resp[i].EntrypointType = entrypoint
-		e := parameter.FindByName(entrypoint.Name, true)
-		if e == nil {
-			continue
-		}
-		resp[i].Schema, err = e.ToJSONSchema()
-		if ctx.handleError(c, err, 0) {
-			return
-		}
-		resp[i].Schema = ast.WrapEntrypointJSONSchema(resp[i].Schema)
-	}
-
 	c.SecureJSON(http.StatusOK, resp)
 }
 
@@ -198,3 +184,25 @@ func (ctx *Context) buildParametersForExecution(network modelTypes.Network, addr
 	}
 	return parameterType.ParametersForExecution(entrypoint, data)
 }
+
+func getEntrypointSchemas(parameter *ast.TypedAst) ([]EntrypointSchema, error) {
+	entrypoints, err := parameter.GetEntrypointsDocs()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]EntrypointSchema, len(entrypoints))
+	for i, entrypoint := range entrypoints {
+		resp[i].EntrypointType = entrypoint
+		e := parameter.FindByName(entrypoint.Name, true)
+		if e == nil {
+			continue
+		}
+		resp[i].Schema, err = e.ToJSONSchema()
+		if err != nil {
+			return nil, err
+		}
+		resp[i].Schema = ast.WrapEntrypointJSONSchema(resp[i].Schema)
+	}
+	return resp, nil
+}
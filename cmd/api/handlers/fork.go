@@ -44,16 +44,7 @@ func (ctx *Context) buildStorageDataFromForkRequest(req forkRequest) (*ForkRespo
 		return nil, err
 	}
 
-	if storageType.Nodes[0].IsPrim(consts.PAIR) {
-		req.Storage = map[string]interface{}{
-			storageType.Nodes[0].GetName(): req.Storage,
-		}
-	}
-	if err = storageType.FromJSONSchema(req.Storage); err != nil {
-		return nil, err
-	}
-
-	storage, err := storageType.ToParameters("")
+	storage, err := buildStorageFromSchema(storageType, req.Storage)
 	if err != nil {
 		return nil, err
 	}
@@ -63,3 +54,33 @@ func (ctx *Context) buildStorageDataFromForkRequest(req forkRequest) (*ForkRespo
 		Storage: storage,
 	}, nil
 }
+
+// buildStorageFromSchema - builds Micheline storage from JSON schema form data
+func buildStorageFromSchema(storageType *ast.TypedAst, data map[string]interface{}) ([]byte, error) {
+	if err := settleFromJSONSchema(storageType, data); err != nil {
+		return nil, err
+	}
+	return storageType.ToParameters("")
+}
+
+// settleFromJSONSchema - sets values of tree from JSON schema form data
+func settleFromJSONSchema(tree *ast.TypedAst, data map[string]interface{}) error {
+	if tree.Nodes[0].IsPrim(consts.PAIR) {
+		data = map[string]interface{}{
+			tree.Nodes[0].GetName(): data,
+		}
+	}
+	return tree.FromJSONSchema(data)
+}
+
+// settleValue - sets values of tree from Michelson or Micheline `value` if it's set, otherwise from JSON schema form data
+func settleValue(tree *ast.TypedAst, value string, data map[string]interface{}) error {
+	if value == "" {
+		return settleFromJSONSchema(tree, data)
+	}
+	micheline, err := michelineFromString(value)
+	if err != nil {
+		return err
+	}
+	return tree.SettleFromBytes(micheline)
+}
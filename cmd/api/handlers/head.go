@@ -6,6 +6,7 @@ import (
 
 	"github.com/baking-bad/bcdhub/internal/models/block"
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
 	"github.com/gin-gonic/gin"
 )
 
@@ -71,3 +72,32 @@ func (ctx *Context) GetHead(c *gin.Context) {
 
 	c.SecureJSON(http.StatusOK, body)
 }
+
+// GetRPCStatus godoc
+// @Summary Show RPC nodes status
+// @Description Get head level, latency and availability of RPC nodes for each network
+// @Tags head
+// @ID get-rpc-status
+// @Accept json
+// @Produce json
+// @Success 200 {array} RPCStatusResponse
+// @Router /v1/rpc/status [get]
+func (ctx *Context) GetRPCStatus(c *gin.Context) {
+	body := make([]RPCStatusResponse, 0, len(ctx.Config.API.Networks))
+	for _, name := range ctx.Config.API.Networks {
+		network := types.NewNetwork(name)
+		rpc, ok := ctx.RPC[network]
+		if !ok {
+			continue
+		}
+		pool, ok := rpc.(*noderpc.Pool)
+		if !ok {
+			continue
+		}
+		body = append(body, RPCStatusResponse{
+			Network: name,
+			Nodes:   pool.Status(),
+		})
+	}
+	c.SecureJSON(http.StatusOK, body)
+}
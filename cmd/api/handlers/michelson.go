@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/forge"
+	"github.com/baking-bad/bcdhub/internal/bcd/formatter"
+	"github.com/baking-bad/bcdhub/internal/bcd/translator"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+)
+
+// MichelsonToMicheline godoc
+// @Summary Convert Michelson to Micheline
+// @Description Translates Michelson text to Micheline JSON. Macros are expanded. Single top-level expression is returned as is, several expressions are wrapped into sequence.
+// @Tags michelson
+// @ID michelson-to-micheline
+// @Param body body michelsonRequest true "Request body"
+// @Accept json
+// @Produce json
+// @Success 200 {object} MichelineResponse
+// @Failure 400 {object} MichelsonError
+// @Failure 500 {object} Error
+// @Router /v1/michelson/to_micheline [post]
+func (ctx *Context) MichelsonToMicheline(c *gin.Context) {
+	var req michelsonRequest
+	if err := c.BindJSON(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	micheline, err := michelsonToMicheline(req.Michelson)
+	if ctx.handleMichelsonError(c, err) {
+		return
+	}
+	c.SecureJSON(http.StatusOK, MichelineResponse{
+		Micheline: micheline,
+	})
+}
+
+// MichelineToMichelson godoc
+// @Summary Convert Micheline to Michelson
+// @Description Formats Micheline JSON to Michelson text. If `inline` is set result is a single line, otherwise lines are wrapped by `line_size` (88 by default).
+// @Tags michelson
+// @ID micheline-to-michelson
+// @Param body body michelineRequest true "Request body"
+// @Accept json
+// @Produce json
+// @Success 200 {object} MichelsonResponse
+// @Failure 400 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/michelson/to_michelson [post]
+func (ctx *Context) MichelineToMichelson(c *gin.Context) {
+	var req michelineRequest
+	if err := c.BindJSON(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	if !isMichelineJSON(req.Micheline) {
+		ctx.handleError(c, errors.New("micheline should be JSON object or array"), http.StatusBadRequest)
+		return
+	}
+
+	lineSize := req.LineSize
+	if lineSize == 0 {
+		lineSize = formatter.DefLineSize
+	}
+
+	michelson, err := formatter.MichelineToMichelson(gjson.ParseBytes(req.Micheline), req.Inline, lineSize)
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	c.SecureJSON(http.StatusOK, MichelsonResponse{
+		Michelson: michelson,
+	})
+}
+
+// GetMichelsonTypeSchema godoc
+// @Summary Get JSON schema of Michelson type
+// @Description Returns JSON schema and default model of form for type expression. Type can be passed as Michelson or Micheline JSON.
+// @Tags michelson
+// @ID michelson-type-schema
+// @Param body body michelsonTypeRequest true "Request body"
+// @Accept json
+// @Produce json
+// @Success 200 {object} MichelsonTypeSchema
+// @Failure 400 {object} MichelsonError
+// @Failure 500 {object} Error
+// @Router /v1/michelson/schema [post]
+func (ctx *Context) GetMichelsonTypeSchema(c *gin.Context) {
+	var req michelsonTypeRequest
+	if err := c.BindJSON(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	typ, err := michelineFromString(req.Type)
+	if ctx.handleMichelsonError(c, err) {
+		return
+	}
+	tree, err := ast.NewTypedAstFromBytes(typ)
+	if ctx.handleError(c, errors.Wrap(err, "invalid type"), http.StatusBadRequest) {
+		return
+	}
+
+	schema := MichelsonTypeSchema{
+		Type:         typ,
+		DefaultModel: make(ast.JSONModel),
+	}
+	schema.Schema, err = tree.ToJSONSchema()
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	tree.GetJSONModel(schema.DefaultModel)
+
+	c.SecureJSON(http.StatusOK, schema)
+}
+
+// PackMichelsonValue godoc
+// @Summary Pack Michelson value
+// @Description Packs value of type like `PACK` instruction and computes script expression hash of packed bytes which is used as big map key hash.
+// @Description Type and `value` are Michelson or Micheline JSON. `data` is JSON schema form data of type. Exactly one of `value` and `data` has to be set.
+// @Tags michelson
+// @ID michelson-pack
+// @Param body body packRequest true "Request body"
+// @Accept json
+// @Produce json
+// @Success 200 {object} PackResponse
+// @Failure 400 {object} MichelsonError
+// @Failure 500 {object} Error
+// @Router /v1/michelson/pack [post]
+func (ctx *Context) PackMichelsonValue(c *gin.Context) {
+	var req packRequest
+	if err := c.BindJSON(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	if err := req.Validate(); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	tree, err := typedAstFromString(req.Type)
+	if ctx.handleMichelsonError(c, err) {
+		return
+	}
+	if err := settleValue(tree, req.Value, req.Data); ctx.handleMichelsonError(c, errors.Wrap(err, "invalid value")) {
+		return
+	}
+
+	node, err := tree.ToBaseNode(false)
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	value, err := json.Marshal(node)
+	if ctx.handleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+	packed, err := ast.Pack(tree)
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	keyHash, err := ast.BigMapKeyHashFromNode(tree.Nodes[0])
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	c.SecureJSON(http.StatusOK, PackResponse{
+		Value:   value,
+		Packed:  packed,
+		KeyHash: keyHash,
+	})
+}
+
+// UnpackMichelsonValue godoc
+// @Summary Unpack Michelson value
+// @Description Unpacks bytes packed by `PACK` instruction to Micheline. If `type` is set value is also decoded to typed Miguel tree.
+// @Tags michelson
+// @ID michelson-unpack
+// @Param body body unpackRequest true "Request body"
+// @Accept json
+// @Produce json
+// @Success 200 {object} UnpackResponse
+// @Failure 400 {object} MichelsonError
+// @Failure 500 {object} Error
+// @Router /v1/michelson/unpack [post]
+func (ctx *Context) UnpackMichelsonValue(c *gin.Context) {
+	var req unpackRequest
+	if err := c.BindJSON(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	nodes, err := forge.UnpackString(strings.TrimPrefix(req.Bytes, "0x"))
+	if ctx.handleError(c, errors.Wrap(err, "invalid packed bytes"), http.StatusBadRequest) {
+		return
+	}
+	if len(nodes) != 1 {
+		ctx.handleError(c, errors.Errorf("packed bytes should contain single value: got %d", len(nodes)), http.StatusBadRequest)
+		return
+	}
+	value, err := json.Marshal(nodes[0])
+	if ctx.handleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+	response := UnpackResponse{
+		Value: value,
+	}
+
+	if req.Type != "" {
+		tree, err := typedAstFromString(req.Type)
+		if ctx.handleMichelsonError(c, err) {
+			return
+		}
+		if err := tree.SettleFromBytes(value); ctx.handleError(c, errors.Wrap(err, "value does not match type"), http.StatusBadRequest) {
+			return
+		}
+		miguel, err := tree.ToMiguel()
+		if ctx.handleError(c, err, http.StatusBadRequest) {
+			return
+		}
+		if len(miguel) > 0 {
+			response.Miguel = miguel[0]
+		}
+	}
+
+	c.SecureJSON(http.StatusOK, response)
+}
+
+// typedAstFromString - parses type passed as Michelson or Micheline JSON
+func typedAstFromString(typ string) (*ast.TypedAst, error) {
+	data, err := michelineFromString(typ)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := ast.NewTypedAstFromBytes(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid type")
+	}
+	return tree, nil
+}
+
+// handleMichelsonError - responds with position of error if Michelson text is invalid
+func (ctx *Context) handleMichelsonError(c *gin.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	var e *translator.Error
+	if !errors.As(err, &e) {
+		return ctx.handleError(c, err, http.StatusBadRequest)
+	}
+	c.AbortWithStatusJSON(http.StatusBadRequest, MichelsonError{
+		Message: err.Error(),
+		Line:    e.Line,
+		Column:  e.Column,
+	})
+	return true
+}
@@ -35,6 +35,7 @@ import (
 // @Param size query integer false "Expected OPG count" mininum(1)
 // @Param status query string false "Comma-separated operations statuses"
 // @Param entrypoints query string false "Comma-separated called entrypoints list"
+// @Param kind query string false "Comma-separated operations kinds"
 // @Param with_storage_diff query bool false "Include storage diff to operations or not"
 // @Accept  json
 // @Produce  json
@@ -300,6 +301,14 @@ func prepareFilters(req operationsRequest) map[string]interface{} {
 	if req.Entrypoints != "" {
 		filters["entrypoints"] = strings.Split(req.Entrypoints, ",")
 	}
+
+	if req.Kind != "" {
+		kinds := make([]modelTypes.OperationKind, 0)
+		for _, item := range strings.Split(req.Kind, ",") {
+			kinds = append(kinds, modelTypes.NewOperationKind(item))
+		}
+		filters["kind"] = kinds
+	}
 	return filters
 }
 
@@ -330,6 +339,14 @@ func (ctx *Context) prepareOperation(operation operation.Operation, bmd []bigmap
 		return op, err
 	}
 
+	if !operation.IsTransaction() && !operation.IsOrigination() {
+		return op, nil
+	}
+
+	if !bcd.IsContract(op.Destination) {
+		return op, nil
+	}
+
 	script, err := ctx.getScript(operation.Network, op.Destination, proto.SymLink)
 	if err != nil {
 		return op, err
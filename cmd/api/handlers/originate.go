@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"bytes"
+	stdJSON "encoding/json"
+	"net/http"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	astContract "github.com/baking-bad/bcdhub/internal/bcd/contract"
+	"github.com/baking-bad/bcdhub/internal/bcd/translator"
+	"github.com/baking-bad/bcdhub/internal/helpers"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// OriginationDryRun godoc
+// @Summary Dry-run of contract origination
+// @Description Typechecks initial storage against code by node, returns entrypoints, tags and interfaces of contract and global constants which are not registered in the network.
+// @Description If `source` is set and all constants are registered origination is simulated to estimate gas, storage and burn.
+// @Tags contract
+// @ID origination-dry-run
+// @Param network path string true "Network"
+// @Param body body originationDryRunRequest true "Request body"
+// @Accept json
+// @Produce json
+// @Success 200 {object} OriginationDryRunResponse
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/simulate/{network}/origination [post]
+func (ctx *Context) OriginationDryRun(c *gin.Context) {
+	var req getByNetwork
+	if err := c.BindUri(&req); ctx.handleError(c, err, http.StatusNotFound) {
+		return
+	}
+	var reqDryRun originationDryRunRequest
+	if err := c.BindJSON(&reqDryRun); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	if err := reqDryRun.Validate(); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	network := req.NetworkID()
+
+	code, err := michelineFromString(reqDryRun.Code)
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	code, missing, err := ctx.resolveConstants(network, code)
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	response := OriginationDryRunResponse{
+		Code:             code,
+		MissingConstants: missing,
+	}
+	if len(missing) > 0 {
+		c.SecureJSON(http.StatusOK, response)
+		return
+	}
+
+	script, err := ast.NewScript(code)
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	rpc, err := ctx.GetRPC(network)
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	response.Storage, response.StorageTree, err = typecheckStorage(rpc, script, reqDryRun)
+	if err != nil {
+		var e noderpc.InvalidNodeResponse
+		if errors.As(err, &e) {
+			ctx.handleError(c, errors.Wrap(err, "ill-typed storage"), http.StatusBadRequest)
+		} else {
+			ctx.handleError(c, err, http.StatusBadRequest)
+		}
+		return
+	}
+
+	parameter, err := script.ParameterType()
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	response.Entrypoints, err = getEntrypointSchemas(parameter)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+	response.Interfaces = ast.FindContractInterfaces(parameter)
+
+	response.Tags, err = originationTags(code, response.Storage)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	if reqDryRun.Source != "" {
+		response.Estimate, err = ctx.estimateOrigination(rpc, network, reqDryRun, code, response.Storage)
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+	}
+
+	c.SecureJSON(http.StatusOK, response)
+}
+
+// resolveConstants - replaces global constants registered in network by their values. Values may contain constants too,
+// so they are loaded and replaced recursively. Returns addresses of unregistered constants found at any depth.
+func (ctx *Context) resolveConstants(network types.Network, code []byte) ([]byte, []string, error) {
+	// code is substituted on the tree, so it doesn't depend on formatting of request
+	var tree base.Node
+	if err := json.Unmarshal(code, &tree); err != nil {
+		return nil, nil, err
+	}
+
+	queue := findConstants(&tree, nil)
+	if len(queue) == 0 {
+		return code, nil, nil
+	}
+
+	values := make(map[string]*base.Node)
+	missing := make([]string, 0)
+	for len(queue) > 0 {
+		globalConstants, err := ctx.GlobalConstants.All(network, queue...)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range globalConstants {
+			var value base.Node
+			if err := json.Unmarshal(globalConstants[i].Value, &value); err != nil {
+				return nil, nil, errors.Wrap(err, globalConstants[i].Address)
+			}
+			values[globalConstants[i].Address] = &value
+		}
+
+		var nested []string
+		for _, address := range queue {
+			value, ok := values[address]
+			if !ok {
+				missing = append(missing, address)
+				continue
+			}
+			nested = findConstants(value, nested)
+		}
+
+		queue = make([]string, 0, len(nested))
+		for _, address := range nested {
+			if _, ok := values[address]; ok || helpers.StringInArray(address, missing) {
+				continue
+			}
+			queue = append(queue, address)
+		}
+	}
+
+	resolver := constantsResolver{
+		values:    values,
+		resolved:  make(map[string]*base.Node),
+		resolving: make(map[string]struct{}),
+	}
+	root, err := resolver.resolve(&tree)
+	if err != nil {
+		return nil, nil, err
+	}
+	if code, err = json.Marshal(root); err != nil {
+		return nil, nil, err
+	}
+	return code, missing, nil
+}
+
+// findConstants - appends addresses of `constant` primitives of node which aren't in `addresses` yet
+func findConstants(node *base.Node, addresses []string) []string {
+	if address, ok := constantAddress(node); ok {
+		if !helpers.StringInArray(address, addresses) {
+			addresses = append(addresses, address)
+		}
+		return addresses
+	}
+	for i := range node.Args {
+		addresses = findConstants(node.Args[i], addresses)
+	}
+	return addresses
+}
+
+// constantAddress - returns address of constant if node is `constant` primitive
+func constantAddress(node *base.Node) (string, bool) {
+	if node.Prim != consts.CONSTANT || len(node.Args) != 1 || node.Args[0].StringValue == nil {
+		return "", false
+	}
+	return *node.Args[0].StringValue, true
+}
+
+// constantsResolver - replaces registered constants by their values which constants are replaced too
+type constantsResolver struct {
+	values    map[string]*base.Node
+	resolved  map[string]*base.Node
+	resolving map[string]struct{}
+}
+
+// resolve - returns node where registered constants are replaced. Unregistered ones are left as is.
+func (r *constantsResolver) resolve(node *base.Node) (*base.Node, error) {
+	if address, ok := constantAddress(node); ok {
+		if _, registered := r.values[address]; !registered {
+			return node, nil
+		}
+		return r.resolveConstant(address)
+	}
+	for i := range node.Args {
+		arg, err := r.resolve(node.Args[i])
+		if err != nil {
+			return nil, err
+		}
+		node.Args[i] = arg
+	}
+	return node, nil
+}
+
+func (r *constantsResolver) resolveConstant(address string) (*base.Node, error) {
+	if value, ok := r.resolved[address]; ok {
+		return value, nil
+	}
+	if _, ok := r.resolving[address]; ok {
+		return nil, errors.Errorf("global constant %s refers to itself", address)
+	}
+
+	r.resolving[address] = struct{}{}
+	value, err := r.resolve(r.values[address])
+	delete(r.resolving, address)
+	if err != nil {
+		return nil, errors.Wrap(err, address)
+	}
+
+	r.resolved[address] = value
+	return value, nil
+}
+
+func (ctx *Context) estimateOrigination(rpc noderpc.INode, network types.Network, req originationDryRunRequest, code, storage []byte) (*SimulatedOperation, error) {
+	state, err := ctx.Cache.CurrentBlock(network)
+	if err != nil {
+		return nil, err
+	}
+	counter, err := rpc.GetCounter(req.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	script, err := json.Marshal(map[string]stdJSON.RawMessage{
+		"code":    code,
+		"storage": storage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := rpc.RunOperationGroup(state.ChainID, state.Hash, []noderpc.RunOperationContent{
+		{
+			Kind:         consts.Origination,
+			Source:       req.Source,
+			Counter:      counter + 1,
+			GasLimit:     state.Protocol.Constants.HardGasLimitPerOperation,
+			StorageLimit: state.Protocol.Constants.HardStorageLimitPerOperation,
+			Balance:      &req.Balance,
+			Script:       script,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Contents) == 0 {
+		return nil, errors.New("empty simulation result")
+	}
+
+	op, err := newSimulation(ctx, network, state).prepareContent(response.Contents[0], 0)
+	if err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
+
+// typecheckStorage - builds storage from form data or raw value and typechecks it by node
+func typecheckStorage(rpc noderpc.INode, script *ast.Script, req originationDryRunRequest) ([]byte, *ast.MiguelNode, error) {
+	storageType, err := script.StorageType()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var storage []byte
+	if req.RawStorage != "" {
+		storage, err = michelineFromString(req.RawStorage)
+	} else {
+		storage, err = buildStorageFromSchema(storageType, req.Storage)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(script.Storage) == 0 {
+		return nil, nil, errors.New("empty storage type")
+	}
+	typ, err := json.Marshal(script.Storage[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := rpc.TypeCheckData(storage, typ); err != nil {
+		return nil, nil, err
+	}
+
+	storageType, err = script.StorageType()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := storageType.SettleFromBytes(storage); err != nil {
+		return nil, nil, err
+	}
+	tree, err := storageType.ToMiguel()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tree) == 0 {
+		return storage, nil, nil
+	}
+	return storage, tree[0], nil
+}
+
+func originationTags(code, storage []byte) ([]string, error) {
+	data, err := json.Marshal(map[string]stdJSON.RawMessage{
+		"code":    code,
+		"storage": storage,
+	})
+	if err != nil {
+		return nil, err
+	}
+	parser, err := astContract.NewParser(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := parser.Parse(); err != nil {
+		return nil, err
+	}
+	tags := types.NewTags(parser.Tags.Values())
+	if parser.IsUpgradable() {
+		tags.Set(types.UpgradableTag)
+	}
+	return tags.ToArray(), nil
+}
+
+// michelineFromString - returns value as is if it's Micheline JSON, otherwise translates it from Michelson
+func michelineFromString(value string) ([]byte, error) {
+	trimmed := bytes.TrimSpace([]byte(value))
+	if len(trimmed) == 0 {
+		return nil, errors.New("empty value")
+	}
+	if isMichelineJSON(trimmed) {
+		return trimmed, nil
+	}
+	return michelsonToMicheline(value)
+}
+
+// michelsonToMicheline - translates Michelson to Micheline JSON. Single top-level expression is unwrapped from sequence.
+func michelsonToMicheline(value string) ([]byte, error) {
+	converter, err := translator.NewConverter()
+	if err != nil {
+		return nil, err
+	}
+	result, err := converter.FromString(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid Michelson")
+	}
+
+	// converter wraps top-level expressions into sequence
+	var items []stdJSON.RawMessage
+	if err := json.Unmarshal([]byte(result), &items); err != nil {
+		return nil, err
+	}
+	if len(items) == 1 {
+		return items[0], nil
+	}
+	return []byte(result), nil
+}
+
+func isMichelineJSON(data []byte) bool {
+	if !stdJSON.Valid(data) {
+		return false
+	}
+	switch data[0] {
+	case '[':
+		return true
+	case '{':
+		var obj map[string]stdJSON.RawMessage
+		return json.Unmarshal(data, &obj) == nil && len(obj) > 0
+	default:
+		return false
+	}
+}
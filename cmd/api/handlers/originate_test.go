@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/global_constant"
+	mock_gc "github.com/baking-bad/bcdhub/internal/models/mock/global_constant"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext_resolveConstants(t *testing.T) {
+	const (
+		registered   = "expru54tk2k4E81xQy63P6x3RijnTz51s2m7BV7pr3fDQH8YDqiYvR"
+		unregistered = "exprtxjJ7S4NgGkA2s5K5ULqRW8pqF2gCGXMG2tAG6sRBq8HGzXcxJ"
+		storageType  = "exprv3MnhXvjthGzZ7jDtXRRFremZyey9rsGtL7JRkeaQX1fThN7WF"
+		nested       = "exprtiRSZkLKYRess9GZ3ryb4cVQD36WLo2oysZBFxKTZ2jXqcHWGj"
+		nestedOuter  = "exprvBEwCMaJsvGaWmWVKBjLvymqNGQFY3d4tB8BRk2eEAP5rBQd1j"
+		nestedBroken = "exprtgHvpVEPbFDDJRZg2JfXSiYjMRu5ck2TKzRxsxhDjGQmrbPBD5"
+		cyclic       = "expruMzy3tGuYExkMBsHYcpNnnKrQqvhb9kz4mjB2h9EtPWYqePsqX"
+	)
+
+	constant := func(address string) string {
+		return `{"prim":"constant","args":[{"string":"` + address + `"}]}`
+	}
+	registry := map[string]string{
+		registered:   `[{"prim":"PUSH","args":[{"prim":"int"},{"int":"10"}]},{"prim":"SWAP"},{"prim":"MUL"}]`,
+		storageType:  `{"prim":"int"}`,
+		nested:       `[{"prim":"DUP"},` + constant(registered) + `]`,
+		nestedOuter:  `[{"prim":"DROP"},` + constant(nested) + `]`,
+		nestedBroken: `[{"prim":"DROP"},` + constant(unregistered) + `]`,
+		cyclic:       `[{"prim":"DROP"},` + constant(cyclic) + `]`,
+	}
+	multiply := `[{"prim":"PUSH","args":[{"prim":"int"},{"int":"10"}]},{"prim":"SWAP"},{"prim":"MUL"}]`
+
+	tests := []struct {
+		name        string
+		code        string
+		calls       [][]string
+		want        string
+		wantMissing []string
+		wantErr     bool
+	}{
+		{
+			name: "without constants",
+			code: `[{"prim":"parameter","args":[{"prim":"unit"}]}]`,
+			want: `[{"prim":"parameter","args":[{"prim":"unit"}]}]`,
+		}, {
+			name:  "compact code",
+			code:  `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"int"}]},{"prim":"code","args":[[{"prim":"CDR"},` + constant(registered) + `,{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+			calls: [][]string{{registered}},
+			want:  `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"int"}]},{"prim":"code","args":[[{"prim":"CDR"},` + multiply + `,{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+		}, {
+			name: "formatted code",
+			code: `[
+				{ "prim": "parameter", "args": [ { "prim": "unit" } ] },
+				{ "prim": "storage", "args": [ { "prim": "constant", "args": [ { "string": "` + storageType + `" } ] } ] },
+				{ "prim": "code", "args": [ [
+					{ "prim": "CDR" },
+					{ "args": [ { "string": "` + registered + `" } ], "prim": "constant" },
+					{ "prim": "NIL", "args": [ { "prim": "operation" } ] },
+					{ "prim": "PAIR" }
+				] ] }
+			]`,
+			calls: [][]string{{storageType, registered}},
+			want:  `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"int"}]},{"prim":"code","args":[[{"prim":"CDR"},` + multiply + `,{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`,
+		}, {
+			name:        "unregistered constant",
+			code:        `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"int"}]},{"prim":"code","args":[[` + constant(unregistered) + `]]}]`,
+			calls:       [][]string{{unregistered}},
+			want:        `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"int"}]},{"prim":"code","args":[[` + constant(unregistered) + `]]}]`,
+			wantMissing: []string{unregistered},
+		}, {
+			name:  "constants in values of constants",
+			code:  `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"int"}]},{"prim":"code","args":[[{"prim":"CDR"},` + constant(nestedOuter) + `,` + constant(registered) + `]]}]`,
+			calls: [][]string{{nestedOuter, registered}, {nested}},
+			want:  `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"int"}]},{"prim":"code","args":[[{"prim":"CDR"},[{"prim":"DROP"},[{"prim":"DUP"},` + multiply + `]],` + multiply + `]]}]`,
+		}, {
+			name:        "unregistered constant in value of constant",
+			code:        `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"int"}]},{"prim":"code","args":[[` + constant(nestedBroken) + `]]}]`,
+			calls:       [][]string{{nestedBroken}, {unregistered}},
+			want:        `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"int"}]},{"prim":"code","args":[[[{"prim":"DROP"},` + constant(unregistered) + `]]]}]`,
+			wantMissing: []string{unregistered},
+		}, {
+			name:    "cyclic constant",
+			code:    `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"int"}]},{"prim":"code","args":[[` + constant(cyclic) + `]]}]`,
+			calls:   [][]string{{cyclic}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			constants := mock_gc.NewMockRepository(ctrl)
+			ctx := &Context{
+				Context: &config.Context{
+					GlobalConstants: constants,
+				},
+			}
+
+			call := 0
+			constants.EXPECT().All(types.Mainnet, gomock.Any()).DoAndReturn(func(network types.Network, addresses ...string) ([]global_constant.GlobalConstant, error) {
+				require.Less(t, call, len(tt.calls))
+				assert.ElementsMatch(t, tt.calls[call], addresses)
+				call++
+
+				result := make([]global_constant.GlobalConstant, 0)
+				for _, address := range addresses {
+					if value, ok := registry[address]; ok {
+						result = append(result, global_constant.GlobalConstant{
+							Address: address,
+							Value:   []byte(value),
+						})
+					}
+				}
+				return result, nil
+			}).Times(len(tt.calls))
+
+			code, missing, err := ctx.resolveConstants(types.Mainnet, []byte(tt.code))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(code))
+			assert.ElementsMatch(t, tt.wantMissing, missing)
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	stdJSON "encoding/json"
 	"strings"
 
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/pkg/errors"
 )
 
 type getContractRequest struct {
@@ -55,6 +60,16 @@ func (req getBigMapRequest) NetworkID() types.Network {
 	return types.NewNetwork(req.Network)
 }
 
+type getSaplingStateRequest struct {
+	Network string `uri:"network" binding:"required,network"`
+	Ptr     int64  `uri:"ptr" binding:"min=0"`
+}
+
+// NetworkID -
+func (req getSaplingStateRequest) NetworkID() types.Network {
+	return types.NewNetwork(req.Network)
+}
+
 type getBigMapByKeyHashRequest struct {
 	Network string `uri:"network" binding:"required,network"`
 	Ptr     int64  `uri:"ptr" binding:"min=0"`
@@ -78,6 +93,7 @@ type operationsRequest struct {
 	To              uint   `form:"to" binding:"omitempty,gtfield=From"`
 	Size            uint64 `form:"size" binding:"min=0"`
 	Status          string `form:"status" binding:"omitempty,status"`
+	Kind            string `form:"kind" binding:"omitempty,operation_kind"`
 	Entrypoints     string `form:"entrypoints" binding:"omitempty,excludesall=\"'"`
 	WithStorageDiff bool   `form:"with_storage_diff"`
 }
@@ -141,9 +157,49 @@ func (req getTokensByVersion) NetworkID() types.Network {
 
 type bigMapSearchRequest struct {
 	pageableRequest
-	Search   string `form:"q"`
-	MaxLevel *int64 `form:"max_level,omitempty" binding:"omitempty,gt_int64_ptr=MinLevel"`
-	MinLevel *int64 `form:"min_level,omitempty" binding:"omitempty"`
+	Search   string   `form:"q"`
+	MaxLevel *int64   `form:"max_level,omitempty" binding:"omitempty,gt_int64_ptr=MinLevel"`
+	MinLevel *int64   `form:"min_level,omitempty" binding:"omitempty"`
+	AtLevel  *int64   `form:"at_level,omitempty" binding:"omitempty,gte=1"`
+	LastID   *int64   `form:"last_id,omitempty" binding:"omitempty,gte=1"`
+	Values   []string `form:"value,omitempty" binding:"max=10"`
+}
+
+// Predicates - parses predicates on value
+func (req bigMapSearchRequest) Predicates() ([]ast.MiguelPredicate, error) {
+	predicates := make([]ast.MiguelPredicate, 0, len(req.Values))
+	for i := range req.Values {
+		predicate, err := ast.ParseMiguelPredicate(req.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, predicate)
+	}
+	return predicates, nil
+}
+
+// getBigMapByKeyRequest - `key` is Michelson or Micheline JSON, `data` is JSON schema form data of key type
+type getBigMapByKeyRequest struct {
+	Key  string                 `json:"key,omitempty"`
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// Validate - checks that exactly one of key representations is set
+func (req getBigMapByKeyRequest) Validate() error {
+	switch {
+	case req.Key == "" && req.Data == nil:
+		return errors.New("key or data is required")
+	case req.Key != "" && req.Data != nil:
+		return errors.New("only one of key and data can be set")
+	}
+	return nil
+}
+
+type bigMapChangesRequest struct {
+	pageableRequest
+
+	FromLevel int64 `form:"from_level" binding:"min=0"`
+	ToLevel   int64 `form:"to_level" binding:"required,gtfield=FromLevel"`
 }
 
 type opgRequest struct {
@@ -177,6 +233,68 @@ type runOperationRequest struct {
 	Source string                 `json:"source,omitempty" binding:"omitempty,address"`
 }
 
+type simulateRequest struct {
+	Source     string                      `json:"source" binding:"required,address"`
+	Operations []simulatedOperationRequest `json:"operations" binding:"required,min=1,max=50,dive"`
+}
+
+// Validate - checks fields required by kinds of operations
+func (req simulateRequest) Validate() error {
+	for i, op := range req.Operations {
+		switch op.Kind {
+		case consts.Transaction:
+			if op.Destination == "" {
+				return errors.Errorf("operation %d: destination is required for transaction", i)
+			}
+			if op.Entrypoint != "" && len(op.Parameters) == 0 && !bcd.IsContract(op.Destination) {
+				return errors.Errorf("operation %d: entrypoint is set for implicit account %s", i, op.Destination)
+			}
+		case consts.Origination:
+			if len(op.Code) == 0 || len(op.Storage) == 0 {
+				return errors.Errorf("operation %d: code and storage are required for origination", i)
+			}
+		}
+	}
+	return nil
+}
+
+// simulatedOperationRequest - transaction parameters are built from `entrypoint` and `data` like in `run_operation` or passed as raw Micheline `parameters`.
+// Raw parameters are required to call contract originated by the previous operation of the group. `amount` is balance of originated contract.
+type simulatedOperationRequest struct {
+	Kind         string                 `json:"kind" binding:"required,oneof=transaction origination"`
+	Destination  string                 `json:"destination,omitempty" binding:"omitempty,address"`
+	Amount       int64                  `json:"amount,omitempty" binding:"min=0"`
+	Entrypoint   string                 `json:"entrypoint,omitempty"`
+	Data         map[string]interface{} `json:"data,omitempty"`
+	Parameters   stdJSON.RawMessage     `json:"parameters,omitempty" swaggertype:"object"`
+	Code         stdJSON.RawMessage     `json:"code,omitempty" swaggertype:"array,object"`
+	Storage      stdJSON.RawMessage     `json:"storage,omitempty" swaggertype:"object"`
+	Delegate     string                 `json:"delegate,omitempty" binding:"omitempty,address"`
+	Fee          int64                  `json:"fee,omitempty" binding:"min=0"`
+	GasLimit     int64                  `json:"gas_limit,omitempty" binding:"min=0"`
+	StorageLimit int64                  `json:"storage_limit,omitempty" binding:"min=0"`
+}
+
+// originationDryRunRequest - `code` and `raw_storage` are Michelson or Micheline JSON. `storage` is form data built by storage JSON schema.
+type originationDryRunRequest struct {
+	Code       string                 `json:"code" binding:"required"`
+	Storage    map[string]interface{} `json:"storage,omitempty"`
+	RawStorage string                 `json:"raw_storage,omitempty"`
+	Source     string                 `json:"source,omitempty" binding:"omitempty,address"`
+	Balance    int64                  `json:"balance,omitempty" binding:"min=0"`
+}
+
+// Validate - checks that exactly one of storage representations is set
+func (req originationDryRunRequest) Validate() error {
+	switch {
+	case req.Storage == nil && req.RawStorage == "":
+		return errors.New("storage or raw_storage is required")
+	case req.Storage != nil && req.RawStorage != "":
+		return errors.New("only one of storage and raw_storage can be set")
+	}
+	return nil
+}
+
 type runCodeRequest struct {
 	Data     map[string]interface{} `json:"data" binding:"required"`
 	Name     string                 `json:"name" binding:"required"`
@@ -209,7 +327,8 @@ func (req forkRequest) NetworkID() types.Network {
 }
 
 type storageRequest struct {
-	Level int `form:"level" binding:"omitempty,gte=1"`
+	Level   int   `form:"level" binding:"omitempty,gte=1"`
+	AtLevel int64 `form:"at_level" binding:"omitempty,gte=1,excluded_with=Level"`
 }
 
 // GetTokenStatsRequest -
@@ -258,7 +377,8 @@ type byTokenIDRequest struct {
 type executeViewRequest struct {
 	Data           map[string]interface{} `json:"data" binding:"required"`
 	Name           string                 `json:"name" binding:"required"`
-	Implementation *int                   `json:"implementation" binding:"required"`
+	Implementation *int                   `json:"implementation" binding:"required_unless=Kind on-chain"`
+	Kind           string                 `json:"kind,omitempty" binding:"omitempty,oneof=on-chain off-chain"`
 	Amount         int64                  `json:"amount,omitempty"`
 	GasLimit       int64                  `json:"gas_limit,omitempty"`
 	Source         string                 `json:"source,omitempty" binding:"omitempty,address"`
@@ -307,3 +427,116 @@ type getGlobalConstantRequest struct {
 func (req getGlobalConstantRequest) NetworkID() types.Network {
 	return types.NewNetwork(req.Network)
 }
+
+type subscriptionRequest struct {
+	Action     string `json:"action" binding:"required,oneof=subscribe unsubscribe"`
+	ID         int64  `json:"id" binding:"required_if=Action unsubscribe"`
+	Channel    string `json:"channel" binding:"required_if=Action subscribe,omitempty,oneof=operations big_maps transfers head"`
+	Network    string `json:"network" binding:"required_if=Action subscribe,omitempty,network"`
+	Address    string `json:"address" binding:"omitempty,address"`
+	Entrypoint string `json:"entrypoint"`
+	Ptr        *int64 `json:"ptr" binding:"omitempty,min=0"`
+}
+
+// NetworkID -
+func (req subscriptionRequest) NetworkID() types.Network {
+	return types.NewNetwork(req.Network)
+}
+
+type webhooksListRequest struct {
+	pageableRequest
+	Network string `form:"network" binding:"omitempty,network"`
+	Address string `form:"address" binding:"omitempty,address"`
+}
+
+// NetworkID -
+func (req webhooksListRequest) NetworkID() types.Network {
+	return types.NewNetwork(req.Network)
+}
+
+type getWebhookRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type createWebhookRequest struct {
+	Network   string `json:"network" binding:"required,network" example:"mainnet"`
+	Address   string `json:"address" binding:"required,address" example:"KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9"`
+	URL       string `json:"url" binding:"required,url" example:"https://example.com/hook"`
+	Secret    string `json:"secret" binding:"max=256"`
+	Alias     string `json:"alias" binding:"max=256"`
+	WatchMask uint   `json:"watch_mask" binding:"required,min=1,max=7" example:"7"`
+}
+
+// NetworkID -
+func (req createWebhookRequest) NetworkID() types.Network {
+	return types.NewNetwork(req.Network)
+}
+
+type updateWebhookRequest struct {
+	URL       *string `json:"url" binding:"omitempty,url"`
+	Secret    *string `json:"secret" binding:"omitempty,max=256"`
+	Alias     *string `json:"alias" binding:"omitempty,max=256"`
+	WatchMask *uint   `json:"watch_mask" binding:"omitempty,min=1,max=7"`
+}
+
+type michelsonRequest struct {
+	Michelson string `json:"michelson" binding:"required"`
+}
+
+type michelineRequest struct {
+	Micheline stdJSON.RawMessage `json:"micheline" binding:"required" swaggertype:"object"`
+	Inline    bool               `json:"inline,omitempty"`
+	LineSize  int                `json:"line_size,omitempty" binding:"min=0"`
+}
+
+// michelsonTypeRequest - `type` is Michelson or Micheline JSON
+type michelsonTypeRequest struct {
+	Type string `json:"type" binding:"required" example:"pair (address %owner) (nat %amount)"`
+}
+
+// packRequest - `type` and `value` are Michelson or Micheline JSON, `data` is JSON schema form data of type
+type packRequest struct {
+	Type  string                 `json:"type" binding:"required" example:"pair (address %owner) (nat %amount)"`
+	Value string                 `json:"value,omitempty"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
+// Validate - checks that exactly one of value representations is set
+func (req packRequest) Validate() error {
+	switch {
+	case req.Value == "" && req.Data == nil:
+		return errors.New("value or data is required")
+	case req.Value != "" && req.Data != nil:
+		return errors.New("only one of value and data can be set")
+	}
+	return nil
+}
+
+// unpackRequest - `bytes` is hex string with `05` prefix, `type` is optional Michelson or Micheline JSON
+type unpackRequest struct {
+	Bytes string `json:"bytes" binding:"required" example:"050a00000016000002298c03ed7d454a101eb7022bc95f7e5f41ac78"`
+	Type  string `json:"type,omitempty"`
+}
+
+// unforgeOperationRequest - `bytes` is hex string of forged operation group without signature, `03` watermark is optional
+type unforgeOperationRequest struct {
+	Bytes string `json:"bytes" binding:"required"`
+}
+
+type base58DecodeRequest struct {
+	Value string `form:"value" binding:"required"`
+}
+
+type base58EncodeRequest struct {
+	Hex    string `form:"hex" binding:"required"`
+	Prefix string `form:"prefix" binding:"required"`
+}
+
+type publicKeyRequest struct {
+	PublicKey string `form:"public_key" binding:"required"`
+}
+
+type originatedAddressRequest struct {
+	Hash  string `form:"hash" binding:"required"`
+	Index int32  `form:"index" binding:"min=0"`
+}
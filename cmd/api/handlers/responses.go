@@ -8,6 +8,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/bcd/formatter"
 	"github.com/baking-bad/bcdhub/internal/bcd/tezerrors"
 	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/block"
 	"github.com/baking-bad/bcdhub/internal/models/contract"
 	"github.com/baking-bad/bcdhub/internal/models/contract_metadata"
@@ -15,8 +16,11 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/global_constant"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/saplingdiff"
 	"github.com/baking-bad/bcdhub/internal/models/tokenmetadata"
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/models/webhook"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
 )
 
 // Error -
@@ -324,6 +328,30 @@ type BigMapDiffItem struct {
 	Timestamp time.Time   `json:"timestamp"`
 }
 
+// BigMapKeyChange -
+type BigMapKeyChange struct {
+	Key       interface{} `json:"key"`
+	KeyHash   string      `json:"key_hash"`
+	KeyString string      `json:"key_string"`
+	OldValue  interface{} `json:"old_value,omitempty" extensions:"x-nullable"`
+	NewValue  interface{} `json:"new_value,omitempty" extensions:"x-nullable"`
+	Level     int64       `json:"level"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// BigMapChangesResponse -
+type BigMapChangesResponse struct {
+	Network   string            `json:"network"`
+	Ptr       int64             `json:"ptr"`
+	FromLevel int64             `json:"from_level"`
+	ToLevel   int64             `json:"to_level"`
+	Total     int64             `json:"total"`
+	Added     []BigMapKeyChange `json:"added"`
+	Removed   []BigMapKeyChange `json:"removed"`
+	Updated   []BigMapKeyChange `json:"updated"`
+	Diff      *ast.MiguelNode   `json:"diff,omitempty" extensions:"x-nullable"`
+}
+
 // BigMapDiffByKeyResponse -
 type BigMapDiffByKeyResponse struct {
 	Key     interface{}      `json:"key,omitempty" extensions:"x-nullable"`
@@ -708,9 +736,11 @@ type MetadataResponse struct {
 type ViewSchema struct {
 	Type           []ast.Typedef   `json:"typedef"`
 	Name           string          `json:"name"`
+	Kind           string          `json:"kind"`
 	Implementation int             `json:"implementation"`
 	Description    string          `json:"description"`
 	Schema         *ast.JSONSchema `json:"schema"`
+	ReturnType     []ast.Typedef   `json:"return_type,omitempty" extensions:"x-nullable"`
 	DefaultModel   interface{}     `json:"default_model,omitempty" extensions:"x-nullable"`
 }
 
@@ -720,6 +750,18 @@ type ForkResponse struct {
 	Storage stdJSON.RawMessage `json:"storage"`
 }
 
+// OriginationDryRunResponse -
+type OriginationDryRunResponse struct {
+	Code             stdJSON.RawMessage  `json:"code" swaggertype:"array,object"`
+	Storage          stdJSON.RawMessage  `json:"storage,omitempty" swaggertype:"object"`
+	StorageTree      *ast.MiguelNode     `json:"storage_tree,omitempty" extensions:"x-nullable"`
+	Entrypoints      []EntrypointSchema  `json:"entrypoints,omitempty"`
+	Tags             []string            `json:"tags,omitempty"`
+	Interfaces       []string            `json:"interfaces,omitempty"`
+	MissingConstants []string            `json:"missing_constants,omitempty"`
+	Estimate         *SimulatedOperation `json:"estimate,omitempty" extensions:"x-nullable"`
+}
+
 // TZIPResponse -
 type TZIPResponse struct {
 	Address     string                     `json:"address,omitempty"`
@@ -772,6 +814,12 @@ type HeadResponse struct {
 	FACount         int64     `json:"fa_count"`
 }
 
+// RPCStatusResponse -
+type RPCStatusResponse struct {
+	Network string               `json:"network"`
+	Nodes   []noderpc.NodeStatus `json:"nodes"`
+}
+
 // TokensCountWithMetadata -
 type TokensCountWithMetadata struct {
 	TZIPResponse
@@ -798,3 +846,236 @@ func NewGlobalConstantFromModel(gc global_constant.GlobalConstant) GlobalConstan
 		Value:     stdJSON.RawMessage(gc.Value),
 	}
 }
+
+// SaplingState -
+type SaplingState struct {
+	Network     string `json:"network"`
+	Ptr         int64  `json:"ptr"`
+	Address     string `json:"address"`
+	MemoSize    int64  `json:"memo_size"`
+	Commitments int64  `json:"commitments"`
+	Nullifiers  int64  `json:"nullifiers"`
+}
+
+// SaplingCommitment -
+type SaplingCommitment struct {
+	Commitment string             `json:"commitment"`
+	Ciphertext stdJSON.RawMessage `json:"ciphertext"`
+	Level      int64              `json:"level"`
+	Timestamp  time.Time          `json:"timestamp"`
+}
+
+// NewSaplingCommitmentFromModel -
+func NewSaplingCommitmentFromModel(diff saplingdiff.SaplingDiff) SaplingCommitment {
+	return SaplingCommitment{
+		Commitment: diff.Commitment,
+		Ciphertext: stdJSON.RawMessage(diff.Ciphertext),
+		Level:      diff.Level,
+		Timestamp:  diff.Timestamp.UTC(),
+	}
+}
+
+// SaplingNullifier -
+type SaplingNullifier struct {
+	Nullifier string    `json:"nullifier"`
+	Level     int64     `json:"level"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewSaplingNullifierFromModel -
+func NewSaplingNullifierFromModel(diff saplingdiff.SaplingDiff) SaplingNullifier {
+	return SaplingNullifier{
+		Nullifier: diff.Nullifier,
+		Level:     diff.Level,
+		Timestamp: diff.Timestamp.UTC(),
+	}
+}
+
+// SubscriptionMessage - message which is sent to websocket subscriber
+type SubscriptionMessage struct {
+	Type         string      `json:"type"`
+	Subscription int64       `json:"subscription,omitempty" extensions:"x-nullable"`
+	Network      string      `json:"network,omitempty" extensions:"x-nullable"`
+	Level        int64       `json:"level,omitempty" extensions:"x-nullable"`
+	Data         interface{} `json:"data,omitempty" extensions:"x-nullable"`
+	Error        string      `json:"error,omitempty" extensions:"x-nullable"`
+}
+
+// BlockHead -
+type BlockHead struct {
+	Network   string    `json:"network"`
+	Level     int64     `json:"level"`
+	Hash      string    `json:"hash"`
+	Timestamp time.Time `json:"time"`
+	Protocol  string    `json:"protocol"`
+}
+
+// NewBlockHeadFromModel -
+func NewBlockHeadFromModel(b block.Block) BlockHead {
+	return BlockHead{
+		Network:   b.Network.String(),
+		Level:     b.Level,
+		Hash:      b.Hash,
+		Timestamp: b.Timestamp.UTC(),
+		Protocol:  b.Protocol.Hash,
+	}
+}
+
+// BigMapUpdate - big map key update. `Value` is null if key was removed.
+type BigMapUpdate struct {
+	Network       string             `json:"network"`
+	Ptr           int64              `json:"ptr"`
+	Contract      string             `json:"contract"`
+	KeyHash       string             `json:"key_hash"`
+	Key           stdJSON.RawMessage `json:"key"`
+	Value         stdJSON.RawMessage `json:"value,omitempty" extensions:"x-nullable"`
+	Level         int64              `json:"level"`
+	Timestamp     time.Time          `json:"timestamp"`
+	OperationHash string             `json:"operation_hash,omitempty" extensions:"x-nullable"`
+}
+
+// NewBigMapUpdateFromModel -
+func NewBigMapUpdateFromModel(diff bigmapdiff.BigMapDiff) BigMapUpdate {
+	update := BigMapUpdate{
+		Network:   diff.Network.String(),
+		Ptr:       diff.Ptr,
+		Contract:  diff.Contract,
+		KeyHash:   diff.KeyHash,
+		Key:       stdJSON.RawMessage(diff.Key),
+		Level:     diff.Level,
+		Timestamp: diff.Timestamp.UTC(),
+	}
+	if len(diff.Value) > 0 {
+		update.Value = stdJSON.RawMessage(diff.Value)
+	}
+	return update
+}
+
+// Webhook - subscription of URL to activity of address. `watch_mask` is a sum of flags: 1 - transactions to contract, 2 - token transfers, 4 - big map updates.
+type Webhook struct {
+	ID        int64     `json:"id"`
+	Network   string    `json:"network"`
+	Address   string    `json:"address"`
+	URL       string    `json:"url"`
+	Alias     string    `json:"alias,omitempty" extensions:"x-nullable"`
+	WatchMask uint      `json:"watch_mask"`
+	Signed    bool      `json:"signed"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewWebhookFromModel -
+func NewWebhookFromModel(sub webhook.Subscription) Webhook {
+	return Webhook{
+		ID:        sub.ID,
+		Network:   sub.Network.String(),
+		Address:   sub.Address,
+		URL:       sub.URL,
+		Alias:     sub.Alias,
+		WatchMask: sub.WatchMask,
+		Signed:    sub.Secret != "",
+		CreatedAt: sub.CreatedAt.UTC(),
+	}
+}
+
+// DeadLetter - notification which was not delivered
+type DeadLetter struct {
+	ID        int64              `json:"id"`
+	Event     string             `json:"event"`
+	Payload   stdJSON.RawMessage `json:"payload"`
+	Error     string             `json:"error"`
+	Attempts  int64              `json:"attempts"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// NewDeadLetterFromModel -
+func NewDeadLetterFromModel(letter webhook.DeadLetter) DeadLetter {
+	return DeadLetter{
+		ID:        letter.ID,
+		Event:     letter.Event,
+		Payload:   stdJSON.RawMessage(letter.Payload),
+		Error:     letter.Error,
+		Attempts:  letter.Attempts,
+		CreatedAt: letter.CreatedAt.UTC(),
+	}
+}
+
+// SimulatedOperation - result of simulated operation. Internal operations follow their initiator.
+type SimulatedOperation struct {
+	Operation
+
+	BigMapDiffs []SimulatedBigMapDiff `json:"big_map_diffs,omitempty" extensions:"x-nullable"`
+	Transfers   []Transfer            `json:"transfers,omitempty" extensions:"x-nullable"`
+}
+
+// SimulatedBigMapDiff - big map diff of simulated operation. Key and value are decoded if type of big map is found in contract storage. `value` is null if key was removed.
+type SimulatedBigMapDiff struct {
+	Ptr       int64       `json:"ptr"`
+	KeyHash   string      `json:"key_hash"`
+	Key       interface{} `json:"key"`
+	KeyString string      `json:"key_string,omitempty" extensions:"x-nullable"`
+	Value     interface{} `json:"value,omitempty" extensions:"x-nullable"`
+}
+
+// MichelsonError - error of Michelson parsing. `line` and `column` are 1-based position of error in source text.
+type MichelsonError struct {
+	Message string `json:"message" example:"text"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+// MichelineResponse -
+type MichelineResponse struct {
+	Micheline stdJSON.RawMessage `json:"micheline" swaggertype:"object"`
+}
+
+// MichelsonResponse -
+type MichelsonResponse struct {
+	Michelson string `json:"michelson"`
+}
+
+// MichelsonTypeSchema -
+type MichelsonTypeSchema struct {
+	Type         stdJSON.RawMessage `json:"type" swaggertype:"object"`
+	Schema       *ast.JSONSchema    `json:"schema"`
+	DefaultModel ast.JSONModel      `json:"default_model,omitempty" extensions:"x-nullable"`
+}
+
+// PackResponse - `packed` is hex string with `05` prefix, `key_hash` is script expression hash of packed bytes
+type PackResponse struct {
+	Value   stdJSON.RawMessage `json:"value" swaggertype:"object"`
+	Packed  string             `json:"packed"`
+	KeyHash string             `json:"key_hash" example:"exprv6UsC1sN3Fk2XfgcJCL8NCerP5rCGy1PRESZAqr7L2JdzX55EN"`
+}
+
+// UnpackResponse - `miguel` is set only if type was passed
+type UnpackResponse struct {
+	Value  stdJSON.RawMessage `json:"value" swaggertype:"object"`
+	Miguel *ast.MiguelNode    `json:"miguel,omitempty" extensions:"x-nullable"`
+}
+
+// UnforgedOperationGroup -
+type UnforgedOperationGroup struct {
+	Branch   string              `json:"branch"`
+	Contents []UnforgedOperation `json:"contents"`
+}
+
+// UnforgedOperation - content of unsigned operation group. `script` and `value` are Micheline of origination and global constant registration.
+type UnforgedOperation struct {
+	Operation
+
+	Script stdJSON.RawMessage `json:"script,omitempty" swaggertype:"object" extensions:"x-nullable"`
+	Value  stdJSON.RawMessage `json:"value,omitempty" swaggertype:"object" extensions:"x-nullable"`
+}
+
+// Base58Value - `type` is kind of value detected by its prefix and `hex` is decoded data without prefix
+type Base58Value struct {
+	Value  string `json:"value" example:"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"`
+	Prefix string `json:"prefix" example:"tz1"`
+	Type   string `json:"type" example:"ed25519 public key hash"`
+	Hex    string `json:"hex" example:"02298c03ed7d454a101eb7022bc95f7e5f41ac78"`
+}
+
+// AddressResponse -
+type AddressResponse struct {
+	Address string `json:"address" example:"KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH"`
+}
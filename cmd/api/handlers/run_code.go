@@ -269,7 +269,7 @@ func (ctx *Context) parseBigMapDiffs(response noderpc.RunCodeResponse, script *a
 		return nil, err
 	}
 
-	parser, err := storage.MakeStorageParser(ctx.BigMapDiffs, rpc, operation.Protocol)
+	parser, err := storage.MakeStorageParser(ctx.BigMapDiffs, ctx.SaplingDiffs, rpc, operation.Protocol)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/gin-gonic/gin"
+)
+
+// GetSaplingState godoc
+// @Summary Get sapling state info by pointer
+// @Description Get sapling state info by pointer: memo size, owner and count of commitments and nullifiers
+// @Tags sapling
+// @ID get-sapling-state
+// @Param network path string true "Network"
+// @Param ptr path integer true "Sapling state pointer"
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} SaplingState
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/sapling/{network}/{ptr} [get]
+func (ctx *Context) GetSaplingState(c *gin.Context) {
+	var req getSaplingStateRequest
+	if err := c.BindUri(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	alloc, err := ctx.SaplingDiffs.Alloc(req.NetworkID(), req.Ptr)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	commitments, err := ctx.SaplingDiffs.Count(req.NetworkID(), req.Ptr, types.SaplingDiffKindCommitment)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	nullifiers, err := ctx.SaplingDiffs.Count(req.NetworkID(), req.Ptr, types.SaplingDiffKindNullifier)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	c.SecureJSON(http.StatusOK, SaplingState{
+		Network:     req.Network,
+		Ptr:         req.Ptr,
+		Address:     alloc.Contract,
+		MemoSize:    alloc.MemoSize,
+		Commitments: commitments,
+		Nullifiers:  nullifiers,
+	})
+}
+
+// GetSaplingCommitments godoc
+// @Summary Get sapling state commitments
+// @Description Get commitments and ciphertexts of sapling state in order of appearance
+// @Tags sapling
+// @ID get-sapling-commitments
+// @Param network path string true "Network"
+// @Param ptr path integer true "Sapling state pointer"
+// @Param offset query integer false "Offset"
+// @Param size query integer false "Requested count" mininum(1) maximum(10)
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} SaplingCommitment
+// @Failure 400 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/sapling/{network}/{ptr}/commitments [get]
+func (ctx *Context) GetSaplingCommitments(c *gin.Context) {
+	var req getSaplingStateRequest
+	if err := c.BindUri(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	var page pageableRequest
+	if err := c.BindQuery(&page); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	diffs, err := ctx.SaplingDiffs.Get(req.NetworkID(), req.Ptr, types.SaplingDiffKindCommitment, page.Size, page.Offset)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	response := make([]SaplingCommitment, len(diffs))
+	for i := range diffs {
+		response[i] = NewSaplingCommitmentFromModel(diffs[i])
+	}
+	c.SecureJSON(http.StatusOK, response)
+}
+
+// GetSaplingNullifiers godoc
+// @Summary Get sapling state nullifiers
+// @Description Get nullifiers of sapling state in order of appearance
+// @Tags sapling
+// @ID get-sapling-nullifiers
+// @Param network path string true "Network"
+// @Param ptr path integer true "Sapling state pointer"
+// @Param offset query integer false "Offset"
+// @Param size query integer false "Requested count" mininum(1) maximum(10)
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} SaplingNullifier
+// @Failure 400 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/sapling/{network}/{ptr}/nullifiers [get]
+func (ctx *Context) GetSaplingNullifiers(c *gin.Context) {
+	var req getSaplingStateRequest
+	if err := c.BindUri(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	var page pageableRequest
+	if err := c.BindQuery(&page); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	diffs, err := ctx.SaplingDiffs.Get(req.NetworkID(), req.Ptr, types.SaplingDiffKindNullifier, page.Size, page.Offset)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	response := make([]SaplingNullifier, len(diffs))
+	for i := range diffs {
+		response[i] = NewSaplingNullifierFromModel(diffs[i])
+	}
+	c.SecureJSON(http.StatusOK, response)
+}
@@ -0,0 +1,487 @@
+package handlers
+
+import (
+	stdJSON "encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/tezerrors"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	"github.com/baking-bad/bcdhub/internal/models/domains"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/parsers/operations"
+	"github.com/gin-gonic/gin"
+)
+
+// SimulateOperations godoc
+// @Summary Simulate operation group
+// @Description Simulates ordered list of transactions and originations from one source as one operation group. Every operation is applied to the state changed by the previous ones.
+// @Tags operations
+// @ID simulate-operations
+// @Param network path string true "Network"
+// @Param body body simulateRequest true "Request body"
+// @Accept json
+// @Produce json
+// @Success 200 {array} SimulatedOperation
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/simulate/{network} [post]
+func (ctx *Context) SimulateOperations(c *gin.Context) {
+	var req getByNetwork
+	if err := c.BindUri(&req); ctx.handleError(c, err, http.StatusNotFound) {
+		return
+	}
+	var reqSimulate simulateRequest
+	if err := c.BindJSON(&reqSimulate); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	if err := reqSimulate.Validate(); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	network := req.NetworkID()
+	state, err := ctx.Cache.CurrentBlock(network)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	rpc, err := ctx.GetRPC(network)
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	counter, err := rpc.GetCounter(reqSimulate.Source)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	constants, err := rpc.GetNetworkConstants(state.Level)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+	gasLimit := state.Protocol.Constants.HardGasLimitPerOperation
+	if constants.HardGasLimitPerBlock > 0 {
+		if limit := constants.HardGasLimitPerBlock / int64(len(reqSimulate.Operations)); limit < gasLimit {
+			gasLimit = limit
+		}
+	}
+
+	contents := make([]noderpc.RunOperationContent, len(reqSimulate.Operations))
+	for i := range reqSimulate.Operations {
+		content, err := ctx.buildSimulatedContent(network, state, reqSimulate.Operations[i])
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		content.Source = reqSimulate.Source
+		content.Counter = counter + int64(i) + 1
+		if content.GasLimit == 0 {
+			content.GasLimit = gasLimit
+		}
+		if content.StorageLimit == 0 {
+			content.StorageLimit = state.Protocol.Constants.HardStorageLimitPerOperation
+		}
+		contents[i] = content
+	}
+
+	response, err := rpc.RunOperationGroup(state.ChainID, state.Hash, contents)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	header := noderpc.Header{
+		Level:       state.Level,
+		Protocol:    state.Protocol.Hash,
+		Timestamp:   state.Timestamp,
+		ChainID:     state.ChainID,
+		Hash:        state.Hash,
+		Predecessor: state.Predecessor,
+	}
+
+	parserParams, err := operations.NewParseParams(
+		rpc,
+		ctx.Context,
+		operations.WithConstants(*state.Protocol.Constants),
+		operations.WithHead(header),
+		operations.WithNetwork(network),
+	)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	parsedModels, err := operations.NewGroup(parserParams).Parse(response)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	sim := newSimulation(ctx, network, state)
+	result := make([]SimulatedOperation, 0, len(parsedModels.Operations))
+	for idx := range response.Contents {
+		var found bool
+		for _, parsed := range parsedModels.Operations {
+			if parsed.ContentIndex != int64(idx) {
+				continue
+			}
+			found = true
+
+			op, err := sim.prepare(parsed)
+			if ctx.handleError(c, err, 0) {
+				return
+			}
+			result = append(result, op)
+		}
+		if found {
+			continue
+		}
+
+		op, err := sim.prepareContent(response.Contents[idx], int64(idx))
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		result = append(result, op)
+	}
+
+	c.SecureJSON(http.StatusOK, result)
+}
+
+func (ctx *Context) buildSimulatedContent(network types.Network, state block.Block, req simulatedOperationRequest) (noderpc.RunOperationContent, error) {
+	content := noderpc.RunOperationContent{
+		Kind:         req.Kind,
+		Fee:          req.Fee,
+		GasLimit:     req.GasLimit,
+		StorageLimit: req.StorageLimit,
+		Delegate:     req.Delegate,
+	}
+
+	switch req.Kind {
+	case consts.Transaction:
+		content.Destination = req.Destination
+		content.Amount = &req.Amount
+
+		switch {
+		case len(req.Parameters) > 0:
+			content.Parameters = req.Parameters
+		case req.Entrypoint != "":
+			parameters, err := ctx.buildParametersForExecution(network, req.Destination, state.Protocol.SymLink, req.Entrypoint, req.Data)
+			if err != nil {
+				return content, err
+			}
+			params, err := json.Marshal(parameters)
+			if err != nil {
+				return content, err
+			}
+			content.Parameters = params
+		}
+	case consts.Origination:
+		script, err := json.Marshal(map[string]interface{}{
+			"code":    req.Code,
+			"storage": req.Storage,
+		})
+		if err != nil {
+			return content, err
+		}
+		content.Script = script
+		content.Balance = &req.Amount
+	}
+	return content, nil
+}
+
+type simulatedStorage struct {
+	storage []byte
+	diffs   map[string]bigmapdiff.BigMapDiff
+}
+
+// simulation - prepares results of simulated operations. Storage of contract is compared with its state after the previous operation of the group if the contract was called before.
+type simulation struct {
+	ctx     *Context
+	network types.Network
+	state   block.Block
+
+	storages map[string]*simulatedStorage
+}
+
+func newSimulation(ctx *Context, network types.Network, state block.Block) *simulation {
+	return &simulation{
+		ctx:      ctx,
+		network:  network,
+		state:    state,
+		storages: make(map[string]*simulatedStorage),
+	}
+}
+
+func (sim *simulation) prepare(model *operation.Operation) (SimulatedOperation, error) {
+	var op SimulatedOperation
+	op.FromModel(*model)
+	op.Protocol = sim.state.Protocol.Hash
+	op.SourceAlias = sim.ctx.Cache.Alias(sim.network, op.Source)
+	op.DestinationAlias = sim.ctx.Cache.Alias(sim.network, op.Destination)
+
+	if err := formatErrors(model.Errors, &op.Operation); err != nil {
+		return op, err
+	}
+
+	for _, t := range model.Transfers {
+		transfer := TransferFromModel(domains.Transfer{
+			Transfer: t,
+			Hash:     model.Hash,
+			Counter:  model.Counter,
+			Nonce:    model.Nonce,
+		})
+		transfer.FromAlias = sim.ctx.Cache.Alias(sim.network, transfer.From)
+		transfer.ToAlias = sim.ctx.Cache.Alias(sim.network, transfer.To)
+		op.Transfers = append(op.Transfers, transfer)
+	}
+
+	if !bcd.IsContract(op.Destination) || (!model.IsTransaction() && !model.IsOrigination()) {
+		return op, nil
+	}
+
+	script, err := simulatedScript(model)
+	if err != nil {
+		return op, err
+	}
+
+	if model.IsCall() && !tezerrors.HasParametersError(op.Errors) {
+		if err := setParameters(model.Parameters, script, &op.Operation); err != nil {
+			return op, err
+		}
+	}
+
+	if !model.IsApplied() || len(model.DeffatedStorage) == 0 {
+		return op, nil
+	}
+
+	storageType, err := script.StorageType()
+	if err != nil {
+		return op, err
+	}
+
+	bmd := make([]bigmapdiff.BigMapDiff, len(model.BigMapDiffs))
+	for i := range model.BigMapDiffs {
+		bmd[i] = *model.BigMapDiffs[i]
+	}
+
+	if err := sim.setStorageDiff(model, storageType, bmd, &op); err != nil {
+		return op, err
+	}
+	op.BigMapDiffs, err = simulatedBigMapDiffs(storageType, model.DeffatedStorage, bmd)
+	return op, err
+}
+
+func (sim *simulation) setStorageDiff(model *operation.Operation, storageType *ast.TypedAst, bmd []bigmapdiff.BigMapDiff, op *SimulatedOperation) error {
+	address := model.Destination.Address
+	prev, ok := sim.storages[address]
+
+	switch {
+	case ok:
+		prevBmd, err := sim.previousDiffs(prev, bmd)
+		if err != nil {
+			return err
+		}
+		prevStorage := &ast.TypedAst{
+			Nodes: []ast.Node{ast.Copy(storageType.Nodes[0])},
+		}
+		if err := prepareStorage(prevStorage, prev.storage, prevBmd); err != nil {
+			return err
+		}
+		currentStorage := &ast.TypedAst{
+			Nodes: []ast.Node{ast.Copy(storageType.Nodes[0])},
+		}
+		if err := prepareStorage(currentStorage, model.DeffatedStorage, bmd); err != nil {
+			return err
+		}
+		if currentStorage.IsSettled() {
+			storageDiff, err := currentStorage.Diff(prevStorage)
+			if err != nil {
+				return err
+			}
+			op.StorageDiff = storageDiff
+		}
+	case model.IsOrigination():
+		currentStorage := &ast.TypedAst{
+			Nodes: []ast.Node{ast.Copy(storageType.Nodes[0])},
+		}
+		if err := prepareStorage(currentStorage, model.DeffatedStorage, bmd); err != nil {
+			return err
+		}
+		if currentStorage.IsSettled() {
+			storageDiff, err := currentStorage.Diff(nil)
+			if err != nil {
+				return err
+			}
+			op.StorageDiff = storageDiff
+		}
+	default:
+		if err := sim.ctx.setStorageDiff(address, model.DeffatedStorage, &op.Operation, bmd, storageType); err != nil {
+			return err
+		}
+	}
+
+	if prev == nil {
+		prev = &simulatedStorage{
+			diffs: make(map[string]bigmapdiff.BigMapDiff),
+		}
+	}
+	prev.storage = model.DeffatedStorage
+	for i := range bmd {
+		prev.diffs[simulatedDiffKey(bmd[i])] = bmd[i]
+	}
+	sim.storages[address] = prev
+	return nil
+}
+
+// previousDiffs - returns previous values of changed keys: from the previous operations of group or from database
+func (sim *simulation) previousDiffs(prev *simulatedStorage, bmd []bigmapdiff.BigMapDiff) ([]bigmapdiff.BigMapDiff, error) {
+	result := make([]bigmapdiff.BigMapDiff, 0, len(bmd))
+	missing := make([]bigmapdiff.BigMapDiff, 0)
+	for i := range bmd {
+		if diff, ok := prev.diffs[simulatedDiffKey(bmd[i])]; ok {
+			result = append(result, diff)
+		} else if bmd[i].Ptr >= 0 {
+			missing = append(missing, bmd[i])
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+	stored, err := sim.ctx.BigMapDiffs.Previous(missing)
+	if err != nil {
+		return nil, err
+	}
+	return append(result, stored...), nil
+}
+
+// prepareContent - builds result of operation which is not parsed by indexer parser, e.g. transaction between implicit accounts or origination of script which is not stored yet
+func (sim *simulation) prepareContent(content noderpc.LightOperation, idx int64) (SimulatedOperation, error) {
+	var data noderpc.Operation
+	if err := json.Unmarshal(content.Raw, &data); err != nil {
+		return SimulatedOperation{}, err
+	}
+
+	model := operation.Operation{
+		Network:      sim.network,
+		Level:        sim.state.Level,
+		Timestamp:    sim.state.Timestamp,
+		Kind:         types.NewOperationKind(data.Kind),
+		Source:       account.Account{Address: data.Source},
+		Delegate:     account.Account{Address: data.Delegate},
+		Fee:          data.Fee,
+		Counter:      data.Counter,
+		GasLimit:     data.GasLimit,
+		StorageLimit: data.StorageLimit,
+		ContentIndex: idx,
+	}
+	if data.Amount != nil {
+		model.Amount = *data.Amount
+	}
+	if data.Destination != nil {
+		model.Destination = account.Account{Address: *data.Destination}
+	}
+
+	if result := data.GetResult(); result != nil {
+		model.Status = types.NewOperationStatus(result.Status)
+		model.ConsumedGas = result.ConsumedGas
+		if result.ConsumedMilligas != nil {
+			model.ConsumedGas = *result.ConsumedMilligas / 1000
+		}
+		if result.StorageSize != nil {
+			model.StorageSize = *result.StorageSize
+		}
+		if result.PaidStorageSizeDiff != nil {
+			model.PaidStorageSizeDiff = *result.PaidStorageSizeDiff
+		}
+		if len(result.Originated) > 0 {
+			model.Destination = account.Account{Address: result.Originated[0]}
+		}
+		model.AllocatedDestinationContract = data.Kind == consts.Origination
+		if result.AllocatedDestinationContract != nil {
+			model.AllocatedDestinationContract = *result.AllocatedDestinationContract
+		}
+		errs, err := tezerrors.ParseArray(result.Errors)
+		if err != nil {
+			return SimulatedOperation{}, err
+		}
+		model.Errors = errs
+	}
+	model.SetBurned(*sim.state.Protocol.Constants)
+
+	var op SimulatedOperation
+	op.FromModel(model)
+	op.Protocol = sim.state.Protocol.Hash
+	op.SourceAlias = sim.ctx.Cache.Alias(sim.network, op.Source)
+	op.DestinationAlias = sim.ctx.Cache.Alias(sim.network, op.Destination)
+	err := formatErrors(model.Errors, &op.Operation)
+	return op, err
+}
+
+func simulatedScript(model *operation.Operation) (*ast.Script, error) {
+	if model.AST != nil {
+		return model.AST, nil
+	}
+	var script struct {
+		Code stdJSON.RawMessage `json:"code"`
+	}
+	if err := json.Unmarshal(model.Script, &script); err != nil {
+		return nil, err
+	}
+	return ast.NewScriptWithoutCode(script.Code)
+}
+
+// simulatedBigMapDiffs - decodes keys and values of big map diffs by types of big maps found in storage
+func simulatedBigMapDiffs(storageType *ast.TypedAst, storage []byte, bmd []bigmapdiff.BigMapDiff) ([]SimulatedBigMapDiff, error) {
+	if len(bmd) == 0 {
+		return nil, nil
+	}
+
+	var data ast.UntypedAST
+	if err := json.Unmarshal(storage, &data); err != nil {
+		return nil, err
+	}
+	tree := &ast.TypedAst{
+		Nodes: []ast.Node{ast.Copy(storageType.Nodes[0])},
+	}
+	if err := tree.Settle(data); err != nil {
+		return nil, err
+	}
+	bigMaps := tree.FindBigMapByPtr()
+
+	result := make([]SimulatedBigMapDiff, 0, len(bmd))
+	for i := range bmd {
+		diff := SimulatedBigMapDiff{
+			Ptr:     bmd[i].Ptr,
+			KeyHash: bmd[i].KeyHash,
+			Key:     stdJSON.RawMessage(bmd[i].Key),
+		}
+		if len(bmd[i].Value) > 0 {
+			diff.Value = stdJSON.RawMessage(bmd[i].Value)
+		}
+
+		if bm, ok := bigMaps[bmd[i].Ptr]; ok {
+			key, value, keyString, err := prepareItem(bmd[i].Key, bmd[i].Value, noderpc.BigMap{
+				KeyType:   &ast.TypedAst{Nodes: []ast.Node{bm.KeyType}},
+				ValueType: &ast.TypedAst{Nodes: []ast.Node{bm.ValueType}},
+			})
+			if err != nil {
+				return nil, err
+			}
+			diff.Key = key
+			diff.KeyString = keyString
+			if value != nil {
+				diff.Value = value
+			}
+		}
+		result = append(result, diff)
+	}
+	return result, nil
+}
+
+func simulatedDiffKey(diff bigmapdiff.BigMapDiff) string {
+	return fmt.Sprintf("%d:%s", diff.Ptr, diff.KeyHash)
+}
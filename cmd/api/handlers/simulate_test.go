@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/cache"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	mock_block "github.com/baking-bad/bcdhub/internal/models/mock/block"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	aliceHash = "exprtgHvpVEPbFDDJRZg2JfXSiYjMRu5ck2TKzRxsxhDjGQmrbPBD5"
+	bobHash   = "exprvBEwCMaJsvGaWmWVKBjLvymqNGQFY3d4tB8BRk2eEAP5rBQd1j"
+)
+
+func testSimulationState() block.Block {
+	return block.Block{
+		Network:   types.Mainnet,
+		Hash:      "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2",
+		ChainID:   "NetXdQprcVkpaWU",
+		Level:     100,
+		Timestamp: time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC),
+		Protocol: protocol.Protocol{
+			ID:      1,
+			Hash:    "PtHangzHogokSuiMHemCuowEavgYTP8J5qQ9fQS793MHYFpCY3r",
+			Network: types.Mainnet,
+			SymLink: bcd.SymLinkBabylon,
+			Constants: &protocol.Constants{
+				CostPerByte:                  250,
+				HardGasLimitPerOperation:     1040000,
+				HardStorageLimitPerOperation: 60000,
+			},
+		},
+	}
+}
+
+// testSimulatedCall - applied operation of `testContract` with storage `Pair 10 total` and updates of `ledger` big map (ptr 10)
+func testSimulatedCall(kind types.OperationKind, total string, diffs ...*bigmapdiff.BigMapDiff) *operation.Operation {
+	return &operation.Operation{
+		Network:         types.Mainnet,
+		Kind:            kind,
+		Status:          types.OperationStatusApplied,
+		Destination:     account.Account{Network: types.Mainnet, Address: testContract, Type: types.AccountTypeContract},
+		DeffatedStorage: []byte(`{"prim":"Pair","args":[{"int":"10"},{"int":"` + total + `"}]}`),
+		BigMapDiffs:     diffs,
+	}
+}
+
+func testLedgerDiff(keyHash, key, value string) *bigmapdiff.BigMapDiff {
+	return &bigmapdiff.BigMapDiff{
+		Network:  types.Mainnet,
+		Contract: testContract,
+		Ptr:      10,
+		KeyHash:  keyHash,
+		Key:      []byte(`{"string":"` + key + `"}`),
+		Value:    []byte(`{"int":"` + value + `"}`),
+	}
+}
+
+// storageDiffChild - returns child of storage diff by its name
+func storageDiffChild(t *testing.T, node *ast.MiguelNode, name string) *ast.MiguelNode {
+	require.NotNil(t, node)
+	for _, child := range node.Children {
+		if child.Name != nil && *child.Name == name {
+			return child
+		}
+	}
+	t.Fatalf("child %s is not found in storage diff", name)
+	return nil
+}
+
+func prepareSimulatedDiffs(t *testing.T, sim *simulation, storageType *ast.TypedAst, model *operation.Operation) *SimulatedOperation {
+	bmd := make([]bigmapdiff.BigMapDiff, len(model.BigMapDiffs))
+	for i := range model.BigMapDiffs {
+		bmd[i] = *model.BigMapDiffs[i]
+	}
+	var op SimulatedOperation
+	op.Network = types.Mainnet.String()
+	require.NoError(t, sim.setStorageDiff(model, storageType, bmd, &op))
+	return &op
+}
+
+func TestSimulation_setStorageDiff(t *testing.T) {
+	storageType, err := ast.NewTypedAstFromString(testStorage)
+	require.NoError(t, err)
+
+	t.Run("calls of stored contract", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tc := newTestStorageContext(ctrl)
+		// stored state is requested only for the first call and for the keys which weren't changed by the group
+		tc.operations.EXPECT().Last(gomock.Any(), int64(0)).Return(*testSimulatedCall(types.OperationKindTransaction, "5"), nil).Times(1)
+		tc.diffs.EXPECT().Previous([]bigmapdiff.BigMapDiff{*testLedgerDiff(aliceHash, "alice", "2")}).Return([]bigmapdiff.BigMapDiff{
+			*testLedgerDiff(aliceHash, "alice", "1"),
+		}, nil).Times(1)
+		tc.diffs.EXPECT().Previous([]bigmapdiff.BigMapDiff{*testLedgerDiff(bobHash, "bob", "4")}).Return(nil, nil).Times(1)
+
+		sim := newSimulation(tc.Context, types.Mainnet, testSimulationState())
+
+		first := prepareSimulatedDiffs(t, sim, storageType, testSimulatedCall(types.OperationKindTransaction, "6",
+			testLedgerDiff(aliceHash, "alice", "2"),
+		))
+		total := storageDiffChild(t, first.StorageDiff, "total")
+		assert.Equal(t, "update", total.DiffType)
+		assert.Equal(t, "5", total.From)
+		assert.Equal(t, "6", total.Value)
+
+		second := prepareSimulatedDiffs(t, sim, storageType, testSimulatedCall(types.OperationKindTransaction, "7",
+			testLedgerDiff(aliceHash, "alice", "3"),
+			testLedgerDiff(bobHash, "bob", "4"),
+		))
+		total = storageDiffChild(t, second.StorageDiff, "total")
+		assert.Equal(t, "update", total.DiffType)
+		assert.Equal(t, "6", total.From)
+		assert.Equal(t, "7", total.Value)
+
+		ledger := storageDiffChild(t, second.StorageDiff, "ledger")
+		diffTypes := make(map[string]string)
+		for _, item := range ledger.Children {
+			require.NotNil(t, item.Name)
+			diffTypes[*item.Name] = item.DiffType
+			if *item.Name == "alice" {
+				assert.Equal(t, "2", item.From)
+				assert.Equal(t, "3", item.Value)
+			}
+		}
+		assert.Equal(t, map[string]string{"alice": "update", "bob": "create"}, diffTypes)
+	})
+
+	t.Run("call of contract originated in the group", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// nothing is requested from storage: state of the contract is known from origination
+		tc := newTestStorageContext(ctrl)
+		sim := newSimulation(tc.Context, types.Mainnet, testSimulationState())
+
+		origination := prepareSimulatedDiffs(t, sim, storageType, testSimulatedCall(types.OperationKindOrigination, "1",
+			testLedgerDiff(aliceHash, "alice", "1"),
+		))
+		total := storageDiffChild(t, origination.StorageDiff, "total")
+		assert.Equal(t, "create", total.DiffType)
+		assert.Equal(t, "1", total.Value)
+
+		call := prepareSimulatedDiffs(t, sim, storageType, testSimulatedCall(types.OperationKindTransaction, "2",
+			testLedgerDiff(aliceHash, "alice", "5"),
+		))
+		total = storageDiffChild(t, call.StorageDiff, "total")
+		assert.Equal(t, "update", total.DiffType)
+		assert.Equal(t, "1", total.From)
+		assert.Equal(t, "2", total.Value)
+
+		ledger := storageDiffChild(t, call.StorageDiff, "ledger")
+		require.Len(t, ledger.Children, 1)
+		assert.Equal(t, "update", ledger.Children[0].DiffType)
+		assert.Equal(t, "1", ledger.Children[0].From)
+		assert.Equal(t, "5", ledger.Children[0].Value)
+	})
+}
+
+func Test_simulateRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     simulateRequest
+		wantErr bool
+	}{
+		{
+			name: "transactions",
+			req: simulateRequest{
+				Source: testAccount,
+				Operations: []simulatedOperationRequest{
+					{Kind: "transaction", Destination: testOther},
+					{Kind: "transaction", Destination: testContract, Entrypoint: "transfer"},
+				},
+			},
+		}, {
+			name: "origination",
+			req: simulateRequest{
+				Source: testAccount,
+				Operations: []simulatedOperationRequest{
+					{Kind: "origination", Code: []byte(`[]`), Storage: []byte(`{"int":"0"}`)},
+				},
+			},
+		}, {
+			name: "transaction without destination",
+			req: simulateRequest{
+				Source:     testAccount,
+				Operations: []simulatedOperationRequest{{Kind: "transaction"}},
+			},
+			wantErr: true,
+		}, {
+			name: "entrypoint of implicit account",
+			req: simulateRequest{
+				Source:     testAccount,
+				Operations: []simulatedOperationRequest{{Kind: "transaction", Destination: testOther, Entrypoint: "transfer"}},
+			},
+			wantErr: true,
+		}, {
+			name: "origination without storage",
+			req: simulateRequest{
+				Source:     testAccount,
+				Operations: []simulatedOperationRequest{{Kind: "origination", Code: []byte(`[]`)}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestContext_SimulateOperations(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "transfers between implicit accounts",
+			body:     `{"source":"` + testAccount + `","operations":[{"kind":"transaction","destination":"` + testOther + `","amount":10},{"kind":"transaction","destination":"` + testOther + `","amount":20,"gas_limit":1500}]}`,
+			wantCode: http.StatusOK,
+		}, {
+			name:     "empty group",
+			body:     `{"source":"` + testAccount + `","operations":[]}`,
+			wantCode: http.StatusBadRequest,
+		}, {
+			name:     "unknown kind",
+			body:     `{"source":"` + testAccount + `","operations":[{"kind":"delegation"}]}`,
+			wantCode: http.StatusBadRequest,
+		}, {
+			name:     "transaction without destination",
+			body:     `{"source":"` + testAccount + `","operations":[{"kind":"transaction"}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			blocks := mock_block.NewMockRepository(ctrl)
+			rpc := noderpc.NewMockINode(ctrl)
+			tc := newTestStorageContext(ctrl)
+			tc.Context.Context.Blocks = blocks
+			tc.Context.Context.RPC = map[types.Network]noderpc.INode{types.Mainnet: rpc}
+			tc.Context.Context.Cache = cache.NewCache(tc.Context.Context.RPC, blocks, tc.contracts, tc.protocols, nil, nil)
+
+			if tt.wantCode == http.StatusOK {
+				state := testSimulationState()
+				blocks.EXPECT().Last(types.Mainnet).Return(state, nil).Times(1)
+				rpc.EXPECT().GetCounter(testAccount).Return(int64(41), nil).Times(1)
+				rpc.EXPECT().GetNetworkConstants(state.Level).Return(noderpc.Constants{HardGasLimitPerBlock: 1000000}, nil).Times(1)
+				rpc.EXPECT().RunOperationGroup(state.ChainID, state.Hash, gomock.Any()).DoAndReturn(
+					func(chainID, branch string, contents []noderpc.RunOperationContent) (noderpc.LightOperationGroup, error) {
+						// counters are increased by the group, gas limit of the block is shared by operations
+						require.Len(t, contents, 2)
+						assert.Equal(t, int64(42), contents[0].Counter)
+						assert.Equal(t, int64(43), contents[1].Counter)
+						assert.Equal(t, int64(500000), contents[0].GasLimit)
+						assert.Equal(t, int64(1500), contents[1].GasLimit)
+						assert.Equal(t, state.Protocol.Constants.HardStorageLimitPerOperation, contents[0].StorageLimit)
+
+						group := noderpc.LightOperationGroup{}
+						for i := range contents {
+							raw := `{"kind":"transaction","source":"` + contents[i].Source + `","destination":"` + contents[i].Destination + `","amount":"` +
+								map[int]string{0: "10", 1: "20"}[i] + `","counter":"` + map[int]string{0: "42", 1: "43"}[i] +
+								`","metadata":{"operation_result":{"status":"applied","consumed_gas":"1420"}}}`
+							group.Contents = append(group.Contents, noderpc.LightOperation{Raw: []byte(raw)})
+						}
+						return group, nil
+					}).Times(1)
+			}
+
+			c, w := testRequest(t, http.MethodPost, "/v1/simulate/mainnet", gin.Params{
+				{Key: "network", Value: types.Mainnet.String()},
+			})
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/simulate/mainnet", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			tc.SimulateOperations(c)
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			// response is written by `SecureJSON`
+			var response []SimulatedOperation
+			require.NoError(t, json.UnmarshalFromString(strings.TrimPrefix(w.Body.String(), "while(1);"), &response))
+			require.Len(t, response, 2)
+			for i, amount := range []int64{10, 20} {
+				assert.Equal(t, "applied", response[i].Status)
+				assert.Equal(t, amount, response[i].Amount)
+				assert.Equal(t, testOther, response[i].Destination)
+				assert.Equal(t, int64(42+i), response[i].Counter)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/bcd/formatter"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/parsers/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,6 +20,7 @@ import (
 // @Param network path string true "Network"
 // @Param address path string true "KT address" minlength(36) maxlength(36)
 // @Param level query integer false "Level"
+// @Param at_level query integer false "Rebuild storage with big maps at the end of the level from indexed data" minimum(1)
 // @Accept json
 // @Produce json
 // @Success 200 {array} ast.MiguelNode
@@ -38,6 +40,19 @@ func (ctx *Context) GetContractStorage(c *gin.Context) {
 	}
 
 	network := types.NewNetwork(req.Network)
+	if sReq.AtLevel > 0 {
+		storageType, err := ctx.getStorageAtLevel(network, req.Address, sReq.AtLevel)
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		resp, err := storageType.ToMiguel()
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		c.SecureJSON(http.StatusOK, resp)
+		return
+	}
+
 	rpc, err := ctx.GetRPC(network)
 	if ctx.handleError(c, err, http.StatusBadRequest) {
 		return
@@ -161,6 +176,7 @@ func (ctx *Context) GetContractStorageRaw(c *gin.Context) {
 // @Param network path string true "Network"
 // @Param address path string true "KT address" minlength(36) maxlength(36)
 // @Param level query integer false "Level"
+// @Param at_level query integer false "Rebuild storage with big maps at the end of the level from indexed data" minimum(1)
 // @Accept json
 // @Produce json
 // @Success 200 {object} gin.H
@@ -178,6 +194,19 @@ func (ctx *Context) GetContractStorageRich(c *gin.Context) {
 	if err := c.BindQuery(&sReq); ctx.handleError(c, err, http.StatusBadRequest) {
 		return
 	}
+	if sReq.AtLevel > 0 {
+		storageType, err := ctx.getStorageAtLevel(req.NetworkID(), req.Address, sReq.AtLevel)
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		response, err := storageType.Nodes[0].ToBaseNode(false)
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		c.SecureJSON(http.StatusOK, response)
+		return
+	}
+
 	filters := map[string]interface{}{
 		"destination": req.Address,
 		"network":     req.NetworkID(),
@@ -304,3 +333,38 @@ func (ctx *Context) GetContractStorageSchema(c *gin.Context) {
 
 	c.SecureJSON(http.StatusOK, schema)
 }
+
+// getStorageAtLevel - rebuilds contract storage with big maps contents at the end of `level` from indexed operations and big map diffs
+func (ctx *Context) getStorageAtLevel(network types.Network, address string, level int64) (*ast.TypedAst, error) {
+	op, err := ctx.Operations.LastAtLevel(network, address, level)
+	if err != nil {
+		return nil, err
+	}
+
+	proto, err := ctx.Cache.ProtocolByID(network, op.ProtocolID)
+	if err != nil {
+		return nil, err
+	}
+	storageType, err := ctx.getStorageType(network, address, proto.SymLink)
+	if err != nil {
+		return nil, err
+	}
+
+	var data ast.UntypedAST
+	if err := json.Unmarshal(op.DeffatedStorage, &data); err != nil {
+		return nil, err
+	}
+	if err := storageType.Settle(data); err != nil {
+		return nil, err
+	}
+
+	states, err := ctx.BigMapDiffs.GetForAddressAtLevel(network, address, level)
+	if err != nil {
+		return nil, err
+	}
+	// keys removed at `level` or earlier are skipped
+	if err := storage.EnrichFromState(storageType, states, true, true); err != nil {
+		return nil, err
+	}
+	return storageType, nil
+}
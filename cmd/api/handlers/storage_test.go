@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/cmd/api/validations"
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/cache"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	mock_general "github.com/baking-bad/bcdhub/internal/models/mock"
+	mock_bmd "github.com/baking-bad/bcdhub/internal/models/mock/bigmapdiff"
+	mock_contract "github.com/baking-bad/bcdhub/internal/models/mock/contract"
+	mock_operation "github.com/baking-bad/bcdhub/internal/models/mock/operation"
+	mock_proto "github.com/baking-bad/bcdhub/internal/models/mock/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-pg/pg/v10"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var registerValidations sync.Once
+
+// testRequest - creates gin context for handler call with registered request validators
+func testRequest(t *testing.T, method, url string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	registerValidations.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		require.True(t, ok)
+		require.NoError(t, validations.Register(v, config.APIConfig{
+			Networks: []string{types.Mainnet.String()},
+		}))
+	})
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, url, nil)
+	c.Params = params
+	return c, w
+}
+
+const (
+	testParameter = `[{"prim":"unit"}]`
+	testStorage   = `[{"prim":"pair","args":[{"prim":"big_map","args":[{"prim":"string"},{"prim":"nat"}],"annots":["%ledger"]},{"prim":"nat","annots":["%total"]}]}]`
+)
+
+type testStorageContext struct {
+	*Context
+	general    *mock_general.MockGeneralRepository
+	operations *mock_operation.MockRepository
+	diffs      *mock_bmd.MockRepository
+	contracts  *mock_contract.MockRepository
+	protocols  *mock_proto.MockRepository
+}
+
+func newTestStorageContext(ctrl *gomock.Controller) testStorageContext {
+	tc := testStorageContext{
+		general:    mock_general.NewMockGeneralRepository(ctrl),
+		operations: mock_operation.NewMockRepository(ctrl),
+		diffs:      mock_bmd.NewMockRepository(ctrl),
+		contracts:  mock_contract.NewMockRepository(ctrl),
+		protocols:  mock_proto.NewMockRepository(ctrl),
+	}
+	tc.Context = &Context{
+		Context: &config.Context{
+			Storage:     tc.general,
+			Operations:  tc.operations,
+			BigMapDiffs: tc.diffs,
+			Contracts:   tc.contracts,
+			Protocols:   tc.protocols,
+			Cache:       cache.NewCache(nil, nil, tc.contracts, tc.protocols, nil, nil),
+		},
+	}
+	return tc
+}
+
+// expectStorageAtLevel - expects rebuilding of storage of `testContract` at level 100 with big map key `alice` and key `bob` removed before the level
+func (tc testStorageContext) expectStorageAtLevel() {
+	tc.operations.EXPECT().LastAtLevel(types.Mainnet, testContract, int64(100)).Return(operation.Operation{
+		Network:         types.Mainnet,
+		ProtocolID:      1,
+		Level:           90,
+		DeffatedStorage: []byte(`{"prim":"Pair","args":[{"int":"10"},{"int":"5"}]}`),
+	}, nil).Times(1)
+	tc.protocols.EXPECT().GetByID(int64(1)).Return(protocol.Protocol{
+		ID:      1,
+		Network: types.Mainnet,
+		SymLink: bcd.SymLinkBabylon,
+	}, nil).Times(1)
+	tc.contracts.EXPECT().Script(types.Mainnet, testContract, bcd.SymLinkBabylon).Return(contract.Script{
+		Code:      []byte(`[]`),
+		Parameter: []byte(testParameter),
+		Storage:   []byte(testStorage),
+	}, nil).Times(1)
+	tc.diffs.EXPECT().GetForAddressAtLevel(types.Mainnet, testContract, int64(100)).Return([]bigmapdiff.BigMapState{
+		{
+			Ptr:             10,
+			Network:         types.Mainnet,
+			Contract:        testContract,
+			KeyHash:         "exprtgHvpVEPbFDDJRZg2JfXSiYjMRu5ck2TKzRxsxhDjGQmrbPBD5",
+			Key:             []byte(`{"string":"alice"}`),
+			Value:           []byte(`{"int":"1"}`),
+			LastUpdateLevel: 80,
+		}, {
+			Ptr:             10,
+			Network:         types.Mainnet,
+			Contract:        testContract,
+			KeyHash:         "exprvBEwCMaJsvGaWmWVKBjLvymqNGQFY3d4tB8BRk2eEAP5rBQd1j",
+			Key:             []byte(`{"string":"bob"}`),
+			Removed:         true,
+			LastUpdateLevel: 85,
+		},
+	}, nil).Times(1)
+}
+
+func TestContext_getStorageAtLevel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tc := newTestStorageContext(ctrl)
+	tc.expectStorageAtLevel()
+
+	storageType, err := tc.getStorageAtLevel(types.Mainnet, testContract, 100)
+	require.NoError(t, err)
+
+	node, err := storageType.Nodes[0].ToBaseNode(false)
+	require.NoError(t, err)
+	data, err := json.MarshalToString(node)
+	require.NoError(t, err)
+	assert.Contains(t, data, "alice")
+	assert.NotContains(t, data, "bob")
+}
+
+func TestContext_getStorageAtLevel_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tc := newTestStorageContext(ctrl)
+	tc.operations.EXPECT().LastAtLevel(types.Mainnet, testContract, int64(1)).Return(operation.Operation{}, pg.ErrNoRows).Times(1)
+	tc.general.EXPECT().IsRecordNotFound(pg.ErrNoRows).Return(true).Times(1)
+
+	c, w := testRequest(t, http.MethodGet, "/v1/contract/mainnet/"+testContract+"/storage?at_level=1", gin.Params{
+		{Key: "network", Value: types.Mainnet.String()},
+		{Key: "address", Value: testContract},
+	})
+	tc.GetContractStorage(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestContext_GetContractStorage_AtLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		handler func(ctx *Context) gin.HandlerFunc
+	}{
+		{
+			name: "storage",
+			url:  "/v1/contract/mainnet/" + testContract + "/storage?at_level=100",
+			handler: func(ctx *Context) gin.HandlerFunc {
+				return ctx.GetContractStorage
+			},
+		}, {
+			name: "rich storage",
+			url:  "/v1/contract/mainnet/" + testContract + "/storage/rich?at_level=100",
+			handler: func(ctx *Context) gin.HandlerFunc {
+				return ctx.GetContractStorageRich
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tc := newTestStorageContext(ctrl)
+			tc.expectStorageAtLevel()
+
+			c, w := testRequest(t, http.MethodGet, tt.url, gin.Params{
+				{Key: "network", Value: types.Mainnet.String()},
+				{Key: "address", Value: testContract},
+			})
+			tt.handler(tc.Context)(c)
+
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), "alice")
+			assert.NotContains(t, w.Body.String(), "bob")
+		})
+	}
+}
+
+func TestContext_GetContractStorage_AtLevelWithLevel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tc := newTestStorageContext(ctrl)
+
+	c, w := testRequest(t, http.MethodGet, "/v1/contract/mainnet/"+testContract+"/storage?at_level=100&level=100", gin.Params{
+		{Key: "network", Value: types.Mainnet.String()},
+		{Key: "address", Value: testContract},
+	})
+	tc.GetContractStorage(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
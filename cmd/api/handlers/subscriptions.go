@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/domains"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/transfer"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/pubsub"
+)
+
+// subscription channels
+const (
+	channelOperations = "operations"
+	channelBigMaps    = "big_maps"
+	channelTransfers  = "transfers"
+	channelHead       = "head"
+)
+
+// subscription message types
+const (
+	messageSubscribed   = "subscribed"
+	messageUnsubscribed = "unsubscribed"
+	messageRollback     = "rollback"
+	messageError        = "error"
+)
+
+// maxPendingLevels - maximum count of levels which are sent at once if hub missed some blocks
+const maxPendingLevels = 10
+
+type subscription struct {
+	ID         int64
+	Channel    string
+	Network    types.Network
+	Address    string
+	Entrypoint string
+	Ptr        *int64
+}
+
+func newSubscription(id int64, req subscriptionRequest) subscription {
+	return subscription{
+		ID:         id,
+		Channel:    req.Channel,
+		Network:    req.NetworkID(),
+		Address:    req.Address,
+		Entrypoint: req.Entrypoint,
+		Ptr:        req.Ptr,
+	}
+}
+
+func (s subscription) matchOperation(op operation.Operation) bool {
+	if s.Address != "" && op.Source.Address != s.Address && op.Destination.Address != s.Address {
+		return false
+	}
+	return s.Entrypoint == "" || op.IsEntrypoint(s.Entrypoint)
+}
+
+func (s subscription) matchBigMapDiff(diff bigmapdiff.BigMapDiff) bool {
+	if s.Address != "" && diff.Contract != s.Address {
+		return false
+	}
+	return s.Ptr == nil || *s.Ptr == diff.Ptr
+}
+
+func (s subscription) matchTransfer(t transfer.Transfer) bool {
+	return s.Address == "" || t.Contract == s.Address || t.From.Address == s.Address || t.To.Address == s.Address
+}
+
+// Hub - delivers blocks committed by indexer to websocket subscribers. Indexer publishes events via Postgres notifications.
+type Hub struct {
+	ctx *Context
+
+	clients map[*wsClient]struct{}
+	levels  map[types.Network]int64
+	mx      sync.RWMutex
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewHub -
+func NewHub(ctx *Context) *Hub {
+	return &Hub{
+		ctx:     ctx,
+		clients: make(map[*wsClient]struct{}),
+		levels:  make(map[types.Network]int64),
+	}
+}
+
+// Start -
+func (hub *Hub) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	hub.cancel = cancel
+
+	hub.wg.Add(1)
+	go func() {
+		defer hub.wg.Done()
+
+		for event := range pubsub.Listen(ctx, hub.ctx.StorageDB.DB) {
+			if err := hub.handleEvent(event); err != nil {
+				logger.Err(err)
+			}
+		}
+	}()
+}
+
+// Close -
+func (hub *Hub) Close() {
+	if hub.cancel != nil {
+		hub.cancel()
+	}
+	hub.wg.Wait()
+
+	hub.mx.Lock()
+	for client := range hub.clients {
+		client.close()
+	}
+	hub.clients = make(map[*wsClient]struct{})
+	hub.mx.Unlock()
+}
+
+func (hub *Hub) register(client *wsClient) {
+	hub.mx.Lock()
+	hub.clients[client] = struct{}{}
+	hub.mx.Unlock()
+}
+
+func (hub *Hub) unregister(client *wsClient) {
+	hub.mx.Lock()
+	delete(hub.clients, client)
+	hub.mx.Unlock()
+}
+
+func (hub *Hub) subscriptions(network types.Network) map[*wsClient][]subscription {
+	hub.mx.RLock()
+	defer hub.mx.RUnlock()
+
+	result := make(map[*wsClient][]subscription)
+	for client := range hub.clients {
+		if subs := client.subscriptions(network); len(subs) > 0 {
+			result[client] = subs
+		}
+	}
+	return result
+}
+
+func (hub *Hub) handleEvent(event pubsub.Event) error {
+	switch event.Kind {
+	case pubsub.KindBlock:
+		hub.mx.Lock()
+		from := hub.levels[event.Network] + 1
+		if from == 1 || event.Level-from >= maxPendingLevels {
+			from = event.Level
+		}
+		if event.Level < from {
+			hub.mx.Unlock()
+			return nil
+		}
+		hub.levels[event.Network] = event.Level
+		hub.mx.Unlock()
+
+		for level := from; level <= event.Level; level++ {
+			if err := hub.sendLevel(event.Network, level); err != nil {
+				return err
+			}
+		}
+	case pubsub.KindRollback:
+		// items of rolled back levels are sent again when indexer commits them one more time
+		hub.mx.Lock()
+		if hub.levels[event.Network] > event.Level {
+			hub.levels[event.Network] = event.Level
+		}
+		hub.mx.Unlock()
+
+		for client := range hub.subscriptions(event.Network) {
+			client.send(SubscriptionMessage{
+				Type:    messageRollback,
+				Network: event.Network.String(),
+				Level:   event.Level,
+			})
+		}
+	}
+	return nil
+}
+
+func (hub *Hub) sendLevel(network types.Network, level int64) error {
+	clients := hub.subscriptions(network)
+	if len(clients) == 0 {
+		return nil
+	}
+
+	data, err := hub.loadLevel(network, level, clients)
+	if err != nil {
+		return err
+	}
+
+	for client, subs := range clients {
+		for _, sub := range subs {
+			items, err := data.filter(hub.ctx, sub)
+			if err != nil {
+				return err
+			}
+			if items == nil {
+				continue
+			}
+			client.send(SubscriptionMessage{
+				Type:         sub.Channel,
+				Subscription: sub.ID,
+				Network:      network.String(),
+				Level:        level,
+				Data:         items,
+			})
+		}
+	}
+	return nil
+}
+
+type levelData struct {
+	head       *BlockHead
+	operations []operation.Operation
+	diffs      []bigmapdiff.BigMapDiff
+	transfers  []transfer.Transfer
+	hashes     map[int64]string
+	prepared   map[int64]Operation
+}
+
+func (hub *Hub) loadLevel(network types.Network, level int64, clients map[*wsClient][]subscription) (*levelData, error) {
+	channels := make(map[string]struct{})
+	for _, subs := range clients {
+		for i := range subs {
+			channels[subs[i].Channel] = struct{}{}
+		}
+	}
+
+	data := &levelData{
+		hashes:   make(map[int64]string),
+		prepared: make(map[int64]Operation),
+	}
+
+	if _, ok := channels[channelHead]; ok {
+		block, err := hub.ctx.Blocks.Get(network, level)
+		if err != nil {
+			return nil, err
+		}
+		head := NewBlockHeadFromModel(block)
+		data.head = &head
+	}
+
+	if len(channels) == 1 && data.head != nil {
+		return data, nil
+	}
+
+	operations, err := hub.ctx.Operations.Get(map[string]interface{}{
+		"operation.network": network,
+		"operation.level":   level,
+	}, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	data.operations = operations
+
+	ids := make([]int64, len(operations))
+	for i := range operations {
+		ids[i] = operations[i].ID
+		data.hashes[operations[i].ID] = operations[i].Hash
+	}
+
+	if _, ok := channels[channelBigMaps]; ok && len(ids) > 0 {
+		data.diffs, err = hub.ctx.BigMapDiffs.GetForOperations(ids...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := channels[channelTransfers]; ok {
+		data.transfers, err = hub.ctx.Transfers.GetAll(network, level)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// filter - returns items matched by subscription or nil if nothing was matched
+func (data *levelData) filter(ctx *Context, sub subscription) (interface{}, error) {
+	switch sub.Channel {
+	case channelHead:
+		if data.head == nil {
+			return nil, nil
+		}
+		return data.head, nil
+	case channelOperations:
+		result := make([]Operation, 0)
+		for i := range data.operations {
+			if !sub.matchOperation(data.operations[i]) {
+				continue
+			}
+			op, ok := data.prepared[data.operations[i].ID]
+			if !ok {
+				prepared, err := ctx.prepareOperation(data.operations[i], nil, false)
+				if err != nil {
+					return nil, err
+				}
+				data.prepared[data.operations[i].ID] = prepared
+				op = prepared
+			}
+			result = append(result, op)
+		}
+		if len(result) == 0 {
+			return nil, nil
+		}
+		return result, nil
+	case channelBigMaps:
+		result := make([]BigMapUpdate, 0)
+		for i := range data.diffs {
+			if !sub.matchBigMapDiff(data.diffs[i]) {
+				continue
+			}
+			update := NewBigMapUpdateFromModel(data.diffs[i])
+			update.OperationHash = data.hashes[data.diffs[i].OperationID]
+			result = append(result, update)
+		}
+		if len(result) == 0 {
+			return nil, nil
+		}
+		return result, nil
+	case channelTransfers:
+		result := make([]Transfer, 0)
+		for i := range data.transfers {
+			if !sub.matchTransfer(data.transfers[i]) {
+				continue
+			}
+			t := TransferFromModel(domains.Transfer{
+				Transfer: &data.transfers[i],
+				Hash:     data.hashes[data.transfers[i].OperationID],
+			})
+			result = append(result, t)
+		}
+		if len(result) == 0 {
+			return nil, nil
+		}
+		return result, nil
+	}
+	return nil, nil
+}
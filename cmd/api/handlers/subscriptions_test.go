@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	mock_bmd "github.com/baking-bad/bcdhub/internal/models/mock/bigmapdiff"
+	mock_block "github.com/baking-bad/bcdhub/internal/models/mock/block"
+	mock_operation "github.com/baking-bad/bcdhub/internal/models/mock/operation"
+	mock_transfer "github.com/baking-bad/bcdhub/internal/models/mock/transfer"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/transfer"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/pubsub"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testContract = "KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH"
+	testAccount  = "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"
+	testOther    = "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb"
+)
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
+
+func TestSubscription_matchOperation(t *testing.T) {
+	entrypoint := "transfer"
+	op := operation.Operation{
+		Source:      account.Account{Address: testAccount},
+		Destination: account.Account{Address: testContract},
+		Entrypoint:  types.NewNullString(&entrypoint),
+	}
+	tests := []struct {
+		name string
+		sub  subscription
+		want bool
+	}{
+		{
+			name: "without filters",
+			want: true,
+		}, {
+			name: "by destination",
+			sub:  subscription{Address: testContract},
+			want: true,
+		}, {
+			name: "by source",
+			sub:  subscription{Address: testAccount},
+			want: true,
+		}, {
+			name: "other address",
+			sub:  subscription{Address: testOther},
+		}, {
+			name: "by entrypoint",
+			sub:  subscription{Address: testContract, Entrypoint: "transfer"},
+			want: true,
+		}, {
+			name: "other entrypoint",
+			sub:  subscription{Address: testContract, Entrypoint: "approve"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.sub.matchOperation(op))
+		})
+	}
+}
+
+func TestSubscription_matchBigMapDiff(t *testing.T) {
+	diff := bigmapdiff.BigMapDiff{Ptr: 10, Contract: testContract}
+	tests := []struct {
+		name string
+		sub  subscription
+		want bool
+	}{
+		{
+			name: "without filters",
+			want: true,
+		}, {
+			name: "by contract",
+			sub:  subscription{Address: testContract},
+			want: true,
+		}, {
+			name: "other contract",
+			sub:  subscription{Address: testOther},
+		}, {
+			name: "by pointer",
+			sub:  subscription{Ptr: int64Ptr(10)},
+			want: true,
+		}, {
+			name: "zero pointer",
+			sub:  subscription{Ptr: int64Ptr(0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.sub.matchBigMapDiff(diff))
+		})
+	}
+}
+
+func TestSubscription_matchTransfer(t *testing.T) {
+	tr := transfer.Transfer{
+		Contract: testContract,
+		From:     account.Account{Address: testAccount},
+	}
+	tests := []struct {
+		name string
+		sub  subscription
+		want bool
+	}{
+		{
+			name: "without filters",
+			want: true,
+		}, {
+			name: "by contract",
+			sub:  subscription{Address: testContract},
+			want: true,
+		}, {
+			name: "by sender",
+			sub:  subscription{Address: testAccount},
+			want: true,
+		}, {
+			name: "other address",
+			sub:  subscription{Address: testOther},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.sub.matchTransfer(tr))
+		})
+	}
+}
+
+type testHub struct {
+	*Hub
+	blocks     *mock_block.MockRepository
+	operations *mock_operation.MockRepository
+	diffs      *mock_bmd.MockRepository
+	transfers  *mock_transfer.MockRepository
+}
+
+func newTestHub(ctrl *gomock.Controller) testHub {
+	th := testHub{
+		blocks:     mock_block.NewMockRepository(ctrl),
+		operations: mock_operation.NewMockRepository(ctrl),
+		diffs:      mock_bmd.NewMockRepository(ctrl),
+		transfers:  mock_transfer.NewMockRepository(ctrl),
+	}
+	th.Hub = NewHub(&Context{
+		Context: &config.Context{
+			Blocks:      th.blocks,
+			Operations:  th.operations,
+			BigMapDiffs: th.diffs,
+			Transfers:   th.transfers,
+		},
+	})
+	return th
+}
+
+func (th testHub) client(subs ...subscription) *wsClient {
+	client := newWSClient(th.Hub, nil)
+	for i := range subs {
+		client.lastID++
+		subs[i].ID = client.lastID
+		client.subs[subs[i].ID] = subs[i]
+	}
+	th.register(client)
+	return client
+}
+
+// messages - returns messages queued for client
+func messages(t *testing.T, client *wsClient) []SubscriptionMessage {
+	result := make([]SubscriptionMessage, 0)
+	for {
+		select {
+		case data := <-client.output:
+			var msg SubscriptionMessage
+			require.NoError(t, json.Unmarshal(data, &msg))
+			result = append(result, msg)
+		default:
+			return result
+		}
+	}
+}
+
+func TestHub_handleEvent_FanOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	hub := newTestHub(ctrl)
+
+	first := hub.client(subscription{Channel: channelHead, Network: types.Mainnet})
+	second := hub.client(
+		subscription{Channel: channelHead, Network: types.Mainnet},
+		subscription{Channel: channelHead, Network: types.Mainnet},
+	)
+	other := hub.client(subscription{Channel: channelHead, Network: types.Hangzhou2net})
+
+	hub.blocks.EXPECT().Get(types.Mainnet, int64(100)).Return(block.Block{
+		Network: types.Mainnet,
+		Level:   100,
+		Hash:    "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2",
+	}, nil).Times(1)
+
+	require.NoError(t, hub.handleEvent(pubsub.NewBlock(types.Mainnet, 100)))
+
+	got := messages(t, first)
+	require.Len(t, got, 1)
+	assert.Equal(t, channelHead, got[0].Type)
+	assert.Equal(t, int64(1), got[0].Subscription)
+	assert.Equal(t, "mainnet", got[0].Network)
+	assert.Equal(t, int64(100), got[0].Level)
+
+	got = messages(t, second)
+	require.Len(t, got, 2)
+	assert.ElementsMatch(t, []int64{1, 2}, []int64{got[0].Subscription, got[1].Subscription})
+
+	assert.Empty(t, messages(t, other))
+}
+
+func TestHub_handleEvent_Filters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	hub := newTestHub(ctrl)
+
+	byContract := hub.client(subscription{Channel: channelBigMaps, Network: types.Mainnet, Address: testContract})
+	byPtr := hub.client(subscription{Channel: channelBigMaps, Network: types.Mainnet, Ptr: int64Ptr(11)})
+	transfers := hub.client(subscription{Channel: channelTransfers, Network: types.Mainnet, Address: testOther})
+	nothing := hub.client(subscription{Channel: channelTransfers, Network: types.Mainnet, Address: testContract})
+
+	hub.operations.EXPECT().Get(map[string]interface{}{
+		"operation.network": types.Mainnet,
+		"operation.level":   int64(100),
+	}, int64(0), false).Return([]operation.Operation{
+		{ID: 1, Hash: "opHash1"},
+		{ID: 2, Hash: "opHash2"},
+	}, nil).Times(1)
+	hub.diffs.EXPECT().GetForOperations(int64(1), int64(2)).Return([]bigmapdiff.BigMapDiff{
+		{Ptr: 10, Contract: testContract, KeyHash: "expr1", Key: []byte(`{"int":"1"}`), OperationID: 1, Network: types.Mainnet},
+		{Ptr: 11, Contract: testAccount, KeyHash: "expr2", Key: []byte(`{"int":"2"}`), OperationID: 2, Network: types.Mainnet},
+	}, nil).Times(1)
+	hub.transfers.EXPECT().GetAll(types.Mainnet, int64(100)).Return([]transfer.Transfer{
+		{Contract: testAccount, To: account.Account{Address: testOther}, OperationID: 2, Network: types.Mainnet},
+	}, nil).Times(1)
+
+	require.NoError(t, hub.handleEvent(pubsub.NewBlock(types.Mainnet, 100)))
+
+	tests := []struct {
+		name   string
+		client *wsClient
+		keys   []string
+		hashes []string
+	}{
+		{
+			name:   "big maps by contract",
+			client: byContract,
+			keys:   []string{"expr1"},
+			hashes: []string{"opHash1"},
+		}, {
+			name:   "big maps by pointer",
+			client: byPtr,
+			keys:   []string{"expr2"},
+			hashes: []string{"opHash2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := messages(t, tt.client)
+			require.Len(t, got, 1)
+			assert.Equal(t, channelBigMaps, got[0].Type)
+
+			updates, ok := got[0].Data.([]interface{})
+			require.True(t, ok)
+			require.Len(t, updates, len(tt.keys))
+			for i := range updates {
+				update := updates[i].(map[string]interface{})
+				assert.Equal(t, tt.keys[i], update["key_hash"])
+				assert.Equal(t, tt.hashes[i], update["operation_hash"])
+			}
+		})
+	}
+
+	t.Run("transfers by recipient", func(t *testing.T) {
+		got := messages(t, transfers)
+		require.Len(t, got, 1)
+		assert.Equal(t, channelTransfers, got[0].Type)
+		items, ok := got[0].Data.([]interface{})
+		require.True(t, ok)
+		require.Len(t, items, 1)
+		assert.Equal(t, "opHash2", items[0].(map[string]interface{})["hash"])
+	})
+
+	t.Run("nothing matched", func(t *testing.T) {
+		assert.Empty(t, messages(t, nothing))
+	})
+}
+
+func TestHub_handleEvent_Levels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	hub := newTestHub(ctrl)
+
+	client := hub.client(subscription{Channel: channelHead, Network: types.Mainnet})
+
+	gomock.InOrder(
+		hub.blocks.EXPECT().Get(types.Mainnet, int64(100)).Return(block.Block{Network: types.Mainnet, Level: 100}, nil),
+		hub.blocks.EXPECT().Get(types.Mainnet, int64(101)).Return(block.Block{Network: types.Mainnet, Level: 101}, nil),
+		hub.blocks.EXPECT().Get(types.Mainnet, int64(102)).Return(block.Block{Network: types.Mainnet, Level: 102}, nil),
+		hub.blocks.EXPECT().Get(types.Mainnet, int64(101)).Return(block.Block{Network: types.Mainnet, Level: 101}, nil),
+	)
+
+	require.NoError(t, hub.handleEvent(pubsub.NewBlock(types.Mainnet, 100)))
+	// missed level 101 is sent before 102
+	require.NoError(t, hub.handleEvent(pubsub.NewBlock(types.Mainnet, 102)))
+	// stale event is skipped
+	require.NoError(t, hub.handleEvent(pubsub.NewBlock(types.Mainnet, 101)))
+	require.NoError(t, hub.handleEvent(pubsub.NewRollback(types.Mainnet, 100)))
+	require.NoError(t, hub.handleEvent(pubsub.NewBlock(types.Mainnet, 101)))
+
+	got := messages(t, client)
+	kinds := make([]string, len(got))
+	levels := make([]int64, len(got))
+	for i := range got {
+		kinds[i] = got[i].Type
+		levels[i] = got[i].Level
+	}
+	assert.Equal(t, []string{channelHead, channelHead, channelHead, messageRollback, channelHead}, kinds)
+	assert.Equal(t, []int64{100, 101, 102, 100, 101}, levels)
+}
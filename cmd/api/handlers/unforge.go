@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/bcd/forge"
+	"github.com/baking-bad/bcdhub/internal/models/block"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// UnforgeOperation godoc
+// @Summary Preview unsigned operation bytes
+// @Description Decodes forged operation group (branch and contents without signature, optionally prefixed by `03` watermark) to operations. Parameters of calls of indexed contracts are decoded by entrypoint types of destination contract.
+// @Description Supported kinds are reveal, transaction, origination, delegation and register_global_constant.
+// @Tags operations
+// @ID unforge-operation
+// @Param network path string true "Network"
+// @Param body body unforgeOperationRequest true "Request body"
+// @Accept json
+// @Produce json
+// @Success 200 {object} UnforgedOperationGroup
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/unforge/{network} [post]
+func (ctx *Context) UnforgeOperation(c *gin.Context) {
+	var req getByNetwork
+	if err := c.BindUri(&req); ctx.handleError(c, err, http.StatusNotFound) {
+		return
+	}
+	var reqUnforge unforgeOperationRequest
+	if err := c.BindJSON(&reqUnforge); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	data, err := hex.DecodeString(strings.TrimPrefix(reqUnforge.Bytes, "0x"))
+	if ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	group, err := unforgeGroup(data)
+	if ctx.handleError(c, errors.Wrap(err, "invalid operation bytes"), http.StatusBadRequest) {
+		return
+	}
+
+	network := req.NetworkID()
+	state, err := ctx.Cache.CurrentBlock(network)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	response := UnforgedOperationGroup{
+		Branch:   group.Branch,
+		Contents: make([]UnforgedOperation, len(group.Contents)),
+	}
+	for i := range group.Contents {
+		op, err := ctx.prepareUnforgedOperation(network, state, group.Contents[i])
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		if err := ctx.setUnforgedParameters(network, state, group.Contents[i], &op); ctx.handleError(c, err, http.StatusBadRequest) {
+			return
+		}
+		op.ContentIndex = int64(i)
+		response.Contents[i] = op
+	}
+
+	c.SecureJSON(http.StatusOK, response)
+}
+
+// generic operation watermark which prefixes operation group bytes when they are signed
+const operationWatermark byte = 0x03
+
+// unforgeGroup - decodes operation group bytes which may be prefixed by watermark. Bytes are decoded as is if they can't be decoded without the first byte: branch may start with it too.
+func unforgeGroup(data []byte) (forge.OperationGroup, error) {
+	if len(data) > 0 && data[0] == operationWatermark {
+		var group forge.OperationGroup
+		if _, err := group.Unforge(data[1:]); err == nil {
+			return group, nil
+		}
+	}
+	var group forge.OperationGroup
+	_, err := group.Unforge(data)
+	return group, err
+}
+
+func (ctx *Context) prepareUnforgedOperation(network types.Network, state block.Block, content forge.Operation) (UnforgedOperation, error) {
+	var op UnforgedOperation
+	op.Network = network.String()
+	op.Protocol = state.Protocol.Hash
+	op.Timestamp = state.Timestamp.UTC()
+	op.Kind = content.Kind
+	op.Source = content.Source
+	op.Fee = content.Fee
+	op.Counter = content.Counter
+	op.GasLimit = content.GasLimit
+	op.StorageLimit = content.StorageLimit
+	op.Amount = content.Amount
+	op.Balance = content.Balance
+	op.Destination = content.Destination
+	op.Delegate = content.Delegate
+	op.PublicKey = content.PublicKey
+	op.SourceAlias = ctx.Cache.Alias(network, op.Source)
+	op.DestinationAlias = ctx.Cache.Alias(network, op.Destination)
+
+	var err error
+	if content.Script != nil {
+		if op.Script, err = json.Marshal(content.Script); err != nil {
+			return op, err
+		}
+	}
+	if content.Value != nil {
+		if op.Value, err = json.Marshal(content.Value); err != nil {
+			return op, err
+		}
+	}
+	return op, nil
+}
+
+// setUnforgedParameters - decodes parameters by entrypoint types of destination contract. Micheline is returned as is if destination is not indexed contract.
+func (ctx *Context) setUnforgedParameters(network types.Network, state block.Block, content forge.Operation, op *UnforgedOperation) error {
+	if content.Parameters == nil {
+		return nil
+	}
+	op.Entrypoint = content.Parameters.Entrypoint
+	op.Parameters = content.Parameters.Value
+
+	if !bcd.IsContract(op.Destination) {
+		return nil
+	}
+	script, err := ctx.getScript(network, op.Destination, state.Protocol.SymLink)
+	if err != nil {
+		if ctx.Storage.IsRecordNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	params, err := json.Marshal(content.Parameters)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(setParameters(params, script, &op.Operation), "parameters do not match contract entrypoints")
+}
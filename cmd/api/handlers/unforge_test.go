@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"github.com/baking-bad/bcdhub/internal/bcd/forge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_unforgeGroup(t *testing.T) {
+	forgeGroup := func(branch []byte) []byte {
+		hash, err := encoding.EncodeBase58(branch, []byte(encoding.PrefixBlockHash))
+		require.NoError(t, err)
+		data, err := (&forge.OperationGroup{
+			Branch: hash,
+			Contents: []forge.Operation{
+				{
+					Kind:         "transaction",
+					Source:       "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+					Fee:          1000,
+					Counter:      2,
+					GasLimit:     10000,
+					StorageLimit: 0,
+					Amount:       1000000,
+					Destination:  "tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6",
+				},
+			},
+		}).Forge()
+		require.NoError(t, err)
+		return data
+	}
+
+	branch := bytes.Repeat([]byte{0x8f}, 32)
+	watermarkedBranch := append([]byte{operationWatermark}, bytes.Repeat([]byte{0x8f}, 31)...)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		wantBranch []byte
+		wantErr    bool
+	}{
+		{
+			name:       "without watermark",
+			data:       forgeGroup(branch),
+			wantBranch: branch,
+		}, {
+			name:       "with watermark",
+			data:       append([]byte{operationWatermark}, forgeGroup(branch)...),
+			wantBranch: branch,
+		}, {
+			name:       "branch starts with watermark byte",
+			data:       forgeGroup(watermarkedBranch),
+			wantBranch: watermarkedBranch,
+		}, {
+			name:       "branch starts with watermark byte with watermark",
+			data:       append([]byte{operationWatermark}, forgeGroup(watermarkedBranch)...),
+			wantBranch: watermarkedBranch,
+		}, {
+			name:    "only watermark",
+			data:    []byte{operationWatermark},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, err := unforgeGroup(tt.data)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			wantBranch, err := encoding.EncodeBase58(tt.wantBranch, []byte(encoding.PrefixBlockHash))
+			require.NoError(t, err)
+			assert.Equal(t, wantBranch, group.Branch)
+			require.Len(t, group.Contents, 1)
+			assert.Equal(t, "transaction", group.Contents[0].Kind)
+			assert.Equal(t, "tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6", group.Contents[0].Destination)
+			assert.EqualValues(t, 1000000, group.Contents[0].Amount)
+		})
+	}
+}
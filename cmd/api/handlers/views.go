@@ -5,22 +5,30 @@ import (
 	"net/http"
 
 	"github.com/baking-bad/bcdhub/internal/bcd/ast"
-	"github.com/baking-bad/bcdhub/internal/bcd/base"
 	"github.com/baking-bad/bcdhub/internal/bcd/consts"
 	"github.com/baking-bad/bcdhub/internal/models/contract_metadata"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
 	"github.com/baking-bad/bcdhub/internal/views"
 	"github.com/gin-gonic/gin"
 )
 
+// view kinds
+const (
+	viewKindOnChain  = "on-chain"
+	viewKindOffChain = "off-chain"
+)
+
 var (
 	errNoViews               = errors.New("there aren't views in the metadata")
+	errUnknownOnChainView    = errors.New("unknown on-chain view")
 	errInvalidImplementation = errors.New("invalid implementation index")
 	errEmptyImplementation   = errors.New("empty implementation")
 )
 
 // GetViewsSchema godoc
-// @Summary Get view schemas of contract metadata
-// @Description Get view schemas of contract metadata
+// @Summary Get view schemas of contract
+// @Description Get view schemas of contract: on-chain views from contract`s script and off-chain views from contract metadata
 // @Tags contract
 // @ID get-contract-tzip-views-schema
 // @Param network path string true "Network"
@@ -38,15 +46,30 @@ func (ctx *Context) GetViewsSchema(c *gin.Context) {
 		return
 	}
 
-	tzip, err := ctx.ContractMetadata.Get(req.NetworkID(), req.Address)
+	schemas := make([]ViewSchema, 0)
+
+	onChainViews, err := ctx.getOnChainViews(req.NetworkID(), req.Address)
 	if ctx.handleError(c, err, 0) {
 		return
 	}
 
-	schemas := make([]ViewSchema, 0)
+	for i := range onChainViews {
+		schema, ok, err := getOnChainViewSchema(onChainViews[i])
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		if ok {
+			schemas = append(schemas, schema)
+		}
+	}
 
-	if len(tzip.Views) == 0 {
-		c.SecureJSON(http.StatusOK, schemas)
+	tzip, err := ctx.ContractMetadata.Get(req.NetworkID(), req.Address)
+	if err != nil {
+		if ctx.Storage.IsRecordNotFound(err) {
+			c.SecureJSON(http.StatusOK, schemas)
+			return
+		}
+		ctx.handleError(c, err, 0)
 		return
 	}
 
@@ -58,6 +81,7 @@ func (ctx *Context) GetViewsSchema(c *gin.Context) {
 
 			schema := ViewSchema{
 				Name:           view.Name,
+				Kind:           viewKindOffChain,
 				Description:    view.Description,
 				Implementation: i,
 			}
@@ -87,8 +111,8 @@ func (ctx *Context) GetViewsSchema(c *gin.Context) {
 }
 
 // ExecuteView godoc
-// @Summary Execute view of contracts metadata
-// @Description Execute view of contracts metadata
+// @Summary Execute view of contract
+// @Description Execute on-chain view of contract or off-chain view of contract metadata
 // @Tags contract
 // @ID contract-execute-view
 // @Param network path string true "Network"
@@ -121,88 +145,189 @@ func (ctx *Context) ExecuteView(c *gin.Context) {
 		return
 	}
 
-	tzipValue, err := ctx.ContractMetadata.Get(req.NetworkID(), req.Address)
-	if ctx.handleError(c, err, 0) {
-		return
+	viewContext := views.Context{
+		Network:                  req.NetworkID(),
+		Contract:                 req.Address,
+		Source:                   execView.Source,
+		Initiator:                execView.Sender,
+		ChainID:                  state.ChainID,
+		HardGasLimitPerOperation: execView.GasLimit,
+		Amount:                   execView.Amount,
+		Protocol:                 state.Protocol.Hash,
 	}
 
-	if len(tzipValue.Views) == 0 {
-		ctx.handleError(c, errNoViews, 0)
-		return
-	}
+	var (
+		parameterTree *ast.TypedAst
+		returnType    *ast.TypedAst
+		execute       func() ([]byte, error)
+	)
 
-	var impl contract_metadata.ViewImplementation
-	for _, view := range tzipValue.Views {
-		if view.Name != execView.Name {
-			continue
+	switch execView.Kind {
+	case viewKindOnChain:
+		onChainViews, err := ctx.getOnChainViews(req.NetworkID(), req.Address)
+		if ctx.handleError(c, err, 0) {
+			return
 		}
-		idx := *execView.Implementation
-		if len(view.Implementations) <= idx {
-			ctx.handleError(c, errInvalidImplementation, 0)
+		view, ok := ast.FindView(onChainViews, execView.Name)
+		if !ok {
+			ctx.handleError(c, errUnknownOnChainView, http.StatusBadRequest)
 			return
 		}
-		impl = view.Implementations[idx]
-		break
+		parameterTree, err = view.ParameterType()
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		returnType, err = view.ReturnTypeTree()
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		onChainView, err := views.NewOnChainView(view)
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		execute = func() ([]byte, error) {
+			return views.ExecuteOnChainView(rpc, onChainView, viewContext)
+		}
+	default:
+		impl, err := ctx.getOffChainViewImplementation(req.NetworkID(), req.Address, execView.Name, *execView.Implementation)
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		parameterTree, err = getViewTree(impl)
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		returnType, err = ast.NewTypedAstFromBytes(impl.MichelsonStorageView.ReturnType)
+		if ctx.handleError(c, err, 0) {
+			return
+		}
+		offChainView := views.NewMichelsonStorageView(impl, execView.Name)
+		execute = func() ([]byte, error) {
+			return executeOffChainView(rpc, offChainView, viewContext)
+		}
 	}
-	if impl.MichelsonStorageView.Empty() {
-		ctx.handleError(c, errEmptyImplementation, 0)
+
+	if err := parameterTree.FromJSONSchema(execView.Data); ctx.handleError(c, err, 0) {
 		return
 	}
-
-	tree, err := getViewTree(impl)
+	parameters, err := parameterTree.ToParameters("")
 	if ctx.handleError(c, err, 0) {
 		return
 	}
-	if err := tree.FromJSONSchema(execView.Data); ctx.handleError(c, err, 0) {
+	viewContext.Parameters = string(parameters)
+
+	response, err := execute()
+	if ctx.handleError(c, err, 0) {
 		return
 	}
-	parameters, err := tree.ToParameters("")
-	if ctx.handleError(c, err, 0) {
+	if response == nil {
+		c.SecureJSON(http.StatusOK, nil)
 		return
 	}
 
-	view := views.NewMichelsonStorageView(impl, execView.Name)
-	response, err := views.ExecuteWithoutParsing(rpc, view, views.Context{
-		Network:                  req.NetworkID(),
-		Contract:                 req.Address,
-		Source:                   execView.Source,
-		Initiator:                execView.Sender,
-		ChainID:                  state.ChainID,
-		HardGasLimitPerOperation: execView.GasLimit,
-		Amount:                   execView.Amount,
-		Protocol:                 state.Protocol.Hash,
-		Parameters:               string(parameters),
-	})
-	if ctx.handleError(c, err, 0) {
+	if err := returnType.SettleFromBytes(response); ctx.handleError(c, err, 0) {
 		return
 	}
 
-	storage, err := ast.NewTypedAstFromBytes(impl.MichelsonStorageView.ReturnType)
+	miguel, err := returnType.ToMiguel()
 	if ctx.handleError(c, err, 0) {
 		return
 	}
 
+	c.SecureJSON(http.StatusOK, miguel)
+}
+
+func (ctx *Context) getOnChainViews(network types.Network, address string) ([]ast.View, error) {
+	state, err := ctx.Cache.CurrentBlock(network)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ctx.Contracts.ScriptPart(network, address, state.Protocol.SymLink, "views")
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewViews(data)
+}
+
+func (ctx *Context) getOffChainViewImplementation(network types.Network, address, name string, idx int) (contract_metadata.ViewImplementation, error) {
+	var impl contract_metadata.ViewImplementation
+
+	tzipValue, err := ctx.ContractMetadata.Get(network, address)
+	if err != nil {
+		return impl, err
+	}
+
+	if len(tzipValue.Views) == 0 {
+		return impl, errNoViews
+	}
+
+	for _, view := range tzipValue.Views {
+		if view.Name != name {
+			continue
+		}
+		if idx < 0 || len(view.Implementations) <= idx {
+			return impl, errInvalidImplementation
+		}
+		impl = view.Implementations[idx]
+		break
+	}
+	if impl.MichelsonStorageView.Empty() {
+		return impl, errEmptyImplementation
+	}
+	return impl, nil
+}
+
+// executeOffChainView - returns nil if view returns `None`
+func executeOffChainView(rpc noderpc.INode, view views.View, viewContext views.Context) ([]byte, error) {
+	response, err := views.ExecuteWithoutParsing(rpc, view, viewContext)
+	if err != nil {
+		return nil, err
+	}
+
 	var responseTree ast.UntypedAST
-	if err := json.Unmarshal(response, &responseTree); ctx.handleError(c, err, 0) {
-		return
+	if err := json.Unmarshal(response, &responseTree); err != nil {
+		return nil, err
 	}
 
+	if len(responseTree) == 0 {
+		return nil, views.ErrNodeReturn
+	}
 	if responseTree[0].Prim == consts.None {
-		c.SecureJSON(http.StatusOK, nil)
-		return
+		return nil, nil
 	}
 
-	settleData := []*base.Node{responseTree[0].Args[0]}
-	if err := storage.Settle(settleData); ctx.handleError(c, err, 0) {
-		return
+	return json.Marshal(responseTree[0].Args[0])
+}
+
+func getOnChainViewSchema(view ast.View) (ViewSchema, bool, error) {
+	schema := ViewSchema{
+		Name: view.Name,
+		Kind: viewKindOnChain,
 	}
 
-	miguel, err := storage.ToMiguel()
-	if ctx.handleError(c, err, 0) {
-		return
+	tree, err := view.ParameterType()
+	if err != nil {
+		return schema, false, err
+	}
+	entrypoints, err := tree.GetEntrypointsDocs()
+	if err != nil {
+		return schema, false, err
+	}
+	if len(entrypoints) != 1 {
+		return schema, false, nil
+	}
+	schema.Type = entrypoints[0].Type
+	schema.Schema, err = tree.ToJSONSchema()
+	if err != nil {
+		return schema, false, err
 	}
 
-	c.SecureJSON(http.StatusOK, miguel)
+	returnType, err := view.ReturnTypeTree()
+	if err != nil {
+		return schema, false, err
+	}
+	schema.ReturnType, err = returnType.Docs(ast.DocsFull)
+	return schema, err == nil, err
 }
 
 func getViewTree(impl contract_metadata.ViewImplementation) (*ast.TypedAst, error) {
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/models/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// WebhooksAuth - checks bearer token of webhooks management endpoints
+func (ctx *Context) WebhooksAuth() gin.HandlerFunc {
+	token := []byte(ctx.Config.API.WebhooksToken)
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			ctx.handleError(c, errors.New("missing token"), http.StatusUnauthorized)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, "Bearer ")), token) != 1 {
+			ctx.handleError(c, errors.New("invalid token"), http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ListWebhooks godoc
+// @Summary List webhooks
+// @Description List webhook subscriptions
+// @Tags webhooks
+// @ID list-webhooks
+// @Param network query string false "network"
+// @Param address query string false "watched address" minlength(36) maxlength(36)
+// @Param size query integer false "Size" mininum(1)
+// @Param offset query integer false "Offset" mininum(0)
+// @Accept json
+// @Produce json
+// @Success 200 {array} Webhook
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 500 {object} Error
+// @Security ApiKeyAuth
+// @Router /v1/webhooks [get]
+func (ctx *Context) ListWebhooks(c *gin.Context) {
+	var req webhooksListRequest
+	if err := c.BindQuery(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	subs, err := ctx.Webhooks.List(req.NetworkID(), req.Address, req.Size, req.Offset)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	response := make([]Webhook, len(subs))
+	for i := range subs {
+		response[i] = NewWebhookFromModel(subs[i])
+	}
+	c.SecureJSON(http.StatusOK, response)
+}
+
+// CreateWebhook godoc
+// @Summary Create webhook
+// @Description Subscribe URL to activity of address. If `secret` is set, payload is signed: `X-BCD-Signature` header contains `sha256=` and hex encoded HMAC-SHA256 of `{X-BCD-Timestamp}.{body}`.
+// @Tags webhooks
+// @ID create-webhook
+// @Param body body createWebhookRequest true "Webhook"
+// @Accept json
+// @Produce json
+// @Success 201 {object} Webhook
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 500 {object} Error
+// @Security ApiKeyAuth
+// @Router /v1/webhooks [post]
+func (ctx *Context) CreateWebhook(c *gin.Context) {
+	var req createWebhookRequest
+	if err := c.BindJSON(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	existing, err := ctx.Webhooks.List(req.NetworkID(), req.Address, 0, 0)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+	for i := range existing {
+		if existing[i].URL == req.URL {
+			ctx.handleError(c, errors.Errorf("webhook already exists: %d", existing[i].ID), http.StatusBadRequest)
+			return
+		}
+	}
+
+	sub := webhook.Subscription{
+		Network:   req.NetworkID(),
+		Address:   req.Address,
+		URL:       req.URL,
+		Secret:    req.Secret,
+		Alias:     req.Alias,
+		WatchMask: req.WatchMask,
+	}
+	if err := ctx.Webhooks.Create(&sub); ctx.handleError(c, err, 0) {
+		return
+	}
+	c.SecureJSON(http.StatusCreated, NewWebhookFromModel(sub))
+}
+
+// GetWebhook godoc
+// @Summary Get webhook
+// @Description Get webhook subscription
+// @Tags webhooks
+// @ID get-webhook
+// @Param id path integer true "Webhook ID"
+// @Accept json
+// @Produce json
+// @Success 200 {object} Webhook
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Security ApiKeyAuth
+// @Router /v1/webhooks/{id} [get]
+func (ctx *Context) GetWebhook(c *gin.Context) {
+	var req getWebhookRequest
+	if err := c.BindUri(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	sub, err := ctx.Webhooks.Get(req.ID)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+	c.SecureJSON(http.StatusOK, NewWebhookFromModel(sub))
+}
+
+// UpdateWebhook godoc
+// @Summary Update webhook
+// @Description Update URL, secret, alias or watch mask of webhook subscription. Omitted fields are not changed.
+// @Tags webhooks
+// @ID update-webhook
+// @Param id path integer true "Webhook ID"
+// @Param body body updateWebhookRequest true "Changed fields"
+// @Accept json
+// @Produce json
+// @Success 200 {object} Webhook
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Security ApiKeyAuth
+// @Router /v1/webhooks/{id} [patch]
+func (ctx *Context) UpdateWebhook(c *gin.Context) {
+	var req getWebhookRequest
+	if err := c.BindUri(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	var body updateWebhookRequest
+	if err := c.BindJSON(&body); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	sub, err := ctx.Webhooks.Get(req.ID)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+	if body.URL != nil {
+		sub.URL = *body.URL
+	}
+	if body.Secret != nil {
+		sub.Secret = *body.Secret
+	}
+	if body.Alias != nil {
+		sub.Alias = *body.Alias
+	}
+	if body.WatchMask != nil {
+		sub.WatchMask = *body.WatchMask
+	}
+
+	if err := ctx.Webhooks.Update(&sub); ctx.handleError(c, err, 0) {
+		return
+	}
+	c.SecureJSON(http.StatusOK, NewWebhookFromModel(sub))
+}
+
+// DeleteWebhook godoc
+// @Summary Delete webhook
+// @Description Delete webhook subscription with its dead letters
+// @Tags webhooks
+// @ID delete-webhook
+// @Param id path integer true "Webhook ID"
+// @Accept json
+// @Produce json
+// @Success 204 ""
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Security ApiKeyAuth
+// @Router /v1/webhooks/{id} [delete]
+func (ctx *Context) DeleteWebhook(c *gin.Context) {
+	var req getWebhookRequest
+	if err := c.BindUri(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	if _, err := ctx.Webhooks.Get(req.ID); ctx.handleError(c, err, 0) {
+		return
+	}
+	if err := ctx.Webhooks.Delete(req.ID); ctx.handleError(c, err, 0) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetWebhookDeadLetters godoc
+// @Summary Get dead letters of webhook
+// @Description Get notifications which were not delivered after all retries
+// @Tags webhooks
+// @ID get-webhook-dead-letters
+// @Param id path integer true "Webhook ID"
+// @Param size query integer false "Size" mininum(1)
+// @Param offset query integer false "Offset" mininum(0)
+// @Accept json
+// @Produce json
+// @Success 200 {array} DeadLetter
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 500 {object} Error
+// @Security ApiKeyAuth
+// @Router /v1/webhooks/{id}/dead_letters [get]
+func (ctx *Context) GetWebhookDeadLetters(c *gin.Context) {
+	var req getWebhookRequest
+	if err := c.BindUri(&req); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	var page pageableRequest
+	if err := c.BindQuery(&page); ctx.handleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	letters, err := ctx.Webhooks.DeadLetters(req.ID, page.Size, page.Offset)
+	if ctx.handleError(c, err, 0) {
+		return
+	}
+
+	response := make([]DeadLetter, len(letters))
+	for i := range letters {
+		response[i] = NewDeadLetterFromModel(letters[i])
+	}
+	c.SecureJSON(http.StatusOK, response)
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait      = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingPeriod     = (wsPongWait * 9) / 10
+	wsMaxMessageSize = 4096
+	wsSendBufferSize = 256
+)
+
+// Subscribe godoc
+// @Summary Subscribe to indexer events
+// @Description WebSocket endpoint. Send `{"action": "subscribe", "channel": "operations", "network": "mainnet", "address": "KT1...", "entrypoint": "transfer"}` to subscribe.
+// @Description Channels: `operations` (filters: `address`, `entrypoint`), `big_maps` (filters: `address`, `ptr`), `transfers` (filter: `address`) and `head`.
+// @Description Server replies with `subscribed` message containing subscription `id`. Send `{"action": "unsubscribe", "id": 1}` to unsubscribe.
+// @Description After rollback server sends `rollback` message with new level and sends items of re-indexed levels again.
+// @Tags subscriptions
+// @ID subscribe
+// @Success 101 {object} SubscriptionMessage
+// @Failure 400 {object} Error
+// @Router /v1/ws [get]
+func (ctx *Context) Subscribe(c *gin.Context) {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+	}
+	if ctx.Config.API.CorsEnabled {
+		upgrader.CheckOrigin = func(r *http.Request) bool { return true }
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Err(err)
+		return
+	}
+
+	client := newWSClient(ctx.Hub, conn)
+	ctx.Hub.register(client)
+
+	go client.writePump()
+	go client.readPump()
+}
+
+type wsClient struct {
+	hub    *Hub
+	conn   *websocket.Conn
+	output chan []byte
+
+	subs   map[int64]subscription
+	lastID int64
+	closed bool
+	mx     sync.RWMutex
+}
+
+func newWSClient(hub *Hub, conn *websocket.Conn) *wsClient {
+	return &wsClient{
+		hub:    hub,
+		conn:   conn,
+		output: make(chan []byte, wsSendBufferSize),
+		subs:   make(map[int64]subscription),
+	}
+}
+
+func (client *wsClient) subscriptions(network types.Network) []subscription {
+	client.mx.RLock()
+	defer client.mx.RUnlock()
+
+	result := make([]subscription, 0)
+	for _, sub := range client.subs {
+		if sub.Network == network {
+			result = append(result, sub)
+		}
+	}
+	return result
+}
+
+// send - queues message. Slow client which doesn't read messages is disconnected.
+func (client *wsClient) send(msg SubscriptionMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		logger.Err(err)
+		return
+	}
+
+	client.mx.Lock()
+	defer client.mx.Unlock()
+
+	if client.closed {
+		return
+	}
+	select {
+	case client.output <- data:
+	default:
+		client.closed = true
+		close(client.output)
+	}
+}
+
+func (client *wsClient) close() {
+	client.mx.Lock()
+	defer client.mx.Unlock()
+
+	if !client.closed {
+		client.closed = true
+		close(client.output)
+	}
+}
+
+func (client *wsClient) readPump() {
+	defer func() {
+		client.hub.unregister(client)
+		client.close()
+		client.conn.Close()
+	}()
+
+	client.conn.SetReadLimit(wsMaxMessageSize)
+	if err := client.conn.SetReadDeadline(time.Now().Add(wsPongWait)); err != nil {
+		return
+	}
+	client.conn.SetPongHandler(func(string) error {
+		return client.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := client.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logger.Err(err)
+			}
+			return
+		}
+
+		var req subscriptionRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			client.send(SubscriptionMessage{Type: messageError, Error: err.Error()})
+			continue
+		}
+		client.handleRequest(req)
+	}
+}
+
+func (client *wsClient) handleRequest(req subscriptionRequest) {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		client.send(SubscriptionMessage{Type: messageError, Error: err.Error()})
+		return
+	}
+	if req.Entrypoint != "" && req.Channel != channelOperations {
+		client.send(SubscriptionMessage{Type: messageError, Error: "entrypoint filter is allowed only for operations channel"})
+		return
+	}
+	if req.Ptr != nil && req.Channel != channelBigMaps {
+		client.send(SubscriptionMessage{Type: messageError, Error: "ptr filter is allowed only for big_maps channel"})
+		return
+	}
+
+	switch req.Action {
+	case "subscribe":
+		client.mx.Lock()
+		client.lastID++
+		sub := newSubscription(client.lastID, req)
+		client.subs[sub.ID] = sub
+		client.mx.Unlock()
+
+		client.send(SubscriptionMessage{
+			Type:         messageSubscribed,
+			Subscription: sub.ID,
+			Network:      sub.Network.String(),
+		})
+	case "unsubscribe":
+		client.mx.Lock()
+		_, ok := client.subs[req.ID]
+		delete(client.subs, req.ID)
+		client.mx.Unlock()
+
+		if !ok {
+			client.send(SubscriptionMessage{Type: messageError, Subscription: req.ID, Error: "unknown subscription"})
+			return
+		}
+		client.send(SubscriptionMessage{Type: messageUnsubscribed, Subscription: req.ID})
+	}
+}
+
+func (client *wsClient) writePump() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		client.conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-client.output:
+			if err := client.conn.SetWriteDeadline(time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+			if !ok {
+				_ = client.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := client.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := client.conn.SetWriteDeadline(time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+			if err := client.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/config"
 	"github.com/baking-bad/bcdhub/internal/helpers"
 	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/telemetry"
 	"github.com/gin-contrib/cache"
 	"github.com/gin-contrib/cache/persistence"
 	"github.com/gin-contrib/cors"
@@ -56,7 +57,7 @@ func newApp() *app {
 
 func (api *app) makeRouter() {
 	r := gin.New()
-	store := persistence.NewInMemoryStore(time.Second * 30)
+	var store persistence.CacheStore = persistence.NewInMemoryStore(time.Second * 30)
 
 	r.MaxMultipartMemory = 4 << 20 // max upload size 4 MiB
 	r.SecureJsonPrefix("")         // do not prepend while(1)
@@ -77,6 +78,14 @@ func (api *app) makeRouter() {
 
 	r.Use(gin.Recovery())
 
+	if prometheus := api.Context.Config.API.Prometheus; prometheus.Enabled {
+		r.Use(telemetry.Middleware())
+		store = telemetry.NewCacheStore(store)
+		if prometheus.Bind == "" {
+			r.GET(telemetry.Path, gin.WrapH(telemetry.Handler()))
+		}
+	}
+
 	if env := os.Getenv(config.EnvironmentVar); env == config.EnvironmentProd {
 		r.Use(loggerFormat())
 	} else {
@@ -92,10 +101,32 @@ func (api *app) makeRouter() {
 		v1.GET("pick_random", api.Context.GetRandomContract)
 		v1.GET("search", api.Context.Search)
 		v1.POST("fork", api.Context.ForkContract)
+		v1.POST("simulate/:network", api.Context.SimulateOperations)
+		v1.POST("simulate/:network/origination", api.Context.OriginationDryRun)
+		v1.POST("unforge/:network", api.Context.UnforgeOperation)
 		v1.GET("config", api.Context.GetConfig)
+		v1.GET("rpc/status", api.Context.GetRPCStatus)
+		v1.GET("ws", api.Context.Subscribe)
 
 		v1.POST("diff", api.Context.GetDiff)
 
+		michelson := v1.Group("michelson")
+		{
+			michelson.POST("to_micheline", api.Context.MichelsonToMicheline)
+			michelson.POST("to_michelson", api.Context.MichelineToMichelson)
+			michelson.POST("schema", api.Context.GetMichelsonTypeSchema)
+			michelson.POST("pack", api.Context.PackMichelsonValue)
+			michelson.POST("unpack", api.Context.UnpackMichelsonValue)
+		}
+
+		base58 := v1.Group("base58")
+		{
+			base58.GET("decode", api.Context.DecodeBase58)
+			base58.GET("encode", api.Context.EncodeBase58)
+			base58.GET("address", api.Context.GetAddressByPublicKey)
+			base58.GET("originated_address", api.Context.GetOriginatedAddress)
+		}
+
 		operation := v1.Group("operation/:id")
 		{
 			operation.GET("error_location", api.Context.GetOperationErrorLocation)
@@ -123,13 +154,22 @@ func (api *app) makeRouter() {
 			bigmap.GET("", cache.CachePage(store, time.Second*30, api.Context.GetBigMap))
 			bigmap.GET("count", api.Context.GetBigMapDiffCount)
 			bigmap.GET("history", api.Context.GetBigMapHistory)
+			bigmap.GET("changes", api.Context.GetBigMapChanges)
 			keys := bigmap.Group("keys")
 			{
 				keys.GET("", api.Context.GetBigMapKeys)
+				keys.POST("", api.Context.GetBigMapByKey)
 				keys.GET(":key_hash", api.Context.GetBigMapByKeyHash)
 			}
 		}
 
+		sapling := v1.Group("sapling/:network/:ptr")
+		{
+			sapling.GET("", api.Context.GetSaplingState)
+			sapling.GET("commitments", api.Context.GetSaplingCommitments)
+			sapling.GET("nullifiers", api.Context.GetSaplingNullifiers)
+		}
+
 		contract := v1.Group("contract/:network/:address")
 		{
 			contract.GET("", api.Context.GetContract)
@@ -206,6 +246,21 @@ func (api *app) makeRouter() {
 		{
 			globalConstants.GET("", api.Context.GetGlobalConstant)
 		}
+
+		if api.Context.Config.API.WebhooksToken != "" {
+			webhooks := v1.Group("webhooks", api.Context.WebhooksAuth())
+			{
+				webhooks.GET("", api.Context.ListWebhooks)
+				webhooks.POST("", api.Context.CreateWebhook)
+				webhook := webhooks.Group(":id")
+				{
+					webhook.GET("", api.Context.GetWebhook)
+					webhook.PATCH("", api.Context.UpdateWebhook)
+					webhook.DELETE("", api.Context.DeleteWebhook)
+					webhook.GET("dead_letters", api.Context.GetWebhookDeadLetters)
+				}
+			}
+		}
 	}
 	api.Router = r
 }
@@ -215,6 +270,12 @@ func (api *app) Close() {
 }
 
 func (api *app) Run() {
+	if prometheus := api.Context.Config.API.Prometheus; prometheus.Enabled && prometheus.Bind != "" {
+		server := telemetry.NewServer(prometheus.Bind)
+		server.Start()
+		defer server.Close()
+	}
+
 	if err := api.Router.Run(api.Context.Config.API.Bind); err != nil {
 		logger.Err(err)
 		helpers.CatchErrorSentry(err)
@@ -232,6 +293,10 @@ func (api *app) Run() {
 // @x-logo {"url": "https://better-call.dev/img/logo_og.png", "altText": "Better Call Dev logo", "href": "https://better-call.dev"}
 
 // @query.collection.format multi
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
 func main() {
 	api := newApp()
 	defer api.Close()
@@ -242,7 +307,7 @@ func main() {
 func corsSettings() gin.HandlerFunc {
 	return cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PATCH"},
+		AllowMethods:     []string{"GET", "POST", "PATCH", "DELETE"},
 		AllowHeaders:     []string{"X-Requested-With", "Authorization", "Origin", "Content-Length", "Content-Type", "Referer", "Cache-Control", "User-Agent"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -8,6 +8,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/bcd/contract"
 	"github.com/baking-bad/bcdhub/internal/config"
 	"github.com/baking-bad/bcdhub/internal/helpers"
+	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/go-playground/validator/v10"
 )
@@ -34,6 +35,10 @@ func Register(v *validator.Validate, cfg config.APIConfig) error {
 		return err
 	}
 
+	if err := v.RegisterValidation("operation_kind", operationKindValidator()); err != nil {
+		return err
+	}
+
 	if err := v.RegisterValidation("faversion", faVersionValidator()); err != nil {
 		return err
 	}
@@ -70,7 +75,7 @@ func addressValidator() validator.Func {
 func networkValidator(networks []string) validator.Func {
 	return func(fl validator.FieldLevel) bool {
 		network := fl.Field().String()
-		return helpers.StringInArray(network, networks)
+		return helpers.StringInArray(network, networks) && types.NewNetwork(network) != types.Empty
 	}
 }
 
@@ -103,6 +108,26 @@ func statusValidator() validator.Func {
 	}
 }
 
+func operationKindValidator() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		kinds := strings.Split(fl.Field().String(), ",")
+		for i := range kinds {
+			if !helpers.StringInArray(kinds[i], []string{
+				consts.Transaction,
+				consts.Origination,
+				consts.OriginationNew,
+				consts.Delegation,
+				consts.Reveal,
+				consts.RegisterGlobalConstant,
+				consts.SetDepositsLimit,
+			}) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
 func faVersionValidator() validator.Func {
 	return func(fl validator.FieldLevel) bool {
 		version := fl.Field().String()
@@ -0,0 +1,11 @@
+-- Indices used by GraphQL connections and batch loaders. The script is executed on every start of the service,
+-- so statements must be idempotent. Names match indices created by indexer for mainnet to avoid duplicates.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS operations_hash_idx ON operations (hash);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS big_map_diff_operation_id_idx ON big_map_diffs (operation_id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS big_map_state_ptr_idx ON big_map_states (network, ptr);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS transfers_operation_id_idx ON transfers (operation_id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS transfers_from_idx ON transfers ("from_id");
+CREATE INDEX CONCURRENTLY IF NOT EXISTS transfers_to_idx ON transfers ("to_id");
+CREATE INDEX CONCURRENTLY IF NOT EXISTS transfers_by_token_idx ON transfers (network, contract, token_id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS token_balances_by_token_idx ON token_balances (network, contract, token_id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS token_metadata_network_level_idx ON token_metadata (network, level);
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/baking-bad/bcdhub/cmd/graphql/resolvers"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/helpers"
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+const defaultMaxDepth = 8
+
+// initScript - SQL script which is executed on start. It's copied to working directory of container.
+const initScript = "init.sql"
+
+type app struct {
+	Router  *gin.Engine
+	Context *config.Context
+}
+
+func newApp() *app {
+	cfg, err := config.LoadDefaultConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	if cfg.GraphQL.SentryEnabled {
+		helpers.InitSentry(cfg.Sentry.Debug, cfg.Sentry.Environment, cfg.Sentry.URI)
+		helpers.SetTagSentry("project", cfg.GraphQL.ProjectName)
+		defer helpers.CatchPanicSentry()
+	}
+
+	ctx, err := config.NewContext(
+		config.WithStorage(cfg.Storage, cfg.GraphQL.ProjectName, int64(cfg.GraphQL.PageSize), cfg.GraphQL.Connections.Open, cfg.GraphQL.Connections.Idle),
+		config.WithNetworks(cfg.Networks),
+		config.WithConfigCopy(cfg),
+	)
+	if err != nil {
+		logger.Err(err)
+		helpers.CatchErrorSentry(err)
+		return nil
+	}
+
+	if err := initDatabase(ctx, initScript); err != nil {
+		logger.Warning().Err(err).Msg("database initialization")
+	}
+
+	maxDepth := cfg.GraphQL.MaxDepth
+	if maxDepth < 1 {
+		maxDepth = defaultMaxDepth
+	}
+	schema, err := resolvers.NewSchema(ctx, graphql.MaxDepth(maxDepth))
+	if err != nil {
+		ctx.Close()
+		logger.Err(err)
+		helpers.CatchErrorSentry(err)
+		return nil
+	}
+
+	api := &app{
+		Context: ctx,
+	}
+	api.makeRouter(schema)
+
+	return api
+}
+
+func (api *app) makeRouter(schema *graphql.Schema) {
+	r := gin.New()
+
+	if api.Context.Config.GraphQL.CorsEnabled {
+		r.Use(corsSettings())
+	}
+
+	if api.Context.Config.GraphQL.SentryEnabled {
+		r.Use(helpers.SentryMiddleware())
+	}
+
+	r.Use(gin.Recovery())
+
+	if env := os.Getenv(config.EnvironmentVar); env == config.EnvironmentProd {
+		r.Use(loggerFormat())
+	} else {
+		r.Use(gin.Logger())
+	}
+
+	r.POST("graphql", gin.WrapH(&relay.Handler{Schema: schema}))
+
+	api.Router = r
+}
+
+// initDatabase - executes statements of SQL script one by one. Missing script is skipped.
+func initDatabase(ctx *config.Context, filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			logger.Info().Msgf("%s is not found, database initialization is skipped", filename)
+			return nil
+		}
+		return err
+	}
+
+	for _, query := range strings.Split(string(data), ";") {
+		if strings.TrimSpace(query) == "" {
+			continue
+		}
+		if _, err := ctx.StorageDB.DB.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close -
+func (api *app) Close() {
+	api.Context.Close()
+}
+
+// Run -
+func (api *app) Run() {
+	if err := api.Router.Run(api.Context.Config.GraphQL.Bind); err != nil {
+		logger.Err(err)
+		helpers.CatchErrorSentry(err)
+		return
+	}
+}
+
+func main() {
+	api := newApp()
+	if api == nil {
+		return
+	}
+	defer api.Close()
+
+	api.Run()
+}
+
+func corsSettings() gin.HandlerFunc {
+	return cors.New(cors.Config{
+		AllowOrigins:     []string{"*"},
+		AllowMethods:     []string{"POST"},
+		AllowHeaders:     []string{"X-Requested-With", "Authorization", "Origin", "Content-Length", "Content-Type", "Referer", "Cache-Control", "User-Agent"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	})
+}
+
+func loggerFormat() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		return fmt.Sprintf("%15s | %3d | %13v | %-7s %s | %s\n%s",
+			param.ClientIP,
+			param.StatusCode,
+			param.Latency,
+			param.Method,
+			param.Path,
+			param.Request.UserAgent(),
+			param.ErrorMessage,
+		)
+	})
+}
@@ -0,0 +1,37 @@
+package resolvers
+
+import "github.com/baking-bad/bcdhub/internal/models/account"
+
+// Account -
+type Account struct {
+	address string
+	alias   string
+}
+
+func newAccount(acc account.Account) *Account {
+	return &Account{
+		address: acc.Address,
+		alias:   acc.Alias,
+	}
+}
+
+// newAccountOrNil - returns nil for empty relations
+func newAccountOrNil(acc account.Account) *Account {
+	if acc.Address == "" {
+		return nil
+	}
+	return newAccount(acc)
+}
+
+// Address -
+func (a *Account) Address() string {
+	return a.address
+}
+
+// Alias -
+func (a *Account) Alias() *string {
+	if a.alias == "" {
+		return nil
+	}
+	return &a.alias
+}
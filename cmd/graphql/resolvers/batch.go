@@ -0,0 +1,193 @@
+package resolvers
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/tokenmetadata"
+	"github.com/baking-bad/bcdhub/internal/models/transfer"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+)
+
+// batch - collects identifiers of one list of items. Nested resolvers of any item load related models of all items at once.
+type batch struct {
+	ctx *config.Context
+
+	accountIDs   []int64
+	operationIDs []int64
+	linkedIDs    []int64
+
+	accountsOnce sync.Once
+	accounts     map[int64]account.Account
+	accountsErr  error
+
+	diffsOnce sync.Once
+	diffs     map[int64][]bigmapdiff.BigMapDiff
+	diffsErr  error
+
+	transfersOnce sync.Once
+	transfers     map[int64][]transfer.Transfer
+	transfersErr  error
+
+	linkedOnce  sync.Once
+	linked      map[int64]operation.Operation
+	linkedBatch *batch
+	linkedErr   error
+
+	tokens   map[string]*tokenmetadata.TokenMetadata
+	tokensMx sync.Mutex
+}
+
+func newBatch(ctx *config.Context) *batch {
+	return &batch{
+		ctx:    ctx,
+		tokens: make(map[string]*tokenmetadata.TokenMetadata),
+	}
+}
+
+func (b *batch) addAccounts(ids ...int64) {
+	for i := range ids {
+		if ids[i] > 0 {
+			b.accountIDs = append(b.accountIDs, ids[i])
+		}
+	}
+}
+
+// addOperation - adds operation which big map diffs and transfers can be requested
+func (b *batch) addOperation(op operation.Operation) {
+	b.operationIDs = append(b.operationIDs, op.ID)
+	b.addAccounts(op.SourceID, op.DestinationID, op.InitiatorID, op.DelegateID)
+}
+
+// addLinked - adds operation which is referenced by big map diff or transfer
+func (b *batch) addLinked(id int64) {
+	if id > 0 {
+		b.linkedIDs = append(b.linkedIDs, id)
+	}
+}
+
+func (b *batch) account(id int64) (*Account, error) {
+	if id == 0 {
+		return nil, nil
+	}
+
+	b.accountsOnce.Do(func() {
+		b.accounts = make(map[int64]account.Account)
+		accounts, err := b.ctx.Accounts.GetByIDs(unique(b.accountIDs)...)
+		if err != nil {
+			b.accountsErr = err
+			return
+		}
+		for i := range accounts {
+			b.accounts[accounts[i].ID] = accounts[i]
+		}
+	})
+	if b.accountsErr != nil {
+		return nil, b.accountsErr
+	}
+
+	acc, ok := b.accounts[id]
+	if !ok || acc.Address == "" {
+		return nil, nil
+	}
+	return newAccount(acc), nil
+}
+
+func (b *batch) bigMapDiffs(operationID int64) ([]bigmapdiff.BigMapDiff, error) {
+	b.diffsOnce.Do(func() {
+		b.diffs = make(map[int64][]bigmapdiff.BigMapDiff)
+		diffs, err := b.ctx.BigMapDiffs.GetForOperations(unique(b.operationIDs)...)
+		if err != nil {
+			b.diffsErr = err
+			return
+		}
+		for i := range diffs {
+			b.diffs[diffs[i].OperationID] = append(b.diffs[diffs[i].OperationID], diffs[i])
+		}
+	})
+	return b.diffs[operationID], b.diffsErr
+}
+
+func (b *batch) operationTransfers(operationID int64) ([]transfer.Transfer, error) {
+	b.transfersOnce.Do(func() {
+		b.transfers = make(map[int64][]transfer.Transfer)
+		transfers, err := b.ctx.Transfers.GetForOperations(unique(b.operationIDs)...)
+		if err != nil {
+			b.transfersErr = err
+			return
+		}
+		for i := range transfers {
+			b.transfers[transfers[i].OperationID] = append(b.transfers[transfers[i].OperationID], transfers[i])
+		}
+	})
+	return b.transfers[operationID], b.transfersErr
+}
+
+// operation - returns linked operation and batch shared by all linked operations for their nested resolvers
+func (b *batch) operation(id int64) (*operation.Operation, *batch, error) {
+	if id == 0 {
+		return nil, nil, nil
+	}
+
+	b.linkedOnce.Do(func() {
+		b.linked = make(map[int64]operation.Operation)
+		b.linkedBatch = newBatch(b.ctx)
+		operations, err := b.ctx.Operations.GetByIDs(unique(b.linkedIDs)...)
+		if err != nil {
+			b.linkedErr = err
+			return
+		}
+		for i := range operations {
+			b.linked[operations[i].ID] = operations[i]
+			b.linkedBatch.addOperation(operations[i])
+		}
+	})
+	if b.linkedErr != nil {
+		return nil, nil, b.linkedErr
+	}
+
+	op, ok := b.linked[id]
+	if !ok {
+		return nil, nil, nil
+	}
+	return &op, b.linkedBatch, nil
+}
+
+// token - token metadata are cached by token, because repository can't select many tokens at once
+func (b *batch) token(network types.Network, contract string, tokenID uint64) (*tokenmetadata.TokenMetadata, error) {
+	key := fmt.Sprintf("%d:%s:%d", network, contract, tokenID)
+
+	b.tokensMx.Lock()
+	defer b.tokensMx.Unlock()
+
+	if token, ok := b.tokens[key]; ok {
+		return token, nil
+	}
+
+	token, err := b.ctx.TokenMetadata.GetOne(network, contract, tokenID)
+	if err != nil {
+		if !b.ctx.Storage.IsRecordNotFound(err) {
+			return nil, err
+		}
+		token = nil
+	}
+	b.tokens[key] = token
+	return token, nil
+}
+
+func unique(ids []int64) []int64 {
+	set := make(map[int64]struct{}, len(ids))
+	result := make([]int64, 0, len(ids))
+	for i := range ids {
+		if _, ok := set[ids[i]]; ok {
+			continue
+		}
+		set[ids[i]] = struct{}{}
+		result = append(result, ids[i])
+	}
+	return result
+}
@@ -0,0 +1,180 @@
+package resolvers
+
+import (
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// BigMapResolver -
+type BigMapResolver struct {
+	r       *Resolver
+	network types.Network
+	ptr     int64
+	stats   bigmapdiff.Stats
+}
+
+// Network -
+func (b *BigMapResolver) Network() string {
+	return b.network.String()
+}
+
+// Ptr -
+func (b *BigMapResolver) Ptr() Int64 {
+	return Int64(b.ptr)
+}
+
+// Contract -
+func (b *BigMapResolver) Contract() string {
+	return b.stats.Contract
+}
+
+// TotalKeys -
+func (b *BigMapResolver) TotalKeys() Int64 {
+	return Int64(b.stats.Total)
+}
+
+// ActiveKeys -
+func (b *BigMapResolver) ActiveKeys() Int64 {
+	return Int64(b.stats.Active)
+}
+
+// Keys -
+func (b *BigMapResolver) Keys(args struct {
+	First *int32
+	After *string
+}) (*BigMapStateConnection, error) {
+	return b.r.bigMapKeys(b.network, b.ptr, args.First, args.After)
+}
+
+// BigMapStateResolver -
+type BigMapStateResolver struct {
+	state bigmapdiff.BigMapState
+}
+
+// Ptr -
+func (s *BigMapStateResolver) Ptr() Int64 {
+	return Int64(s.state.Ptr)
+}
+
+// Contract -
+func (s *BigMapStateResolver) Contract() string {
+	return s.state.Contract
+}
+
+// KeyHash -
+func (s *BigMapStateResolver) KeyHash() string {
+	return s.state.KeyHash
+}
+
+// Key -
+func (s *BigMapStateResolver) Key() JSON {
+	return JSON(s.state.Key)
+}
+
+// Value -
+func (s *BigMapStateResolver) Value() *JSON {
+	return newJSON(s.state.Value)
+}
+
+// Removed -
+func (s *BigMapStateResolver) Removed() bool {
+	return s.state.Removed
+}
+
+// Updates -
+func (s *BigMapStateResolver) Updates() Int64 {
+	return Int64(s.state.Count)
+}
+
+// LastUpdateLevel -
+func (s *BigMapStateResolver) LastUpdateLevel() Int64 {
+	return Int64(s.state.LastUpdateLevel)
+}
+
+// LastUpdateTime -
+func (s *BigMapStateResolver) LastUpdateTime() graphql.Time {
+	return graphql.Time{Time: s.state.LastUpdateTime}
+}
+
+// BigMapStateConnection -
+type BigMapStateConnection struct {
+	nodes    []*BigMapStateResolver
+	pageInfo *PageInfo
+}
+
+// Nodes -
+func (c *BigMapStateConnection) Nodes() []*BigMapStateResolver {
+	return c.nodes
+}
+
+// PageInfo -
+func (c *BigMapStateConnection) PageInfo() *PageInfo {
+	return c.pageInfo
+}
+
+// BigMapDiffResolver -
+type BigMapDiffResolver struct {
+	r     *Resolver
+	diff  bigmapdiff.BigMapDiff
+	batch *batch
+}
+
+func newBigMapDiffResolvers(r *Resolver, diffs []bigmapdiff.BigMapDiff) []*BigMapDiffResolver {
+	b := newBatch(r.ctx)
+	result := make([]*BigMapDiffResolver, len(diffs))
+	for i := range diffs {
+		b.addLinked(diffs[i].OperationID)
+		result[i] = &BigMapDiffResolver{r, diffs[i], b}
+	}
+	return result
+}
+
+// ID -
+func (d *BigMapDiffResolver) ID() Int64 {
+	return Int64(d.diff.ID)
+}
+
+// Ptr -
+func (d *BigMapDiffResolver) Ptr() Int64 {
+	return Int64(d.diff.Ptr)
+}
+
+// Contract -
+func (d *BigMapDiffResolver) Contract() string {
+	return d.diff.Contract
+}
+
+// KeyHash -
+func (d *BigMapDiffResolver) KeyHash() string {
+	return d.diff.KeyHash
+}
+
+// Key -
+func (d *BigMapDiffResolver) Key() JSON {
+	return JSON(d.diff.Key)
+}
+
+// Value -
+func (d *BigMapDiffResolver) Value() *JSON {
+	return newJSON(d.diff.Value)
+}
+
+// Level -
+func (d *BigMapDiffResolver) Level() Int64 {
+	return Int64(d.diff.Level)
+}
+
+// Timestamp -
+func (d *BigMapDiffResolver) Timestamp() graphql.Time {
+	return graphql.Time{Time: d.diff.Timestamp}
+}
+
+// Operation -
+func (d *BigMapDiffResolver) Operation() (*OperationResolver, error) {
+	op, linked, err := d.batch.operation(d.diff.OperationID)
+	if err != nil || op == nil {
+		return nil, err
+	}
+	return &OperationResolver{d.r, *op, linked}, nil
+}
@@ -0,0 +1,193 @@
+package resolvers
+
+import (
+	"strconv"
+
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	"github.com/baking-bad/bcdhub/internal/models/tokenmetadata"
+	"github.com/baking-bad/bcdhub/internal/models/transfer"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// ContractResolver -
+type ContractResolver struct {
+	r        *Resolver
+	contract contract.Contract
+}
+
+// Network -
+func (c *ContractResolver) Network() string {
+	return c.contract.Network.String()
+}
+
+// Address -
+func (c *ContractResolver) Address() string {
+	return c.contract.Account.Address
+}
+
+// Alias -
+func (c *ContractResolver) Alias() *string {
+	if c.contract.Account.Alias == "" {
+		return nil
+	}
+	return &c.contract.Account.Alias
+}
+
+// Manager -
+func (c *ContractResolver) Manager() *Account {
+	return newAccountOrNil(c.contract.Manager)
+}
+
+// Delegate -
+func (c *ContractResolver) Delegate() *Account {
+	return newAccountOrNil(c.contract.Delegate)
+}
+
+// Level -
+func (c *ContractResolver) Level() Int64 {
+	return Int64(c.contract.Level)
+}
+
+// Timestamp -
+func (c *ContractResolver) Timestamp() graphql.Time {
+	return graphql.Time{Time: c.contract.Timestamp}
+}
+
+// LastAction -
+func (c *ContractResolver) LastAction() graphql.Time {
+	return graphql.Time{Time: c.contract.LastAction}
+}
+
+// TxCount -
+func (c *ContractResolver) TxCount() Int64 {
+	return Int64(c.contract.TxCount)
+}
+
+// MigrationsCount -
+func (c *ContractResolver) MigrationsCount() Int64 {
+	return Int64(c.contract.MigrationsCount)
+}
+
+// Tags -
+func (c *ContractResolver) Tags() []string {
+	return c.contract.Tags.ToArray()
+}
+
+// Operations - operations are paginated by groups of hash and counter. Cursor is the least operation id of page.
+func (c *ContractResolver) Operations(args struct {
+	First       *int32
+	After       *string
+	Entrypoints *[]string
+	Kinds       *[]string
+	Statuses    *[]string
+}) (*OperationConnection, error) {
+	size, err := c.r.pageSize(args.First)
+	if err != nil {
+		return nil, err
+	}
+	lastID, err := decodeCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := make(map[string]interface{})
+	if lastID > 0 {
+		filters["last_id"] = strconv.FormatInt(lastID, 10)
+	}
+	if args.Entrypoints != nil && len(*args.Entrypoints) > 0 {
+		filters["entrypoints"] = *args.Entrypoints
+	}
+	if args.Kinds != nil && len(*args.Kinds) > 0 {
+		kinds := make([]types.OperationKind, len(*args.Kinds))
+		for i, kind := range *args.Kinds {
+			kinds[i] = types.NewOperationKind(kind)
+		}
+		filters["kind"] = kinds
+	}
+	if args.Statuses != nil && len(*args.Statuses) > 0 {
+		statuses := make([]types.OperationStatus, len(*args.Statuses))
+		for i, status := range *args.Statuses {
+			statuses[i] = types.NewOperationStatus(status)
+		}
+		filters["status"] = statuses
+	}
+
+	page, err := c.r.ctx.Operations.GetByAccount(c.contract.Account, uint64(size), filters)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]struct{})
+	cursor := int64(0)
+	for i := range page.Operations {
+		groups[page.Operations[i].Hash+":"+strconv.FormatInt(page.Operations[i].Counter, 10)] = struct{}{}
+		if cursor == 0 || page.Operations[i].ID < cursor {
+			cursor = page.Operations[i].ID
+		}
+	}
+
+	return &OperationConnection{
+		nodes:    newOperationResolvers(c.r, page.Operations),
+		pageInfo: newPageInfo(cursor, int64(len(groups)) == size),
+	}, nil
+}
+
+// Transfers -
+func (c *ContractResolver) Transfers(args struct {
+	First   *int32
+	After   *string
+	TokenID *string
+}) (*TransferConnection, error) {
+	tokenID, err := parseTokenID(args.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	return c.r.transfers(transfer.GetContext{
+		Network:   c.contract.Network,
+		Contracts: []string{c.contract.Account.Address},
+		AccountID: -1,
+		TokenID:   tokenID,
+	}, args.First, args.After)
+}
+
+// BigMaps -
+func (c *ContractResolver) BigMaps() ([]*BigMapResolver, error) {
+	states, err := c.r.ctx.BigMapDiffs.GetForAddress(c.contract.Network, c.contract.Account.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[int64]*bigmapdiff.Stats)
+	ptrs := make([]int64, 0)
+	for i := range states {
+		s, ok := stats[states[i].Ptr]
+		if !ok {
+			s = &bigmapdiff.Stats{Contract: states[i].Contract}
+			stats[states[i].Ptr] = s
+			ptrs = append(ptrs, states[i].Ptr)
+		}
+		s.Total++
+		if !states[i].Removed {
+			s.Active++
+		}
+	}
+
+	result := make([]*BigMapResolver, len(ptrs))
+	for i, ptr := range ptrs {
+		result[i] = &BigMapResolver{c.r, c.contract.Network, ptr, *stats[ptr]}
+	}
+	return result, nil
+}
+
+// Tokens -
+func (c *ContractResolver) Tokens(args struct {
+	First *int32
+	After *string
+}) (*TokenMetadataConnection, error) {
+	return c.r.tokens(tokenmetadata.GetContext{
+		Network:  c.contract.Network,
+		Contract: c.contract.Account.Address,
+	}, args.First, args.After)
+}
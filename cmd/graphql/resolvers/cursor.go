@@ -0,0 +1,72 @@
+package resolvers
+
+import (
+	"encoding/base64"
+	"strconv"
+
+	"github.com/baking-bad/bcdhub/internal/postgres/consts"
+	"github.com/pkg/errors"
+)
+
+const maxPageSize = 100
+
+// PageInfo -
+type PageInfo struct {
+	endCursor   *string
+	hasNextPage bool
+}
+
+// EndCursor -
+func (p PageInfo) EndCursor() *string {
+	return p.endCursor
+}
+
+// HasNextPage -
+func (p PageInfo) HasNextPage() bool {
+	return p.hasNextPage
+}
+
+// newPageInfo - cursor points to last item of page. It's set only if next page may exist.
+func newPageInfo(cursor int64, hasNextPage bool) *PageInfo {
+	if !hasNextPage {
+		return &PageInfo{}
+	}
+	value := encodeCursor(cursor)
+	return &PageInfo{
+		endCursor:   &value,
+		hasNextPage: true,
+	}
+}
+
+// Cursor is opaque for clients. Depending on connection it contains identifier of last item or offset of next page.
+func encodeCursor(value int64) string {
+	return base64.StdEncoding.EncodeToString([]byte(strconv.FormatInt(value, 10)))
+}
+
+func decodeCursor(cursor *string) (int64, error) {
+	if cursor == nil || *cursor == "" {
+		return 0, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(*cursor)
+	if err != nil {
+		return 0, errors.Errorf("invalid cursor: %s", *cursor)
+	}
+	value, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || value < 0 {
+		return 0, errors.Errorf("invalid cursor: %s", *cursor)
+	}
+	return value, nil
+}
+
+func (r *Resolver) pageSize(first *int32) (int64, error) {
+	if first == nil {
+		if size := int64(r.ctx.Config.GraphQL.PageSize); size > 0 && size <= maxPageSize {
+			return size, nil
+		}
+		return consts.DefaultSize, nil
+	}
+	if *first < 1 || *first > maxPageSize {
+		return 0, errors.Errorf("first must be between 1 and %d", maxPageSize)
+	}
+	return int64(*first), nil
+}
@@ -0,0 +1,125 @@
+package resolvers
+
+import (
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/postgres/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+func TestCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		cursor  *string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "nil",
+			want: 0,
+		}, {
+			name:   "empty",
+			cursor: stringPtr(""),
+			want:   0,
+		}, {
+			name:   "encoded",
+			cursor: stringPtr(encodeCursor(12345)),
+			want:   12345,
+		}, {
+			name:    "not base64",
+			cursor:  stringPtr("!!!"),
+			wantErr: true,
+		}, {
+			name:    "not a number",
+			cursor:  stringPtr("YWJj"),
+			wantErr: true,
+		}, {
+			name:    "negative",
+			cursor:  stringPtr(encodeCursor(-1)),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCursor(tt.cursor)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewPageInfo(t *testing.T) {
+	last := newPageInfo(10, false)
+	assert.False(t, last.HasNextPage())
+	assert.Nil(t, last.EndCursor())
+
+	next := newPageInfo(10, true)
+	assert.True(t, next.HasNextPage())
+	require.NotNil(t, next.EndCursor())
+	value, err := decodeCursor(next.EndCursor())
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), value)
+}
+
+func TestResolver_pageSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageSize uint64
+		first    *int32
+		want     int64
+		wantErr  bool
+	}{
+		{
+			name: "default",
+			want: consts.DefaultSize,
+		}, {
+			name:     "from config",
+			pageSize: 20,
+			want:     20,
+		}, {
+			name:     "config value is too big",
+			pageSize: maxPageSize + 1,
+			want:     consts.DefaultSize,
+		}, {
+			name:  "first",
+			first: int32Ptr(5),
+			want:  5,
+		}, {
+			name:    "zero",
+			first:   int32Ptr(0),
+			wantErr: true,
+		}, {
+			name:    "too big",
+			first:   int32Ptr(maxPageSize + 1),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Resolver{ctx: &config.Context{}}
+			r.ctx.Config.GraphQL.PageSize = tt.pageSize
+
+			got, err := r.pageSize(tt.first)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package resolvers
+
+import (
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// OperationResolver -
+type OperationResolver struct {
+	r         *Resolver
+	operation operation.Operation
+	batch     *batch
+}
+
+func newOperationResolvers(r *Resolver, operations []operation.Operation) []*OperationResolver {
+	b := newBatch(r.ctx)
+	result := make([]*OperationResolver, len(operations))
+	for i := range operations {
+		b.addOperation(operations[i])
+		result[i] = &OperationResolver{r, operations[i], b}
+	}
+	return result
+}
+
+// OperationConnection -
+type OperationConnection struct {
+	nodes    []*OperationResolver
+	pageInfo *PageInfo
+}
+
+// Nodes -
+func (c *OperationConnection) Nodes() []*OperationResolver {
+	return c.nodes
+}
+
+// PageInfo -
+func (c *OperationConnection) PageInfo() *PageInfo {
+	return c.pageInfo
+}
+
+// ID -
+func (o *OperationResolver) ID() Int64 {
+	return Int64(o.operation.ID)
+}
+
+// Hash -
+func (o *OperationResolver) Hash() string {
+	return o.operation.Hash
+}
+
+// Network -
+func (o *OperationResolver) Network() string {
+	return o.operation.Network.String()
+}
+
+// Level -
+func (o *OperationResolver) Level() Int64 {
+	return Int64(o.operation.Level)
+}
+
+// Timestamp -
+func (o *OperationResolver) Timestamp() graphql.Time {
+	return graphql.Time{Time: o.operation.Timestamp}
+}
+
+// Kind -
+func (o *OperationResolver) Kind() string {
+	return o.operation.Kind.String()
+}
+
+// Status -
+func (o *OperationResolver) Status() string {
+	return o.operation.Status.String()
+}
+
+// Counter -
+func (o *OperationResolver) Counter() Int64 {
+	return Int64(o.operation.Counter)
+}
+
+// ContentIndex -
+func (o *OperationResolver) ContentIndex() Int64 {
+	return Int64(o.operation.ContentIndex)
+}
+
+// Nonce -
+func (o *OperationResolver) Nonce() *Int64 {
+	if o.operation.Nonce == nil {
+		return nil
+	}
+	nonce := Int64(*o.operation.Nonce)
+	return &nonce
+}
+
+// Source -
+func (o *OperationResolver) Source() (*Account, error) {
+	return o.batch.account(o.operation.SourceID)
+}
+
+// Destination -
+func (o *OperationResolver) Destination() (*Account, error) {
+	return o.batch.account(o.operation.DestinationID)
+}
+
+// Initiator -
+func (o *OperationResolver) Initiator() (*Account, error) {
+	return o.batch.account(o.operation.InitiatorID)
+}
+
+// Delegate -
+func (o *OperationResolver) Delegate() (*Account, error) {
+	return o.batch.account(o.operation.DelegateID)
+}
+
+// Amount -
+func (o *OperationResolver) Amount() Int64 {
+	return Int64(o.operation.Amount)
+}
+
+// Fee -
+func (o *OperationResolver) Fee() Int64 {
+	return Int64(o.operation.Fee)
+}
+
+// GasLimit -
+func (o *OperationResolver) GasLimit() Int64 {
+	return Int64(o.operation.GasLimit)
+}
+
+// StorageLimit -
+func (o *OperationResolver) StorageLimit() Int64 {
+	return Int64(o.operation.StorageLimit)
+}
+
+// ConsumedGas -
+func (o *OperationResolver) ConsumedGas() Int64 {
+	return Int64(o.operation.ConsumedGas)
+}
+
+// StorageSize -
+func (o *OperationResolver) StorageSize() Int64 {
+	return Int64(o.operation.StorageSize)
+}
+
+// PaidStorageSizeDiff -
+func (o *OperationResolver) PaidStorageSizeDiff() Int64 {
+	return Int64(o.operation.PaidStorageSizeDiff)
+}
+
+// Burned -
+func (o *OperationResolver) Burned() Int64 {
+	return Int64(o.operation.Burned)
+}
+
+// Entrypoint -
+func (o *OperationResolver) Entrypoint() *string {
+	if !o.operation.Entrypoint.Valid {
+		return nil
+	}
+	return &o.operation.Entrypoint.Str
+}
+
+// Parameters -
+func (o *OperationResolver) Parameters() *JSON {
+	return newJSON(o.operation.Parameters)
+}
+
+// BigMapDiffs -
+func (o *OperationResolver) BigMapDiffs() ([]*BigMapDiffResolver, error) {
+	diffs, err := o.batch.bigMapDiffs(o.operation.ID)
+	if err != nil {
+		return nil, err
+	}
+	return newBigMapDiffResolvers(o.r, diffs), nil
+}
+
+// Transfers -
+func (o *OperationResolver) Transfers() ([]*TransferResolver, error) {
+	transfers, err := o.batch.operationTransfers(o.operation.ID)
+	if err != nil {
+		return nil, err
+	}
+	return newTransferResolvers(o.r, transfers), nil
+}
@@ -0,0 +1,280 @@
+package resolvers
+
+import (
+	"strconv"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/tokenmetadata"
+	"github.com/baking-bad/bcdhub/internal/models/transfer"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/pkg/errors"
+)
+
+// Resolver - root resolver
+type Resolver struct {
+	ctx *config.Context
+}
+
+func (r *Resolver) network(name string) (types.Network, error) {
+	network := types.NewNetwork(name)
+	if network == types.Empty {
+		return network, errors.Errorf("unknown network: %s", name)
+	}
+	return network, nil
+}
+
+func (r *Resolver) notFound(err error) bool {
+	return err != nil && r.ctx.Storage.IsRecordNotFound(err)
+}
+
+func parseTokenID(value *string) (*uint64, error) {
+	if value == nil {
+		return nil, nil
+	}
+	tokenID, err := strconv.ParseUint(*value, 10, 64)
+	if err != nil {
+		return nil, errors.Errorf("invalid token id: %s", *value)
+	}
+	return &tokenID, nil
+}
+
+// Contract -
+func (r *Resolver) Contract(args struct {
+	Network string
+	Address string
+}) (*ContractResolver, error) {
+	network, err := r.network(args.Network)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := r.ctx.Contracts.Get(network, args.Address)
+	if err != nil {
+		if r.notFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ContractResolver{r, contract}, nil
+}
+
+// OperationGroup -
+func (r *Resolver) OperationGroup(args struct {
+	Network string
+	Hash    string
+}) ([]*OperationResolver, error) {
+	network, err := r.network(args.Network)
+	if err != nil {
+		return nil, err
+	}
+	operations, err := r.ctx.Operations.Get(map[string]interface{}{
+		"operation.network": network,
+		"operation.hash":    args.Hash,
+	}, 0, true)
+	if err != nil {
+		return nil, err
+	}
+	return newOperationResolvers(r, operations), nil
+}
+
+// BigMap -
+func (r *Resolver) BigMap(args struct {
+	Network string
+	Ptr     Int64
+}) (*BigMapResolver, error) {
+	network, err := r.network(args.Network)
+	if err != nil {
+		return nil, err
+	}
+	ptr := int64(args.Ptr)
+	stats, err := r.ctx.BigMapDiffs.GetStats(network, ptr)
+	if err != nil {
+		if r.notFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if stats.Contract == "" {
+		return nil, nil
+	}
+	return &BigMapResolver{r, network, ptr, stats}, nil
+}
+
+// Transfers -
+func (r *Resolver) Transfers(args struct {
+	Network   string
+	Contracts *[]string
+	Account   *string
+	TokenID   *string
+	First     *int32
+	After     *string
+}) (*TransferConnection, error) {
+	network, err := r.network(args.Network)
+	if err != nil {
+		return nil, err
+	}
+	tokenID, err := parseTokenID(args.TokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := transfer.GetContext{
+		Network:   network,
+		AccountID: -1,
+		TokenID:   tokenID,
+	}
+	if args.Contracts != nil {
+		ctx.Contracts = *args.Contracts
+	}
+	if args.Account != nil {
+		acc, err := r.ctx.Accounts.Get(network, *args.Account)
+		if err != nil {
+			if r.notFound(err) {
+				return &TransferConnection{pageInfo: &PageInfo{}}, nil
+			}
+			return nil, err
+		}
+		ctx.AccountID = acc.ID
+	}
+	return r.transfers(ctx, args.First, args.After)
+}
+
+func (r *Resolver) transfers(ctx transfer.GetContext, first *int32, after *string) (*TransferConnection, error) {
+	size, err := r.pageSize(first)
+	if err != nil {
+		return nil, err
+	}
+	lastID, err := decodeCursor(after)
+	if err != nil {
+		return nil, err
+	}
+	if lastID > 0 {
+		ctx.LastID = strconv.FormatInt(lastID, 10)
+	}
+	ctx.Size = size
+
+	page, err := r.ctx.Transfers.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	connection := &TransferConnection{
+		nodes:    newTransferResolvers(r, page.Transfers),
+		pageInfo: &PageInfo{},
+	}
+	if count := len(page.Transfers); int64(count) == size {
+		connection.pageInfo = newPageInfo(page.Transfers[count-1].ID, true)
+	}
+	return connection, nil
+}
+
+// Tokens -
+func (r *Resolver) Tokens(args struct {
+	Network  string
+	Contract *string
+	TokenID  *string
+	First    *int32
+	After    *string
+}) (*TokenMetadataConnection, error) {
+	network, err := r.network(args.Network)
+	if err != nil {
+		return nil, err
+	}
+	tokenID, err := parseTokenID(args.TokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := tokenmetadata.GetContext{
+		Network: network,
+		TokenID: tokenID,
+	}
+	if args.Contract != nil {
+		ctx.Contract = *args.Contract
+	}
+	return r.tokens(ctx, args.First, args.After)
+}
+
+func (r *Resolver) tokens(ctx tokenmetadata.GetContext, first *int32, after *string) (*TokenMetadataConnection, error) {
+	size, err := r.pageSize(first)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := decodeCursor(after)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := r.ctx.TokenMetadata.Get([]tokenmetadata.GetContext{ctx}, size, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*TokenMetadataResolver, len(tokens))
+	for i := range tokens {
+		nodes[i] = &TokenMetadataResolver{r, tokens[i]}
+	}
+	return &TokenMetadataConnection{
+		nodes:    nodes,
+		pageInfo: newPageInfo(offset+int64(len(tokens)), int64(len(tokens)) == size),
+	}, nil
+}
+
+// TokenHolders -
+func (r *Resolver) TokenHolders(args struct {
+	Network  string
+	Contract string
+	TokenID  string
+}) ([]*TokenBalanceResolver, error) {
+	network, err := r.network(args.Network)
+	if err != nil {
+		return nil, err
+	}
+	tokenID, err := parseTokenID(&args.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	return r.holders(network, args.Contract, *tokenID)
+}
+
+func (r *Resolver) holders(network types.Network, contract string, tokenID uint64) ([]*TokenBalanceResolver, error) {
+	balances, err := r.ctx.TokenBalances.GetHolders(network, contract, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*TokenBalanceResolver, len(balances))
+	for i := range balances {
+		result[i] = &TokenBalanceResolver{balances[i]}
+	}
+	return result, nil
+}
+
+func (r *Resolver) bigMapKeys(network types.Network, ptr int64, first *int32, after *string) (*BigMapStateConnection, error) {
+	size, err := r.pageSize(first)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := decodeCursor(after)
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := r.ctx.BigMapDiffs.Keys(bigmapdiff.GetContext{
+		Network: network,
+		Ptr:     &ptr,
+		Size:    size,
+		Offset:  offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*BigMapStateResolver, len(states))
+	for i := range states {
+		nodes[i] = &BigMapStateResolver{states[i]}
+	}
+	return &BigMapStateConnection{
+		nodes:    nodes,
+		pageInfo: newPageInfo(offset+int64(len(states)), int64(len(states)) == size),
+	}, nil
+}
@@ -0,0 +1,323 @@
+package resolvers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/contract"
+	mock_general "github.com/baking-bad/bcdhub/internal/models/mock"
+	mock_account "github.com/baking-bad/bcdhub/internal/models/mock/account"
+	mock_bmd "github.com/baking-bad/bcdhub/internal/models/mock/bigmapdiff"
+	mock_contract "github.com/baking-bad/bcdhub/internal/models/mock/contract"
+	mock_token_metadata "github.com/baking-bad/bcdhub/internal/models/mock/tokenmetadata"
+	mock_transfer "github.com/baking-bad/bcdhub/internal/models/mock/transfer"
+	"github.com/baking-bad/bcdhub/internal/models/tokenmetadata"
+	"github.com/baking-bad/bcdhub/internal/models/transfer"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/go-pg/pg/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testContext struct {
+	ctx           *config.Context
+	general       *mock_general.MockGeneralRepository
+	accounts      *mock_account.MockRepository
+	bigMapDiffs   *mock_bmd.MockRepository
+	contracts     *mock_contract.MockRepository
+	tokenMetadata *mock_token_metadata.MockRepository
+	transfers     *mock_transfer.MockRepository
+}
+
+func newTestContext(ctrl *gomock.Controller) testContext {
+	tc := testContext{
+		general:       mock_general.NewMockGeneralRepository(ctrl),
+		accounts:      mock_account.NewMockRepository(ctrl),
+		bigMapDiffs:   mock_bmd.NewMockRepository(ctrl),
+		contracts:     mock_contract.NewMockRepository(ctrl),
+		tokenMetadata: mock_token_metadata.NewMockRepository(ctrl),
+		transfers:     mock_transfer.NewMockRepository(ctrl),
+	}
+	tc.ctx = &config.Context{
+		Storage:       tc.general,
+		Accounts:      tc.accounts,
+		BigMapDiffs:   tc.bigMapDiffs,
+		Contracts:     tc.contracts,
+		TokenMetadata: tc.tokenMetadata,
+		Transfers:     tc.transfers,
+	}
+	tc.ctx.Config.GraphQL.PageSize = 2
+	return tc
+}
+
+// exec - runs query and returns `data` of response or errors
+func (tc testContext) exec(t *testing.T, query string, variables map[string]interface{}) (map[string]interface{}, []string) {
+	schema, err := NewSchema(tc.ctx)
+	require.NoError(t, err)
+
+	response := schema.Exec(context.Background(), query, "", variables)
+	if len(response.Errors) > 0 {
+		messages := make([]string, len(response.Errors))
+		for i := range response.Errors {
+			messages[i] = response.Errors[i].Message
+		}
+		return nil, messages
+	}
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(response.Data, &data))
+	return data, nil
+}
+
+const transfersQuery = `
+query ($account: String, $after: String) {
+	transfers(network: "mainnet", account: $account, after: $after) {
+		nodes { id from { address } to { address } amount }
+		pageInfo { endCursor hasNextPage }
+	}
+}`
+
+func TestResolver_Transfers(t *testing.T) {
+	tests := []struct {
+		name          string
+		variables     map[string]interface{}
+		expect        func(tc testContext)
+		wantIDs       []float64
+		wantNextPage  bool
+		wantEndCursor interface{}
+	}{
+		{
+			name: "first page",
+			expect: func(tc testContext) {
+				tc.transfers.EXPECT().Get(transfer.GetContext{
+					Network:   types.Mainnet,
+					AccountID: -1,
+					Size:      2,
+				}).Return(transfer.Pageable{
+					Transfers: []transfer.Transfer{
+						{ID: 30, From: account.Account{Address: "tz1a"}},
+						{ID: 20, To: account.Account{Address: "tz1b"}},
+					},
+				}, nil).Times(1)
+			},
+			wantIDs:       []float64{30, 20},
+			wantNextPage:  true,
+			wantEndCursor: encodeCursor(20),
+		}, {
+			name:      "last page after cursor",
+			variables: map[string]interface{}{"after": encodeCursor(20)},
+			expect: func(tc testContext) {
+				tc.transfers.EXPECT().Get(transfer.GetContext{
+					Network:   types.Mainnet,
+					AccountID: -1,
+					LastID:    "20",
+					Size:      2,
+				}).Return(transfer.Pageable{
+					Transfers: []transfer.Transfer{{ID: 10}},
+				}, nil).Times(1)
+			},
+			wantIDs: []float64{10},
+		}, {
+			name:      "by account",
+			variables: map[string]interface{}{"account": "tz1a"},
+			expect: func(tc testContext) {
+				tc.accounts.EXPECT().Get(types.Mainnet, "tz1a").Return(account.Account{ID: 5, Address: "tz1a"}, nil).Times(1)
+				tc.transfers.EXPECT().Get(transfer.GetContext{
+					Network:   types.Mainnet,
+					AccountID: 5,
+					Size:      2,
+				}).Return(transfer.Pageable{
+					Transfers: []transfer.Transfer{{ID: 30, From: account.Account{Address: "tz1a"}}},
+				}, nil).Times(1)
+			},
+			wantIDs: []float64{30},
+		}, {
+			name:      "unknown account",
+			variables: map[string]interface{}{"account": "tz1unknown"},
+			expect: func(tc testContext) {
+				tc.accounts.EXPECT().Get(types.Mainnet, "tz1unknown").Return(account.Account{}, pg.ErrNoRows).Times(1)
+				tc.general.EXPECT().IsRecordNotFound(pg.ErrNoRows).Return(true).Times(1)
+			},
+			wantIDs: []float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tc := newTestContext(ctrl)
+			tt.expect(tc)
+
+			data, errs := tc.exec(t, transfersQuery, tt.variables)
+			require.Empty(t, errs)
+
+			connection := data["transfers"].(map[string]interface{})
+			nodes := connection["nodes"].([]interface{})
+			ids := make([]float64, len(nodes))
+			for i := range nodes {
+				ids[i] = nodes[i].(map[string]interface{})["id"].(float64)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+
+			pageInfo := connection["pageInfo"].(map[string]interface{})
+			assert.Equal(t, tt.wantNextPage, pageInfo["hasNextPage"])
+			assert.Equal(t, tt.wantEndCursor, pageInfo["endCursor"])
+		})
+	}
+}
+
+func TestResolver_Transfers_Relations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tc := newTestContext(ctrl)
+	tc.transfers.EXPECT().Get(gomock.Any()).Return(transfer.Pageable{
+		Transfers: []transfer.Transfer{
+			{ID: 1, From: account.Account{Address: "tz1a", Alias: "Alice"}},
+		},
+	}, nil).Times(1)
+
+	data, errs := tc.exec(t, `{ transfers(network: "mainnet") { nodes { from { address alias } to { address } amount } } }`, nil)
+	require.Empty(t, errs)
+
+	node := data["transfers"].(map[string]interface{})["nodes"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"address": "tz1a", "alias": "Alice"}, node["from"])
+	assert.Nil(t, node["to"])
+	assert.Equal(t, "0", node["amount"])
+}
+
+func TestResolver_Tokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tc := newTestContext(ctrl)
+	contractAddress := "KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH"
+	ctx := []tokenmetadata.GetContext{{Network: types.Mainnet, Contract: contractAddress}}
+	gomock.InOrder(
+		tc.tokenMetadata.EXPECT().Get(ctx, int64(2), int64(0)).Return([]tokenmetadata.TokenMetadata{
+			{Contract: contractAddress, TokenID: 0},
+			{Contract: contractAddress, TokenID: 1},
+		}, nil),
+		tc.tokenMetadata.EXPECT().Get(ctx, int64(2), int64(2)).Return([]tokenmetadata.TokenMetadata{
+			{Contract: contractAddress, TokenID: 2},
+		}, nil),
+	)
+
+	query := `query ($after: String) {
+		tokens(network: "mainnet", contract: "KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH", after: $after) {
+			nodes { tokenId }
+			pageInfo { endCursor hasNextPage }
+		}
+	}`
+
+	data, errs := tc.exec(t, query, nil)
+	require.Empty(t, errs)
+	pageInfo := data["tokens"].(map[string]interface{})["pageInfo"].(map[string]interface{})
+	assert.Equal(t, true, pageInfo["hasNextPage"])
+	assert.Equal(t, encodeCursor(2), pageInfo["endCursor"])
+
+	data, errs = tc.exec(t, query, map[string]interface{}{"after": pageInfo["endCursor"]})
+	require.Empty(t, errs)
+	tokens := data["tokens"].(map[string]interface{})
+	assert.Equal(t, []interface{}{map[string]interface{}{"tokenId": "2"}}, tokens["nodes"])
+	assert.Equal(t, map[string]interface{}{"endCursor": nil, "hasNextPage": false}, tokens["pageInfo"])
+}
+
+func TestResolver_BigMap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tc := newTestContext(ctrl)
+	ptr := int64(10)
+	tc.bigMapDiffs.EXPECT().GetStats(types.Mainnet, ptr).Return(bigmapdiff.Stats{Total: 3, Active: 2, Contract: "KT1"}, nil).Times(1)
+	tc.bigMapDiffs.EXPECT().Keys(bigmapdiff.GetContext{
+		Network: types.Mainnet,
+		Ptr:     &ptr,
+		Size:    1,
+		Offset:  0,
+	}).Return([]bigmapdiff.BigMapState{
+		{Ptr: ptr, Contract: "KT1", KeyHash: "expr1", Key: []byte(`{"int":"1"}`), Value: []byte(`{"string":"a"}`)},
+	}, nil).Times(1)
+
+	data, errs := tc.exec(t, `{
+		bigMap(network: "mainnet", ptr: 10) {
+			totalKeys activeKeys
+			keys(first: 1) { nodes { keyHash key value } pageInfo { endCursor hasNextPage } }
+		}
+	}`, nil)
+	require.Empty(t, errs)
+
+	bigMap := data["bigMap"].(map[string]interface{})
+	assert.Equal(t, float64(3), bigMap["totalKeys"])
+	assert.Equal(t, float64(2), bigMap["activeKeys"])
+	keys := bigMap["keys"].(map[string]interface{})
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"keyHash": "expr1",
+		"key":     map[string]interface{}{"int": "1"},
+		"value":   map[string]interface{}{"string": "a"},
+	}}, keys["nodes"])
+	assert.Equal(t, map[string]interface{}{"endCursor": encodeCursor(1), "hasNextPage": true}, keys["pageInfo"])
+}
+
+func TestResolver_Contract(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		expect  func(tc testContext)
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:  "found",
+			query: `{ contract(network: "mainnet", address: "KT1") { address network manager { address } } }`,
+			expect: func(tc testContext) {
+				tc.contracts.EXPECT().Get(types.Mainnet, "KT1").Return(contract.Contract{
+					Network: types.Mainnet,
+					Account: account.Account{Address: "KT1"},
+					Manager: account.Account{Address: "tz1a"},
+				}, nil).Times(1)
+			},
+			want: map[string]interface{}{
+				"address": "KT1",
+				"network": "mainnet",
+				"manager": map[string]interface{}{"address": "tz1a"},
+			},
+		}, {
+			name:  "not found",
+			query: `{ contract(network: "mainnet", address: "KT1") { address } }`,
+			expect: func(tc testContext) {
+				tc.contracts.EXPECT().Get(types.Mainnet, "KT1").Return(contract.Contract{}, pg.ErrNoRows).Times(1)
+				tc.general.EXPECT().IsRecordNotFound(pg.ErrNoRows).Return(true).Times(1)
+			},
+			want: nil,
+		}, {
+			name:    "unknown network",
+			query:   `{ contract(network: "unknownnet", address: "KT1") { address } }`,
+			expect:  func(tc testContext) {},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tc := newTestContext(ctrl)
+			tt.expect(tc)
+
+			data, errs := tc.exec(t, tt.query, nil)
+			if tt.wantErr {
+				assert.NotEmpty(t, errs)
+				return
+			}
+			require.Empty(t, errs)
+			assert.Equal(t, tt.want, data["contract"])
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package resolvers
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Int64 - 64-bit integer scalar
+type Int64 int64
+
+// ImplementsGraphQLType -
+func (Int64) ImplementsGraphQLType(name string) bool {
+	return name == "Int64"
+}
+
+// UnmarshalGraphQL -
+func (i *Int64) UnmarshalGraphQL(input interface{}) error {
+	switch value := input.(type) {
+	case int32:
+		*i = Int64(value)
+	case int64:
+		*i = Int64(value)
+	case float64:
+		*i = Int64(value)
+	case string:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		*i = Int64(parsed)
+	default:
+		return errors.Errorf("wrong type for Int64: %T", input)
+	}
+	return nil
+}
+
+// MarshalJSON -
+func (i Int64) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(i), 10), nil
+}
+
+// JSON - Micheline JSON scalar
+type JSON json.RawMessage
+
+// ImplementsGraphQLType -
+func (JSON) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+// UnmarshalGraphQL -
+func (j *JSON) UnmarshalGraphQL(input interface{}) error {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	*j = data
+	return nil
+}
+
+// MarshalJSON -
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func newJSON(data []byte) *JSON {
+	if len(data) == 0 {
+		return nil
+	}
+	j := JSON(data)
+	return &j
+}
@@ -0,0 +1,192 @@
+package resolvers
+
+import (
+	"github.com/baking-bad/bcdhub/internal/config"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Schema - GraphQL schema of Better Call Dev models
+const Schema = `
+schema {
+	query: Query
+}
+
+"64-bit integer"
+scalar Int64
+"Micheline JSON"
+scalar JSON
+"RFC 3339 timestamp"
+scalar Time
+
+type Query {
+	contract(network: String!, address: String!): Contract
+	operationGroup(network: String!, hash: String!): [Operation!]!
+	bigMap(network: String!, ptr: Int64!): BigMap
+	transfers(network: String!, contracts: [String!], account: String, tokenId: String, first: Int, after: String): TransferConnection!
+	tokens(network: String!, contract: String, tokenId: String, first: Int, after: String): TokenMetadataConnection!
+	tokenHolders(network: String!, contract: String!, tokenId: String!): [TokenBalance!]!
+}
+
+type PageInfo {
+	endCursor: String
+	hasNextPage: Boolean!
+}
+
+type Account {
+	address: String!
+	alias: String
+}
+
+type Contract {
+	network: String!
+	address: String!
+	alias: String
+	manager: Account
+	delegate: Account
+	level: Int64!
+	timestamp: Time!
+	lastAction: Time!
+	txCount: Int64!
+	migrationsCount: Int64!
+	tags: [String!]!
+	operations(first: Int, after: String, entrypoints: [String!], kinds: [String!], statuses: [String!]): OperationConnection!
+	transfers(first: Int, after: String, tokenId: String): TransferConnection!
+	bigMaps: [BigMap!]!
+	tokens(first: Int, after: String): TokenMetadataConnection!
+}
+
+type Operation {
+	id: Int64!
+	hash: String!
+	network: String!
+	level: Int64!
+	timestamp: Time!
+	kind: String!
+	status: String!
+	counter: Int64!
+	contentIndex: Int64!
+	nonce: Int64
+	source: Account
+	destination: Account
+	initiator: Account
+	delegate: Account
+	amount: Int64!
+	fee: Int64!
+	gasLimit: Int64!
+	storageLimit: Int64!
+	consumedGas: Int64!
+	storageSize: Int64!
+	paidStorageSizeDiff: Int64!
+	burned: Int64!
+	entrypoint: String
+	parameters: JSON
+	bigMapDiffs: [BigMapDiff!]!
+	transfers: [Transfer!]!
+}
+
+type OperationConnection {
+	nodes: [Operation!]!
+	pageInfo: PageInfo!
+}
+
+type BigMap {
+	network: String!
+	ptr: Int64!
+	contract: String!
+	totalKeys: Int64!
+	activeKeys: Int64!
+	keys(first: Int, after: String): BigMapStateConnection!
+}
+
+type BigMapState {
+	ptr: Int64!
+	contract: String!
+	keyHash: String!
+	key: JSON!
+	value: JSON
+	removed: Boolean!
+	updates: Int64!
+	lastUpdateLevel: Int64!
+	lastUpdateTime: Time!
+}
+
+type BigMapStateConnection {
+	nodes: [BigMapState!]!
+	pageInfo: PageInfo!
+}
+
+type BigMapDiff {
+	id: Int64!
+	ptr: Int64!
+	contract: String!
+	keyHash: String!
+	key: JSON!
+	value: JSON
+	level: Int64!
+	timestamp: Time!
+	operation: Operation
+}
+
+type Transfer {
+	id: Int64!
+	network: String!
+	contract: String!
+	initiator: Account
+	from: Account
+	to: Account
+	tokenId: String!
+	amount: String!
+	status: String!
+	level: Int64!
+	timestamp: Time!
+	parent: String
+	entrypoint: String
+	operation: Operation
+	token: TokenMetadata
+}
+
+type TransferConnection {
+	nodes: [Transfer!]!
+	pageInfo: PageInfo!
+}
+
+type TokenBalance {
+	account: Account!
+	contract: String!
+	tokenId: String!
+	balance: String!
+}
+
+type TokenMetadata {
+	network: String!
+	contract: String!
+	tokenId: String!
+	level: Int64!
+	timestamp: Time!
+	name: String
+	symbol: String
+	decimals: Int
+	description: String
+	artifactUri: String
+	displayUri: String
+	thumbnailUri: String
+	externalUri: String
+	isTransferable: Boolean!
+	isBooleanAmount: Boolean!
+	shouldPreferSymbol: Boolean!
+	tags: [String!]!
+	creators: [String!]!
+	supply: String!
+	holders: [TokenBalance!]!
+}
+
+type TokenMetadataConnection {
+	nodes: [TokenMetadata!]!
+	pageInfo: PageInfo!
+}
+`
+
+// NewSchema - parses schema and binds it to resolvers
+func NewSchema(ctx *config.Context, opts ...graphql.SchemaOpt) (*graphql.Schema, error) {
+	return graphql.ParseSchema(Schema, &Resolver{ctx}, opts...)
+}
@@ -0,0 +1,173 @@
+package resolvers
+
+import (
+	"strconv"
+
+	"github.com/baking-bad/bcdhub/internal/models/tokenbalance"
+	"github.com/baking-bad/bcdhub/internal/models/tokenmetadata"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// TokenMetadataResolver -
+type TokenMetadataResolver struct {
+	r     *Resolver
+	token tokenmetadata.TokenMetadata
+}
+
+// TokenMetadataConnection -
+type TokenMetadataConnection struct {
+	nodes    []*TokenMetadataResolver
+	pageInfo *PageInfo
+}
+
+// Nodes -
+func (c *TokenMetadataConnection) Nodes() []*TokenMetadataResolver {
+	return c.nodes
+}
+
+// PageInfo -
+func (c *TokenMetadataConnection) PageInfo() *PageInfo {
+	return c.pageInfo
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// Network -
+func (t *TokenMetadataResolver) Network() string {
+	return t.token.Network.String()
+}
+
+// Contract -
+func (t *TokenMetadataResolver) Contract() string {
+	return t.token.Contract
+}
+
+// TokenID -
+func (t *TokenMetadataResolver) TokenID() string {
+	return strconv.FormatUint(t.token.TokenID, 10)
+}
+
+// Level -
+func (t *TokenMetadataResolver) Level() Int64 {
+	return Int64(t.token.Level)
+}
+
+// Timestamp -
+func (t *TokenMetadataResolver) Timestamp() graphql.Time {
+	return graphql.Time{Time: t.token.Timestamp}
+}
+
+// Name -
+func (t *TokenMetadataResolver) Name() *string {
+	return optionalString(t.token.Name)
+}
+
+// Symbol -
+func (t *TokenMetadataResolver) Symbol() *string {
+	return optionalString(t.token.Symbol)
+}
+
+// Decimals -
+func (t *TokenMetadataResolver) Decimals() *int32 {
+	if t.token.Decimals == nil {
+		return nil
+	}
+	decimals := int32(*t.token.Decimals)
+	return &decimals
+}
+
+// Description -
+func (t *TokenMetadataResolver) Description() *string {
+	return optionalString(t.token.Description)
+}
+
+// ArtifactURI -
+func (t *TokenMetadataResolver) ArtifactURI() *string {
+	return optionalString(t.token.ArtifactURI)
+}
+
+// DisplayURI -
+func (t *TokenMetadataResolver) DisplayURI() *string {
+	return optionalString(t.token.DisplayURI)
+}
+
+// ThumbnailURI -
+func (t *TokenMetadataResolver) ThumbnailURI() *string {
+	return optionalString(t.token.ThumbnailURI)
+}
+
+// ExternalURI -
+func (t *TokenMetadataResolver) ExternalURI() *string {
+	return optionalString(t.token.ExternalURI)
+}
+
+// IsTransferable -
+func (t *TokenMetadataResolver) IsTransferable() bool {
+	return t.token.IsTransferable
+}
+
+// IsBooleanAmount -
+func (t *TokenMetadataResolver) IsBooleanAmount() bool {
+	return t.token.IsBooleanAmount
+}
+
+// ShouldPreferSymbol -
+func (t *TokenMetadataResolver) ShouldPreferSymbol() bool {
+	return t.token.ShouldPreferSymbol
+}
+
+// Tags -
+func (t *TokenMetadataResolver) Tags() []string {
+	if t.token.Tags == nil {
+		return []string{}
+	}
+	return t.token.Tags
+}
+
+// Creators -
+func (t *TokenMetadataResolver) Creators() []string {
+	if t.token.Creators == nil {
+		return []string{}
+	}
+	return t.token.Creators
+}
+
+// Supply -
+func (t *TokenMetadataResolver) Supply() (string, error) {
+	return t.r.ctx.TokenBalances.TokenSupply(t.token.Network, t.token.Contract, t.token.TokenID)
+}
+
+// Holders -
+func (t *TokenMetadataResolver) Holders() ([]*TokenBalanceResolver, error) {
+	return t.r.holders(t.token.Network, t.token.Contract, t.token.TokenID)
+}
+
+// TokenBalanceResolver -
+type TokenBalanceResolver struct {
+	balance tokenbalance.TokenBalance
+}
+
+// Account -
+func (b *TokenBalanceResolver) Account() *Account {
+	return newAccount(b.balance.Account)
+}
+
+// Contract -
+func (b *TokenBalanceResolver) Contract() string {
+	return b.balance.Contract
+}
+
+// TokenID -
+func (b *TokenBalanceResolver) TokenID() string {
+	return strconv.FormatUint(b.balance.TokenID, 10)
+}
+
+// Balance -
+func (b *TokenBalanceResolver) Balance() string {
+	return b.balance.Balance.String()
+}
@@ -0,0 +1,130 @@
+package resolvers
+
+import (
+	"strconv"
+
+	"github.com/baking-bad/bcdhub/internal/models/transfer"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// TransferResolver -
+type TransferResolver struct {
+	r        *Resolver
+	transfer transfer.Transfer
+	batch    *batch
+}
+
+func newTransferResolvers(r *Resolver, transfers []transfer.Transfer) []*TransferResolver {
+	b := newBatch(r.ctx)
+	result := make([]*TransferResolver, len(transfers))
+	for i := range transfers {
+		b.addLinked(transfers[i].OperationID)
+		result[i] = &TransferResolver{r, transfers[i], b}
+	}
+	return result
+}
+
+// TransferConnection -
+type TransferConnection struct {
+	nodes    []*TransferResolver
+	pageInfo *PageInfo
+}
+
+// Nodes -
+func (c *TransferConnection) Nodes() []*TransferResolver {
+	return c.nodes
+}
+
+// PageInfo -
+func (c *TransferConnection) PageInfo() *PageInfo {
+	return c.pageInfo
+}
+
+// ID -
+func (t *TransferResolver) ID() Int64 {
+	return Int64(t.transfer.ID)
+}
+
+// Network -
+func (t *TransferResolver) Network() string {
+	return t.transfer.Network.String()
+}
+
+// Contract -
+func (t *TransferResolver) Contract() string {
+	return t.transfer.Contract
+}
+
+// Initiator -
+func (t *TransferResolver) Initiator() *Account {
+	return newAccountOrNil(t.transfer.Initiator)
+}
+
+// From -
+func (t *TransferResolver) From() *Account {
+	return newAccountOrNil(t.transfer.From)
+}
+
+// To -
+func (t *TransferResolver) To() *Account {
+	return newAccountOrNil(t.transfer.To)
+}
+
+// TokenID -
+func (t *TransferResolver) TokenID() string {
+	return strconv.FormatUint(t.transfer.TokenID, 10)
+}
+
+// Amount -
+func (t *TransferResolver) Amount() string {
+	return t.transfer.Amount.String()
+}
+
+// Status -
+func (t *TransferResolver) Status() string {
+	return t.transfer.Status.String()
+}
+
+// Level -
+func (t *TransferResolver) Level() Int64 {
+	return Int64(t.transfer.Level)
+}
+
+// Timestamp -
+func (t *TransferResolver) Timestamp() graphql.Time {
+	return graphql.Time{Time: t.transfer.Timestamp}
+}
+
+// Parent -
+func (t *TransferResolver) Parent() *string {
+	if !t.transfer.Parent.Valid {
+		return nil
+	}
+	return &t.transfer.Parent.Str
+}
+
+// Entrypoint -
+func (t *TransferResolver) Entrypoint() *string {
+	if t.transfer.Entrypoint == "" {
+		return nil
+	}
+	return &t.transfer.Entrypoint
+}
+
+// Operation -
+func (t *TransferResolver) Operation() (*OperationResolver, error) {
+	op, linked, err := t.batch.operation(t.transfer.OperationID)
+	if err != nil || op == nil {
+		return nil, err
+	}
+	return &OperationResolver{t.r, *op, linked}, nil
+}
+
+// Token -
+func (t *TransferResolver) Token() (*TokenMetadataResolver, error) {
+	token, err := t.batch.token(t.transfer.Network, t.transfer.Contract, t.transfer.TokenID)
+	if err != nil || token == nil {
+		return nil, err
+	}
+	return &TokenMetadataResolver{t.r, *token}, nil
+}
@@ -20,7 +20,9 @@ import (
 	"github.com/baking-bad/bcdhub/internal/parsers/migrations"
 	"github.com/baking-bad/bcdhub/internal/parsers/operations"
 	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	"github.com/baking-bad/bcdhub/internal/pubsub"
 	"github.com/baking-bad/bcdhub/internal/rollback"
+	"github.com/baking-bad/bcdhub/internal/telemetry"
 	"github.com/go-pg/pg/v10"
 	"github.com/pkg/errors"
 )
@@ -35,10 +37,12 @@ type BoostIndexer struct {
 
 	rpc             noderpc.INode
 	state           block.Block
+	headLevel       int64
 	currentProtocol protocol.Protocol
 
-	updateTicker *time.Ticker
-	Network      types.Network
+	updateTicker  *time.Ticker
+	Network       types.Network
+	catchUpParams catchUpParams
 
 	indicesInit sync.Once
 }
@@ -47,15 +51,13 @@ type BoostIndexer struct {
 func NewBoostIndexer(ctx context.Context, internalCtx config.Context, rpcConfig config.RPCConfig, network types.Network) (*BoostIndexer, error) {
 	logger.Info().Str("network", network.String()).Msg("Creating indexer object...")
 
-	rpc := noderpc.NewWaitNodeRPC(
-		rpcConfig.URI,
-		noderpc.WithTimeout(time.Duration(rpcConfig.Timeout)*time.Second),
-	)
+	rpc := rpcConfig.NewNode(true)
 
 	bi := &BoostIndexer{
-		Context: &internalCtx,
-		Network: network,
-		rpc:     rpc,
+		Context:       &internalCtx,
+		Network:       network,
+		rpc:           rpc,
+		catchUpParams: newCatchUpParams(internalCtx.Config.Indexer.CatchUp),
 	}
 
 	if err := bi.init(ctx, bi.Context.StorageDB); err != nil {
@@ -66,6 +68,10 @@ func NewBoostIndexer(ctx context.Context, internalCtx config.Context, rpcConfig
 }
 
 func (bi *BoostIndexer) init(ctx context.Context, db *core.Postgres) error {
+	if err := bi.registerChainID(); err != nil {
+		return err
+	}
+
 	currentState, err := bi.Blocks.Last(bi.Network)
 	if err != nil {
 		return err
@@ -98,6 +104,22 @@ func (bi *BoostIndexer) init(ctx context.Context, db *core.Postgres) error {
 	return nil
 }
 
+// registerChainID - saves chain id of network to network registry if it's unknown yet
+func (bi *BoostIndexer) registerChainID() error {
+	if bi.Network.ChainID() != "" {
+		return nil
+	}
+	head, err := bi.rpc.GetHead()
+	if err != nil {
+		return err
+	}
+	network, err := bi.Networks.Register(bi.Network.String(), head.ChainID)
+	if err != nil {
+		return err
+	}
+	return types.RegisterNetwork(network.Info())
+}
+
 // Sync -
 func (bi *BoostIndexer) Sync(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
@@ -161,6 +183,12 @@ func (bi *BoostIndexer) setUpdateTicker(seconds int) {
 
 // Index -
 func (bi *BoostIndexer) Index(ctx context.Context, head noderpc.Header) error {
+	if bi.catchUpParams.enabled(bi.state.Level, head.Level) {
+		if err := bi.catchUp(ctx, head.Level-catchUpHeadDistance); err != nil {
+			return err
+		}
+	}
+
 	for level := bi.state.Level + 1; level <= head.Level; level++ {
 		helpers.SetTagSentry("block", fmt.Sprintf("%d", level))
 
@@ -170,13 +198,15 @@ func (bi *BoostIndexer) Index(ctx context.Context, head noderpc.Header) error {
 		default:
 		}
 
+		start := time.Now()
 		head, err := bi.rpc.GetHeader(level)
 		if err != nil {
 			return err
 		}
+		telemetry.ObserveStage(bi.Network.String(), telemetry.StageFetch, start)
 
-		if bi.state.Level > 0 && head.Predecessor != bi.state.Hash {
-			return errRollback
+		if err := bi.checkPredecessor(head); err != nil {
+			return err
 		}
 
 		if err := bi.handleBlock(ctx, head); err != nil {
@@ -209,6 +239,7 @@ func (bi *BoostIndexer) handleBlock(ctx context.Context, head noderpc.Header) er
 				return err
 			}
 
+			start := time.Now()
 			if err := res.Save(tx); err != nil {
 				return err
 			}
@@ -217,10 +248,28 @@ func (bi *BoostIndexer) handleBlock(ctx context.Context, head noderpc.Header) er
 			if err := bi.createBlock(head, tx); err != nil {
 				return err
 			}
+			if err := pubsub.Publish(tx, pubsub.NewBlock(bi.Network, head.Level)); err != nil {
+				return err
+			}
+			telemetry.ObserveStage(bi.Network.String(), telemetry.StageSave, start)
 			return nil
 		},
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	bi.observeIndexed(1, len(result.Operations))
+	return nil
+}
+
+// observeIndexed - updates indexer metrics after saving blocks
+func (bi *BoostIndexer) observeIndexed(blocks, operations int) {
+	network := bi.Network.String()
+	telemetry.BlocksParsed.WithLabelValues(network).Add(float64(blocks))
+	telemetry.OperationsParsed.WithLabelValues(network).Add(float64(operations))
+	if bi.headLevel > 0 {
+		telemetry.HeadLag.WithLabelValues(network).Set(float64(helpers.MaxInt64(bi.headLevel-bi.state.Level, 0)))
+	}
 }
 
 // Rollback -
@@ -238,6 +287,7 @@ func (bi *BoostIndexer) Rollback(ctx context.Context) error {
 	}
 
 	helpers.CatchErrorSentry(errors.Errorf("[%s] Rollback from %7d to %7d", bi.Network, bi.state.Level, lastLevel))
+	telemetry.Rollbacks.WithLabelValues(bi.Network.String()).Inc()
 
 	newState, err := bi.Blocks.Last(bi.Network)
 	if err != nil {
@@ -286,6 +336,9 @@ func (bi *BoostIndexer) process(ctx context.Context) error {
 	logger.Info().Str("network", bi.Network.String()).Msgf("Current node state: %7d", head.Level)
 	logger.Info().Str("network", bi.Network.String()).Msgf("Current indexer state: %7d", bi.state.Level)
 
+	bi.headLevel = head.Level
+	telemetry.HeadLag.WithLabelValues(bi.Network.String()).Set(float64(helpers.MaxInt64(head.Level-bi.state.Level, 0)))
+
 	if head.Level > bi.state.Level {
 		if err := bi.Index(ctx, head); err != nil {
 			if errors.Is(err, errBcdQuit) {
@@ -335,15 +388,22 @@ func (bi *BoostIndexer) createBlock(head noderpc.Header, tx pg.DBI) error {
 }
 
 func (bi *BoostIndexer) getDataFromBlock(head noderpc.Header) (*parsers.Result, error) {
-	result := parsers.NewResult()
 	if head.Level <= 1 {
-		return result, nil
+		return parsers.NewResult(), nil
 	}
+	start := time.Now()
 	opg, err := bi.rpc.GetLightOPG(head.Level)
 	if err != nil {
 		return nil, err
 	}
+	telemetry.ObserveStage(bi.Network.String(), telemetry.StageFetch, start)
+	return bi.parseBlock(head, opg)
+}
 
+func (bi *BoostIndexer) parseBlock(head noderpc.Header, opg []noderpc.LightOperationGroup) (*parsers.Result, error) {
+	defer telemetry.ObserveStage(bi.Network.String(), telemetry.StageParse, time.Now())
+
+	result := parsers.NewResult()
 	for i := range opg {
 		parserParams, err := operations.NewParseParams(
 			bi.rpc,
@@ -0,0 +1,348 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/parsers"
+	"github.com/baking-bad/bcdhub/internal/pubsub"
+	"github.com/baking-bad/bcdhub/internal/telemetry"
+	"github.com/go-pg/pg/v10"
+)
+
+// Catch-up defaults
+const (
+	DefaultCatchUpThreshold = 100
+	DefaultCatchUpBatchSize = 50
+	DefaultCatchUpWorkers   = 8
+
+	// catch-up stops when indexer is closer to the head than this distance, the rest is indexed block by block
+	catchUpHeadDistance = 5
+)
+
+var (
+	contractAddressRegexp = regexp.MustCompile(`KT1[1-9A-HJ-NP-Za-km-z]{33}`)
+
+	registerGlobalConstantKind = []byte(`"register_global_constant"`)
+	lazyStorageCopyAction      = []byte(`"copy"`)
+)
+
+type catchUpParams struct {
+	threshold int64
+	batchSize int
+	workers   int
+}
+
+func newCatchUpParams(cfg config.CatchUpConfig) catchUpParams {
+	params := catchUpParams{
+		threshold: cfg.Threshold,
+		batchSize: cfg.BatchSize,
+		workers:   cfg.Workers,
+	}
+	if params.threshold == 0 {
+		params.threshold = DefaultCatchUpThreshold
+	}
+	if params.batchSize < 1 {
+		params.batchSize = DefaultCatchUpBatchSize
+	}
+	if params.workers < 1 {
+		params.workers = DefaultCatchUpWorkers
+	}
+	return params
+}
+
+// enabled - catch-up is disabled by negative threshold
+func (params catchUpParams) enabled(state, head int64) bool {
+	return params.threshold > 0 && head-state > params.threshold
+}
+
+// fetchedBlock - block prefetched from node with its operation groups and result of parsing
+type fetchedBlock struct {
+	header noderpc.Header
+	opg    []noderpc.LightOperationGroup
+	err    error
+
+	// contracts - addresses of contracts mentioned in block operations
+	contracts map[string]struct{}
+	// registersConstants - block registers global constants which can be used by next blocks
+	registersConstants bool
+	// copiesLazyStorage - block copies big maps or sapling states which can be changed by previous blocks
+	copiesLazyStorage bool
+
+	result *parsers.Result
+}
+
+func newFetchedBlock(header noderpc.Header, opg []noderpc.LightOperationGroup) *fetchedBlock {
+	block := &fetchedBlock{
+		header:    header,
+		opg:       opg,
+		contracts: make(map[string]struct{}),
+	}
+	for i := range opg {
+		for j := range opg[i].Contents {
+			raw := opg[i].Contents[j].Raw
+			for _, address := range contractAddressRegexp.FindAll(raw, -1) {
+				block.contracts[string(address)] = struct{}{}
+			}
+			if !block.registersConstants {
+				block.registersConstants = bytes.Contains(raw, registerGlobalConstantKind)
+			}
+			if !block.copiesLazyStorage {
+				block.copiesLazyStorage = bytes.Contains(raw, lazyStorageCopyAction)
+			}
+		}
+	}
+	return block
+}
+
+// catchUpBatch - consecutive blocks which can be parsed independently from each other.
+// Parsers read contracts state from the database, so block can't be parsed before previous blocks touching the same contracts are saved.
+type catchUpBatch struct {
+	blocks    []*fetchedBlock
+	contracts map[string]struct{}
+	closed    bool
+}
+
+func newCatchUpBatch(size int) *catchUpBatch {
+	return &catchUpBatch{
+		blocks:    make([]*fetchedBlock, 0, size),
+		contracts: make(map[string]struct{}),
+	}
+}
+
+// accepts - returns true if block doesn't depend on blocks of the batch
+func (b *catchUpBatch) accepts(block *fetchedBlock) bool {
+	if len(b.blocks) == 0 {
+		return true
+	}
+	if b.closed || block.copiesLazyStorage || len(b.blocks) == cap(b.blocks) {
+		return false
+	}
+	for address := range block.contracts {
+		if _, ok := b.contracts[address]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *catchUpBatch) add(block *fetchedBlock) {
+	b.blocks = append(b.blocks, block)
+	for address := range block.contracts {
+		b.contracts[address] = struct{}{}
+	}
+	b.closed = block.registersConstants
+}
+
+func (b *catchUpBatch) reset() {
+	b.blocks = b.blocks[:0]
+	b.contracts = make(map[string]struct{})
+	b.closed = false
+}
+
+// prefetch - fetches headers and operation groups of blocks from `from` to `to` level by `workers` concurrent requests.
+// Blocks are sent to the channel in level order. Channel is closed after the last block, an error or context cancellation.
+func (bi *BoostIndexer) prefetch(ctx context.Context, from, to int64, workers int) <-chan *fetchedBlock {
+	output := make(chan *fetchedBlock)
+	queue := make(chan chan *fetchedBlock, workers*2)
+
+	go func() {
+		defer close(queue)
+
+		semaphore := make(chan struct{}, workers)
+		for level := from; level <= to; level++ {
+			item := make(chan *fetchedBlock, 1)
+			select {
+			case <-ctx.Done():
+				return
+			case semaphore <- struct{}{}:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case queue <- item:
+			}
+
+			go func(level int64) {
+				defer func() { <-semaphore }()
+				item <- bi.fetchBlock(level)
+			}(level)
+		}
+	}()
+
+	go func() {
+		defer close(output)
+
+		for item := range queue {
+			var block *fetchedBlock
+			select {
+			case <-ctx.Done():
+				return
+			case block = <-item:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case output <- block:
+			}
+			if block.err != nil {
+				return
+			}
+		}
+	}()
+
+	return output
+}
+
+func (bi *BoostIndexer) fetchBlock(level int64) *fetchedBlock {
+	defer telemetry.ObserveStage(bi.Network.String(), telemetry.StageFetch, time.Now())
+
+	header, err := bi.rpc.GetHeader(level)
+	if err != nil {
+		return &fetchedBlock{err: err}
+	}
+	var opg []noderpc.LightOperationGroup
+	if level > 1 {
+		opg, err = bi.rpc.GetLightOPG(level)
+		if err != nil {
+			return &fetchedBlock{err: err}
+		}
+	}
+	return newFetchedBlock(header, opg)
+}
+
+// catchUp - indexes blocks up to `to` level: blocks are prefetched concurrently, independent blocks are parsed in parallel and saved in level order by batches
+func (bi *BoostIndexer) catchUp(ctx context.Context, to int64) error {
+	logger.Info().Str("network", bi.Network.String()).Msgf("Catching up from %d to %d", bi.state.Level+1, to)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batch := newCatchUpBatch(bi.catchUpParams.batchSize)
+	for block := range bi.prefetch(ctx, bi.state.Level+1, to, bi.catchUpParams.workers) {
+		if block.err != nil {
+			return block.err
+		}
+
+		if block.header.Protocol != bi.currentProtocol.Hash || (bi.Network == types.Mainnet && block.header.Level == 1) {
+			if err := bi.flush(ctx, batch); err != nil {
+				return err
+			}
+			if err := bi.checkPredecessor(block.header); err != nil {
+				return err
+			}
+			if err := bi.handleBlock(ctx, block.header); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !batch.accepts(block) {
+			if err := bi.flush(ctx, batch); err != nil {
+				return err
+			}
+		}
+		batch.add(block)
+	}
+
+	select {
+	case <-ctx.Done():
+		return errBcdQuit
+	default:
+	}
+
+	return bi.flush(ctx, batch)
+}
+
+// flush - parses blocks of the batch in parallel and saves them in one transaction
+func (bi *BoostIndexer) flush(ctx context.Context, batch *catchUpBatch) error {
+	if len(batch.blocks) == 0 {
+		return nil
+	}
+	defer batch.reset()
+
+	if err := bi.parseBatch(batch); err != nil {
+		return err
+	}
+
+	state := bi.state
+	start := time.Now()
+	err := bi.StorageDB.DB.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		for _, block := range batch.blocks {
+			if err := bi.checkPredecessor(block.header); err != nil {
+				return err
+			}
+			if err := block.result.Save(tx); err != nil {
+				return err
+			}
+			if err := bi.createBlock(block.header, tx); err != nil {
+				return err
+			}
+			if err := pubsub.Publish(tx, pubsub.NewBlock(bi.Network, block.header.Level)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		bi.state = state
+		return err
+	}
+	telemetry.ObserveStage(bi.Network.String(), telemetry.StageSave, start)
+
+	var operations int
+	for _, block := range batch.blocks {
+		operations += len(block.result.Operations)
+	}
+	bi.observeIndexed(len(batch.blocks), operations)
+
+	first, last := batch.blocks[0].header.Level, batch.blocks[len(batch.blocks)-1].header.Level
+	logger.Info().Str("network", bi.Network.String()).Msgf("indexed %7d - %7d blocks", first, last)
+	return nil
+}
+
+func (bi *BoostIndexer) parseBatch(batch *catchUpBatch) error {
+	var (
+		wg       sync.WaitGroup
+		mx       sync.Mutex
+		firstErr error
+	)
+	semaphore := make(chan struct{}, bi.catchUpParams.workers)
+	for _, block := range batch.blocks {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(block *fetchedBlock) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			result, err := bi.parseBlock(block.header, block.opg)
+			if err != nil {
+				mx.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mx.Unlock()
+				return
+			}
+			block.result = result
+		}(block)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+func (bi *BoostIndexer) checkPredecessor(header noderpc.Header) error {
+	if bi.state.Level > 0 && header.Predecessor != bi.state.Hash {
+		return errRollback
+	}
+	return nil
+}
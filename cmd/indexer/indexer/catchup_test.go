@@ -0,0 +1,314 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testContract1 = "KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH"
+	testContract2 = "KT1Hkg5qeNhfwpKW4fXvq7HGZB9z2EnmCCA9"
+	testContract3 = "KT1GRSvLoikDsXujKgZPsGLX8k8VvR2Tq95b"
+)
+
+// testBlock - builds prefetched block of `level` which mentions `contracts`
+func testBlock(level int64, contracts ...string) *fetchedBlock {
+	block := &fetchedBlock{
+		header:    noderpc.Header{Level: level},
+		contracts: make(map[string]struct{}),
+	}
+	for i := range contracts {
+		block.contracts[contracts[i]] = struct{}{}
+	}
+	return block
+}
+
+func TestNewFetchedBlock(t *testing.T) {
+	tests := []struct {
+		name                   string
+		raw                    []string
+		wantContracts          []string
+		wantRegistersConstants bool
+		wantCopiesLazyStorage  bool
+	}{
+		{
+			name: "empty block",
+		}, {
+			name: "transaction",
+			raw: []string{
+				`{"kind":"transaction","source":"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx","destination":"` + testContract1 + `","metadata":{"internal_operation_results":[{"kind":"transaction","destination":"` + testContract2 + `"}]}}`,
+			},
+			wantContracts: []string{testContract1, testContract2},
+		}, {
+			name: "register global constant",
+			raw: []string{
+				`{"kind":"register_global_constant","source":"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx","value":{"int":"1"}}`,
+			},
+			wantRegistersConstants: true,
+		}, {
+			name: "copy of big map",
+			raw: []string{
+				`{"kind":"origination","metadata":{"operation_result":{"originated_contracts":["` + testContract3 + `"],"lazy_storage_diff":[{"kind":"big_map","id":"12","diff":{"action":"copy","source":"5"}}]}}}`,
+			},
+			wantContracts:         []string{testContract3},
+			wantCopiesLazyStorage: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opg := []noderpc.LightOperationGroup{{}}
+			for i := range tt.raw {
+				opg[0].Contents = append(opg[0].Contents, noderpc.LightOperation{Raw: []byte(tt.raw[i])})
+			}
+
+			block := newFetchedBlock(noderpc.Header{Level: 10}, opg)
+
+			contracts := make([]string, 0, len(block.contracts))
+			for address := range block.contracts {
+				contracts = append(contracts, address)
+			}
+			assert.ElementsMatch(t, tt.wantContracts, contracts)
+			assert.Equal(t, tt.wantRegistersConstants, block.registersConstants)
+			assert.Equal(t, tt.wantCopiesLazyStorage, block.copiesLazyStorage)
+		})
+	}
+}
+
+func TestCatchUpBatch(t *testing.T) {
+	registersConstants := func(block *fetchedBlock) *fetchedBlock {
+		block.registersConstants = true
+		return block
+	}
+	copiesLazyStorage := func(block *fetchedBlock) *fetchedBlock {
+		block.copiesLazyStorage = true
+		return block
+	}
+
+	tests := []struct {
+		name   string
+		size   int
+		blocks []*fetchedBlock
+		want   [][]int64
+	}{
+		{
+			name: "independent blocks",
+			size: 10,
+			blocks: []*fetchedBlock{
+				testBlock(1, testContract1),
+				testBlock(2, testContract2),
+				testBlock(3),
+				testBlock(4, testContract3),
+			},
+			want: [][]int64{{1, 2, 3, 4}},
+		}, {
+			name: "batch size",
+			size: 2,
+			blocks: []*fetchedBlock{
+				testBlock(1),
+				testBlock(2),
+				testBlock(3),
+				testBlock(4),
+				testBlock(5),
+			},
+			want: [][]int64{{1, 2}, {3, 4}, {5}},
+		}, {
+			name: "shared contract",
+			size: 10,
+			blocks: []*fetchedBlock{
+				testBlock(1, testContract1),
+				testBlock(2, testContract2),
+				testBlock(3, testContract3, testContract1),
+				testBlock(4, testContract2),
+				testBlock(5),
+			},
+			want: [][]int64{{1, 2}, {3, 4, 5}},
+		}, {
+			name: "contracts of flushed batch are forgotten",
+			size: 10,
+			blocks: []*fetchedBlock{
+				testBlock(1, testContract1),
+				testBlock(2, testContract1),
+				testBlock(3, testContract2),
+				testBlock(4, testContract1),
+			},
+			want: [][]int64{{1}, {2, 3}, {4}},
+		}, {
+			name: "global constant registration closes batch",
+			size: 10,
+			blocks: []*fetchedBlock{
+				testBlock(1, testContract1),
+				registersConstants(testBlock(2)),
+				testBlock(3, testContract2),
+				testBlock(4, testContract3),
+			},
+			want: [][]int64{{1, 2}, {3, 4}},
+		}, {
+			name: "global constant registration in first block",
+			size: 10,
+			blocks: []*fetchedBlock{
+				registersConstants(testBlock(1)),
+				testBlock(2),
+			},
+			want: [][]int64{{1}, {2}},
+		}, {
+			name: "lazy storage copy is refused by non-empty batch",
+			size: 10,
+			blocks: []*fetchedBlock{
+				testBlock(1, testContract1),
+				copiesLazyStorage(testBlock(2, testContract2)),
+				testBlock(3, testContract3),
+				copiesLazyStorage(testBlock(4)),
+				copiesLazyStorage(testBlock(5)),
+			},
+			want: [][]int64{{1}, {2, 3}, {4}, {5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := newCatchUpBatch(tt.size)
+			got := make([][]int64, 0)
+			flush := func() {
+				if len(batch.blocks) == 0 {
+					return
+				}
+				levels := make([]int64, len(batch.blocks))
+				for i := range batch.blocks {
+					levels[i] = batch.blocks[i].header.Level
+				}
+				got = append(got, levels)
+				batch.reset()
+			}
+
+			for _, block := range tt.blocks {
+				if !batch.accepts(block) {
+					flush()
+					require.True(t, batch.accepts(block), "empty batch has to accept any block")
+				}
+				batch.add(block)
+			}
+			flush()
+
+			assert.Equal(t, tt.want, got)
+			assert.Empty(t, batch.contracts)
+			assert.False(t, batch.closed)
+		})
+	}
+}
+
+func newTestBoostIndexer(rpc noderpc.INode) *BoostIndexer {
+	return &BoostIndexer{
+		rpc:     rpc,
+		Network: types.Mainnet,
+	}
+}
+
+func TestBoostIndexer_prefetch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rpc := noderpc.NewMockINode(ctrl)
+	const from, to = int64(1), int64(20)
+	for level := from; level <= to; level++ {
+		// later levels are fetched faster, so responses come out of order
+		delay := time.Duration(to-level) * time.Millisecond
+		rpc.EXPECT().GetHeader(level).DoAndReturn(func(level int64) (noderpc.Header, error) {
+			time.Sleep(delay)
+			return noderpc.Header{Level: level, Hash: fmt.Sprintf("block%d", level)}, nil
+		}).Times(1)
+		if level > 1 {
+			rpc.EXPECT().GetLightOPG(level).Return([]noderpc.LightOperationGroup{
+				{Contents: []noderpc.LightOperation{{Raw: []byte(`{"destination":"` + testContract1 + `"}`)}}},
+			}, nil).Times(1)
+		}
+	}
+
+	bi := newTestBoostIndexer(rpc)
+	levels := make([]int64, 0)
+	for block := range bi.prefetch(context.Background(), from, to, 4) {
+		require.NoError(t, block.err)
+		levels = append(levels, block.header.Level)
+		if block.header.Level > 1 {
+			assert.Contains(t, block.contracts, testContract1)
+		}
+	}
+
+	want := make([]int64, 0)
+	for level := from; level <= to; level++ {
+		want = append(want, level)
+	}
+	assert.Equal(t, want, levels)
+}
+
+func TestBoostIndexer_prefetch_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	errNode := errors.New("node is unavailable")
+	rpc := noderpc.NewMockINode(ctrl)
+	rpc.EXPECT().GetHeader(gomock.Any()).DoAndReturn(func(level int64) (noderpc.Header, error) {
+		if level == 3 {
+			return noderpc.Header{}, errNode
+		}
+		return noderpc.Header{Level: level}, nil
+	}).AnyTimes()
+	rpc.EXPECT().GetLightOPG(gomock.Any()).Return(nil, nil).AnyTimes()
+
+	bi := newTestBoostIndexer(rpc)
+	var blocks []*fetchedBlock
+	for block := range bi.prefetch(context.Background(), 1, 10, 2) {
+		blocks = append(blocks, block)
+	}
+
+	require.Len(t, blocks, 3)
+	assert.Equal(t, int64(1), blocks[0].header.Level)
+	assert.Equal(t, int64(2), blocks[1].header.Level)
+	assert.Equal(t, errNode, blocks[2].err)
+}
+
+func TestBoostIndexer_prefetch_Cancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rpc := noderpc.NewMockINode(ctrl)
+	rpc.EXPECT().GetHeader(gomock.Any()).DoAndReturn(func(level int64) (noderpc.Header, error) {
+		return noderpc.Header{Level: level}, nil
+	}).AnyTimes()
+	rpc.EXPECT().GetLightOPG(gomock.Any()).Return(nil, nil).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bi := newTestBoostIndexer(rpc)
+	output := bi.prefetch(ctx, 1, 1000000, 4)
+
+	first := <-output
+	require.NotNil(t, first)
+	assert.Equal(t, int64(1), first.header.Level)
+	cancel()
+
+	// channel is closed after cancellation, blocks which were ready before it may be received
+	timeout := time.After(5 * time.Second)
+	received := 0
+	for {
+		select {
+		case block, ok := <-output:
+			if !ok {
+				assert.Less(t, received, 100)
+				return
+			}
+			received++
+			require.NotNil(t, block)
+		case <-timeout:
+			t.Fatal("prefetch channel was not closed after cancellation")
+		}
+	}
+}
@@ -26,7 +26,12 @@ func CreateIndexers(ctx context.Context, internalCtx *config.Context, cfg config
 			return nil, errors.Errorf("Unknown network %s", network)
 		}
 
-		bi, err := NewBoostIndexer(ctx, *internalCtx, rpc, types.NewNetwork(network))
+		typ := types.NewNetwork(network)
+		if typ == types.Empty {
+			return nil, errors.Errorf("Network %s is not registered", network)
+		}
+
+		bi, err := NewBoostIndexer(ctx, *internalCtx, rpc, typ)
 		if err != nil {
 			return nil, err
 		}
@@ -11,11 +11,13 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/global_constant"
 	"github.com/baking-bad/bcdhub/internal/models/migration"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/saplingdiff"
 	"github.com/baking-bad/bcdhub/internal/models/service"
 	"github.com/baking-bad/bcdhub/internal/models/tokenbalance"
 	"github.com/baking-bad/bcdhub/internal/models/tokenmetadata"
 	"github.com/baking-bad/bcdhub/internal/models/transfer"
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/models/webhook"
 )
 
 func (bi *BoostIndexer) createIndices() {
@@ -116,6 +118,19 @@ func (bi *BoostIndexer) createIndices() {
 		logger.Error().Err(err).Msg("can't create index")
 	}
 
+	// Sapling diffs
+	if _, err := bi.Context.StorageDB.DB.Model((*saplingdiff.SaplingDiff)(nil)).Exec(`
+		CREATE INDEX CONCURRENTLY IF NOT EXISTS sapling_diffs_ptr_idx ON ?TableName (network, ptr, kind)
+	`); err != nil {
+		logger.Error().Err(err).Msg("can't create index")
+	}
+
+	if _, err := bi.Context.StorageDB.DB.Model((*saplingdiff.SaplingDiff)(nil)).Exec(`
+		CREATE INDEX CONCURRENTLY IF NOT EXISTS sapling_diffs_network_level_idx ON ?TableName (network, level)
+	`); err != nil {
+		logger.Error().Err(err).Msg("can't create index")
+	}
+
 	// Migrations
 	if _, err := bi.Context.StorageDB.DB.Model((*migration.Migration)(nil)).Exec(`
 		CREATE INDEX CONCURRENTLY IF NOT EXISTS migrations_network_level_idx ON ?TableName (network, level)
@@ -142,6 +157,12 @@ func (bi *BoostIndexer) createIndices() {
 		logger.Error().Err(err).Msg("can't create index")
 	}
 
+	if _, err := bi.Context.StorageDB.DB.Model((*operation.Operation)(nil)).Exec(`
+		CREATE INDEX CONCURRENTLY IF NOT EXISTS operations_delegate_idx ON ?TableName (delegate_id)
+	`); err != nil {
+		logger.Error().Err(err).Msg("can't create index")
+	}
+
 	if _, err := bi.Context.StorageDB.DB.Model((*operation.Operation)(nil)).Exec(`
 		CREATE INDEX CONCURRENTLY IF NOT EXISTS operations_opg_idx ON ?TableName (hash, counter, content_index)
 	`); err != nil {
@@ -248,4 +269,11 @@ func (bi *BoostIndexer) createIndices() {
 	`); err != nil {
 		logger.Error().Err(err).Msg("can't create index")
 	}
+
+	// Webhooks
+	if _, err := bi.Context.StorageDB.DB.Model(new(webhook.Delivery)).Exec(`
+		CREATE INDEX CONCURRENTLY IF NOT EXISTS webhook_deliveries_subscription_idx ON ?TableName (subscription_id, id)
+	`); err != nil {
+		logger.Error().Err(err).Msg("can't create index")
+	}
 }
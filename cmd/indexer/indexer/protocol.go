@@ -6,11 +6,12 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/pkg/errors"
 )
 
 func createProtocol(rpc noderpc.INode, network types.Network, hash string, level int64) (protocol protocol.Protocol, err error) {
 	logger.Info().Str("network", network.String()).Msgf("Creating new protocol %s starting at %d", hash, level)
-	protocol.SymLink, err = bcd.GetProtoSymLink(hash)
+	protocol.SymLink, err = getProtoSymLink(rpc, network, hash, level)
 	if err != nil {
 		return
 	}
@@ -25,6 +26,32 @@ func createProtocol(rpc noderpc.INode, network types.Network, hash string, level
 	return
 }
 
+// getProtoSymLink - returns symlink of protocol. If protocol is unknown it detects symlink by protocol of the previous block.
+func getProtoSymLink(rpc noderpc.INode, network types.Network, hash string, level int64) (string, error) {
+	symLink, err := bcd.GetProtoSymLink(hash)
+	if err == nil {
+		return symLink, nil
+	}
+	if level < 2 {
+		return "", err
+	}
+
+	predecessor, err := rpc.GetHeader(level - 1)
+	if err != nil {
+		return "", err
+	}
+	symLink, err = bcd.DetectProtoSymLink(predecessor.Protocol)
+	if err != nil {
+		return "", errors.Wrapf(err, "Unknown protocol: %s", hash)
+	}
+	if err := bcd.RegisterProtoSymLink(hash, symLink); err != nil {
+		return "", err
+	}
+
+	logger.Warning().Str("network", network.String()).Msgf("Unknown protocol %s is accepted with symlink %s of predecessor %s", hash, symLink, predecessor.Protocol)
+	return symLink, nil
+}
+
 func setProtocolConstants(rpc noderpc.INode, proto *protocol.Protocol) error {
 	if proto.StartLevel > 0 {
 		resp, err := rpc.GetNetworkConstants(proto.StartLevel)
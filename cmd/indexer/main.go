@@ -11,6 +11,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/config"
 	"github.com/baking-bad/bcdhub/internal/helpers"
 	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/telemetry"
 )
 
 func main() {
@@ -28,11 +29,18 @@ func main() {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	internalCtx := config.NewContext(
+	internalCtx, err := config.NewContext(
 		config.WithConfigCopy(cfg),
 		config.WithStorage(cfg.Storage, "indexer", 10, cfg.Indexer.Connections.Open, cfg.Indexer.Connections.Idle),
+		config.WithNetworks(cfg.Networks),
 		config.WithSearch(cfg.Storage),
 	)
+	if err != nil {
+		cancel()
+		logger.Err(err)
+		helpers.CatchErrorSentry(err)
+		return
+	}
 	defer internalCtx.Close()
 
 	indexers, err := indexer.CreateIndexers(ctx, internalCtx, cfg)
@@ -43,6 +51,12 @@ func main() {
 		return
 	}
 
+	if cfg.Indexer.Prometheus.Enabled {
+		server := telemetry.NewServer(cfg.Indexer.Prometheus.Bind)
+		server.Start()
+		defer server.Close()
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 
@@ -11,6 +11,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/config"
 	"github.com/baking-bad/bcdhub/internal/helpers"
 	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/telemetry"
 )
 
 var ctx *config.Context
@@ -31,13 +32,19 @@ func main() {
 		defer helpers.CatchPanicSentry()
 	}
 
-	ctx = config.NewContext(
+	ctx, err = config.NewContext(
 		config.WithStorage(cfg.Storage, cfg.Metrics.ProjectName, 0, cfg.Metrics.Connections.Open, cfg.Metrics.Connections.Idle),
+		config.WithNetworks(cfg.Networks),
 		config.WithRPC(cfg.RPC),
 		config.WithSearch(cfg.Storage),
 		config.WithDomains(cfg.Domains),
 		config.WithConfigCopy(cfg),
 	)
+	if err != nil {
+		logger.Err(err)
+		helpers.CatchErrorSentry(err)
+		return
+	}
 	defer ctx.Close()
 
 	if err := ctx.Searcher.CreateIndexes(); err != nil {
@@ -68,27 +75,46 @@ func main() {
 			time.Second*15,
 			bulkSize,
 		),
-		services.NewStorageBased(
-			"operations",
-			ctx.Services,
-			services.NewOperationsHandler(ctx),
-			time.Second*15,
-			bulkSize,
-		),
-		services.NewStorageBased(
-			"contracts",
-			ctx.Services,
-			services.NewContractsHandler(ctx),
-			time.Second*15,
-			bulkSize,
-		),
-		services.NewStorageBased(
-			"big_map_diffs",
-			ctx.Services,
-			services.NewBigMapDiffHandler(ctx),
-			time.Second*15,
-			bulkSize,
-		),
+	}
+
+	// postgres backend searches right in the tables of indexer, so search documents are needed only by Elastic
+	if cfg.Storage.SearchBackend() == config.SearchElastic {
+		workers = append(workers,
+			services.NewStorageBased(
+				"operations",
+				ctx.Services,
+				services.NewOperationsHandler(ctx),
+				time.Second*15,
+				bulkSize,
+			),
+			services.NewStorageBased(
+				"contracts",
+				ctx.Services,
+				services.NewContractsHandler(ctx),
+				time.Second*15,
+				bulkSize,
+			),
+			services.NewStorageBased(
+				"big_map_diffs",
+				ctx.Services,
+				services.NewBigMapDiffHandler(ctx),
+				time.Second*15,
+				bulkSize,
+			),
+		)
+	}
+
+	if cfg.Metrics.Webhooks.Enabled {
+		workers = append(workers,
+			services.NewWebhooks(ctx, time.Second*5, bulkSize),
+			services.NewWebhookDeliveries(ctx, time.Second*5),
+		)
+	}
+
+	if cfg.Metrics.Prometheus.Enabled {
+		server := telemetry.NewServer(cfg.Metrics.Prometheus.Bind)
+		server.Start()
+		defer server.Close()
 	}
 
 	signals := make(chan os.Signal, 1)
@@ -7,6 +7,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/config"
 	"github.com/baking-bad/bcdhub/internal/logger"
 	"github.com/baking-bad/bcdhub/internal/models"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 )
 
 // BigMapDiffHandler -
@@ -43,3 +44,8 @@ func (oh *BigMapDiffHandler) Chunk(lastID, size int64) ([]models.Model, error) {
 	}
 	return data, nil
 }
+
+// MaxID -
+func (oh *BigMapDiffHandler) MaxID() (int64, error) {
+	return getMaxID(oh.StorageDB.DB, (*bigmapdiff.BigMapDiff)(nil))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/handlers"
 	"github.com/baking-bad/bcdhub/internal/logger"
 	"github.com/baking-bad/bcdhub/internal/models"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/domains"
 	"github.com/pkg/errors"
 )
@@ -90,3 +91,8 @@ func (cm *ContractMetadataHandler) Chunk(lastID, size int64) ([]models.Model, er
 	}
 	return data, nil
 }
+
+// MaxID -
+func (cm *ContractMetadataHandler) MaxID() (int64, error) {
+	return getMaxID(cm.StorageDB.DB, (*bigmapdiff.BigMapDiff)(nil))
+}
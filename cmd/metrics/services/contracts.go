@@ -7,6 +7,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/config"
 	"github.com/baking-bad/bcdhub/internal/logger"
 	"github.com/baking-bad/bcdhub/internal/models"
+	"github.com/baking-bad/bcdhub/internal/models/contract"
 )
 
 // ContractsHandler -
@@ -43,3 +44,8 @@ func (ch *ContractsHandler) Chunk(lastID, size int64) ([]models.Model, error) {
 	}
 	return data, nil
 }
+
+// MaxID -
+func (ch *ContractsHandler) MaxID() (int64, error) {
+	return getMaxID(ch.StorageDB.DB, (*contract.Contract)(nil))
+}
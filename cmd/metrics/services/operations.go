@@ -7,6 +7,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/config"
 	"github.com/baking-bad/bcdhub/internal/logger"
 	"github.com/baking-bad/bcdhub/internal/models"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
 )
 
 // OperationsHandler -
@@ -43,3 +44,8 @@ func (oh *OperationsHandler) Chunk(lastID, size int64) ([]models.Model, error) {
 	}
 	return data, nil
 }
+
+// MaxID -
+func (oh *OperationsHandler) MaxID() (int64, error) {
+	return getMaxID(oh.StorageDB.DB, (*operation.Operation)(nil))
+}
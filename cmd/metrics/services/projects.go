@@ -64,6 +64,11 @@ func (p *ProjectsHandler) Chunk(lastID, size int64) ([]models.Model, error) {
 	return data, nil
 }
 
+// MaxID -
+func (p *ProjectsHandler) MaxID() (int64, error) {
+	return getMaxID(p.StorageDB.DB, (*contract.Script)(nil))
+}
+
 func (p *ProjectsHandler) process(script *contract.Script, chunk []contract.Script) error {
 	if script.ProjectID.Valid {
 		return nil
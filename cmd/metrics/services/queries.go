@@ -67,3 +67,8 @@ func saveSearchModels(ctx context.Context, internalContext *config.Context, item
 
 	return internalContext.Searcher.Save(ctx, data)
 }
+
+func getMaxID(db pg.DBI, model interface{}) (id int64, err error) {
+	err = db.Model(model).ColumnExpr("COALESCE(MAX(id), 0)").Select(&id)
+	return
+}
@@ -18,4 +18,6 @@ type Service interface {
 type Handler interface {
 	Chunk(lastID, size int64) ([]models.Model, error)
 	Handle(ctx context.Context, items []models.Model, wg *sync.WaitGroup) error
+	// MaxID - returns the max ID of handled table, it's used to measure backlog of service
+	MaxID() (int64, error)
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/baking-bad/bcdhub/internal/logger"
 	"github.com/baking-bad/bcdhub/internal/models/service"
+	"github.com/baking-bad/bcdhub/internal/telemetry"
 )
 
 // StorageBased -
@@ -119,5 +120,22 @@ func (s *StorageBased) do(ctx context.Context, wg *sync.WaitGroup) (bool, error)
 		}
 	}
 
+	if err := s.observeBacklog(); err != nil {
+		logger.Err(err)
+	}
+
 	return len(items) == int(s.bulkSize), nil
 }
+
+func (s *StorageBased) observeBacklog() error {
+	maxID, err := s.handler.MaxID()
+	if err != nil {
+		return err
+	}
+	backlog := maxID - s.state.LastID
+	if backlog < 0 {
+		backlog = 0
+	}
+	telemetry.ServiceBacklog.WithLabelValues(s.name).Set(float64(backlog))
+	return nil
+}
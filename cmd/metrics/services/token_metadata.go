@@ -8,6 +8,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/handlers"
 	"github.com/baking-bad/bcdhub/internal/logger"
 	"github.com/baking-bad/bcdhub/internal/models"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/domains"
 	"github.com/pkg/errors"
 )
@@ -88,3 +89,8 @@ func (tm *TokenMetadataHandler) Chunk(lastID, size int64) ([]models.Model, error
 	}
 	return data, nil
 }
+
+// MaxID -
+func (tm *TokenMetadataHandler) MaxID() (int64, error) {
+	return getMaxID(tm.StorageDB.DB, (*bigmapdiff.BigMapDiff)(nil))
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/models"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/models/webhook"
+	webhookService "github.com/baking-bad/bcdhub/internal/services/webhook"
+	"github.com/pkg/errors"
+)
+
+// Webhooks - delivers notifications about new operations to webhook subscribers.
+// On the first start it skips already indexed operations.
+type Webhooks struct {
+	*StorageBased
+	ctx *config.Context
+}
+
+// NewWebhooks -
+func NewWebhooks(ctx *config.Context, updatePeriod time.Duration, bulkSize int64) *Webhooks {
+	return &Webhooks{
+		StorageBased: NewStorageBased("webhooks", ctx.Services, NewWebhooksHandler(ctx), updatePeriod, bulkSize),
+		ctx:          ctx,
+	}
+}
+
+// Init -
+func (w *Webhooks) Init() error {
+	if err := w.seed(); err != nil {
+		return err
+	}
+	if err := w.StorageBased.Init(); err != nil {
+		return err
+	}
+	if w.state.LastID > 0 {
+		return nil
+	}
+
+	lastID, err := getMaxID(w.ctx.StorageDB.DB, (*operation.Operation)(nil))
+	if err != nil {
+		return err
+	}
+	w.state.LastID = lastID
+	return w.repo.Save(w.state)
+}
+
+func (w *Webhooks) seed() error {
+	if !w.ctx.Config.API.SeedEnabled {
+		return nil
+	}
+	for _, s := range w.ctx.Config.API.Seed.Subscriptions {
+		if s.URL == "" {
+			continue
+		}
+		network := types.NewNetwork(s.Network)
+		if network == types.Empty {
+			return errors.Errorf("unknown network of seed subscription: %s", s.Network)
+		}
+		sub := webhook.Subscription{
+			Network:   network,
+			Address:   s.Address,
+			URL:       s.URL,
+			Secret:    s.Secret,
+			Alias:     s.Alias,
+			WatchMask: s.WatchMask & webhook.WatchAll,
+		}
+		if err := sub.Save(w.ctx.StorageDB.DB); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WebhooksHandler - queues notifications about new operations. They are sent by `WebhookDeliveries`.
+type WebhooksHandler struct {
+	*config.Context
+}
+
+// NewWebhooksHandler -
+func NewWebhooksHandler(ctx *config.Context) *WebhooksHandler {
+	return &WebhooksHandler{ctx}
+}
+
+type notification struct {
+	sub     webhook.Subscription
+	payload webhookService.Payload
+}
+
+// Handle -
+func (wh *WebhooksHandler) Handle(ctx context.Context, items []models.Model, wg *sync.WaitGroup) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	byNetwork := make(map[types.Network][]*operation.Operation)
+	for i := range items {
+		op, ok := items[i].(*operation.Operation)
+		if !ok {
+			return errors.Errorf("invalid model type: %T", items[i])
+		}
+		byNetwork[op.Network] = append(byNetwork[op.Network], op)
+	}
+
+	notifications := make(map[int64][]notification)
+	var count int
+	for network, operations := range byNetwork {
+		subs, err := wh.Webhooks.ByNetwork(network)
+		if err != nil {
+			return err
+		}
+		if len(subs) == 0 {
+			continue
+		}
+		found, err := wh.collect(subs, operations)
+		if err != nil {
+			return err
+		}
+		for i := range found {
+			notifications[found[i].sub.ID] = append(notifications[found[i].sub.ID], found[i])
+		}
+		count += len(found)
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	deliveries := make([]webhook.Delivery, 0, count)
+	for _, items := range notifications {
+		for _, n := range items {
+			body, err := json.Marshal(n.payload)
+			if err != nil {
+				return err
+			}
+			deliveries = append(deliveries, webhook.Delivery{
+				SubscriptionID: n.sub.ID,
+				Event:          n.payload.Event,
+				Payload:        string(body),
+			})
+		}
+	}
+	if err := wh.Webhooks.SaveDeliveries(deliveries); err != nil {
+		return err
+	}
+	logger.Info().Msgf("%3d webhook notifications are queued", count)
+	return nil
+}
+
+// Chunk -
+func (wh *WebhooksHandler) Chunk(lastID, size int64) ([]models.Model, error) {
+	operations, err := getOperations(wh.StorageDB.DB, lastID, size)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]models.Model, len(operations))
+	for i := range operations {
+		data[i] = &operations[i]
+	}
+	return data, nil
+}
+
+// MaxID -
+func (wh *WebhooksHandler) MaxID() (int64, error) {
+	return getMaxID(wh.StorageDB.DB, (*operation.Operation)(nil))
+}
+
+func (wh *WebhooksHandler) collect(subs []webhook.Subscription, operations []*operation.Operation) ([]notification, error) {
+	byAddress := make(map[string][]webhook.Subscription)
+	var mask uint
+	for i := range subs {
+		byAddress[subs[i].Address] = append(byAddress[subs[i].Address], subs[i])
+		mask |= subs[i].WatchMask
+	}
+
+	result := make([]notification, 0)
+	ids := make([]int64, 0, len(operations))
+	hashes := make(map[int64]string)
+	for _, op := range operations {
+		ids = append(ids, op.ID)
+		hashes[op.ID] = op.Hash
+
+		if !op.IsTransaction() {
+			continue
+		}
+		for _, sub := range byAddress[op.Destination.Address] {
+			if sub.Watches(webhook.WatchTransactions) {
+				result = append(result, notification{sub, webhookService.NewTransactionPayload(sub, *op)})
+			}
+		}
+	}
+
+	if mask&webhook.WatchTransfers != 0 {
+		transfers, err := wh.Transfers.GetForOperations(ids...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range transfers {
+			addresses := []string{transfers[i].From.Address}
+			if transfers[i].To.Address != transfers[i].From.Address {
+				addresses = append(addresses, transfers[i].To.Address)
+			}
+			for _, address := range addresses {
+				for _, sub := range byAddress[address] {
+					if sub.Watches(webhook.WatchTransfers) {
+						result = append(result, notification{sub, webhookService.NewTransferPayload(sub, transfers[i], hashes[transfers[i].OperationID])})
+					}
+				}
+			}
+		}
+	}
+
+	if mask&webhook.WatchBigMaps != 0 {
+		diffs, err := wh.BigMapDiffs.GetForOperations(ids...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range diffs {
+			for _, sub := range byAddress[diffs[i].Contract] {
+				if sub.Watches(webhook.WatchBigMaps) {
+					result = append(result, notification{sub, webhookService.NewBigMapPayload(sub, diffs[i], hashes[diffs[i].OperationID])})
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// WebhookDeliveries - sends queued webhook notifications. Notifications of each subscription are sent in order:
+// failed one is retried with exponential backoff and delays only the following notifications of its subscription.
+// Notification is moved to dead letters when retries are exhausted.
+type WebhookDeliveries struct {
+	*TimeBased
+	ctx     *config.Context
+	sender  *webhookService.Sender
+	workers int
+}
+
+// NewWebhookDeliveries -
+func NewWebhookDeliveries(ctx *config.Context, period time.Duration) *WebhookDeliveries {
+	cfg := ctx.Config.Metrics.Webhooks
+	opts := []webhookService.SenderOption{
+		webhookService.WithTimeout(time.Duration(cfg.Timeout) * time.Second),
+		webhookService.WithMaxInterval(time.Duration(cfg.MaxInterval) * time.Second),
+	}
+	if cfg.MaxRetries > 0 {
+		opts = append(opts, webhookService.WithMaxRetries(cfg.MaxRetries))
+	}
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = 4
+	}
+
+	d := &WebhookDeliveries{
+		ctx:     ctx,
+		sender:  webhookService.NewSender(opts...),
+		workers: workers,
+	}
+	d.TimeBased = NewTimeBased(d.deliver, period)
+	return d
+}
+
+// deliver - sends due notifications until queue has nothing to send right now
+func (d *WebhookDeliveries) deliver(ctx context.Context) error {
+	for {
+		deliveries, err := d.ctx.Webhooks.DueDeliveries(int64(d.workers) * 10)
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		queue := make(chan webhook.Delivery, len(deliveries))
+		for i := range deliveries {
+			queue <- deliveries[i]
+		}
+		close(queue)
+
+		var (
+			wg       sync.WaitGroup
+			mx       sync.Mutex
+			firstErr error
+		)
+		for i := 0; i < d.workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for delivery := range queue {
+					if err := d.send(ctx, delivery); err != nil {
+						mx.Lock()
+						if firstErr == nil {
+							firstErr = err
+						}
+						mx.Unlock()
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		if firstErr != nil {
+			return firstErr
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// send - makes one attempt to deliver notification and updates queue by result
+func (d *WebhookDeliveries) send(ctx context.Context, delivery webhook.Delivery) error {
+	sub, err := d.ctx.Webhooks.Get(delivery.SubscriptionID)
+	if err != nil {
+		if d.ctx.Storage.IsRecordNotFound(err) {
+			return d.ctx.Webhooks.Delivered(delivery.ID)
+		}
+		return err
+	}
+
+	var payload webhookService.Payload
+	if err := json.Unmarshal([]byte(delivery.Payload), &payload); err != nil {
+		return err
+	}
+
+	err = d.sender.Post(ctx, sub.URL, sub.Secret, payload, []byte(delivery.Payload))
+	switch {
+	case err == nil:
+		return d.ctx.Webhooks.Delivered(delivery.ID)
+	case ctx.Err() != nil:
+		return nil
+	}
+
+	delivery.Attempts++
+	delivery.Error = err.Error()
+	if webhookService.IsPermanent(err) || uint64(delivery.Attempts) > d.sender.MaxRetries() {
+		logger.Warning().Err(err).Int64("subscription", sub.ID).Str("url", sub.URL).Msg("webhook is not delivered")
+		return d.ctx.Webhooks.MoveToDeadLetters(delivery)
+	}
+
+	delivery.NextAttemptAt = time.Now().Add(d.sender.RetryDelay(delivery.Attempts))
+	return d.ctx.Webhooks.Postpone(&delivery)
+}
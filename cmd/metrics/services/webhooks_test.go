@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/config"
+	mock_general "github.com/baking-bad/bcdhub/internal/models/mock"
+	mock_webhook "github.com/baking-bad/bcdhub/internal/models/mock/webhook"
+	"github.com/baking-bad/bcdhub/internal/models/webhook"
+	webhookService "github.com/baking-bad/bcdhub/internal/services/webhook"
+	"github.com/go-pg/pg/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveries_send(t *testing.T) {
+	const payload = `{"id":"1","event":"transaction","subscription_id":1}`
+
+	tests := []struct {
+		name     string
+		status   int
+		attempts int64
+		expect   func(repo *mock_webhook.MockRepositoryMockRecorder)
+	}{
+		{
+			name:   "delivered",
+			status: http.StatusOK,
+			expect: func(repo *mock_webhook.MockRepositoryMockRecorder) {
+				repo.Delivered(int64(10)).Return(nil).Times(1)
+			},
+		}, {
+			name:   "postponed",
+			status: http.StatusBadGateway,
+			expect: func(repo *mock_webhook.MockRepositoryMockRecorder) {
+				repo.Postpone(gomock.Any()).DoAndReturn(func(delivery *webhook.Delivery) error {
+					assert.Equal(t, int64(1), delivery.Attempts)
+					assert.NotEmpty(t, delivery.Error)
+					assert.True(t, delivery.NextAttemptAt.After(time.Now()))
+					return nil
+				}).Times(1)
+			},
+		}, {
+			name:   "client error goes to dead letters",
+			status: http.StatusNotFound,
+			expect: func(repo *mock_webhook.MockRepositoryMockRecorder) {
+				repo.MoveToDeadLetters(gomock.Any()).DoAndReturn(func(delivery webhook.Delivery) error {
+					assert.Equal(t, int64(1), delivery.Attempts)
+					return nil
+				}).Times(1)
+			},
+		}, {
+			name:     "retries are exhausted",
+			status:   http.StatusInternalServerError,
+			attempts: 2,
+			expect: func(repo *mock_webhook.MockRepositoryMockRecorder) {
+				repo.MoveToDeadLetters(gomock.Any()).DoAndReturn(func(delivery webhook.Delivery) error {
+					assert.Equal(t, int64(3), delivery.Attempts)
+					assert.Equal(t, payload, delivery.Payload)
+					return nil
+				}).Times(1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "1", r.Header.Get(webhookService.HeaderDelivery))
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			repo := mock_webhook.NewMockRepository(ctrl)
+			repo.EXPECT().Get(int64(1)).Return(webhook.Subscription{ID: 1, URL: server.URL}, nil).Times(1)
+			tt.expect(repo.EXPECT())
+
+			d := &WebhookDeliveries{
+				ctx:     &config.Context{Webhooks: repo},
+				sender:  webhookService.NewSender(webhookService.WithMaxRetries(2)),
+				workers: 1,
+			}
+			err := d.send(context.Background(), webhook.Delivery{
+				ID:             10,
+				SubscriptionID: 1,
+				Event:          webhookService.EventTransaction,
+				Payload:        payload,
+				Attempts:       tt.attempts,
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWebhookDeliveries_send_DeletedSubscription(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_webhook.NewMockRepository(ctrl)
+	general := mock_general.NewMockGeneralRepository(ctrl)
+	repo.EXPECT().Get(int64(1)).Return(webhook.Subscription{}, pg.ErrNoRows).Times(1)
+	general.EXPECT().IsRecordNotFound(pg.ErrNoRows).Return(true).Times(1)
+	repo.EXPECT().Delivered(int64(10)).Return(nil).Times(1)
+
+	d := &WebhookDeliveries{
+		ctx:     &config.Context{Webhooks: repo, Storage: general},
+		sender:  webhookService.NewSender(),
+		workers: 1,
+	}
+	require.NoError(t, d.send(context.Background(), webhook.Delivery{ID: 10, SubscriptionID: 1, Payload: `{}`}))
+}
@@ -23,6 +23,8 @@ require (
 	github.com/golang/mock v1.4.4
 	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/google/uuid v1.1.2
+	github.com/gorilla/websocket v1.4.2
+	github.com/graph-gophers/graphql-go v1.3.0
 	github.com/iancoleman/strcase v0.1.3
 	github.com/ipfs/go-cid v0.1.0
 	github.com/jessevdk/go-flags v1.4.0
@@ -36,7 +38,10 @@ require (
 	github.com/mattn/go-isatty v0.0.14 // indirect
 	github.com/microcosm-cc/bluemonday v1.0.16
 	github.com/onsi/gomega v1.10.4 // indirect
+	github.com/opentracing/opentracing-go v1.2.0 // indirect
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.10.0
+	github.com/prometheus/client_model v0.2.0
 	github.com/rs/zerolog v1.15.0
 	github.com/schollz/progressbar/v3 v3.1.1
 	github.com/sergi/go-diff v1.1.0
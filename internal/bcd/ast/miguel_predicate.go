@@ -0,0 +1,131 @@
+package ast
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/pkg/errors"
+)
+
+// predicate operators
+const (
+	PredicateEq  = "eq"
+	PredicateNe  = "ne"
+	PredicateGt  = "gt"
+	PredicateGte = "gte"
+	PredicateLt  = "lt"
+	PredicateLte = "lte"
+	PredicateHas = "has"
+)
+
+// MiguelPredicate - condition on value of field of decoded tree.
+// `Path` is list of field names from root of tree, `*` matches any child, empty path is root itself.
+// Predicate is true if any node found by path satisfies it. `has` operator is true if node, any of its descendants or any key of nested maps is equal to value.
+type MiguelPredicate struct {
+	Path     []string
+	Operator string
+	Value    string
+}
+
+// ParseMiguelPredicate - parses predicate from string `path:operator:value`, e.g. `owner:eq:tz1...` or `token.amount:gt:100`
+func ParseMiguelPredicate(str string) (MiguelPredicate, error) {
+	parts := strings.SplitN(str, ":", 3)
+	if len(parts) != 3 {
+		return MiguelPredicate{}, errors.Errorf("invalid predicate '%s': expected 'path:operator:value'", str)
+	}
+
+	p := MiguelPredicate{
+		Operator: parts[1],
+		Value:    parts[2],
+	}
+	switch p.Operator {
+	case PredicateEq, PredicateNe, PredicateGt, PredicateGte, PredicateLt, PredicateLte, PredicateHas:
+	default:
+		return p, errors.Errorf("invalid predicate '%s': unknown operator '%s'", str, p.Operator)
+	}
+	if parts[0] != "" {
+		p.Path = strings.Split(parts[0], ".")
+	}
+	return p, nil
+}
+
+// Match -
+func (p MiguelPredicate) Match(node *MiguelNode) bool {
+	for _, found := range findMiguelByPath(node, p.Path) {
+		if p.match(found) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p MiguelPredicate) match(node *MiguelNode) bool {
+	if p.Operator == PredicateHas {
+		if node.Value != nil && compareMiguelValue(node.Value, p.Value) == 0 {
+			return true
+		}
+		isMap := node.Prim == consts.MAP || node.Prim == consts.BIGMAP
+		for _, child := range node.Children {
+			// keys of map are names of its children
+			if isMap && child.Name != nil && compareMiguelValue(*child.Name, p.Value) == 0 {
+				return true
+			}
+			if p.match(child) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if node.Value == nil {
+		return false
+	}
+	cmp := compareMiguelValue(node.Value, p.Value)
+	switch p.Operator {
+	case PredicateEq:
+		return cmp == 0
+	case PredicateNe:
+		return cmp != 0
+	case PredicateGt:
+		return cmp > 0
+	case PredicateGte:
+		return cmp >= 0
+	case PredicateLt:
+		return cmp < 0
+	case PredicateLte:
+		return cmp <= 0
+	default:
+		return false
+	}
+}
+
+func findMiguelByPath(node *MiguelNode, path []string) []*MiguelNode {
+	if node == nil {
+		return nil
+	}
+	if len(path) == 0 {
+		return []*MiguelNode{node}
+	}
+
+	result := make([]*MiguelNode, 0)
+	for _, child := range node.Children {
+		if path[0] == "*" || (child.Name != nil && *child.Name == path[0]) {
+			result = append(result, findMiguelByPath(child, path[1:])...)
+		}
+	}
+	return result
+}
+
+// compareMiguelValue - compares values as integers if both of them are integers and as strings otherwise
+func compareMiguelValue(value interface{}, str string) int {
+	s := fmt.Sprintf("%v", value)
+
+	x, okX := new(big.Int).SetString(s, 10)
+	y, okY := new(big.Int).SetString(str, 10)
+	if okX && okY {
+		return x.Cmp(y)
+	}
+	return strings.Compare(s, str)
+}
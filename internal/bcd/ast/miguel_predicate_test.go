@@ -0,0 +1,81 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiguelPredicate_Match(t *testing.T) {
+	typ := `{"prim":"pair","args":[{"prim":"address","annots":["%owner"]},{"prim":"pair","args":[{"prim":"nat","annots":["%balance"]},{"prim":"map","args":[{"prim":"address"},{"prim":"nat"}],"annots":["%allowances"]}]}]}`
+	value := `{"prim":"Pair","args":[{"string":"tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6"},{"prim":"Pair","args":[{"int":"150"},[{"prim":"Elt","args":[{"string":"KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn"},{"int":"10"}]}]]}]}`
+
+	tree, err := NewTypedAstFromString(typ)
+	require.NoError(t, err)
+	require.NoError(t, tree.SettleFromBytes([]byte(value)))
+	miguel, err := tree.ToMiguel()
+	require.NoError(t, err)
+	require.Len(t, miguel, 1)
+
+	tests := []struct {
+		predicate string
+		want      bool
+	}{
+		{predicate: "owner:eq:tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6", want: true},
+		{predicate: "owner:ne:tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6", want: false},
+		{predicate: "balance:gt:100", want: true},
+		{predicate: "balance:gt:150", want: false},
+		{predicate: "balance:gte:150", want: true},
+		{predicate: "balance:lt:20", want: false},
+		{predicate: "balance:lte:150", want: true},
+		{predicate: "unknown:eq:150", want: false},
+		{predicate: "allowances.*:gte:10", want: true},
+		{predicate: ":has:KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn", want: true},
+		{predicate: ":has:tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb", want: false},
+		{predicate: "allowances:has:tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.predicate, func(t *testing.T) {
+			p, err := ParseMiguelPredicate(tt.predicate)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, p.Match(miguel[0]))
+		})
+	}
+}
+
+func TestParseMiguelPredicate(t *testing.T) {
+	tests := []struct {
+		str     string
+		want    MiguelPredicate
+		wantErr bool
+	}{
+		{
+			str:  "token.amount:gt:100",
+			want: MiguelPredicate{Path: []string{"token", "amount"}, Operator: PredicateGt, Value: "100"},
+		}, {
+			str:  "updated:lt:2021-01-01T00:00:00Z",
+			want: MiguelPredicate{Path: []string{"updated"}, Operator: PredicateLt, Value: "2021-01-01T00:00:00Z"},
+		}, {
+			str:  ":has:tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6",
+			want: MiguelPredicate{Operator: PredicateHas, Value: "tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6"},
+		}, {
+			str:     "amount:like:100",
+			wantErr: true,
+		}, {
+			str:     "amount",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			got, err := ParseMiguelPredicate(tt.str)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
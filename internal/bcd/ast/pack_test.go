@@ -57,3 +57,51 @@ func TestBigMapKeyHash(t *testing.T) {
 		})
 	}
 }
+
+func TestBigMapKeyHashFromNode(t *testing.T) {
+	tests := []struct {
+		name     string
+		typ      string
+		value    string
+		expected string
+	}{
+		{
+			name:     "readable address",
+			typ:      `{"prim":"address"}`,
+			value:    `{"string":"tz1MsmYzmqxHs9trE1qQugZxxcLPqAXdQaX9"}`,
+			expected: "expru2YV8AanTTUSV4K21P7X4DzbuWQFVk7NewDuP1A5uamffiiFA3",
+		}, {
+			name:     "optimized address",
+			typ:      `{"prim":"address"}`,
+			value:    `{"bytes":"000018896fcfc6690baefa9aedc6d759f9bf05727e8c"}`,
+			expected: "expru2YV8AanTTUSV4K21P7X4DzbuWQFVk7NewDuP1A5uamffiiFA3",
+		}, {
+			name:     "nat",
+			typ:      `{"prim":"nat"}`,
+			value:    `{"int":"505506"}`,
+			expected: "exprufzwVGdAX7zG91UpiAkR2yVxEDE75tHD5YgSBmYMUx22teZTCM",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := NewTypedAstFromString(tt.typ)
+			if err != nil {
+				t.Errorf("NewTypedAstFromString error: %v", err)
+				return
+			}
+			if err := tree.SettleFromBytes([]byte(tt.value)); err != nil {
+				t.Errorf("SettleFromBytes error: %v", err)
+				return
+			}
+			result, err := BigMapKeyHashFromNode(tree.Nodes[0])
+			if err != nil {
+				t.Errorf("BigMapKeyHashFromNode error: %v", err)
+				return
+			}
+			if result != tt.expected {
+				t.Errorf("error in BigMapKeyHashFromNode, got: %v, expected: %v", result, tt.expected)
+			}
+		})
+	}
+}
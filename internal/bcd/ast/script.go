@@ -19,12 +19,6 @@ type Script struct {
 	Views     []UntypedAST `json:"-"`
 }
 
-// View -
-type View struct {
-	Name string
-	Code UntypedAST
-}
-
 type sectionNode struct {
 	Prim string             `json:"prim"`
 	Args stdJSON.RawMessage `json:"args"`
@@ -170,6 +164,19 @@ func (s *Script) ParameterType() (*TypedAst, error) {
 	return s.Parameter.ToTypedAST()
 }
 
+// GetViews - returns decoded on-chain views of script
+func (s *Script) GetViews() ([]View, error) {
+	views := make([]View, 0, len(s.Views))
+	for i := range s.Views {
+		view, err := NewView(s.Views[i])
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
 // SectionType -
 type SectionType struct {
 	Default
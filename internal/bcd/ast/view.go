@@ -0,0 +1,76 @@
+package ast
+
+import (
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/pkg/errors"
+)
+
+// View - on-chain view of contract
+type View struct {
+	Name       string
+	Parameter  UntypedAST
+	ReturnType UntypedAST
+	Code       UntypedAST
+}
+
+// NewView - creates `View` from arguments of `view` section: name, parameter type, return type and code
+func NewView(args UntypedAST) (View, error) {
+	if len(args) != 4 {
+		return View{}, errors.Wrapf(consts.ErrInvalidArgsCount, "view: expected 4 got %d", len(args))
+	}
+	if args[0].StringValue == nil {
+		return View{}, errors.Wrap(consts.ErrInvalidType, "view name must be a string")
+	}
+	return View{
+		Name:       *args[0].StringValue,
+		Parameter:  UntypedAST{args[1]},
+		ReturnType: UntypedAST{args[2]},
+		Code:       UntypedAST{args[3]},
+	}, nil
+}
+
+// NewViews - decodes list of `view` sections. It's format of views stored in contract`s script.
+func NewViews(data []byte) ([]View, error) {
+	if len(data) == 0 {
+		return []View{}, nil
+	}
+
+	var sections []base.Node
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return nil, err
+	}
+
+	views := make([]View, 0, len(sections))
+	for i := range sections {
+		if sections[i].Prim != consts.View {
+			return nil, errors.Wrapf(consts.ErrInvalidPrim, "expected %s got %s", consts.View, sections[i].Prim)
+		}
+		view, err := NewView(sections[i].Args)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// FindView - returns view by its name
+func FindView(views []View, name string) (View, bool) {
+	for i := range views {
+		if views[i].Name == name {
+			return views[i], true
+		}
+	}
+	return View{}, false
+}
+
+// ParameterType - returns typed tree of view`s parameter
+func (v View) ParameterType() (*TypedAst, error) {
+	return v.Parameter.ToTypedAST()
+}
+
+// ReturnTypeTree - returns typed tree of view`s return type
+func (v View) ReturnTypeTree() (*TypedAst, error) {
+	return v.ReturnType.ToTypedAST()
+}
@@ -0,0 +1,90 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewViews(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           string
+		wantNames      []string
+		wantParameter  []string
+		wantReturnType []string
+		wantErr        bool
+	}{
+		{
+			name:      "empty",
+			data:      ``,
+			wantNames: []string{},
+		}, {
+			name:           "two views",
+			data:           `[{"prim":"view","args":[{"string":"get_balance"},{"prim":"address"},{"prim":"nat"},[{"prim":"UNPAIR"},{"prim":"DROP"},{"prim":"CAR"}]]},{"prim":"view","args":[{"string":"total"},{"prim":"unit"},{"prim":"int"},[{"prim":"CDR"},{"prim":"CDR"}]]}]`,
+			wantNames:      []string{"get_balance", "total"},
+			wantParameter:  []string{"address", "unit"},
+			wantReturnType: []string{"nat", "int"},
+		}, {
+			name:    "invalid args count",
+			data:    `[{"prim":"view","args":[{"string":"total"},{"prim":"unit"},{"prim":"int"}]}]`,
+			wantErr: true,
+		}, {
+			name:    "name is not string",
+			data:    `[{"prim":"view","args":[{"int":"1"},{"prim":"unit"},{"prim":"int"},[]]}]`,
+			wantErr: true,
+		}, {
+			name:    "invalid prim",
+			data:    `[{"prim":"code","args":[]}]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewViews([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewViews() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !assert.Len(t, got, len(tt.wantNames)) {
+				return
+			}
+			for i := range got {
+				assert.Equal(t, tt.wantNames[i], got[i].Name)
+				assert.Equal(t, tt.wantParameter[i], got[i].Parameter[0].Prim)
+				assert.Equal(t, tt.wantReturnType[i], got[i].ReturnType[0].Prim)
+			}
+		})
+	}
+}
+
+func TestScript_GetViews(t *testing.T) {
+	data := `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"nat"}]},{"prim":"code","args":[[{"prim":"CDR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]},{"prim":"view","args":[{"string":"add"},{"prim":"nat"},{"prim":"nat"},[{"prim":"UNPAIR"},{"prim":"ADD"}]]}]`
+	script, err := NewScript([]byte(data))
+	if !assert.NoError(t, err) {
+		return
+	}
+	views, err := script.GetViews()
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, views, 1) {
+		return
+	}
+	view, ok := FindView(views, "add")
+	if !assert.True(t, ok) {
+		return
+	}
+	typ, err := view.ParameterType()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, typ.Nodes, 1)
+	assert.Len(t, view.Code[0].Args, 2)
+
+	_, ok = FindView(views, "unknown")
+	assert.False(t, ok)
+}
@@ -8,6 +8,9 @@ const (
 	Origination            = "origination"
 	OriginationNew         = "origination_new"
 	RegisterGlobalConstant = "register_global_constant"
+	Delegation             = "delegation"
+	Reveal                 = "reveal"
+	SetDepositsLimit       = "set_deposits_limit"
 )
 
 // Error IDs
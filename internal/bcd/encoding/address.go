@@ -0,0 +1,66 @@
+package encoding
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+)
+
+const addressHashLength = 20
+
+var publicKeyHashPrefixes = map[string]string{
+	PrefixED25519PublicKey:   PrefixPublicKeyTZ1,
+	PrefixSecp256k1PublicKey: PrefixPublicKeyTZ2,
+	PrefixP256PublicKey:      PrefixPublicKeyTZ3,
+}
+
+// PublicKeyHash - derives implicit address (tz1, tz2 or tz3) from public key
+func PublicKeyHash(publicKey string) (string, error) {
+	value, err := ParseBase58(publicKey)
+	if err != nil {
+		return "", err
+	}
+	prefix, ok := publicKeyHashPrefixes[value.Prefix]
+	if !ok {
+		return "", errors.Errorf("Invalid public key: %s", publicKey)
+	}
+	hash, err := addressHash(value.Data)
+	if err != nil {
+		return "", err
+	}
+	return EncodeBase58(hash, []byte(prefix))
+}
+
+// OriginatedAddress - computes address of contract originated by operation group with hash `opgHash`. `index` is a number of origination in the group including internal ones starting from 0.
+func OriginatedAddress(opgHash string, index int32) (string, error) {
+	value, err := ParseBase58(opgHash)
+	if err != nil {
+		return "", err
+	}
+	if value.Prefix != PrefixOperationHash {
+		return "", errors.Errorf("Invalid operation hash: %s", opgHash)
+	}
+	if index < 0 {
+		return "", errors.Errorf("Invalid origination index: %d", index)
+	}
+
+	nonce := make([]byte, 4)
+	binary.BigEndian.PutUint32(nonce, uint32(index))
+	hash, err := addressHash(append(value.Data, nonce...))
+	if err != nil {
+		return "", err
+	}
+	return EncodeBase58(hash, []byte(PrefixPublicKeyKT1))
+}
+
+func addressHash(data []byte) ([]byte, error) {
+	h, err := blake2b.New(addressHashLength, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(data); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,89 @@
+package encoding
+
+import (
+	"testing"
+)
+
+func TestPublicKeyHash(t *testing.T) {
+	tests := []struct {
+		name      string
+		publicKey string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "ed25519",
+			publicKey: "edpkuBknW28nW72KG6RoHtYW7p12T6GKc7nAbwYX5m8Wd9sDVC9yav",
+			want:      "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+		}, {
+			name:      "ed25519 2",
+			publicKey: "edpktzNbDAUjUk697W7gYg2CRuBQjyPxbEg8dLccYYwKSKvkPvjtV9",
+			want:      "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN",
+		}, {
+			name:      "not a public key",
+			publicKey: "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+			wantErr:   true,
+		}, {
+			name:      "invalid checksum",
+			publicKey: "edpkuBknW28nW72KG6RoHtYW7p12T6GKc7nAbwYX5m8Wd9sDVC9yaa",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PublicKeyHash(tt.publicKey)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PublicKeyHash() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("PublicKeyHash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOriginatedAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		hash    string
+		index   int32
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "origination",
+			hash: "onv6Q1dNejAGEJeQzwRannWsDSGw85FuFdhLnBrY18TBcC9p8kC",
+			want: "KT1AbjG7vtpV8osdoJXcMRck8eTwst8dWoz4",
+		}, {
+			name: "origination 2",
+			hash: "onzUDQhwunz2yqzfEsoURXEBz9p7Gk8DgY4QBva52Z4b3AJCZjt",
+			want: "KT1NppzrgyLZD3aku7fssfhYPm5QqZwyabvR",
+		}, {
+			name: "internal origination",
+			hash: "op4fFMvYsxvSUKZmLWC7aUf25VMYqigaDwTZCAoBBi8zACbHTNg",
+			want: "KT1JgHoXtZPjVfG82BY3FSys2VJhKVZo2EJU",
+		}, {
+			name:    "not an operation hash",
+			hash:    "KT1AbjG7vtpV8osdoJXcMRck8eTwst8dWoz4",
+			wantErr: true,
+		}, {
+			name:    "negative index",
+			hash:    "onv6Q1dNejAGEJeQzwRannWsDSGw85FuFdhLnBrY18TBcC9p8kC",
+			index:   -1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OriginatedAddress(tt.hash, tt.index)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("OriginatedAddress() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("OriginatedAddress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 var base58Enc = base58.New(base58.AlphabetBitcoin)
 
+const checksumLength = 4
+
 type base58Encoding struct {
 	EncodedPrefix []byte
 	EncodedLength int
@@ -113,7 +115,7 @@ func getBase58EncodingForDecode(data []byte) (base58Encoding, error) {
 
 func getBase58EncodingForEncode(data, prefix []byte) (base58Encoding, error) {
 	for _, e := range base58Encodings {
-		if len(data) != e.DecodedLength {
+		if len(data) != e.DecodedLength || len(prefix) > len(e.EncodedPrefix) {
 			continue
 		}
 		found := true
@@ -130,23 +132,55 @@ func getBase58EncodingForEncode(data, prefix []byte) (base58Encoding, error) {
 	return base58Encoding{}, errors.New("Unknown base58 encoding")
 }
 
-// DecodeBase58 -
-func DecodeBase58(data string) ([]byte, error) {
-	decoded, err := base58Enc.CheckDecode(data)
+// checkDecode - `CheckDecode` of library panics if decoded value is shorter than checksum
+func checkDecode(data string) ([]byte, error) {
+	decoded, err := base58Enc.Decode(data)
 	if err != nil {
 		return nil, err
 	}
+	if len(decoded) < checksumLength {
+		return nil, errors.New("value is shorter than checksum")
+	}
+	return base58Enc.CheckDecode(data)
+}
+
+// Base58Value - decoded base58 value with type detected by its prefix
+type Base58Value struct {
+	Prefix   string
+	DataType string
+	Data     []byte
+}
+
+// ParseBase58 - decodes base58 string and detects type of value by prefix and length
+func ParseBase58(data string) (Base58Value, error) {
+	decoded, err := checkDecode(data)
+	if err != nil {
+		return Base58Value{}, err
+	}
 	enc, err := getBase58EncodingForDecode(decoded)
 	if err != nil {
-		return nil, err
+		return Base58Value{}, err
 	}
 
-	return decoded[len(enc.DecodedPrefix):], nil
+	return Base58Value{
+		Prefix:   string(enc.EncodedPrefix),
+		DataType: enc.DataType,
+		Data:     decoded[len(enc.DecodedPrefix):],
+	}, nil
+}
+
+// DecodeBase58 -
+func DecodeBase58(data string) ([]byte, error) {
+	value, err := ParseBase58(data)
+	if err != nil {
+		return nil, err
+	}
+	return value.Data, nil
 }
 
 // DecodeBase58ToString -
 func DecodeBase58ToString(data string) (string, error) {
-	decoded, err := base58Enc.CheckDecode(data)
+	decoded, err := checkDecode(data)
 	if err != nil {
 		return "", err
 	}
@@ -174,3 +174,70 @@ func TestEncodeBase58String(t *testing.T) {
 		})
 	}
 }
+
+func TestParseBase58(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		prefix   string
+		dataType string
+		wantErr  bool
+	}{
+		{
+			name:     "tz1",
+			data:     "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+			prefix:   PrefixPublicKeyTZ1,
+			dataType: "ed25519 public key hash",
+		}, {
+			name:     "KT1",
+			data:     "KT1AbjG7vtpV8osdoJXcMRck8eTwst8dWoz4",
+			prefix:   PrefixPublicKeyKT1,
+			dataType: "Originated address",
+		}, {
+			name:     "operation hash",
+			data:     "onv6Q1dNejAGEJeQzwRannWsDSGw85FuFdhLnBrY18TBcC9p8kC",
+			prefix:   PrefixOperationHash,
+			dataType: "operation hash",
+		}, {
+			name:     "block hash",
+			data:     "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2",
+			prefix:   PrefixBlockHash,
+			dataType: "block hash",
+		}, {
+			name:     "script expression",
+			data:     "exprtZBwZUeYYYfUs9B9Rg2ywHezVHnCCnmF9WsDQVrs582dSK63dC",
+			prefix:   PrefixScriptExpr,
+			dataType: "script expression",
+		}, {
+			name:    "invalid",
+			data:    "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSy",
+			wantErr: true,
+		}, {
+			name:    "shorter than checksum",
+			data:    "abc",
+			wantErr: true,
+		}, {
+			name:    "empty",
+			data:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBase58(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseBase58() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.Prefix != tt.prefix || got.DataType != tt.dataType {
+				t.Errorf("ParseBase58() = %v %v, want %v %v", got.Prefix, got.DataType, tt.prefix, tt.dataType)
+			}
+		})
+	}
+}
+
+func TestEncodeBase58_LongPrefix(t *testing.T) {
+	if _, err := EncodeBase58(make([]byte, 20), []byte("tz1tz1")); err == nil {
+		t.Error("EncodeBase58() error expected")
+	}
+}
@@ -151,6 +151,16 @@ var primKeywords = []string{
 	"SUB_MUTEZ",
 }
 
+// IsKeyword - returns true if `prim` is Michelson primitive which can be forged
+func IsKeyword(prim string) bool {
+	for i := range primKeywords {
+		if primKeywords[i] == prim {
+			return true
+		}
+	}
+	return false
+}
+
 // first bytes
 const (
 	ByteInt            = 0x00
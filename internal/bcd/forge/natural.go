@@ -0,0 +1,42 @@
+package forge
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// natural - unsigned zarith number used in operation envelopes for fee, counter, limits and amounts
+type natural struct {
+	Value int64
+}
+
+// Unforge -
+func (n *natural) Unforge(data []byte) (int, error) {
+	var value uint64
+	for i := range data {
+		if i > 8 {
+			return i, errors.Errorf("natural.Unforge: number is too big")
+		}
+		value |= uint64(data[i]&0x7f) << (7 * uint(i))
+		if data[i] < 0x80 {
+			n.Value = int64(value)
+			return i + 1, nil
+		}
+	}
+	return len(data), errors.Wrap(ErrTooFewBytes, fmt.Sprintf("natural.Unforge: %x", data))
+}
+
+// Forge -
+func (n *natural) Forge() ([]byte, error) {
+	if n.Value < 0 {
+		return nil, errors.Errorf("natural.Forge: negative value %d", n.Value)
+	}
+	value := uint64(n.Value)
+	data := make([]byte, 0)
+	for value >= 0x80 {
+		data = append(data, byte(value&0x7f)|0x80)
+		value >>= 7
+	}
+	return append(data, byte(value)), nil
+}
@@ -0,0 +1,460 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/bcd/encoding"
+	"github.com/pkg/errors"
+)
+
+// operation tags
+const (
+	OperationTagReveal                 byte = 0x6b
+	OperationTagTransaction            byte = 0x6c
+	OperationTagOrigination            byte = 0x6d
+	OperationTagDelegation             byte = 0x6e
+	OperationTagRegisterGlobalConstant byte = 0x6f
+)
+
+const (
+	branchLength       = 32
+	entrypointTagNamed = 0xff
+)
+
+var operationTags = map[string]byte{
+	consts.Reveal:                 OperationTagReveal,
+	consts.Transaction:            OperationTagTransaction,
+	consts.Origination:            OperationTagOrigination,
+	consts.Delegation:             OperationTagDelegation,
+	consts.RegisterGlobalConstant: OperationTagRegisterGlobalConstant,
+}
+
+var entrypointTags = []string{
+	consts.DefaultEntrypoint,
+	"root",
+	"do",
+	"set_delegate",
+	"remove_delegate",
+}
+
+// OperationGroup - unsigned operation group: branch and contents in node RPC format
+type OperationGroup struct {
+	Branch   string      `json:"branch"`
+	Contents []Operation `json:"contents"`
+}
+
+// Operation - manager operation in node RPC format
+type Operation struct {
+	Kind         string      `json:"kind"`
+	Source       string      `json:"source"`
+	Fee          int64       `json:"fee,string"`
+	Counter      int64       `json:"counter,string"`
+	GasLimit     int64       `json:"gas_limit,string"`
+	StorageLimit int64       `json:"storage_limit,string"`
+	PublicKey    string      `json:"public_key,omitempty"`
+	Amount       int64       `json:"amount,omitempty,string"`
+	Destination  string      `json:"destination,omitempty"`
+	Parameters   *Parameters `json:"parameters,omitempty"`
+	Balance      int64       `json:"balance,omitempty,string"`
+	Delegate     string      `json:"delegate,omitempty"`
+	Script       *Script     `json:"script,omitempty"`
+	Value        *base.Node  `json:"value,omitempty"`
+}
+
+// Parameters - parameters of transaction
+type Parameters struct {
+	Entrypoint string     `json:"entrypoint"`
+	Value      *base.Node `json:"value"`
+}
+
+// Script - script of origination
+type Script struct {
+	Code    *base.Node `json:"code"`
+	Storage *base.Node `json:"storage"`
+}
+
+// Forge -
+func (group *OperationGroup) Forge() ([]byte, error) {
+	branch, err := encoding.DecodeBase58(group.Branch)
+	if err != nil {
+		return nil, errors.Wrap(err, "branch")
+	}
+	if len(branch) != branchLength || group.Branch[:1] != encoding.PrefixBlockHash {
+		return nil, errors.Errorf("Invalid branch: %s", group.Branch)
+	}
+	if len(group.Contents) == 0 {
+		return nil, errors.New("Empty operation contents")
+	}
+
+	data := branch
+	for i := range group.Contents {
+		content, err := group.Contents[i].Forge()
+		if err != nil {
+			return nil, errors.Wrapf(err, "content %d", i)
+		}
+		data = append(data, content...)
+	}
+	return data, nil
+}
+
+// Unforge -
+func (group *OperationGroup) Unforge(data []byte) (int, error) {
+	if len(data) < branchLength {
+		return 0, errors.Wrap(ErrTooFewBytes, fmt.Sprintf("OperationGroup.Unforge: %d < %d", len(data), branchLength))
+	}
+	branch, err := encoding.EncodeBase58(data[:branchLength], []byte(encoding.PrefixBlockHash))
+	if err != nil {
+		return 0, err
+	}
+	group.Branch = branch
+	group.Contents = make([]Operation, 0)
+
+	n := branchLength
+	for n < len(data) {
+		var content Operation
+		size, err := content.Unforge(data[n:])
+		if err != nil {
+			return n, errors.Wrapf(err, "content %d", len(group.Contents))
+		}
+		group.Contents = append(group.Contents, content)
+		n += size
+	}
+	if len(group.Contents) == 0 {
+		return n, errors.New("Empty operation contents")
+	}
+	return n, nil
+}
+
+// Forge -
+func (op *Operation) Forge() ([]byte, error) {
+	tag, ok := operationTags[op.Kind]
+	if !ok {
+		return nil, errors.Errorf("Unsupported operation kind: %s", op.Kind)
+	}
+
+	w := new(operationWriter)
+	w.WriteByte(tag)
+	w.implicitAddress(op.Source)
+	w.natural(op.Fee)
+	w.natural(op.Counter)
+	w.natural(op.GasLimit)
+	w.natural(op.StorageLimit)
+
+	switch op.Kind {
+	case consts.Reveal:
+		w.publicKey(op.PublicKey)
+	case consts.Transaction:
+		w.natural(op.Amount)
+		w.address(op.Destination)
+		w.parameters(op.Parameters)
+	case consts.Origination:
+		w.natural(op.Balance)
+		w.delegate(op.Delegate)
+		if op.Script == nil {
+			return nil, errors.New("Empty origination script")
+		}
+		w.micheline(op.Script.Code)
+		w.micheline(op.Script.Storage)
+	case consts.Delegation:
+		w.delegate(op.Delegate)
+	case consts.RegisterGlobalConstant:
+		w.micheline(op.Value)
+	}
+
+	if w.err != nil {
+		return nil, errors.Wrap(w.err, op.Kind)
+	}
+	return w.Bytes(), nil
+}
+
+// Unforge -
+func (op *Operation) Unforge(data []byte) (int, error) {
+	r := &operationReader{data: data}
+	tag := r.byte()
+	kind, ok := operationKind(tag)
+	if r.err == nil && !ok {
+		return 1, errors.Errorf("Unsupported operation tag: %d", tag)
+	}
+	op.Kind = kind
+	op.Source = r.implicitAddress()
+	op.Fee = r.natural()
+	op.Counter = r.natural()
+	op.GasLimit = r.natural()
+	op.StorageLimit = r.natural()
+
+	switch op.Kind {
+	case consts.Reveal:
+		op.PublicKey = r.publicKey()
+	case consts.Transaction:
+		op.Amount = r.natural()
+		op.Destination = r.address()
+		op.Parameters = r.parameters()
+	case consts.Origination:
+		op.Balance = r.natural()
+		op.Delegate = r.delegate()
+		op.Script = &Script{
+			Code:    r.micheline(),
+			Storage: r.micheline(),
+		}
+	case consts.Delegation:
+		op.Delegate = r.delegate()
+	case consts.RegisterGlobalConstant:
+		op.Value = r.micheline()
+	}
+
+	if r.err != nil {
+		return r.offset, errors.Wrap(r.err, op.Kind)
+	}
+	return r.offset, nil
+}
+
+func operationKind(tag byte) (string, bool) {
+	for kind, value := range operationTags {
+		if value == tag {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// operationWriter - accumulates forged fields of operation and keeps the first error
+type operationWriter struct {
+	bytes.Buffer
+	err error
+}
+
+func (w *operationWriter) write(data []byte, err error) {
+	if w.err != nil {
+		return
+	}
+	if err != nil {
+		w.err = err
+		return
+	}
+	w.Write(data)
+}
+
+func (w *operationWriter) natural(value int64) {
+	w.write((&natural{value}).Forge())
+}
+
+func (w *operationWriter) bool(value bool) {
+	if value {
+		w.write([]byte{0xff}, nil)
+	} else {
+		w.write([]byte{0x00}, nil)
+	}
+}
+
+func (w *operationWriter) implicitAddress(value string) {
+	if len(value) < 3 || value[:2] != "tz" {
+		w.write(nil, errors.Wrapf(consts.ErrInvalidAddress, "implicit address expected: %s", value))
+		return
+	}
+	w.write(Address(value, true))
+}
+
+func (w *operationWriter) address(value string) {
+	if len(value) < 3 {
+		w.write(nil, errors.Wrap(consts.ErrInvalidAddress, value))
+		return
+	}
+	w.write(Address(value, false))
+}
+
+func (w *operationWriter) publicKey(value string) {
+	w.write(PublicKey(value))
+}
+
+func (w *operationWriter) delegate(value string) {
+	w.bool(value != "")
+	if value != "" {
+		w.implicitAddress(value)
+	}
+}
+
+func (w *operationWriter) micheline(node *base.Node) {
+	if node == nil {
+		w.write(nil, errors.New("empty Micheline expression"))
+		return
+	}
+	data, err := Forge(node)
+	if err != nil {
+		w.write(nil, err)
+		return
+	}
+	w.write((&length{len(data)}).Forge())
+	w.write(data, nil)
+}
+
+func (w *operationWriter) parameters(params *Parameters) {
+	if params == nil {
+		w.bool(false)
+		return
+	}
+	w.bool(true)
+
+	entrypoint := params.Entrypoint
+	if entrypoint == "" {
+		entrypoint = consts.DefaultEntrypoint
+	}
+	tag := entrypointTagNamed
+	for i := range entrypointTags {
+		if entrypointTags[i] == entrypoint {
+			tag = i
+			break
+		}
+	}
+	w.write([]byte{byte(tag)}, nil)
+	if tag == entrypointTagNamed {
+		if len(entrypoint) > 31 {
+			w.write(nil, errors.Errorf("entrypoint name is too long: %s", entrypoint))
+			return
+		}
+		w.write([]byte{byte(len(entrypoint))}, nil)
+		w.write([]byte(entrypoint), nil)
+	}
+	w.micheline(params.Value)
+}
+
+// operationReader - reads fields of forged operation and keeps the first error
+type operationReader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func (r *operationReader) next(count int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data)-r.offset < count {
+		r.err = errors.Wrap(ErrTooFewBytes, fmt.Sprintf("operation: %d < %d", len(r.data)-r.offset, count))
+		return nil
+	}
+	data := r.data[r.offset : r.offset+count]
+	r.offset += count
+	return data
+}
+
+func (r *operationReader) byte() byte {
+	data := r.next(1)
+	if data == nil {
+		return 0
+	}
+	return data[0]
+}
+
+func (r *operationReader) unforge(unforger Unforger) {
+	if r.err != nil {
+		return
+	}
+	n, err := unforger.Unforge(r.data[r.offset:])
+	r.offset += n
+	r.err = err
+}
+
+func (r *operationReader) natural() int64 {
+	value := new(natural)
+	r.unforge(value)
+	return value.Value
+}
+
+func (r *operationReader) bool() bool {
+	switch value := r.byte(); value {
+	case 0x00:
+		return false
+	case 0xff:
+		return true
+	default:
+		if r.err == nil {
+			r.err = errors.Errorf("invalid boolean byte: %d", value)
+		}
+		return false
+	}
+}
+
+func (r *operationReader) encoded(count int, decoder func(string) (string, error)) string {
+	data := r.next(count)
+	if data == nil {
+		return ""
+	}
+	value, err := decoder(hex.EncodeToString(data))
+	if err != nil {
+		r.err = err
+	}
+	return value
+}
+
+func (r *operationReader) implicitAddress() string {
+	return r.encoded(21, UnforgeAddress)
+}
+
+func (r *operationReader) address() string {
+	return r.encoded(22, UnforgeAddress)
+}
+
+func (r *operationReader) publicKey() string {
+	if r.err != nil || r.offset >= len(r.data) {
+		r.next(1)
+		return ""
+	}
+	size := 33
+	if r.data[r.offset] == 0 {
+		size = 32
+	}
+	return r.encoded(size+1, UnforgePublicKey)
+}
+
+func (r *operationReader) delegate() string {
+	if !r.bool() {
+		return ""
+	}
+	return r.implicitAddress()
+}
+
+func (r *operationReader) micheline() *base.Node {
+	l := new(length)
+	r.unforge(l)
+	data := r.next(l.Value)
+	if data == nil {
+		return nil
+	}
+	unforger := NewMichelson()
+	n, err := unforger.Unforge(data)
+	switch {
+	case err != nil:
+		r.err = err
+		return nil
+	case n != len(data) || len(unforger.Nodes) != 1:
+		r.err = errors.Errorf("invalid Micheline expression: %x", data)
+		return nil
+	}
+	return unforger.Nodes[0]
+}
+
+func (r *operationReader) parameters() *Parameters {
+	if !r.bool() {
+		return nil
+	}
+	params := new(Parameters)
+	tag := int(r.byte())
+	switch {
+	case r.err != nil:
+		return nil
+	case tag < len(entrypointTags):
+		params.Entrypoint = entrypointTags[tag]
+	case tag == entrypointTagNamed:
+		size := int(r.byte())
+		params.Entrypoint = string(r.next(size))
+	default:
+		r.err = errors.Errorf("unknown entrypoint tag: %d", tag)
+		return nil
+	}
+	params.Value = r.micheline()
+	return params
+}
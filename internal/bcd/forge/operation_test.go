@@ -0,0 +1,233 @@
+package forge
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/base"
+	"github.com/baking-bad/bcdhub/internal/bcd/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBranch      = "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2"
+	testBranchBytes = "8fcf233671b6a04fcf679d2a381c2544ea6c1ea29ba6157776ed8424c7ccd00b"
+)
+
+func TestNatural(t *testing.T) {
+	tests := []struct {
+		name  string
+		value int64
+		data  string
+	}{
+		{
+			name:  "zero",
+			value: 0,
+			data:  "00",
+		}, {
+			name:  "small",
+			value: 127,
+			data:  "7f",
+		}, {
+			name:  "fee",
+			value: 1000,
+			data:  "e807",
+		}, {
+			name:  "1 tez",
+			value: 1000000,
+			data:  "c0843d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := (&natural{tt.value}).Forge()
+			require.NoError(t, err)
+			assert.Equal(t, tt.data, hex.EncodeToString(data))
+
+			decoded, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+			n := new(natural)
+			size, err := n.Unforge(decoded)
+			require.NoError(t, err)
+			assert.Equal(t, len(decoded), size)
+			assert.Equal(t, tt.value, n.Value)
+		})
+	}
+}
+
+func TestOperationGroup_Forge(t *testing.T) {
+	group := OperationGroup{
+		Branch: testBranch,
+		Contents: []Operation{
+			{
+				Kind:         "transaction",
+				Source:       "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+				Fee:          1000,
+				Counter:      1,
+				GasLimit:     10300,
+				StorageLimit: 0,
+				Amount:       1000000,
+				Destination:  "KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH",
+				Parameters: &Parameters{
+					Entrypoint: "transfer",
+					Value:      &base.Node{IntValue: types.NewBigInt(0)},
+				},
+			},
+		},
+	}
+	want := testBranchBytes +
+		"6c" +
+		"0002298c03ed7d454a101eb7022bc95f7e5f41ac78" +
+		"e807" + "01" + "bc50" + "00" + "c0843d" +
+		"01e5fec2566787cf3a1f1e0928ec9f44b052a8672800" +
+		"ff" + "ff08" + hex.EncodeToString([]byte("transfer")) + "00000002" + "0000"
+
+	data, err := group.Forge()
+	require.NoError(t, err)
+	assert.Equal(t, want, hex.EncodeToString(data))
+}
+
+func TestOperationGroup_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content Operation
+	}{
+		{
+			name: "reveal",
+			content: Operation{
+				Kind:         "reveal",
+				Source:       "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+				Fee:          1257,
+				Counter:      1,
+				GasLimit:     1000,
+				StorageLimit: 0,
+				PublicKey:    "edpktxGsKjnk43ZZ7v6gJe6PFV85peHvoWqVUzDQjTfN8idYwVkBwN",
+			},
+		}, {
+			name: "transaction without parameters",
+			content: Operation{
+				Kind:         "transaction",
+				Source:       "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+				Fee:          1420,
+				Counter:      2,
+				GasLimit:     1527,
+				StorageLimit: 257,
+				Amount:       1000000,
+				Destination:  "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+			},
+		}, {
+			name: "transaction with default entrypoint",
+			content: Operation{
+				Kind:        "transaction",
+				Source:      "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+				Counter:     3,
+				Destination: "KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH",
+				Parameters: &Parameters{
+					Entrypoint: "default",
+					Value:      &base.Node{Prim: "Unit"},
+				},
+			},
+		}, {
+			name: "origination",
+			content: Operation{
+				Kind:         "origination",
+				Source:       "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+				Fee:          2000,
+				Counter:      4,
+				GasLimit:     2000,
+				StorageLimit: 500,
+				Balance:      10,
+				Delegate:     "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+				Script: &Script{
+					Code: &base.Node{
+						Prim: "_array",
+						Args: []*base.Node{
+							{Prim: "parameter", Args: []*base.Node{{Prim: "unit"}}},
+							{Prim: "storage", Args: []*base.Node{{Prim: "unit"}}},
+							{Prim: "code", Args: []*base.Node{{
+								Prim: "_array",
+								Args: []*base.Node{
+									{Prim: "CDR"},
+									{Prim: "NIL", Args: []*base.Node{{Prim: "operation"}}},
+									{Prim: "PAIR"},
+								},
+							}}},
+						},
+					},
+					Storage: &base.Node{Prim: "Unit"},
+				},
+			},
+		}, {
+			name: "delegation",
+			content: Operation{
+				Kind:     "delegation",
+				Source:   "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+				Counter:  5,
+				Delegate: "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+			},
+		}, {
+			name: "withdraw delegate",
+			content: Operation{
+				Kind:    "delegation",
+				Source:  "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+				Counter: 6,
+			},
+		}, {
+			name: "register global constant",
+			content: Operation{
+				Kind:    "register_global_constant",
+				Source:  "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+				Counter: 7,
+				Value:   &base.Node{Prim: "nat", Annots: []string{"%amount"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := OperationGroup{
+				Branch:   testBranch,
+				Contents: []Operation{tt.content},
+			}
+			data, err := group.Forge()
+			require.NoError(t, err)
+
+			var got OperationGroup
+			n, err := got.Unforge(data)
+			require.NoError(t, err)
+			assert.Equal(t, len(data), n)
+			assert.Equal(t, group, got)
+		})
+	}
+}
+
+func TestOperationGroup_Unforge_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "too short branch",
+			data: "8fcf2336",
+		}, {
+			name: "empty contents",
+			data: testBranchBytes,
+		}, {
+			name: "unknown tag",
+			data: testBranchBytes + "01",
+		}, {
+			name: "truncated content",
+			data: testBranchBytes + "6c0002298c03ed7d454a101eb7022bc95f7e5f41ac78e807",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+
+			var group OperationGroup
+			_, err = group.Unforge(data)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package bcd
 
 import (
+	"sync"
+
 	"github.com/pkg/errors"
 )
 
@@ -34,14 +36,98 @@ var symLinks = map[string]string{
 	"PsiThaCaT47Zboaw71QWScM8sXeMM7bbQFncK9FLqYc6EKdpjVP": SymLinkBabylon, // Itacanet
 }
 
+// Genesis protocols. Networks start from one of them and activate the first real protocol after it.
+var genesisProtocols = map[string]struct{}{
+	"ProtoGenesisGenesisGenesisGenesisGenesisGenesk612im": {},
+	"PrihK96nBAFSxVL1GLJTVhu9YnzkMFiBeuJRPA8NwuZVZCE1L6i": {},
+}
+
+// Symlinks saved in the `protocols` table: protocols which are not in the list above and were detected by predecessor
+// or symlinks overridden manually. They are loaded on start and take precedence over the list above.
+var (
+	registeredSymLinks   = make(map[string]string)
+	registeredSymLinksMx sync.RWMutex
+
+	symLinkLoader ProtoSymLinkLoader
+)
+
+// ProtoSymLinkLoader - returns symlink of protocol saved outside of the process, e.g. in `protocols` table by indexer
+type ProtoSymLinkLoader func(protocol string) (string, error)
+
+// SetProtoSymLinkLoader - sets loader which is asked for symlinks of protocols missed in the registry.
+// Processes which don't index blocks learn about protocols accepted after their start by it.
+func SetProtoSymLinkLoader(loader ProtoSymLinkLoader) {
+	registeredSymLinksMx.Lock()
+	symLinkLoader = loader
+	registeredSymLinksMx.Unlock()
+}
+
 // GetProtoSymLink -
 func GetProtoSymLink(protocol string) (string, error) {
+	registeredSymLinksMx.RLock()
+	protoSymLink, ok := registeredSymLinks[protocol]
+	loader := symLinkLoader
+	registeredSymLinksMx.RUnlock()
+	if ok {
+		return protoSymLink, nil
+	}
+
 	if protoSymLink, ok := symLinks[protocol]; ok {
 		return protoSymLink, nil
 	}
+
+	if loader != nil {
+		protoSymLink, err := loader(protocol)
+		if err == nil && protoSymLink != "" {
+			if err := RegisterProtoSymLink(protocol, protoSymLink); err != nil {
+				return "", err
+			}
+			return protoSymLink, nil
+		}
+	}
 	return "", errors.Errorf("Unknown protocol: %s", protocol)
 }
 
+// RegisterProtoSymLink - sets symlink for protocol which is not in the list of supported protocols
+func RegisterProtoSymLink(protocol, symLink string) error {
+	if !IsValidSymLink(symLink) {
+		return errors.Errorf("Unknown symlink %s of protocol %s", symLink, protocol)
+	}
+
+	registeredSymLinksMx.Lock()
+	registeredSymLinks[protocol] = symLink
+	registeredSymLinksMx.Unlock()
+	return nil
+}
+
+// DetectProtoSymLink - returns symlink of unknown protocol by its predecessor.
+// Every protocol since Babylon keeps its Michelson and binary encoding lineage, so successors of Babylon's lineage and of genesis are handled by the same symlink.
+// Successors of other protocols can't be detected and have to be added to the list.
+func DetectProtoSymLink(predecessor string) (string, error) {
+	if _, ok := genesisProtocols[predecessor]; ok {
+		return SymLinkBabylon, nil
+	}
+
+	symLink, err := GetProtoSymLink(predecessor)
+	if err != nil {
+		return "", err
+	}
+	if symLink != SymLinkBabylon {
+		return "", errors.Errorf("Can't detect symlink of successor of %s protocol", predecessor)
+	}
+	return symLink, nil
+}
+
+// IsValidSymLink -
+func IsValidSymLink(symLink string) bool {
+	switch symLink {
+	case SymLinkAlpha, SymLinkBabylon:
+		return true
+	default:
+		return false
+	}
+}
+
 // GetCurrentProtocol - returns last supported protocol
 func GetCurrentProtocol() string {
 	return "PtGRANADsDU8R9daYKAgWnQYAJ64omN1o3KMGVCykShA97vQbvV"
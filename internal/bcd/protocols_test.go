@@ -0,0 +1,117 @@
+package bcd
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectProtoSymLink(t *testing.T) {
+	tests := []struct {
+		name        string
+		predecessor string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:        "successor of babylon lineage",
+			predecessor: "PsiThaCaT47Zboaw71QWScM8sXeMM7bbQFncK9FLqYc6EKdpjVP",
+			want:        SymLinkBabylon,
+		}, {
+			name:        "successor of genesis",
+			predecessor: "ProtoGenesisGenesisGenesisGenesisGenesisGenesk612im",
+			want:        SymLinkBabylon,
+		}, {
+			name:        "successor of alpha lineage",
+			predecessor: "PsddFKi32cMJ2qPjf43Qv5GDWLDPZb3T3bF6fLKiF5HtvHNU7aP",
+			wantErr:     true,
+		}, {
+			name:        "unknown predecessor",
+			predecessor: "PtUnknownProtocol",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectProtoSymLink(tt.predecessor)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegisterProtoSymLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		symLink  string
+		wantErr  bool
+	}{
+		{
+			name:     "new protocol",
+			protocol: "PtNewProtocol",
+			symLink:  SymLinkBabylon,
+		}, {
+			name:     "override",
+			protocol: "PtYuensgYBb3G3x1hLLbCmcav8ue8Kyd2khADcL5LsT5R1hcXex",
+			symLink:  SymLinkBabylon,
+		}, {
+			name:     "invalid symlink",
+			protocol: "PtNewProtocol2",
+			symLink:  "carthage",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterProtoSymLink(tt.protocol, tt.symLink)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			got, err := GetProtoSymLink(tt.protocol)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.symLink, got)
+		})
+	}
+}
+
+func TestGetProtoSymLink_Loader(t *testing.T) {
+	const (
+		accepted = "PtAcceptedAfterStart"
+		unknown  = "PtUnknownProtocol"
+	)
+
+	calls := make(map[string]int)
+	SetProtoSymLinkLoader(func(protocol string) (string, error) {
+		calls[protocol]++
+		if protocol == accepted {
+			return SymLinkBabylon, nil
+		}
+		return "", errors.New("not found")
+	})
+	defer SetProtoSymLinkLoader(nil)
+
+	for i := 0; i < 2; i++ {
+		got, err := GetProtoSymLink(accepted)
+		require.NoError(t, err)
+		assert.Equal(t, SymLinkBabylon, got)
+	}
+	assert.Equal(t, 1, calls[accepted], "loaded symlink has to be registered")
+
+	_, err := GetProtoSymLink(unknown)
+	assert.Error(t, err)
+
+	got, err := GetProtoSymLink("PsiThaCaT47Zboaw71QWScM8sXeMM7bbQFncK9FLqYc6EKdpjVP")
+	require.NoError(t, err)
+	assert.Equal(t, SymLinkBabylon, got)
+	assert.Len(t, calls, 2, "loader isn't asked for supported protocols")
+}
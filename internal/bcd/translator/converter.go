@@ -60,7 +60,7 @@ func (c Converter) FromString(input string) (string, error) {
 
 	ast, err := c.parser.ParseAndGetAst(input, nil)
 	if err != nil {
-		return "", err
+		return "", fromParserError(err)
 	}
 
 	return NewJSONTranslator().Translate(ast)
@@ -44,3 +44,98 @@ func TestConverter_FromFile(t *testing.T) {
 		})
 	}
 }
+
+func TestConverter_FromString_Macros(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "CDAR with annotation",
+			input: "CDAR @x",
+			want:  `[[{"prim":"CDR"},{"prim":"CAR","annots":["@x"]}]]`,
+		}, {
+			name:  "DIIIP",
+			input: "DIIIP { DROP }",
+			want:  `[{"prim":"DIP","args":[{"int":"3"},[{"prim":"DROP"}]]}]`,
+		}, {
+			name:  "primitives are not expanded",
+			input: "{ CAR ; DUP ; DIP { DROP } ; PAIR ; UNPAIR }",
+			want:  `[[{"prim":"CAR"},{"prim":"DUP"},{"prim":"DIP","args":[[{"prim":"DROP"}]]},{"prim":"PAIR"},{"prim":"UNPAIR"}]]`,
+		}, {
+			name:    "FAIL with argument",
+			input:   "FAIL 1",
+			wantErr: true,
+		}, {
+			name:    "DIIP without sequence",
+			input:   "DIIP 1",
+			wantErr: true,
+		}, {
+			name:    "ASSERT with annotation",
+			input:   "ASSERT @a",
+			wantErr: true,
+		}, {
+			name:    "invalid pair macro",
+			input:   "PAPAR",
+			wantErr: true,
+		}, {
+			name:    "unknown primitive",
+			input:   "CADDY",
+			wantErr: true,
+		},
+	}
+
+	c, err := NewConverter()
+	if err != nil {
+		t.Errorf("Converter.NewConverter() error = %v", err)
+		return
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.FromString(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Converter.FromString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				assert.JSONEq(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConverter_FromString_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  *Error
+	}{
+		{
+			name:  "unknown primitive",
+			input: "{ DUP ;\n  FOO }",
+			want:  &Error{Line: 2, Column: 3, Message: "Invalid primitive FOO"},
+		}, {
+			name:  "macro arguments",
+			input: "{ DUP ;\n  CMPEQ 1 }",
+			want:  &Error{Line: 2, Column: 3, Message: "Wrong number of arguments to macro CMPEQ: expected 0, got 1"},
+		}, {
+			name:  "syntax error",
+			input: "{ DUP ;\n (",
+			want:  &Error{Line: 2, Column: 2, Message: "syntax error"},
+		},
+	}
+
+	c, err := NewConverter()
+	if err != nil {
+		t.Errorf("NewConverter error %v", err)
+		return
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.FromString(tt.input)
+			assert.Equal(t, tt.want, err)
+		})
+	}
+}
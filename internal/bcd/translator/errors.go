@@ -0,0 +1,43 @@
+package translator
+
+import (
+	"fmt"
+
+	"github.com/yhirose/go-peg"
+)
+
+// Error - error of Michelson translation with position in the source text
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error -
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d %s", e.Line, e.Column, e.Message)
+}
+
+func newError(ast *peg.Ast, err error) error {
+	if _, ok := err.(*Error); ok {
+		return err
+	}
+	return &Error{
+		Line:    ast.Ln,
+		Column:  ast.Col,
+		Message: err.Error(),
+	}
+}
+
+func fromParserError(err error) error {
+	pegErr, ok := err.(*peg.Error)
+	if !ok || len(pegErr.Details) == 0 {
+		return err
+	}
+	detail := pegErr.Details[0]
+	return &Error{
+		Line:    detail.Ln,
+		Column:  detail.Col,
+		Message: detail.Msg,
+	}
+}
@@ -0,0 +1,505 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Macros are expanded the same way as `tezos-client` does it: most of them are turned into sequence of primitives,
+// `DII+P` and `DUU+P` are turned into `DIP n` and `DUP n`.
+
+var (
+	cadrRegexp    = regexp.MustCompile(`^C[AD]{2,}R$`)
+	setCadrRegexp = regexp.MustCompile(`^SET_C[AD]+R$`)
+	mapCadrRegexp = regexp.MustCompile(`^MAP_C[AD]+R$`)
+	diipRegexp    = regexp.MustCompile(`^DI{2,}P$`)
+	duupRegexp    = regexp.MustCompile(`^DU{2,}P$`)
+	pairRegexp    = regexp.MustCompile(`^P[PAI]{3,}R$`)
+	unpairRegexp  = regexp.MustCompile(`^UNP[PAI]{3,}R$`)
+)
+
+var comparisons = map[string]struct{}{
+	"EQ":  {},
+	"NEQ": {},
+	"LT":  {},
+	"GT":  {},
+	"LE":  {},
+	"GE":  {},
+}
+
+var (
+	failSeq    = seqJSON(primJSON("UNIT", nil), primJSON("FAILWITH", nil))
+	failBranch = seqJSON(failSeq)
+)
+
+// isMacro -
+func isMacro(prim string) bool {
+	switch prim {
+	case "FAIL", "IF_SOME", "IF_RIGHT":
+		return true
+	}
+	if strings.HasPrefix(prim, "ASSERT") {
+		return true
+	}
+	if op, ok := trimPrefix(prim, "IFCMP"); ok {
+		return isComparison(op)
+	}
+	if op, ok := trimPrefix(prim, "CMP"); ok {
+		return isComparison(op)
+	}
+	if op, ok := trimPrefix(prim, "IF"); ok && isComparison(op) {
+		return true
+	}
+	for _, re := range []*regexp.Regexp{
+		cadrRegexp, setCadrRegexp, mapCadrRegexp, diipRegexp, duupRegexp, pairRegexp, unpairRegexp,
+	} {
+		if re.MatchString(prim) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandMacro - expands macro to Micheline. `args` are already translated arguments.
+func expandMacro(prim string, annots, args []string) (string, error) {
+	switch prim {
+	case "FAIL":
+		if err := checkMacro(prim, annots, args, 0, false); err != nil {
+			return "", err
+		}
+		return failSeq, nil
+	case "IF_SOME":
+		if err := checkMacro(prim, nil, args, 2, true); err != nil {
+			return "", err
+		}
+		return seqJSON(primJSON("IF_NONE", annots, args[1], args[0])), nil
+	case "IF_RIGHT":
+		if err := checkMacro(prim, nil, args, 2, true); err != nil {
+			return "", err
+		}
+		return seqJSON(primJSON("IF_LEFT", annots, args[1], args[0])), nil
+	}
+
+	if strings.HasPrefix(prim, "ASSERT") {
+		return expandAssert(prim, annots, args)
+	}
+	if op, ok := trimPrefix(prim, "IFCMP"); ok {
+		if err := checkMacro(prim, nil, args, 2, true); err != nil {
+			return "", err
+		}
+		return seqJSON(primJSON("COMPARE", nil), primJSON(op, nil), primJSON("IF", annots, args...)), nil
+	}
+	if op, ok := trimPrefix(prim, "CMP"); ok {
+		if err := checkMacro(prim, nil, args, 0, false); err != nil {
+			return "", err
+		}
+		return seqJSON(primJSON("COMPARE", nil), primJSON(op, annots)), nil
+	}
+	if op, ok := trimPrefix(prim, "IF"); ok && isComparison(op) {
+		if err := checkMacro(prim, nil, args, 2, true); err != nil {
+			return "", err
+		}
+		return seqJSON(primJSON(op, nil), primJSON("IF", annots, args...)), nil
+	}
+
+	switch {
+	case cadrRegexp.MatchString(prim):
+		if err := checkMacro(prim, nil, args, 0, false); err != nil {
+			return "", err
+		}
+		path := prim[1 : len(prim)-1]
+		items := make([]string, len(path))
+		for i := range path {
+			var itemAnnots []string
+			if i == len(path)-1 {
+				itemAnnots = annots
+			}
+			items[i] = primJSON(cadrPrim(path[i]), itemAnnots)
+		}
+		return seqJSON(items...), nil
+	case setCadrRegexp.MatchString(prim):
+		if err := checkMacro(prim, nil, args, 0, false); err != nil {
+			return "", err
+		}
+		return expandSetCadr(prim, annots)
+	case mapCadrRegexp.MatchString(prim):
+		if err := checkMacro(prim, nil, args, 1, true); err != nil {
+			return "", err
+		}
+		return expandMapCadr(prim, annots, args[0])
+	case diipRegexp.MatchString(prim):
+		if err := checkMacro(prim, nil, args, 1, true); err != nil {
+			return "", err
+		}
+		return primJSON("DIP", annots, intJSON(len(prim)-2), args[0]), nil
+	case duupRegexp.MatchString(prim):
+		if err := checkMacro(prim, nil, args, 0, false); err != nil {
+			return "", err
+		}
+		return primJSON("DUP", annots, intJSON(len(prim)-2)), nil
+	case pairRegexp.MatchString(prim):
+		if err := checkMacro(prim, nil, args, 0, false); err != nil {
+			return "", err
+		}
+		return expandPair(prim, annots)
+	case unpairRegexp.MatchString(prim):
+		if err := checkMacro(prim, nil, args, 0, false); err != nil {
+			return "", err
+		}
+		return expandUnpair(prim, annots)
+	}
+	return "", errors.Errorf("Unknown macro %s", prim)
+}
+
+func expandAssert(prim string, annots, args []string) (string, error) {
+	if err := checkMacro(prim, nil, args, 0, false); err != nil {
+		return "", err
+	}
+
+	switch prim {
+	case "ASSERT", "ASSERT_NONE":
+		if len(annots) > 0 {
+			return "", errors.Errorf("Unexpected annotation on macro %s", prim)
+		}
+		instr := "IF"
+		if prim == "ASSERT_NONE" {
+			instr = "IF_NONE"
+		}
+		return seqJSON(primJSON(instr, nil, "[]", failBranch)), nil
+	case "ASSERT_SOME":
+		return seqJSON(primJSON("IF_NONE", nil, failBranch, renameJSON(annots))), nil
+	case "ASSERT_LEFT":
+		return seqJSON(primJSON("IF_LEFT", nil, renameJSON(annots), failBranch)), nil
+	case "ASSERT_RIGHT":
+		return seqJSON(primJSON("IF_LEFT", nil, failBranch, renameJSON(annots))), nil
+	}
+
+	if len(annots) > 0 {
+		return "", errors.Errorf("Unexpected annotation on macro %s", prim)
+	}
+	if op, ok := trimPrefix(prim, "ASSERT_CMP"); ok && isComparison(op) {
+		return seqJSON(
+			seqJSON(primJSON("COMPARE", nil), primJSON(op, nil)),
+			primJSON("IF", nil, "[]", failBranch),
+		), nil
+	}
+	if op, ok := trimPrefix(prim, "ASSERT_"); ok && isComparison(op) {
+		return seqJSON(primJSON(op, nil), primJSON("IF", nil, "[]", failBranch)), nil
+	}
+	return "", errors.Errorf("Unknown macro %s", prim)
+}
+
+// expandSetCadr - SET_CAR => { CDR @%% ; SWAP ; PAIR % %@ }, SET_CDR => { CAR @%% ; PAIR %@ % }
+func expandSetCadr(prim string, annots []string) (string, error) {
+	field, err := fieldAnnot(prim, annots)
+	if err != nil {
+		return "", err
+	}
+	path := prim[len("SET_C") : len(prim)-1]
+	last := path[len(path)-1]
+
+	items := make([]string, 0)
+	if field != "" {
+		items = append(items, primJSON("DUP", nil), primJSON(cadrPrim(last), []string{field}), primJSON("DROP", nil))
+	}
+	pairAnnot := field
+	if pairAnnot == "" {
+		pairAnnot = "%"
+	}
+	if last == 'A' {
+		items = append(items,
+			primJSON("CDR", []string{"@%%"}),
+			primJSON("SWAP", nil),
+			primJSON("PAIR", []string{pairAnnot, "%@"}),
+		)
+	} else {
+		items = append(items,
+			primJSON("CAR", []string{"@%%"}),
+			primJSON("PAIR", []string{"%@", pairAnnot}),
+		)
+	}
+
+	return wrapCadrAccess(path[:len(path)-1], seqJSON(items...)), nil
+}
+
+// expandMapCadr - MAP_CAR code => { DUP ; CDR @%% ; DIP { CAR ; code } ; SWAP ; PAIR % %@ }, MAP_CDR code => { DUP ; CDR ; code ; SWAP ; CAR @%% ; PAIR %@ % }
+func expandMapCadr(prim string, annots []string, code string) (string, error) {
+	field, err := fieldAnnot(prim, annots)
+	if err != nil {
+		return "", err
+	}
+	path := prim[len("MAP_C") : len(prim)-1]
+	last := path[len(path)-1]
+
+	var accessAnnots []string
+	pairAnnot := "%"
+	if field != "" {
+		accessAnnots = []string{"@" + field[1:]}
+		pairAnnot = field
+	}
+
+	var init string
+	if last == 'A' {
+		init = seqJSON(
+			primJSON("DUP", nil),
+			primJSON("CDR", []string{"@%%"}),
+			primJSON("DIP", nil, seqJSON(primJSON("CAR", accessAnnots), code)),
+			primJSON("SWAP", nil),
+			primJSON("PAIR", []string{pairAnnot, "%@"}),
+		)
+	} else {
+		init = seqJSON(
+			primJSON("DUP", nil),
+			primJSON("CDR", accessAnnots),
+			code,
+			primJSON("SWAP", nil),
+			primJSON("CAR", []string{"@%%"}),
+			primJSON("PAIR", []string{"%@", pairAnnot}),
+		)
+	}
+	return wrapCadrAccess(path[:len(path)-1], init), nil
+}
+
+// wrapCadrAccess - wraps code applied to nested pair into access by `path`
+func wrapCadrAccess(path string, code string) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == 'A' {
+			code = seqJSON(
+				primJSON("DUP", nil),
+				primJSON("DIP", nil, seqJSON(primJSON("CAR", []string{"@%%"}), code)),
+				primJSON("CDR", []string{"@%%"}),
+				primJSON("SWAP", nil),
+				primJSON("PAIR", []string{"%@", "%@"}),
+			)
+		} else {
+			code = seqJSON(
+				primJSON("DUP", nil),
+				primJSON("DIP", nil, seqJSON(primJSON("CDR", []string{"@%%"}), code)),
+				primJSON("CAR", []string{"@%%"}),
+				primJSON("PAIR", []string{"%@", "%@"}),
+			)
+		}
+	}
+	return code
+}
+
+// pairNode - node of tree described by `PAPPAIIR`-like macro. Leaves are nil.
+type pairNode struct {
+	left  *pairNode
+	right *pairNode
+
+	leftAnnot  string
+	rightAnnot string
+}
+
+func parsePairTree(prim, body string) (*pairNode, error) {
+	var pos int
+	var parse func(left bool) (*pairNode, error)
+	parse = func(left bool) (*pairNode, error) {
+		if pos >= len(body) {
+			return nil, errors.Errorf("Invalid macro %s", prim)
+		}
+		c := body[pos]
+		pos++
+		switch {
+		case c == 'P':
+			l, err := parse(true)
+			if err != nil {
+				return nil, err
+			}
+			r, err := parse(false)
+			if err != nil {
+				return nil, err
+			}
+			return &pairNode{left: l, right: r}, nil
+		case c == 'A' && left, c == 'I' && !left:
+			return nil, nil
+		default:
+			return nil, errors.Errorf("Invalid macro %s", prim)
+		}
+	}
+
+	root, err := parse(false)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil || pos != len(body) {
+		return nil, errors.Errorf("Invalid macro %s", prim)
+	}
+	return root, nil
+}
+
+// setLeafAnnots - distributes annotations between leaves of tree from left to right. Returns unused annotations.
+func (node *pairNode) setLeafAnnots(annots []string) []string {
+	if node.left != nil {
+		annots = node.left.setLeafAnnots(annots)
+	} else if len(annots) > 0 {
+		node.leftAnnot, annots = annots[0], annots[1:]
+	}
+	if node.right != nil {
+		annots = node.right.setLeafAnnots(annots)
+	} else if len(annots) > 0 {
+		node.rightAnnot, annots = annots[0], annots[1:]
+	}
+	return annots
+}
+
+func (node *pairNode) annots(empty string) []string {
+	switch {
+	case node.leftAnnot == "" && node.rightAnnot == "":
+		return nil
+	case node.rightAnnot == "":
+		return []string{node.leftAnnot}
+	case node.leftAnnot == "":
+		return []string{empty, node.rightAnnot}
+	default:
+		return []string{node.leftAnnot, node.rightAnnot}
+	}
+}
+
+// expandPair - PA(\right)R => DIP ((\right)R) ; PAIR, P(\left)IR => (\left)R ; PAIR, P(\left)(\right)R => (\left)R ; DIP ((\right)R) ; PAIR
+func expandPair(prim string, annots []string) (string, error) {
+	root, err := parsePairTree(prim, prim[:len(prim)-1])
+	if err != nil {
+		return "", err
+	}
+	fields, other := splitFieldAnnots(annots)
+	if len(root.setLeafAnnots(fields)) > 0 {
+		return "", errors.Errorf("Too many field annotations on macro %s", prim)
+	}
+
+	var expand func(*pairNode, bool) []string
+	expand = func(node *pairNode, isRoot bool) []string {
+		items := make([]string, 0)
+		if node.left != nil {
+			items = append(items, expand(node.left, false)...)
+		}
+		if node.right != nil {
+			items = append(items, primJSON("DIP", nil, seqJSON(expand(node.right, false)...)))
+		}
+		pairAnnots := node.annots("%")
+		if isRoot {
+			pairAnnots = append(pairAnnots, other...)
+		}
+		return append(items, primJSON("PAIR", pairAnnots))
+	}
+	return seqJSON(expand(root, true)...), nil
+}
+
+// expandUnpair - UNPA(\right)R => UNPAIR ; DIP (UN(\right)R), UNP(\left)IR => UNPAIR ; UN(\left)R, UNP(\left)(\right)R => UNPAIR ; DIP (UN(\right)R) ; UN(\left)R
+func expandUnpair(prim string, annots []string) (string, error) {
+	root, err := parsePairTree(prim, prim[2:len(prim)-1])
+	if err != nil {
+		return "", err
+	}
+	if len(root.setLeafAnnots(annots)) > 0 {
+		return "", errors.Errorf("Too many annotations on macro %s", prim)
+	}
+
+	var expand func(*pairNode) []string
+	expand = func(node *pairNode) []string {
+		items := []string{primJSON("UNPAIR", node.annots("@"))}
+		if node.right != nil {
+			items = append(items, primJSON("DIP", nil, seqJSON(expand(node.right)...)))
+		}
+		if node.left != nil {
+			items = append(items, expand(node.left)...)
+		}
+		return items
+	}
+	return seqJSON(expand(root)...), nil
+}
+
+func checkMacro(prim string, annots, args []string, arity int, sequences bool) error {
+	if len(args) != arity {
+		return errors.Errorf("Wrong number of arguments to macro %s: expected %d, got %d", prim, arity, len(args))
+	}
+	if sequences {
+		for i := range args {
+			if !strings.HasPrefix(args[i], "[") {
+				return errors.Errorf("Macro %s expects a sequence", prim)
+			}
+		}
+	}
+	if len(annots) > 0 {
+		return errors.Errorf("Unexpected annotation on macro %s", prim)
+	}
+	return nil
+}
+
+func fieldAnnot(prim string, annots []string) (string, error) {
+	switch {
+	case len(annots) == 0:
+		return "", nil
+	case len(annots) == 1 && strings.HasPrefix(annots[0], "%"):
+		return annots[0], nil
+	default:
+		return "", errors.Errorf("Unexpected annotation on macro %s", prim)
+	}
+}
+
+func splitFieldAnnots(annots []string) (fields []string, other []string) {
+	for i := range annots {
+		if strings.HasPrefix(annots[i], "%") {
+			fields = append(fields, annots[i])
+		} else {
+			other = append(other, annots[i])
+		}
+	}
+	return
+}
+
+func cadrPrim(c byte) string {
+	if c == 'A' {
+		return "CAR"
+	}
+	return "CDR"
+}
+
+func renameJSON(annots []string) string {
+	if len(annots) == 0 {
+		return "[]"
+	}
+	return seqJSON(primJSON("RENAME", annots))
+}
+
+func trimPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
+func isComparison(op string) bool {
+	_, ok := comparisons[op]
+	return ok
+}
+
+func primJSON(prim string, annots []string, args ...string) string {
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf(`{"prim":"%s"`, prim))
+	if len(annots) > 0 {
+		quoted := make([]string, len(annots))
+		for i := range annots {
+			quoted[i] = strconv.Quote(annots[i])
+		}
+		s.WriteString(fmt.Sprintf(`,"annots":[%s]`, strings.Join(quoted, ",")))
+	}
+	if len(args) > 0 {
+		s.WriteString(fmt.Sprintf(`,"args":[%s]`, strings.Join(args, ",")))
+	}
+	s.WriteByte('}')
+	return s.String()
+}
+
+func seqJSON(items ...string) string {
+	return fmt.Sprintf("[%s]", strings.Join(items, ","))
+}
+
+func intJSON(value int) string {
+	return fmt.Sprintf(`{"int":"%d"}`, value)
+}
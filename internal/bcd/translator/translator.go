@@ -2,9 +2,9 @@ package translator
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 
+	"github.com/baking-bad/bcdhub/internal/bcd/forge"
 	"github.com/pkg/errors"
 	"github.com/yhirose/go-peg"
 )
@@ -52,6 +52,10 @@ func (t *MichelineTranslator) pass(ast *peg.Ast) (string, error) {
 }
 
 func (t *MichelineTranslator) exprTranslate(ast *peg.Ast) (string, error) {
+	if len(ast.Nodes) > 0 && ast.Nodes[0].Name == "prim" && isMacro(ast.Nodes[0].Token) {
+		return t.macroTranslate(ast)
+	}
+
 	var s strings.Builder
 	s.WriteByte('{')
 	for i := range ast.Nodes {
@@ -74,10 +78,38 @@ func (t *MichelineTranslator) exprTranslate(ast *peg.Ast) (string, error) {
 	return s.String(), nil
 }
 
+func (t *MichelineTranslator) macroTranslate(ast *peg.Ast) (string, error) {
+	annots := make([]string, 0)
+	args := make([]string, 0)
+	for _, node := range ast.Nodes[1:] {
+		switch node.Name {
+		case "annots":
+			for i := range node.Nodes {
+				annots = append(annots, node.Nodes[i].Token)
+			}
+		case "args":
+			for i := range node.Nodes {
+				arg, err := t.Translate(node.Nodes[i])
+				if err != nil {
+					return "", err
+				}
+				if arg != "" {
+					args = append(args, arg)
+				}
+			}
+		}
+	}
+	result, err := expandMacro(ast.Nodes[0].Token, annots, args)
+	if err != nil {
+		return "", newError(ast, err)
+	}
+	return result, nil
+}
+
 func (t *MichelineTranslator) tokenTranslate(ast *peg.Ast) (string, error) {
 	if ast.Name == "prim" {
 		if err := validatePrimitive(ast.Token); err != nil {
-			return "", err
+			return "", newError(ast, err)
 		}
 	}
 	return ast.Token, nil
@@ -166,14 +198,7 @@ func sanitizeString(token string) string {
 }
 
 func validatePrimitive(prim string) error {
-	// TODO: handle macros + add FAIL prim
-	valid, err := regexp.MatchString(
-		"^INT|ISNAT|CAST|RENAME|DROP|DUP|SWAP|PUSH|SOME|NONE|UNIT|IF_NONE|PAIR|CAR|CDR|LEFT|RIGHT|IF_LEFT|IF_RIGHT|NIL|CONS|IF_CONS|SIZE|EMPTY_SET|EMPTY_MAP|MAP|ITER|MEM|GET|UPDATE|IF|LOOP|LOOP_LEFT|LAMBDA|EXEC|DIP|FAILWITH|CONCAT|SLICE|PACK|UNPACK|ADD|SUB|MUL|EDIV|ABS|NEG|LSL|LSR|OR|AND|XOR|NOT|COMPARE|EQ|NEQ|LT|GT|LE|GE|CHECK_SIGNATURE|BLAKE2B|SHA256|SHA512|HASH_KEY|DIG|DUG|EMPTY_BIG_MAP|APPLY|SELF|CONTRACT|TRANSFER_TOKENS|SET_DELEGATE|CREATE_CONTRACT|IMPLICIT_ACCOUNT|NOW|AMOUNT|BALANCE|STEPS_TO_QUOTA|SOURCE|SENDER|ADDRESS|CHAIN_ID|option|list|set|contract|pair|or|lambda|map|big_map|key|unit|signature|operation|address|int|nat|string|bytes|mutez|bool|key_hash|timestamp|chain_id|code|storage|parameter|Unit|False|True|Elt|None|Some|Pair|Left|Right$",
-		prim)
-	if err != nil {
-		return err
-	}
-	if !valid {
+	if !forge.IsKeyword(prim) {
 		return errors.Errorf("Invalid primitive %s", prim)
 	}
 	return nil
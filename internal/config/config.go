@@ -20,6 +20,7 @@ const (
 
 // Config -
 type Config struct {
+	Networks     map[string]NetworkConfig `yaml:"networks"`
 	RPC          map[string]RPCConfig     `yaml:"rpc"`
 	TzKT         map[string]TzKTConfig    `yaml:"tzkt"`
 	Services     map[string]ServiceConfig `yaml:"services"`
@@ -37,15 +38,21 @@ type Config struct {
 		ProjectName   string              `yaml:"project_name"`
 		SentryEnabled bool                `yaml:"sentry_enabled"`
 		Connections   Connections         `yaml:"connections"`
+		CatchUp       CatchUpConfig       `yaml:"catch_up"`
+		Prometheus    PrometheusConfig    `yaml:"prometheus"`
 	} `yaml:"indexer"`
 
 	Metrics struct {
-		ProjectName         string      `yaml:"project_name"`
-		SentryEnabled       bool        `yaml:"sentry_enabled"`
-		CacheAliasesSeconds int         `yaml:"cache_aliases_seconds"`
-		Connections         Connections `yaml:"connections"`
+		ProjectName         string           `yaml:"project_name"`
+		SentryEnabled       bool             `yaml:"sentry_enabled"`
+		CacheAliasesSeconds int              `yaml:"cache_aliases_seconds"`
+		Connections         Connections      `yaml:"connections"`
+		Webhooks            WebhooksConfig   `yaml:"webhooks"`
+		Prometheus          PrometheusConfig `yaml:"prometheus"`
 	} `yaml:"metrics"`
 
+	GraphQL GraphQLConfig `yaml:"graphql"`
+
 	Scripts struct {
 		AWS         AWSConfig   `yaml:"aws"`
 		Networks    []string    `yaml:"networks"`
@@ -53,11 +60,52 @@ type Config struct {
 	} `yaml:"scripts"`
 }
 
+// NetworkConfig - network which is registered in network registry on start. RPC and TzKT endpoints are optional.
+type NetworkConfig struct {
+	ChainID string     `yaml:"chain_id"`
+	RPC     RPCConfig  `yaml:"rpc"`
+	TzKT    TzKTConfig `yaml:"tzkt"`
+}
+
 // RPCConfig -
 type RPCConfig struct {
-	URI     string `yaml:"uri"`
-	Timeout int    `yaml:"timeout"`
-	Cache   string `yaml:"cache"`
+	URI     string   `yaml:"uri"`
+	URIs    []string `yaml:"uris"`
+	Timeout int      `yaml:"timeout"`
+	Cache   string   `yaml:"cache"`
+	MaxLag  int64    `yaml:"max_lag"`
+	Record  string   `yaml:"record"`
+	Replay  string   `yaml:"replay"`
+}
+
+// URLs - returns `uri` followed by `uris` without empty and duplicated values
+func (cfg RPCConfig) URLs() []string {
+	urls := make([]string, 0, len(cfg.URIs)+1)
+	seen := make(map[string]struct{})
+	for _, uri := range append([]string{cfg.URI}, cfg.URIs...) {
+		if uri == "" {
+			continue
+		}
+		if _, ok := seen[uri]; ok {
+			continue
+		}
+		seen[uri] = struct{}{}
+		urls = append(urls, uri)
+	}
+	return urls
+}
+
+// CatchUpConfig - settings of indexer catch-up mode. Zero values mean defaults, negative `threshold` disables the mode.
+type CatchUpConfig struct {
+	Threshold int64 `yaml:"threshold"`
+	BatchSize int   `yaml:"batch_size"`
+	Workers   int   `yaml:"workers"`
+}
+
+// PrometheusConfig - settings of `/metrics` endpoint. API serves it on its own address if `bind` is empty.
+type PrometheusConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Bind    string `yaml:"bind"`
 }
 
 // TzKTConfig -
@@ -67,18 +115,50 @@ type TzKTConfig struct {
 	Timeout int    `yaml:"timeout"`
 }
 
-// ServiceConfig -
+// WebhooksConfig - settings of webhook delivery. Timeout and max interval between retries are in seconds.
+type WebhooksConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	Timeout     int    `yaml:"timeout"`
+	MaxRetries  uint64 `yaml:"max_retries"`
+	MaxInterval int    `yaml:"max_interval"`
+	Workers     int    `yaml:"workers"`
+}
+
+// ServiceConfig - sources of pending operations. If `mempool_monitor` is enabled, mempool is streamed from the first node of network RPC instead of `mempool` service.
 type ServiceConfig struct {
-	MempoolURI string `yaml:"mempool"`
+	MempoolURI     string               `yaml:"mempool"`
+	MempoolMonitor MempoolMonitorConfig `yaml:"mempool_monitor"`
 }
 
+// MempoolMonitorConfig - settings of native mempool monitor. `size` is max count of stored operation groups, `ttl` is count of blocks after which not included operation is dropped.
+type MempoolMonitorConfig struct {
+	Enabled bool  `yaml:"enabled"`
+	Size    int   `yaml:"size"`
+	TTL     int64 `yaml:"ttl"`
+}
+
+// search backends
+const (
+	SearchElastic  = "elastic"
+	SearchPostgres = "postgres"
+)
+
 // StorageConfig -
 type StorageConfig struct {
 	Postgres string   `yaml:"pg"`
 	Elastic  []string `yaml:"elastic"`
+	Search   string   `yaml:"search"`
 	Timeout  int      `yaml:"timeout"`
 }
 
+// SearchBackend - returns search backend name. Elastic is used by default.
+func (cfg StorageConfig) SearchBackend() string {
+	if cfg.Search == "" {
+		return SearchElastic
+	}
+	return cfg.Search
+}
+
 // DatabaseConfig -
 type DatabaseConfig struct {
 	ConnString string `yaml:"conn_string"`
@@ -133,6 +213,8 @@ type SeedConfig struct {
 		Network   string `yaml:"network"`
 		Alias     string `yaml:"alias"`
 		WatchMask uint   `yaml:"watch_mask"`
+		URL       string `yaml:"url"`
+		Secret    string `yaml:"secret"`
 	} `yaml:"subscriptions"`
 	Aliases []struct {
 		Alias   string `yaml:"alias"`
@@ -147,17 +229,30 @@ type SeedConfig struct {
 }
 
 type APIConfig struct {
-	ProjectName   string         `yaml:"project_name"`
-	Bind          string         `yaml:"bind"`
-	SwaggerHost   string         `yaml:"swagger_host"`
-	CorsEnabled   bool           `yaml:"cors_enabled"`
-	SentryEnabled bool           `yaml:"sentry_enabled"`
-	SeedEnabled   bool           `yaml:"seed_enabled"`
-	Frontend      FrontendConfig `yaml:"frontend"`
-	Seed          SeedConfig     `yaml:"seed"`
-	Networks      []string       `yaml:"networks"`
-	PageSize      uint64         `yaml:"page_size"`
-	Connections   Connections    `yaml:"connections"`
+	ProjectName   string           `yaml:"project_name"`
+	Bind          string           `yaml:"bind"`
+	SwaggerHost   string           `yaml:"swagger_host"`
+	CorsEnabled   bool             `yaml:"cors_enabled"`
+	SentryEnabled bool             `yaml:"sentry_enabled"`
+	SeedEnabled   bool             `yaml:"seed_enabled"`
+	WebhooksToken string           `yaml:"webhooks_token"`
+	Frontend      FrontendConfig   `yaml:"frontend"`
+	Seed          SeedConfig       `yaml:"seed"`
+	Networks      []string         `yaml:"networks"`
+	PageSize      uint64           `yaml:"page_size"`
+	Connections   Connections      `yaml:"connections"`
+	Prometheus    PrometheusConfig `yaml:"prometheus"`
+}
+
+// GraphQLConfig -
+type GraphQLConfig struct {
+	ProjectName   string      `yaml:"project_name"`
+	Bind          string      `yaml:"bind"`
+	CorsEnabled   bool        `yaml:"cors_enabled"`
+	SentryEnabled bool        `yaml:"sentry_enabled"`
+	PageSize      uint64      `yaml:"page_size"`
+	MaxDepth      int         `yaml:"max_depth"`
+	Connections   Connections `yaml:"connections"`
 }
 
 // SentryConfig -
@@ -214,9 +309,33 @@ func LoadConfig(filename string) (Config, error) {
 		return config, fmt.Errorf("unmarshaling configuration file %s error: %w", filename, err)
 	}
 
+	config.mergeNetworks()
+
 	return config, nil
 }
 
+// mergeNetworks - copies endpoints of registered networks to `rpc` and `tzkt` sections if they are not set there
+func (cfg *Config) mergeNetworks() {
+	for name, network := range cfg.Networks {
+		if len(network.RPC.URLs()) > 0 || network.RPC.Replay != "" {
+			if cfg.RPC == nil {
+				cfg.RPC = make(map[string]RPCConfig)
+			}
+			if _, ok := cfg.RPC[name]; !ok {
+				cfg.RPC[name] = network.RPC
+			}
+		}
+		if network.TzKT.URI != "" || network.TzKT.BaseURI != "" {
+			if cfg.TzKT == nil {
+				cfg.TzKT = make(map[string]TzKTConfig)
+			}
+			if _, ok := cfg.TzKT[name]; !ok {
+				cfg.TzKT[name] = network.TzKT
+			}
+		}
+	}
+}
+
 var defaultEnv = regexp.MustCompile(`\${(?P<name>[\w\.]{1,}):-(?P<value>[\w\.:/-]*)}`)
 
 func expandEnv(data string) string {
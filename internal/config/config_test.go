@@ -38,3 +38,35 @@ func Test_expandEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_mergeNetworks(t *testing.T) {
+	cfg := Config{
+		Networks: map[string]NetworkConfig{
+			"mainnet": {
+				RPC: RPCConfig{URI: "https://network.example.com"},
+			},
+			"privatenet": {
+				ChainID: "NetXprivate",
+				RPC:     RPCConfig{URI: "https://private.example.com", Timeout: 10},
+				TzKT:    TzKTConfig{BaseURI: "https://tzkt.private.example.com"},
+			},
+		},
+		RPC: map[string]RPCConfig{
+			"mainnet": {URI: "https://rpc.example.com"},
+		},
+	}
+	cfg.mergeNetworks()
+
+	if got := cfg.RPC["mainnet"].URI; got != "https://rpc.example.com" {
+		t.Errorf("mainnet rpc = %v, want %v", got, "https://rpc.example.com")
+	}
+	if got := cfg.RPC["privatenet"]; got.URI != "https://private.example.com" || got.Timeout != 10 {
+		t.Errorf("privatenet rpc = %v", got)
+	}
+	if got := cfg.TzKT["privatenet"].BaseURI; got != "https://tzkt.private.example.com" {
+		t.Errorf("privatenet tzkt = %v, want %v", got, "https://tzkt.private.example.com")
+	}
+	if _, ok := cfg.TzKT["mainnet"]; ok {
+		t.Errorf("mainnet tzkt should not be set")
+	}
+}
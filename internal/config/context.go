@@ -1,8 +1,11 @@
 package config
 
 import (
+	"io"
+
 	"github.com/baking-bad/bcdhub/internal/aws"
 	"github.com/baking-bad/bcdhub/internal/cache"
+	"github.com/baking-bad/bcdhub/internal/logger"
 	"github.com/baking-bad/bcdhub/internal/models"
 	"github.com/baking-bad/bcdhub/internal/models/account"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapaction"
@@ -14,13 +17,16 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/domains"
 	"github.com/baking-bad/bcdhub/internal/models/global_constant"
 	"github.com/baking-bad/bcdhub/internal/models/migration"
+	"github.com/baking-bad/bcdhub/internal/models/network"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/saplingdiff"
 	"github.com/baking-bad/bcdhub/internal/models/service"
 	"github.com/baking-bad/bcdhub/internal/models/tokenbalance"
 	"github.com/baking-bad/bcdhub/internal/models/tokenmetadata"
 	"github.com/baking-bad/bcdhub/internal/models/transfer"
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/models/webhook"
 	"github.com/baking-bad/bcdhub/internal/noderpc"
 	"github.com/baking-bad/bcdhub/internal/postgres/core"
 	"github.com/baking-bad/bcdhub/internal/search"
@@ -33,7 +39,7 @@ import (
 type Context struct {
 	AWS             *aws.Client
 	RPC             map[types.Network]noderpc.INode
-	MempoolServices map[types.Network]*mempool.Mempool
+	MempoolServices map[types.Network]mempool.Service
 
 	StorageDB *core.Postgres
 
@@ -52,8 +58,10 @@ type Context struct {
 	DApps            dapp.Repository
 	GlobalConstants  global_constant.Repository
 	Migrations       migration.Repository
+	Networks         network.Repository
 	Operations       operation.Repository
 	Protocols        protocol.Repository
+	SaplingDiffs     saplingdiff.Repository
 	TokenBalances    tokenbalance.Repository
 	TokenMetadata    tokenmetadata.Repository
 	Transfers        transfer.Repository
@@ -61,15 +69,18 @@ type Context struct {
 	Domains          domains.Repository
 	Services         service.Repository
 	Scripts          contract.ScriptRepository
+	Webhooks         webhook.Repository
 
 	Searcher search.Searcher
 
 	Cache     *cache.Cache
 	Sanitizer *bluemonday.Policy
+
+	err error
 }
 
-// NewContext -
-func NewContext(opts ...ContextOption) *Context {
+// NewContext - applies options in order and stops on the first one which fails
+func NewContext(opts ...ContextOption) (*Context, error) {
 	ctx := &Context{
 		Sanitizer: bluemonday.UGCPolicy(),
 	}
@@ -77,12 +88,16 @@ func NewContext(opts ...ContextOption) *Context {
 
 	for _, opt := range opts {
 		opt(ctx)
+		if ctx.err != nil {
+			ctx.Close()
+			return nil, ctx.err
+		}
 	}
 
 	ctx.Cache = cache.NewCache(
 		ctx.RPC, ctx.Blocks, ctx.Contracts, ctx.Protocols, ctx.ContractMetadata, ctx.Sanitizer,
 	)
-	return ctx
+	return ctx, nil
 }
 
 // GetRPC -
@@ -94,7 +109,7 @@ func (ctx *Context) GetRPC(network types.Network) (noderpc.INode, error) {
 }
 
 // GetMempoolService -
-func (ctx *Context) GetMempoolService(network types.Network) (*mempool.Mempool, error) {
+func (ctx *Context) GetMempoolService(network types.Network) (mempool.Service, error) {
 	if rpc, ok := ctx.MempoolServices[network]; ok {
 		return rpc, nil
 	}
@@ -103,6 +118,13 @@ func (ctx *Context) GetMempoolService(network types.Network) (*mempool.Mempool,
 
 // Close -
 func (ctx *Context) Close() {
+	for _, svc := range ctx.MempoolServices {
+		if closer, ok := svc.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logger.Err(err)
+			}
+		}
+	}
 	if ctx.StorageDB != nil {
 		ctx.StorageDB.Close()
 	}
@@ -5,8 +5,11 @@ import (
 	"time"
 
 	"github.com/baking-bad/bcdhub/internal/aws"
+	"github.com/baking-bad/bcdhub/internal/bcd"
 	"github.com/baking-bad/bcdhub/internal/bcd/tezerrors"
 	"github.com/baking-bad/bcdhub/internal/elastic"
+	"github.com/baking-bad/bcdhub/internal/logger"
+	modelsProtocol "github.com/baking-bad/bcdhub/internal/models/protocol"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/postgres/account"
 	"github.com/baking-bad/bcdhub/internal/postgres/bigmapdiff"
@@ -16,19 +19,24 @@ import (
 	"github.com/baking-bad/bcdhub/internal/postgres/domains"
 	"github.com/baking-bad/bcdhub/internal/postgres/global_constant"
 	"github.com/baking-bad/bcdhub/internal/postgres/migration"
+	"github.com/baking-bad/bcdhub/internal/postgres/network"
 	"github.com/baking-bad/bcdhub/internal/postgres/operation"
 	"github.com/baking-bad/bcdhub/internal/postgres/protocol"
+	"github.com/baking-bad/bcdhub/internal/postgres/saplingdiff"
 	"github.com/baking-bad/bcdhub/internal/postgres/service"
 	"github.com/baking-bad/bcdhub/internal/postgres/tokenbalance"
 	"github.com/baking-bad/bcdhub/internal/postgres/tokenmetadata"
 	"github.com/baking-bad/bcdhub/internal/postgres/transfer"
+	"github.com/baking-bad/bcdhub/internal/postgres/webhook"
 	"github.com/baking-bad/bcdhub/internal/services/mempool"
 
 	"github.com/baking-bad/bcdhub/internal/postgres/bigmapaction"
 	"github.com/baking-bad/bcdhub/internal/postgres/block"
 	pgCore "github.com/baking-bad/bcdhub/internal/postgres/core"
+	pgSearch "github.com/baking-bad/bcdhub/internal/postgres/search"
 
 	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/pkg/errors"
 )
 
 // ContextOption -
@@ -43,19 +51,40 @@ func WithRPC(rpcConfig map[string]RPCConfig) ContextOption {
 		rpc := make(map[types.Network]noderpc.INode)
 		for name, rpcProvider := range rpcConfig {
 			network := types.NewNetwork(name)
-			rpc[network] = noderpc.NewPool(
-				[]string{rpcProvider.URI},
-				noderpc.WithTimeout(time.Second*time.Duration(rpcProvider.Timeout)),
-			)
+			rpc[network] = rpcProvider.NewNode(false)
 		}
 		ctx.RPC = rpc
 	}
 }
 
+// NewNode - creates pool of RPC nodes. If `replay` is set node responses are served from the archive without requests to nodes.
+// If `wait` is true, it waits until any node is available.
+func (cfg RPCConfig) NewNode(wait bool) noderpc.INode {
+	if cfg.Replay != "" {
+		return noderpc.NewReplayNode(noderpc.NewArchive(cfg.Replay))
+	}
+
+	nodeOpts := []noderpc.NodeOption{
+		noderpc.WithTimeout(time.Second * time.Duration(cfg.Timeout)),
+	}
+	if cfg.Record != "" {
+		nodeOpts = append(nodeOpts, noderpc.WithRecorder(noderpc.NewArchive(cfg.Record)))
+	}
+	var poolOpts []noderpc.PoolOption
+	if cfg.MaxLag > 0 {
+		poolOpts = append(poolOpts, noderpc.WithMaxLag(cfg.MaxLag))
+	}
+
+	if wait {
+		return noderpc.NewWaitPool(cfg.URLs(), nodeOpts, poolOpts...)
+	}
+	return noderpc.NewPool(cfg.URLs(), nodeOpts, poolOpts...)
+}
+
 // WithStorage -
 func WithStorage(cfg StorageConfig, appName string, maxPageSize int64, maxConnCount, idleConnCount int) ContextOption {
 	return func(ctx *Context) {
-		if len(cfg.Elastic) == 0 {
+		if cfg.Postgres == "" {
 			panic("Please set connection strings to storage in config")
 		}
 
@@ -77,8 +106,10 @@ func WithStorage(cfg StorageConfig, appName string, maxPageSize int64, maxConnCo
 		ctx.Contracts = contractStorage
 		ctx.ContractMetadata = cm.NewStorage(pg)
 		ctx.Migrations = migration.NewStorage(pg)
+		ctx.Networks = network.NewStorage(pg)
 		ctx.Operations = operation.NewStorage(pg)
 		ctx.Protocols = protocol.NewStorage(pg)
+		ctx.SaplingDiffs = saplingdiff.NewStorage(pg)
 		ctx.TokenBalances = tokenbalance.NewStorage(pg)
 		ctx.TokenMetadata = tokenmetadata.NewStorage(pg)
 		ctx.Transfers = transfer.NewStorage(pg)
@@ -86,17 +117,110 @@ func WithStorage(cfg StorageConfig, appName string, maxPageSize int64, maxConnCo
 		ctx.Domains = domains.NewStorage(pg)
 		ctx.Services = service.NewStorage(pg)
 		ctx.Scripts = contractStorage
+		ctx.Webhooks = webhook.NewStorage(pg)
+
+		if err := registerProtoSymLinks(ctx.Protocols); err != nil {
+			logger.Warning().Err(err).Msg("can't load symlinks of protocols")
+		}
+		bcd.SetProtoSymLinkLoader(newProtoSymLinkLoader(ctx.Protocols))
 	}
 }
 
-// WithSearch -
-func WithSearch(cfg StorageConfig) ContextOption {
+// newProtoSymLinkLoader - returns loader of symlinks of protocols accepted by indexer after the process start
+func newProtoSymLinkLoader(repo modelsProtocol.Repository) bcd.ProtoSymLinkLoader {
+	return func(hash string) (string, error) {
+		protocols, err := repo.GetAll()
+		if err != nil {
+			return "", err
+		}
+		for i := range protocols {
+			if protocols[i].Hash == hash && bcd.IsValidSymLink(protocols[i].SymLink) {
+				return protocols[i].SymLink, nil
+			}
+		}
+		return "", errors.Errorf("protocol %s is not found", hash)
+	}
+}
+
+// registerProtoSymLinks - registers symlinks saved in `protocols` table: detected ones and overridden by `bcdctl`
+func registerProtoSymLinks(repo modelsProtocol.Repository) error {
+	protocols, err := repo.GetAll()
+	if err != nil {
+		return err
+	}
+	for i := range protocols {
+		if !bcd.IsValidSymLink(protocols[i].SymLink) {
+			continue
+		}
+		if err := bcd.RegisterProtoSymLink(protocols[i].Hash, protocols[i].SymLink); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithNetworks - loads network registry from database and registers networks from config. It should be passed after `WithStorage` and before other options which use networks.
+// Registry errors are returned by `NewContext`.
+func WithNetworks(cfg map[string]NetworkConfig) ContextOption {
 	return func(ctx *Context) {
-		searcher := elastic.WaitNew(cfg.Elastic, cfg.Timeout)
-		ctx.Searcher = searcher
-		ctx.Statistics = searcher
+		ctx.err = loadNetworks(ctx, cfg)
+	}
+}
+
+func loadNetworks(ctx *Context, cfg map[string]NetworkConfig) error {
+	if ctx.StorageDB == nil {
+		return errors.New("network registry requires storage")
+	}
+	storage := network.NewStorage(ctx.StorageDB)
+	if err := storage.CreateTable(); err != nil {
+		return errors.Wrap(err, "networks table")
+	}
+
+	for _, info := range types.DefaultNetworks() {
+		if _, err := storage.Register(info.Name, info.ChainID); err != nil {
+			return errors.Wrapf(err, "register network %s", info.Name)
+		}
+	}
+	for name, networkCfg := range cfg {
+		if _, err := storage.Register(name, networkCfg.ChainID); err != nil {
+			return errors.Wrapf(err, "register network %s", name)
+		}
 	}
 
+	networks, err := storage.All()
+	if err != nil {
+		return err
+	}
+	for i := range networks {
+		if err := types.RegisterNetwork(networks[i].Info()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithSearch - should be called after `WithStorage` because postgres search backend uses its connection
+func WithSearch(cfg StorageConfig) ContextOption {
+	return func(ctx *Context) {
+		switch cfg.SearchBackend() {
+		case SearchElastic:
+			if len(cfg.Elastic) == 0 {
+				panic("Please set connection strings to elastic in config")
+			}
+			searcher := elastic.WaitNew(cfg.Elastic, cfg.Timeout)
+			ctx.Searcher = searcher
+			ctx.Statistics = searcher
+		case SearchPostgres:
+			if ctx.StorageDB == nil {
+				panic("postgres search backend requires storage")
+			}
+			searcher := pgSearch.NewStorage(ctx.StorageDB)
+			ctx.Searcher = searcher
+			ctx.Statistics = searcher
+		default:
+			panic(fmt.Sprintf("unknown search backend: %s", cfg.Search))
+		}
+	}
 }
 
 // WithConfigCopy -
@@ -106,19 +230,39 @@ func WithConfigCopy(cfg Config) ContextOption {
 	}
 }
 
-// WithMempool -
-func WithMempool(cfg map[string]ServiceConfig) ContextOption {
+// WithMempool - should be called after `WithRPC` because mempool monitor receives blocks from context RPC.
+// Monitor streams mempool from the preferred node of RPC pool, so it follows failover of the pool.
+func WithMempool(cfg map[string]ServiceConfig, rpcConfig map[string]RPCConfig) ContextOption {
 	return func(ctx *Context) {
 		if len(cfg) == 0 {
 			return
 		}
-		svc := make(map[types.Network]*mempool.Mempool)
+		svc := make(map[types.Network]mempool.Service)
 		for network, svcCfg := range cfg {
-			if svcCfg.MempoolURI == "" {
-				continue
-			}
 			typ := types.NewNetwork(network)
-			svc[typ] = mempool.NewMempool(svcCfg.MempoolURI)
+			switch {
+			case svcCfg.MempoolMonitor.Enabled:
+				rpc, ok := ctx.RPC[typ]
+				if !ok {
+					panic(fmt.Sprintf("unknown RPC of mempool monitor: %s", network))
+				}
+				urls := rpcConfig[network].URLs()
+				if len(urls) == 0 {
+					panic(fmt.Sprintf("mempool monitor requires RPC uri: %s", network))
+				}
+				opts := []mempool.MonitorOption{
+					mempool.WithStoreSize(svcCfg.MempoolMonitor.Size),
+					mempool.WithTTL(svcCfg.MempoolMonitor.TTL),
+				}
+				if pool, ok := rpc.(*noderpc.Pool); ok {
+					opts = append(opts, mempool.WithNodeSelector(pool.URL))
+				}
+				monitor := mempool.NewMonitor(typ, urls[0], rpc, opts...)
+				monitor.Start()
+				svc[typ] = monitor
+			case svcCfg.MempoolURI != "":
+				svc[typ] = mempool.NewMempool(svcCfg.MempoolURI)
+			}
 		}
 		ctx.MempoolServices = svc
 	}
@@ -0,0 +1,60 @@
+package bigmapdiff
+
+import "bytes"
+
+// Kinds of key changes
+const (
+	KeyAdded   = "added"
+	KeyRemoved = "removed"
+	KeyUpdated = "updated"
+)
+
+// KeyChange - change of big map key between two levels
+type KeyChange struct {
+	Kind    string
+	KeyHash string
+	Before  *BigMapState
+	After   *BigMapState
+}
+
+// NewKeyChanges - compares key states at the beginning and at the end of levels range.
+// `before` contains states of changed keys at the start level, `after` - their states at the end level.
+// Keys which have same value at both levels (including created and removed inside range) are skipped.
+func NewKeyChanges(before, after []BigMapState) []KeyChange {
+	old := make(map[string]*BigMapState, len(before))
+	for i := range before {
+		if !before[i].Removed {
+			old[before[i].KeyHash] = &before[i]
+		}
+	}
+
+	changes := make([]KeyChange, 0)
+	for i := range after {
+		prev, existed := old[after[i].KeyHash]
+		switch {
+		case !existed && after[i].Removed:
+			continue
+		case !existed:
+			changes = append(changes, KeyChange{
+				Kind:    KeyAdded,
+				KeyHash: after[i].KeyHash,
+				After:   &after[i],
+			})
+		case after[i].Removed:
+			changes = append(changes, KeyChange{
+				Kind:    KeyRemoved,
+				KeyHash: after[i].KeyHash,
+				Before:  prev,
+				After:   &after[i],
+			})
+		case !bytes.Equal(prev.Value, after[i].Value):
+			changes = append(changes, KeyChange{
+				Kind:    KeyUpdated,
+				KeyHash: after[i].KeyHash,
+				Before:  prev,
+				After:   &after[i],
+			})
+		}
+	}
+	return changes
+}
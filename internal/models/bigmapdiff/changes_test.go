@@ -0,0 +1,75 @@
+package bigmapdiff
+
+import (
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKeyChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []BigMapState
+		after  []BigMapState
+		want   []string
+	}{
+		{
+			name: "empty",
+			want: []string{},
+		}, {
+			name: "added",
+			after: []BigMapState{
+				{KeyHash: "a", Value: types.Bytes(`{"int":"1"}`)},
+			},
+			want: []string{"a:added"},
+		}, {
+			name: "added and removed inside range",
+			after: []BigMapState{
+				{KeyHash: "a", Removed: true},
+			},
+			want: []string{},
+		}, {
+			name: "removed",
+			before: []BigMapState{
+				{KeyHash: "a", Value: types.Bytes(`{"int":"1"}`)},
+			},
+			after: []BigMapState{
+				{KeyHash: "a", Removed: true},
+			},
+			want: []string{"a:removed"},
+		}, {
+			name: "re-added after removal",
+			before: []BigMapState{
+				{KeyHash: "a", Removed: true},
+			},
+			after: []BigMapState{
+				{KeyHash: "a", Value: types.Bytes(`{"int":"1"}`)},
+			},
+			want: []string{"a:added"},
+		}, {
+			name: "updated and unchanged",
+			before: []BigMapState{
+				{KeyHash: "a", Value: types.Bytes(`{"int":"1"}`)},
+				{KeyHash: "b", Value: types.Bytes(`{"int":"2"}`)},
+			},
+			after: []BigMapState{
+				{KeyHash: "a", Value: types.Bytes(`{"int":"3"}`)},
+				{KeyHash: "b", Value: types.Bytes(`{"int":"2"}`)},
+			},
+			want: []string{"a:updated"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := NewKeyChanges(tt.before, tt.after)
+			got := make([]string, len(changes))
+			for i := range changes {
+				got[i] = changes[i].KeyHash + ":" + changes[i].Kind
+				assert.NotNil(t, changes[i].After)
+				assert.Equal(t, changes[i].Kind == KeyAdded, changes[i].Before == nil)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
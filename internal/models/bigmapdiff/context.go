@@ -12,5 +12,7 @@ type GetContext struct {
 	MaxLevel     *int64
 	MinLevel     *int64
 	CurrentLevel *int64
+	AtLevel      *int64
+	LastID       *int64
 	Contract     string
 }
@@ -11,6 +11,8 @@ type Repository interface {
 	GetByPtr(network types.Network, contract string, ptr int64) ([]BigMapState, error)
 	GetByPtrAndKeyHash(ptr int64, network types.Network, keyHash string, size int64, offset int64) ([]BigMapDiff, int64, error)
 	GetForAddress(network types.Network, address string) ([]BigMapState, error)
+	// GetForAddressAtLevel - returns states of contract's big map keys as they were at the end of `level`
+	GetForAddressAtLevel(network types.Network, address string, level int64) ([]BigMapState, error)
 	GetValuesByKey(keyHash string) ([]BigMapState, error)
 	Count(network types.Network, ptr int64) (int64, error)
 	Current(network types.Network, keyHash string, ptr int64) (BigMapState, error)
@@ -18,6 +20,9 @@ type Repository interface {
 	Previous([]BigMapDiff) ([]BigMapDiff, error)
 	GetStats(network types.Network, ptr int64) (Stats, error)
 	StatesChangedAfter(network types.Network, level int64) ([]BigMapState, error)
+	// Changes - returns states of keys which were changed in levels range (`fromLevel`, `toLevel`]: `before` - at the end of `fromLevel`, `after` - at the end of `toLevel`.
+	// Keys are ordered by key hash and paginated by `size` and `offset`. `total` is the count of keys changed in the range.
+	Changes(network types.Network, ptr, fromLevel, toLevel, size, offset int64) (before, after []BigMapState, total int64, err error)
 	LastDiff(network types.Network, ptr int64, keyHash string, skipRemoved bool) (BigMapDiff, error)
 	Keys(ctx GetContext) (states []BigMapState, err error)
 }
@@ -10,12 +10,15 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/dapp"
 	"github.com/baking-bad/bcdhub/internal/models/global_constant"
 	"github.com/baking-bad/bcdhub/internal/models/migration"
+	"github.com/baking-bad/bcdhub/internal/models/network"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/saplingdiff"
 	"github.com/baking-bad/bcdhub/internal/models/service"
 	"github.com/baking-bad/bcdhub/internal/models/tokenbalance"
 	"github.com/baking-bad/bcdhub/internal/models/tokenmetadata"
 	"github.com/baking-bad/bcdhub/internal/models/transfer"
+	"github.com/baking-bad/bcdhub/internal/models/webhook"
 )
 
 // Document names
@@ -30,13 +33,18 @@ const (
 	DocDApps            = "dapps"
 	DocGlobalConstants  = "global_constants"
 	DocMigrations       = "migrations"
+	DocNetworks         = "networks"
 	DocOperations       = "operations"
 	DocProtocol         = "protocols"
+	DocSaplingDiffs     = "sapling_diffs"
 	DocServices         = "states"
 	DocScripts          = "scripts"
 	DocTokenBalances    = "token_balances"
 	DocTokenMetadata    = "token_metadata"
 	DocTransfers        = "transfers"
+	DocWebhooks         = "webhook_subscriptions"
+	DocDeadLetters      = "webhook_dead_letters"
+	DocDeliveries       = "webhook_deliveries"
 )
 
 // AllDocuments - returns all document names
@@ -54,6 +62,7 @@ func AllDocuments() []string {
 		DocMigrations,
 		DocOperations,
 		DocProtocol,
+		DocSaplingDiffs,
 		DocScripts,
 		DocTokenBalances,
 		DocTokenMetadata,
@@ -66,12 +75,14 @@ func AllDocuments() []string {
 func AllModels() []Model {
 	return []Model{
 		&service.State{},
+		&network.Network{},
 		&protocol.Protocol{},
 		&block.Block{},
 		&account.Account{},
 		&bigmapaction.BigMapAction{},
 		&bigmapdiff.BigMapDiff{},
 		&bigmapdiff.BigMapState{},
+		&saplingdiff.SaplingDiff{},
 		&transfer.Transfer{},
 		&operation.Operation{},
 		&global_constant.GlobalConstant{},
@@ -83,6 +94,9 @@ func AllModels() []Model {
 		&tokenmetadata.TokenMetadata{},
 		&cm.ContractMetadata{},
 		&dapp.DApp{},
+		&webhook.Subscription{},
+		&webhook.DeadLetter{},
+		&webhook.Delivery{},
 	}
 }
 
@@ -144,6 +144,38 @@ func (mr *MockRepositoryMockRecorder) GetForAddress(network, address interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForAddress", reflect.TypeOf((*MockRepository)(nil).GetForAddress), network, address)
 }
 
+// GetForAddressAtLevel mocks base method
+func (m *MockRepository) GetForAddressAtLevel(network types.Network, address string, level int64) ([]model.BigMapState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForAddressAtLevel", network, address, level)
+	ret0, _ := ret[0].([]model.BigMapState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForAddressAtLevel indicates an expected call of GetForAddressAtLevel
+func (mr *MockRepositoryMockRecorder) GetForAddressAtLevel(network, address, level interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForAddressAtLevel", reflect.TypeOf((*MockRepository)(nil).GetForAddressAtLevel), network, address, level)
+}
+
+// Changes mocks base method
+func (m *MockRepository) Changes(network types.Network, ptr, fromLevel, toLevel, size, offset int64) ([]model.BigMapState, []model.BigMapState, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Changes", network, ptr, fromLevel, toLevel, size, offset)
+	ret0, _ := ret[0].([]model.BigMapState)
+	ret1, _ := ret[1].([]model.BigMapState)
+	ret2, _ := ret[2].(int64)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// Changes indicates an expected call of Changes
+func (mr *MockRepositoryMockRecorder) Changes(network, ptr, fromLevel, toLevel, size, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Changes", reflect.TypeOf((*MockRepository)(nil).Changes), network, ptr, fromLevel, toLevel, size, offset)
+}
+
 // GetValuesByKey mocks base method
 func (m *MockRepository) GetValuesByKey(keyHash string) ([]model.BigMapState, error) {
 	m.ctrl.T.Helper()
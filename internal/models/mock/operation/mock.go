@@ -65,6 +65,21 @@ func (mr *MockRepositoryMockRecorder) Last(filter, lastID interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Last", reflect.TypeOf((*MockRepository)(nil).Last), filter, lastID)
 }
 
+// LastAtLevel mocks base method
+func (m *MockRepository) LastAtLevel(network types.Network, address string, level int64) (model.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastAtLevel", network, address, level)
+	ret0, _ := ret[0].(model.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastAtLevel indicates an expected call of LastAtLevel
+func (mr *MockRepositoryMockRecorder) LastAtLevel(network, address, level interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastAtLevel", reflect.TypeOf((*MockRepository)(nil).LastAtLevel), network, address, level)
+}
+
 // Get mocks base method
 func (m *MockRepository) Get(filter map[string]interface{}, size int64, sort bool) ([]model.Operation, error) {
 	m.ctrl.T.Helper()
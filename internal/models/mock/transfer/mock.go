@@ -65,6 +65,25 @@ func (mr *MockRepositoryMockRecorder) GetAll(network, level interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRepository)(nil).GetAll), network, level)
 }
 
+// GetForOperations mocks base method
+func (m *MockRepository) GetForOperations(ids ...int64) ([]transferModel.Transfer, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range ids {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetForOperations", varargs...)
+	ret0, _ := ret[0].([]transferModel.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForOperations indicates an expected call of GetForOperations
+func (mr *MockRepositoryMockRecorder) GetForOperations(ids ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForOperations", reflect.TypeOf((*MockRepository)(nil).GetForOperations), ids...)
+}
+
 // GetTransfered mocks base method
 func (m *MockRepository) GetTransfered(network types.Network, contract string, tokenID uint64) (float64, error) {
 	m.ctrl.T.Helper()
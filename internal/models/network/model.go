@@ -0,0 +1,44 @@
+package network
+
+import (
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/go-pg/pg/v10"
+)
+
+// Network - record of network registry. Its identifier is stored in `network` column of all tables.
+type Network struct {
+	// nolint
+	tableName struct{} `pg:"networks"`
+
+	ID      int64  `pg:",pk,type:SMALLINT"`
+	Name    string `pg:",unique"`
+	ChainID string
+}
+
+// GetID -
+func (n *Network) GetID() int64 {
+	return n.ID
+}
+
+// GetIndex -
+func (n *Network) GetIndex() string {
+	return "networks"
+}
+
+// Save -
+func (n *Network) Save(tx pg.DBI) error {
+	_, err := tx.Model(n).
+		OnConflict("(id) DO UPDATE").
+		Set("chain_id = EXCLUDED.chain_id").
+		Insert()
+	return err
+}
+
+// Info - returns registry record of network
+func (n Network) Info() types.NetworkInfo {
+	return types.NetworkInfo{
+		ID:      types.Network(n.ID),
+		Name:    n.Name,
+		ChainID: n.ChainID,
+	}
+}
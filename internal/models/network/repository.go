@@ -0,0 +1,8 @@
+package network
+
+// Repository -
+type Repository interface {
+	All() ([]Network, error)
+	GetByName(name string) (Network, error)
+	Register(name, chainID string) (Network, error)
+}
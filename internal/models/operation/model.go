@@ -10,6 +10,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/bigmapaction"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/saplingdiff"
 	"github.com/baking-bad/bcdhub/internal/models/transfer"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/go-pg/pg/v10"
@@ -65,6 +66,7 @@ type Operation struct {
 	Transfers     []*transfer.Transfer         `pg:"rel:has-many"`
 	BigMapDiffs   []*bigmapdiff.BigMapDiff     `pg:"rel:has-many"`
 	BigMapActions []*bigmapaction.BigMapAction `pg:"rel:has-many"`
+	SaplingDiffs  []*saplingdiff.SaplingDiff   `pg:"rel:has-many"`
 
 	AllocatedDestinationContract bool `pg:",use_zero"`
 	Internal                     bool `pg:",use_zero"`
@@ -10,6 +10,8 @@ type Repository interface {
 	GetByAccount(acc account.Account, size uint64, filters map[string]interface{}) (Pageable, error)
 	// Last -  get last operation by `filters` with not empty deffated_storage.
 	Last(filter map[string]interface{}, lastID int64) (Operation, error)
+	// LastAtLevel - get last applied operation of contract with not empty deffated_storage which was included in block `level` or earlier.
+	LastAtLevel(network types.Network, address string, level int64) (Operation, error)
 
 	// GetOperations - get operation by `filter`. `Size` - if 0 - return all, else certain `size` operations.
 	// `Sort` - sort by time and content index by desc
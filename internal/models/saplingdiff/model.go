@@ -0,0 +1,55 @@
+package saplingdiff
+
+import (
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/go-pg/pg/v10"
+)
+
+// SaplingDiff - one item of `sapling_state` lazy storage diff: allocation with memo size, commitment with ciphertext or nullifier
+type SaplingDiff struct {
+	// nolint
+	tableName struct{} `pg:"sapling_diffs"`
+
+	ID          int64
+	Ptr         int64                 `pg:",use_zero"`
+	Kind        types.SaplingDiffKind `pg:",type:SMALLINT"`
+	MemoSize    int64                 `pg:",use_zero"`
+	Commitment  string
+	Ciphertext  types.Bytes `pg:",type:bytea"`
+	Nullifier   string
+	Level       int64
+	Contract    string
+	Network     types.Network `pg:",type:SMALLINT"`
+	Timestamp   time.Time
+	ProtocolID  int64 `pg:",type:SMALLINT"`
+	OperationID int64
+}
+
+// GetID -
+func (s *SaplingDiff) GetID() int64 {
+	return s.ID
+}
+
+// GetIndex -
+func (s *SaplingDiff) GetIndex() string {
+	return "sapling_diffs"
+}
+
+// Save -
+func (s *SaplingDiff) Save(tx pg.DBI) error {
+	_, err := tx.Model(s).Returning("id").Insert()
+	return err
+}
+
+// LogFields -
+func (s *SaplingDiff) LogFields() map[string]interface{} {
+	return map[string]interface{}{
+		"network":  s.Network.String(),
+		"contract": s.Contract,
+		"ptr":      s.Ptr,
+		"block":    s.Level,
+		"kind":     s.Kind.String(),
+	}
+}
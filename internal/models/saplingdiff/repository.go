@@ -0,0 +1,11 @@
+package saplingdiff
+
+import "github.com/baking-bad/bcdhub/internal/models/types"
+
+// Repository -
+type Repository interface {
+	Get(network types.Network, ptr int64, kind types.SaplingDiffKind, size, offset int64) ([]SaplingDiff, error)
+	GetByPtr(network types.Network, ptr int64) ([]SaplingDiff, error)
+	Count(network types.Network, ptr int64, kind types.SaplingDiffKind) (int64, error)
+	Alloc(network types.Network, ptr int64) (SaplingDiff, error)
+}
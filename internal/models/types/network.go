@@ -1,12 +1,15 @@
 package types
 
 import (
+	"sort"
 	"strconv"
+	"sync"
 
 	"github.com/pkg/errors"
 )
 
-// Network -
+// Network - identifier of network. Values are assigned persistently by the network registry (`networks` table).
+// Constants below are identifiers of networks known at build time and they are registered by default.
 type Network int64
 
 // Network names
@@ -24,35 +27,138 @@ const (
 	Ithacanet
 )
 
-var networkNames = map[Network]string{
-	Mainnet:      "mainnet",
-	Carthagenet:  "carthagenet",
-	Delphinet:    "delphinet",
-	Edo2net:      "edo2net",
-	Florencenet:  "florencenet",
-	Granadanet:   "granadanet",
-	Sandboxnet:   "sandboxnet",
-	Hangzhounet:  "hangzhounet",
-	Hangzhou2net: "hangzhou2net",
-	Ithacanet:    "ithacanet",
+// RuntimeNetworksStart - networks which are not known at build time get identifiers starting from this value.
+// Lower identifiers are reserved for networks which can be added to `DefaultNetworks` later.
+const RuntimeNetworksStart Network = 1000
+
+// NextRuntimeNetwork - returns identifier for new network registered at runtime. `maxID` is the greatest identifier in the registry.
+func NextRuntimeNetwork(maxID Network) Network {
+	if maxID < RuntimeNetworksStart {
+		return RuntimeNetworksStart
+	}
+	return maxID + 1
+}
+
+// MainnetChainID -
+const MainnetChainID = "NetXdQprcVkpaWU"
+
+// NetworkInfo - network registry record
+type NetworkInfo struct {
+	ID      Network
+	Name    string
+	ChainID string
+}
+
+type networkRegistry struct {
+	byID      map[Network]NetworkInfo
+	byName    map[string]Network
+	byChainID map[string]Network
+	mx        sync.RWMutex
+}
+
+var registry = newNetworkRegistry()
+
+func newNetworkRegistry() *networkRegistry {
+	r := &networkRegistry{
+		byID:      make(map[Network]NetworkInfo),
+		byName:    make(map[string]Network),
+		byChainID: make(map[string]Network),
+	}
+	for _, info := range DefaultNetworks() {
+		r.byID[info.ID] = info
+		r.byName[info.Name] = info.ID
+		if info.ChainID != "" {
+			r.byChainID[info.ChainID] = info.ID
+		}
+	}
+	return r
+}
+
+// DefaultNetworks - returns networks which are known at build time
+func DefaultNetworks() []NetworkInfo {
+	return []NetworkInfo{
+		{ID: Mainnet, Name: "mainnet", ChainID: MainnetChainID},
+		{ID: Carthagenet, Name: "carthagenet"},
+		{ID: Delphinet, Name: "delphinet"},
+		{ID: Edo2net, Name: "edo2net"},
+		{ID: Florencenet, Name: "florencenet"},
+		{ID: Granadanet, Name: "granadanet"},
+		{ID: Sandboxnet, Name: "sandboxnet"},
+		{ID: Hangzhounet, Name: "hangzhounet"},
+		{ID: Hangzhou2net, Name: "hangzhou2net"},
+		{ID: Ithacanet, Name: "ithacanet"},
+	}
+}
+
+// RegisterNetwork - adds network to the registry. It returns error if name or identifier is already taken by another network.
+func RegisterNetwork(info NetworkInfo) error {
+	if info.ID == Empty {
+		return errors.Errorf("invalid network identifier for %s", info.Name)
+	}
+	if info.Name == "" {
+		return errors.Errorf("empty name of network %d", info.ID)
+	}
+
+	registry.mx.Lock()
+	defer registry.mx.Unlock()
+
+	if existing, ok := registry.byID[info.ID]; ok && existing.Name != info.Name {
+		return errors.Errorf("network identifier %d is already taken by %s", info.ID, existing.Name)
+	}
+	if id, ok := registry.byName[info.Name]; ok && id != info.ID {
+		return errors.Errorf("network %s is already registered with identifier %d", info.Name, id)
+	}
+
+	if existing, ok := registry.byID[info.ID]; ok && existing.ChainID != "" && existing.ChainID != info.ChainID {
+		delete(registry.byChainID, existing.ChainID)
+	}
+
+	registry.byID[info.ID] = info
+	registry.byName[info.Name] = info.ID
+	if info.ChainID != "" {
+		registry.byChainID[info.ChainID] = info.ID
+	}
+	return nil
 }
 
-var namesToNetwork = map[string]Network{
-	"mainnet":      Mainnet,
-	"carthagenet":  Carthagenet,
-	"delphinet":    Delphinet,
-	"edo2net":      Edo2net,
-	"florencenet":  Florencenet,
-	"granadanet":   Granadanet,
-	"sandboxnet":   Sandboxnet,
-	"hangzhounet":  Hangzhounet,
-	"hangzhou2net": Hangzhou2net,
-	"ithacanet":    Ithacanet,
+// RegisteredNetworks - returns all registered networks ordered by identifier
+func RegisteredNetworks() []NetworkInfo {
+	registry.mx.RLock()
+	defer registry.mx.RUnlock()
+
+	result := make([]NetworkInfo, 0, len(registry.byID))
+	for _, info := range registry.byID {
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// NetworkByChainID - returns network by its chain id
+func NetworkByChainID(chainID string) (Network, bool) {
+	registry.mx.RLock()
+	defer registry.mx.RUnlock()
+
+	network, ok := registry.byChainID[chainID]
+	return network, ok
+}
+
+// ChainID - returns chain id of network if it's known
+func (network Network) ChainID() string {
+	registry.mx.RLock()
+	defer registry.mx.RUnlock()
+
+	return registry.byID[network].ChainID
 }
 
 // String - convert enum to string for printing
 func (network Network) String() string {
-	return networkNames[network]
+	registry.mx.RLock()
+	defer registry.mx.RUnlock()
+
+	return registry.byID[network].Name
 }
 
 // UnmarshalJSON -
@@ -61,9 +167,9 @@ func (network *Network) UnmarshalJSON(data []byte) error {
 	if err != nil {
 		return err
 	}
-	newValue, ok := namesToNetwork[name]
-	if !ok {
-		return errors.Errorf("Unknown network: %d", network)
+	newValue := NewNetwork(name)
+	if newValue == Empty {
+		return errors.Errorf("Unknown network: %s", name)
 	}
 
 	*network = newValue
@@ -72,8 +178,8 @@ func (network *Network) UnmarshalJSON(data []byte) error {
 
 // MarshalJSON -
 func (network Network) MarshalJSON() ([]byte, error) {
-	name, ok := networkNames[network]
-	if !ok {
+	name := network.String()
+	if name == "" {
 		return nil, errors.Errorf("Unknown network: %d", network)
 	}
 
@@ -82,5 +188,8 @@ func (network Network) MarshalJSON() ([]byte, error) {
 
 // NewNetwork -
 func NewNetwork(name string) Network {
-	return namesToNetwork[name]
+	registry.mx.RLock()
+	defer registry.mx.RUnlock()
+
+	return registry.byName[name]
 }
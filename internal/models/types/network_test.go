@@ -0,0 +1,128 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterNetwork(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    NetworkInfo
+		wantErr bool
+	}{
+		{
+			name: "new private network",
+			info: NetworkInfo{ID: 100, Name: "privatenet", ChainID: "NetXprivate"},
+		}, {
+			name: "same network again",
+			info: NetworkInfo{ID: 100, Name: "privatenet", ChainID: "NetXprivate"},
+		}, {
+			name:    "taken identifier",
+			info:    NetworkInfo{ID: Mainnet, Name: "othernet"},
+			wantErr: true,
+		}, {
+			name:    "taken name",
+			info:    NetworkInfo{ID: 101, Name: "mainnet"},
+			wantErr: true,
+		}, {
+			name:    "empty identifier",
+			info:    NetworkInfo{Name: "emptynet"},
+			wantErr: true,
+		}, {
+			name:    "empty name",
+			info:    NetworkInfo{ID: 102},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterNetwork(tt.info)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.info.ID, NewNetwork(tt.info.Name))
+			assert.Equal(t, tt.info.Name, tt.info.ID.String())
+
+			network, ok := NetworkByChainID(tt.info.ChainID)
+			assert.True(t, ok)
+			assert.Equal(t, tt.info.ID, network)
+		})
+	}
+}
+
+func TestNetwork_JSON(t *testing.T) {
+	assert.NoError(t, RegisterNetwork(NetworkInfo{ID: 110, Name: "jsonnet"}))
+
+	tests := []struct {
+		name    string
+		network Network
+		data    string
+		wantErr bool
+	}{
+		{
+			name:    "mainnet",
+			network: Mainnet,
+			data:    `"mainnet"`,
+		}, {
+			name:    "registered network",
+			network: 110,
+			data:    `"jsonnet"`,
+		}, {
+			name:    "unknown network",
+			network: 111,
+			data:    `"unknownnet"`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.network.MarshalJSON()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.data, string(data))
+			}
+
+			var network Network
+			err = network.UnmarshalJSON([]byte(tt.data))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.network, network)
+		})
+	}
+}
+
+func TestNextRuntimeNetwork(t *testing.T) {
+	tests := []struct {
+		name  string
+		maxID Network
+		want  Network
+	}{
+		{
+			name:  "empty registry",
+			maxID: Empty,
+			want:  RuntimeNetworksStart,
+		}, {
+			name:  "only default networks",
+			maxID: Ithacanet,
+			want:  RuntimeNetworksStart,
+		}, {
+			name:  "runtime networks registered",
+			maxID: RuntimeNetworksStart + 2,
+			want:  RuntimeNetworksStart + 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NextRuntimeNetwork(tt.maxID))
+		})
+	}
+}
@@ -16,6 +16,10 @@ func NewOperationKind(value string) OperationKind {
 		return OperationKindDelegation
 	case "register_global_constant":
 		return OperationKindRegisterGlobalConstant
+	case "reveal":
+		return OperationKindReveal
+	case "set_deposits_limit":
+		return OperationKindSetDepositsLimit
 	default:
 		return 0
 	}
@@ -34,6 +38,10 @@ func (kind OperationKind) String() string {
 		return "delegation"
 	case OperationKindRegisterGlobalConstant:
 		return "register_global_constant"
+	case OperationKindReveal:
+		return "reveal"
+	case OperationKindSetDepositsLimit:
+		return "set_deposits_limit"
 	default:
 		return ""
 	}
@@ -45,4 +53,6 @@ const (
 	OperationKindOriginationNew
 	OperationKindDelegation
 	OperationKindRegisterGlobalConstant
+	OperationKindReveal
+	OperationKindSetDepositsLimit
 )
@@ -0,0 +1,46 @@
+package types
+
+// SaplingDiffKind -
+type SaplingDiffKind int
+
+// NewSaplingDiffKind -
+func NewSaplingDiffKind(value string) SaplingDiffKind {
+	switch value {
+	case SaplingDiffKindStringAlloc:
+		return SaplingDiffKindAlloc
+	case SaplingDiffKindStringCommitment:
+		return SaplingDiffKindCommitment
+	case SaplingDiffKindStringNullifier:
+		return SaplingDiffKindNullifier
+	default:
+		return 0
+	}
+}
+
+// String -
+func (kind SaplingDiffKind) String() string {
+	switch kind {
+	case SaplingDiffKindAlloc:
+		return SaplingDiffKindStringAlloc
+	case SaplingDiffKindCommitment:
+		return SaplingDiffKindStringCommitment
+	case SaplingDiffKindNullifier:
+		return SaplingDiffKindStringNullifier
+	default:
+		return ""
+	}
+}
+
+// int values
+const (
+	SaplingDiffKindAlloc SaplingDiffKind = iota + 1
+	SaplingDiffKindCommitment
+	SaplingDiffKindNullifier
+)
+
+// string values
+const (
+	SaplingDiffKindStringAlloc      = "alloc"
+	SaplingDiffKindStringCommitment = "commitment"
+	SaplingDiffKindStringNullifier  = "nullifier"
+)
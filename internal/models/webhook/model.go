@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/go-pg/pg/v10"
+)
+
+// Watch mask flags
+const (
+	WatchTransactions uint = 1 << iota
+	WatchTransfers
+	WatchBigMaps
+
+	WatchAll = WatchTransactions | WatchTransfers | WatchBigMaps
+)
+
+// Subscription - registered URL which receives notifications about activity of address
+type Subscription struct {
+	// nolint
+	tableName struct{} `pg:"webhook_subscriptions"`
+
+	ID        int64
+	Network   types.Network `pg:",type:SMALLINT,notnull,unique:webhook_subscription,use_zero"`
+	Address   string        `pg:",notnull,unique:webhook_subscription"`
+	URL       string        `pg:",notnull,unique:webhook_subscription"`
+	Secret    string
+	Alias     string
+	WatchMask uint      `pg:",use_zero"`
+	CreatedAt time.Time `pg:"default:now()"`
+}
+
+// GetID -
+func (s *Subscription) GetID() int64 {
+	return s.ID
+}
+
+// GetIndex -
+func (s *Subscription) GetIndex() string {
+	return "webhook_subscriptions"
+}
+
+// Save -
+func (s *Subscription) Save(tx pg.DBI) error {
+	_, err := tx.Model(s).
+		OnConflict("(network, address, url) DO UPDATE").
+		Set("secret = EXCLUDED.secret, alias = EXCLUDED.alias, watch_mask = EXCLUDED.watch_mask").
+		Returning("id").
+		Insert()
+	return err
+}
+
+// Watches - returns true if subscription watches all events of `flag`
+func (s Subscription) Watches(flag uint) bool {
+	return s.WatchMask&flag == flag
+}
+
+// DeadLetter - notification which was not delivered after all retries
+type DeadLetter struct {
+	// nolint
+	tableName struct{} `pg:"webhook_dead_letters"`
+
+	ID             int64
+	SubscriptionID int64  `pg:",notnull"`
+	Event          string `pg:",notnull"`
+	Payload        string `pg:",type:jsonb"`
+	Error          string
+	Attempts       int64     `pg:",use_zero"`
+	CreatedAt      time.Time `pg:"default:now()"`
+}
+
+// GetID -
+func (d *DeadLetter) GetID() int64 {
+	return d.ID
+}
+
+// GetIndex -
+func (d *DeadLetter) GetIndex() string {
+	return "webhook_dead_letters"
+}
+
+// Save -
+func (d *DeadLetter) Save(tx pg.DBI) error {
+	_, err := tx.Model(d).Returning("id").Insert()
+	return err
+}
+
+// Delivery - notification which is waiting to be sent. Deliveries of subscription are sent one by one in order of identifiers.
+type Delivery struct {
+	// nolint
+	tableName struct{} `pg:"webhook_deliveries"`
+
+	ID             int64
+	SubscriptionID int64  `pg:",notnull"`
+	Event          string `pg:",notnull"`
+	Payload        string `pg:",type:jsonb"`
+	Error          string
+	Attempts       int64     `pg:",use_zero"`
+	NextAttemptAt  time.Time `pg:"default:now()"`
+	CreatedAt      time.Time `pg:"default:now()"`
+}
+
+// GetID -
+func (d *Delivery) GetID() int64 {
+	return d.ID
+}
+
+// GetIndex -
+func (d *Delivery) GetIndex() string {
+	return "webhook_deliveries"
+}
+
+// Save -
+func (d *Delivery) Save(tx pg.DBI) error {
+	_, err := tx.Model(d).Returning("id").Insert()
+	return err
+}
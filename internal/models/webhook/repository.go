@@ -0,0 +1,22 @@
+package webhook
+
+import "github.com/baking-bad/bcdhub/internal/models/types"
+
+// Repository -
+type Repository interface {
+	Get(id int64) (Subscription, error)
+	List(network types.Network, address string, size, offset int64) ([]Subscription, error)
+	ByNetwork(network types.Network) ([]Subscription, error)
+	Create(subscription *Subscription) error
+	Update(subscription *Subscription) error
+	Delete(id int64) error
+
+	SaveDeadLetter(letter *DeadLetter) error
+	DeadLetters(subscriptionID, size, offset int64) ([]DeadLetter, error)
+
+	SaveDeliveries(deliveries []Delivery) error
+	DueDeliveries(limit int64) ([]Delivery, error)
+	Delivered(id int64) error
+	Postpone(delivery *Delivery) error
+	MoveToDeadLetters(delivery Delivery) error
+}
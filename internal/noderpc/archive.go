@@ -0,0 +1,206 @@
+package noderpc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	stdJSON "encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const replayURL = "http://replay/"
+
+// Archive - directory with recorded node responses. Response of GET request is stored in `{dir}/{request path}.json`,
+// response of POST request is stored in `{dir}/{request path}/{hash of request body}.json`. Query string of request is appended to the path after `@`.
+// Responses of `head` requests are overwritten by every new record, so replay returns the last recorded head.
+type Archive struct {
+	dir string
+}
+
+// Record - recorded node response. JSON body is stored as is, other bodies (e.g. plain text errors) are stored as string.
+type Record struct {
+	StatusCode int                `json:"status_code"`
+	Body       stdJSON.RawMessage `json:"body,omitempty"`
+	Text       string             `json:"text,omitempty"`
+}
+
+// NewRecord -
+func NewRecord(statusCode int, body []byte) Record {
+	record := Record{StatusCode: statusCode}
+	if json.Valid(body) {
+		record.Body = body
+	} else {
+		record.Text = string(body)
+	}
+	return record
+}
+
+// Data - returns recorded response body
+func (r Record) Data() []byte {
+	if len(r.Body) > 0 {
+		return r.Body
+	}
+	return []byte(r.Text)
+}
+
+// NewArchive -
+func NewArchive(dir string) *Archive {
+	return &Archive{dir}
+}
+
+// Get - returns recorded response or `ErrNotRecorded`
+func (a *Archive) Get(key string) (Record, error) {
+	var record Record
+	data, err := ioutil.ReadFile(a.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return record, errors.Wrap(ErrNotRecorded, key)
+		}
+		return record, err
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, errors.Wrap(err, key)
+	}
+	return record, nil
+}
+
+// Put - saves response. File is replaced atomically, so archive can be written concurrently.
+func (a *Archive) Put(key string, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	name := a.path(key)
+	if err := os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".record-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+func (a *Archive) path(key string) string {
+	return filepath.Join(a.dir, filepath.FromSlash(key)+".json")
+}
+
+// archiveKey - returns key of request relative to node base path
+func archiveKey(basePath string, req *http.Request) (string, error) {
+	key := strings.Trim(strings.TrimPrefix(path.Clean("/"+req.URL.Path), path.Clean("/"+basePath)), "/")
+	if query := req.URL.Query(); len(query) > 0 {
+		// `Encode` sorts parameters by name and escapes slashes, so the same query is always stored in the same file
+		key = fmt.Sprintf("%s@%s", key, query.Encode())
+	}
+	if req.Method != http.MethodPost || req.Body == nil {
+		return key, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return "", err
+	}
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.Sum256(body)
+	return path.Join(key, hex.EncodeToString(hash[:])), nil
+}
+
+// recorder - http transport which saves node responses with their status codes to archive
+type recorder struct {
+	next     http.RoundTripper
+	archive  *Archive
+	basePath string
+}
+
+// RoundTrip -
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := archiveKey(r.basePath, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.archive.Put(key, NewRecord(resp.StatusCode, data)); err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return resp, nil
+}
+
+// replayer - http transport which serves responses from archive without requests to node
+type replayer struct {
+	archive *Archive
+}
+
+// RoundTrip -
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := archiveKey("", req)
+	if err != nil {
+		return nil, err
+	}
+	record, err := r.archive.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	data := record.Data()
+	contentType := "application/json"
+	if len(record.Body) == 0 {
+		contentType = "text/plain"
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", record.StatusCode, http.StatusText(record.StatusCode)),
+		StatusCode:    record.StatusCode,
+		Proto:         req.Proto,
+		ProtoMajor:    req.ProtoMajor,
+		ProtoMinor:    req.ProtoMinor,
+		Header:        http.Header{"Content-Type": []string{contentType}},
+		Body:          ioutil.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+// NewReplayNode - creates node which serves responses recorded by `WithRecorder` option. Request which wasn't recorded fails with `ErrNotRecorded`.
+func NewReplayNode(archive *Archive) *NodeRPC {
+	node := NewNodeRPC(replayURL, WithRetryCount(1))
+	node.client.Transport = &replayer{archive}
+	return node
+}
+
+func basePath(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
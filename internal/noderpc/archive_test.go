@@ -0,0 +1,206 @@
+package noderpc
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive_recordAndReplay(t *testing.T) {
+	responses := map[string]string{
+		"/mainnet/chains/main/blocks/head/header":                          `{"level":101,"protocol":"PtHangz2aRngywmSRGGvrcTyMbbdpWdpFKuS4uMWxg2RaH9i1qx","chain_id":"NetXdQprcVkpaWU","hash":"BLhead","predecessor":"BL100"}`,
+		"/mainnet/chains/main/blocks/100/header":                           `{"level":100,"protocol":"PtHangz2aRngywmSRGGvrcTyMbbdpWdpFKuS4uMWxg2RaH9i1qx","chain_id":"NetXdQprcVkpaWU","hash":"BL100","predecessor":"BL99"}`,
+		"/mainnet/chains/main/blocks/100/operations/3":                     `[{"protocol":"PtHangz2aRngywmSRGGvrcTyMbbdpWdpFKuS4uMWxg2RaH9i1qx","hash":"oo1","contents":[{"kind":"transaction","source":"tz1","destination":"KT1","metadata":{"balance_updates":[]}}]}]`,
+		"/mainnet/chains/main/blocks/head/helpers/scripts/run_script_view": `{"data":{"int":"1"}}`,
+	}
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/mainnet/chains/main/blocks/300/header" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Internal error")) //nolint
+			return
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(response)) //nolint
+	}))
+	defer server.Close()
+
+	archive := NewArchive(t.TempDir())
+	node := NewNodeRPC(server.URL+"/mainnet", WithRecorder(archive))
+
+	head, err := node.GetHead()
+	require.NoError(t, err)
+	header, err := node.GetHeader(100)
+	require.NoError(t, err)
+	opg, err := node.GetLightOPG(100)
+	require.NoError(t, err)
+	view, err := node.RunScriptView("KT1", "view", []byte(`{"int":"1"}`), "", "", "", 0)
+	require.NoError(t, err)
+	_, notFoundErr := node.GetHeader(200)
+	require.Error(t, notFoundErr)
+	_, internalErr := node.GetHeader(300)
+	require.Error(t, internalErr)
+
+	requestsCount := requests
+	replay := NewReplayNode(archive)
+
+	replayHead, err := replay.GetHead()
+	require.NoError(t, err)
+	assert.Equal(t, head, replayHead)
+
+	replayHeader, err := replay.GetHeader(100)
+	require.NoError(t, err)
+	assert.Equal(t, header, replayHeader)
+
+	replayOPG, err := replay.GetLightOPG(100)
+	require.NoError(t, err)
+	assert.Equal(t, opg, replayOPG)
+
+	replayView, err := replay.RunScriptView("KT1", "view", []byte(`{"int":"1"}`), "", "", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, view, replayView)
+
+	_, err = replay.RunScriptView("KT1", "view", []byte(`{"int":"2"}`), "", "", "", 0)
+	assert.True(t, errors.Is(err, ErrNotRecorded), err)
+
+	_, err = replay.GetHeader(200)
+	assert.Equal(t, InvalidStatusCodeError{Code: http.StatusNotFound}, err)
+	assert.Equal(t, notFoundErr, err)
+
+	_, err = replay.GetHeader(300)
+	require.Error(t, err)
+	assert.Equal(t, internalErr.Error(), err.Error())
+
+	_, err = replay.GetHeader(400)
+	assert.True(t, errors.Is(err, ErrNotRecorded), err)
+
+	assert.Equal(t, requestsCount, requests, "replay must not send requests to node")
+}
+
+func TestArchive_Put(t *testing.T) {
+	archive := NewArchive(t.TempDir())
+
+	require.NoError(t, archive.Put("chains/main/blocks/head/header", NewRecord(http.StatusOK, []byte(`{"level":1}`))))
+	require.NoError(t, archive.Put("chains/main/blocks/head/header", NewRecord(http.StatusOK, []byte(`{"level":2}`))))
+	require.NoError(t, archive.Put("chains/main/blocks/head", NewRecord(http.StatusOK, []byte(`{}`))))
+	require.NoError(t, archive.Put("chains/main/blocks/head/context", NewRecord(http.StatusNotFound, []byte(`Not found`))))
+
+	record, err := archive.Get("chains/main/blocks/head/header")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, record.StatusCode)
+	assert.Equal(t, `{"level":2}`, string(record.Data()))
+
+	record, err = archive.Get("chains/main/blocks/head/context")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, record.StatusCode)
+	assert.Equal(t, `Not found`, string(record.Data()))
+
+	files, err := ioutil.ReadDir(archive.dir + "/chains/main/blocks/head")
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "temporary files must be removed")
+}
+
+func TestArchive_recordQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"query":"` + r.URL.RawQuery + `"}`)) //nolint
+	}))
+	defer server.Close()
+
+	archive := NewArchive(t.TempDir())
+	record := &http.Client{Transport: &recorder{http.DefaultTransport, archive, "/mainnet"}}
+	replay := &http.Client{Transport: &replayer{archive}}
+
+	for _, query := range []string{"b=2&a=1", "a=2"} {
+		resp, err := record.Get(server.URL + "/mainnet/chains/main/mempool/monitor_operations?" + query)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	tests := []struct {
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{
+			query: "a=1&b=2",
+			want:  `{"query":"b=2&a=1"}`,
+		}, {
+			query: "a=2",
+			want:  `{"query":"a=2"}`,
+		}, {
+			query:   "a=3",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp, err := replay.Get(replayURL + "chains/main/mempool/monitor_operations?" + tt.query)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrNotRecorded), err)
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			data, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+}
+
+func TestArchiveKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		basePath string
+		method   string
+		url      string
+		body     string
+		want     string
+	}{
+		{
+			name:     "get",
+			basePath: "/mainnet",
+			method:   http.MethodGet,
+			url:      "http://node/mainnet/chains/main/blocks/head/header",
+			want:     "chains/main/blocks/head/header",
+		}, {
+			name:     "get with query",
+			basePath: "/mainnet",
+			method:   http.MethodGet,
+			url:      "http://node/mainnet/chains/main/mempool/monitor_operations?refused=true&applied=true",
+			want:     "chains/main/mempool/monitor_operations@applied=true&refused=true",
+		}, {
+			name:   "slash in query is escaped",
+			method: http.MethodGet,
+			url:    "http://node/chains/main/blocks/head?path=../../etc",
+			want:   "chains/main/blocks/head@path=..%2F..%2Fetc",
+		}, {
+			name:   "post",
+			method: http.MethodPost,
+			url:    "http://node/chains/main/blocks/head/helpers/scripts/run_code",
+			body:   `{}`,
+			want:   "chains/main/blocks/head/helpers/scripts/run_code/44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			got, err := archiveKey(tt.basePath, req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
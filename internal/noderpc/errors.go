@@ -3,6 +3,7 @@ package noderpc
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 )
 
@@ -79,4 +80,26 @@ func (e InvalidNodeResponse) Error() string {
 // Errors
 var (
 	ErrInvalidStatusCode = errors.New("invalid status code")
+	ErrNotRecorded       = errors.New("response is not recorded")
 )
+
+// InvalidStatusCodeError - node responded with unexpected status code
+type InvalidStatusCodeError struct {
+	Code int
+}
+
+// Error -
+func (e InvalidStatusCodeError) Error() string {
+	return fmt.Sprintf("%s: %d", ErrInvalidStatusCode.Error(), e.Code)
+}
+
+// Is -
+func (e InvalidStatusCodeError) Is(target error) bool {
+	return target == ErrInvalidStatusCode
+}
+
+// IsUnknownRPC - returns true if node doesn't support requested RPC endpoint
+func IsUnknownRPC(err error) bool {
+	var e InvalidStatusCodeError
+	return errors.As(err, &e) && e.Code == http.StatusNotFound
+}
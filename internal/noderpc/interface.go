@@ -14,9 +14,13 @@ type INode interface {
 	GetContractsByBlock(int64) ([]string, error)
 	GetNetworkConstants(int64) (Constants, error)
 	RunCode([]byte, []byte, []byte, string, string, string, string, string, int64, int64) (RunCodeResponse, error)
+	RunScriptView(string, string, []byte, string, string, string, int64) (RunScriptViewResponse, error)
 	RunOperation(string, string, string, string, int64, int64, int64, int64, int64, []byte) (OperationGroup, error)
 	RunOperationLight(string, string, string, string, int64, int64, int64, int64, int64, []byte) (LightOperationGroup, error)
+	RunOperationGroup(chainID, branch string, contents []RunOperationContent) (LightOperationGroup, error)
+	TypeCheckData(data, typ []byte) error
 	GetCounter(string) (int64, error)
 	GetBigMapType(ptr, level int64) (BigMap, error)
+	GetBigMapValue(ptr int64, keyHash string, level int64) ([]byte, error)
 	GetBlockMetadata(level int64) (metadata Metadata, err error)
 }
@@ -212,6 +212,21 @@ func (mr *MockINodeMockRecorder) RunCode(arg0, arg1, arg2, arg3, arg4, arg5, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunCode", reflect.TypeOf((*MockINode)(nil).RunCode), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9)
 }
 
+// RunScriptView mocks base method
+func (m *MockINode) RunScriptView(arg0, arg1 string, arg2 []byte, arg3, arg4, arg5 string, arg6 int64) (RunScriptViewResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunScriptView", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].(RunScriptViewResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunScriptView indicates an expected call of RunScriptView
+func (mr *MockINodeMockRecorder) RunScriptView(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunScriptView", reflect.TypeOf((*MockINode)(nil).RunScriptView), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
 // RunOperation mocks base method
 func (m *MockINode) RunOperation(arg0, arg1, arg2, arg3 string, arg4, arg5, arg6, arg7, arg8 int64, arg9 []byte) (OperationGroup, error) {
 	m.ctrl.T.Helper()
@@ -242,6 +257,35 @@ func (mr *MockINodeMockRecorder) RunOperationLight(arg0, arg1, arg2, arg3, arg4,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunOperationLight", reflect.TypeOf((*MockINode)(nil).RunOperationLight), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9)
 }
 
+// RunOperationGroup mocks base method
+func (m *MockINode) RunOperationGroup(arg0, arg1 string, arg2 []RunOperationContent) (LightOperationGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunOperationGroup", arg0, arg1, arg2)
+	ret0, _ := ret[0].(LightOperationGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunOperationGroup indicates an expected call of RunOperationGroup
+func (mr *MockINodeMockRecorder) RunOperationGroup(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunOperationGroup", reflect.TypeOf((*MockINode)(nil).RunOperationGroup), arg0, arg1, arg2)
+}
+
+// TypeCheckData mocks base method
+func (m *MockINode) TypeCheckData(arg0, arg1 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TypeCheckData", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TypeCheckData indicates an expected call of TypeCheckData
+func (mr *MockINodeMockRecorder) TypeCheckData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TypeCheckData", reflect.TypeOf((*MockINode)(nil).TypeCheckData), arg0, arg1)
+}
+
 // GetCounter mocks base method
 func (m *MockINode) GetCounter(arg0 string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -272,6 +316,21 @@ func (mr *MockINodeMockRecorder) GetBigMapType(ptr, level interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBigMapType", reflect.TypeOf((*MockINode)(nil).GetBigMapType), ptr, level)
 }
 
+// GetBigMapValue mocks base method
+func (m *MockINode) GetBigMapValue(ptr int64, keyHash string, level int64) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBigMapValue", ptr, keyHash, level)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBigMapValue indicates an expected call of GetBigMapValue
+func (mr *MockINodeMockRecorder) GetBigMapValue(ptr, keyHash, level interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBigMapValue", reflect.TypeOf((*MockINode)(nil).GetBigMapValue), ptr, keyHash, level)
+}
+
 // GetBlockMetadata mocks base method
 func (m *MockINode) GetBlockMetadata(level int64) (Metadata, error) {
 	m.ctrl.T.Helper()
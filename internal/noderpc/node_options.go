@@ -18,3 +18,10 @@ func WithRetryCount(retryCount int) NodeOption {
 		node.retryCount = retryCount
 	}
 }
+
+// WithRecorder - saves node responses to archive
+func WithRecorder(archive *Archive) NodeOption {
+	return func(node *NodeRPC) {
+		node.archive = archive
+	}
+}
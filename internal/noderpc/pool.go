@@ -1,273 +1,553 @@
 package noderpc
 
 import (
-	"math/rand"
-	"reflect"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/telemetry"
 	"github.com/pkg/errors"
 )
 
-// Pool - node pool
-type Pool []*poolItem
+// Pool defaults
+const (
+	DefaultMaxLag            = 3
+	DefaultFailureThreshold  = 3
+	DefaultCooldown          = time.Minute
+	DefaultHealthCheckPeriod = time.Second * 15
+)
+
+// ErrNoAvailableNodes -
+var ErrNoAvailableNodes = errors.New("no available nodes")
+
+// Pool - node pool which implements `INode`. It tracks head level and latency of each node,
+// prefers fast nodes which are not lagging and disables nodes failing in a row (circuit breaker).
+// Idempotent (GET) calls are retried on the next node if node is unavailable.
+type Pool struct {
+	nodes []*poolNode
 
-type poolItem struct {
-	node      *NodeRPC
-	blockTime time.Time
+	maxLag            int64
+	failureThreshold  int
+	cooldown          time.Duration
+	healthCheckPeriod time.Duration
+
+	lastCheck int64
+	checking  int32
 }
 
-func newPoolItem(url string, opts ...NodeOption) *poolItem {
-	return &poolItem{
-		node:      NewNodeRPC(url, opts...),
-		blockTime: time.Now(),
+// PoolOption -
+type PoolOption func(*Pool)
+
+// WithMaxLag - count of blocks which node may be behind the highest head of pool before it's considered lagging
+func WithMaxLag(maxLag int64) PoolOption {
+	return func(p *Pool) {
+		if maxLag > 0 {
+			p.maxLag = maxLag
+		}
 	}
 }
 
-func newWaitPoolItem(url string, opts ...NodeOption) *poolItem {
-	return &poolItem{
-		node:      NewWaitNodeRPC(url, opts...),
-		blockTime: time.Now(),
+// WithFailureThreshold - count of failures in a row after which node is disabled for cooldown period
+func WithFailureThreshold(threshold int) PoolOption {
+	return func(p *Pool) {
+		if threshold > 0 {
+			p.failureThreshold = threshold
+		}
 	}
 }
 
-func (p *poolItem) block() {
-	p.blockTime = time.Now().Add(time.Minute * 5)
+// WithCooldown - period while failed node is not requested
+func WithCooldown(cooldown time.Duration) PoolOption {
+	return func(p *Pool) {
+		if cooldown > 0 {
+			p.cooldown = cooldown
+		}
+	}
 }
 
-// func (p *poolItem) isBlocked() bool {
-// 	return time.Now().After(p.blockTime)
-// }
+// WithHealthCheckPeriod - period of heads requests to all nodes. Zero disables background checks.
+func WithHealthCheckPeriod(period time.Duration) PoolOption {
+	return func(p *Pool) {
+		p.healthCheckPeriod = period
+	}
+}
 
-// NewPool - creates `Pool` struct by `urls`
-func NewPool(urls []string, opts ...NodeOption) Pool {
-	pool := make(Pool, len(urls))
+// NewPool - creates `Pool` by `urls`
+func NewPool(urls []string, nodeOpts []NodeOption, opts ...PoolOption) *Pool {
+	nodes := make([]*poolNode, 0, len(urls))
 	for i := range urls {
-		pool[i] = newPoolItem(urls[i], opts...)
+		if urls[i] == "" {
+			continue
+		}
+		nodes = append(nodes, newPoolNode(urls[i], NewNodeRPC(urls[i], nodeOpts...)))
+	}
+	return newPool(nodes, opts...)
+}
+
+// NewWaitPool - creates `Pool` by `urls` and waits until any node is up
+func NewWaitPool(urls []string, nodeOpts []NodeOption, opts ...PoolOption) *Pool {
+	pool := NewPool(urls, nodeOpts, opts...)
+	for {
+		pool.checkHealth()
+		if pool.hasAvailable() {
+			break
+		}
+		logger.Warning().Strs("nodes", pool.hosts()).Msg("Waiting any node up 30 second...")
+		time.Sleep(time.Second * 30)
 	}
 	return pool
 }
 
-// NewWaitPool -
-func NewWaitPool(urls []string, opts ...NodeOption) Pool {
-	pool := make(Pool, len(urls))
-	for i := range urls {
-		pool[i] = newWaitPoolItem(urls[i], opts...)
+func newPool(nodes []*poolNode, opts ...PoolOption) *Pool {
+	pool := &Pool{
+		nodes:             nodes,
+		maxLag:            DefaultMaxLag,
+		failureThreshold:  DefaultFailureThreshold,
+		cooldown:          DefaultCooldown,
+		healthCheckPeriod: DefaultHealthCheckPeriod,
+	}
+	for _, opt := range opts {
+		opt(pool)
 	}
 	return pool
 }
 
-func (p Pool) getNode() (*poolItem, error) {
-	rand.Seed(time.Now().UnixNano())
-	nodes := make([]*poolItem, 0)
-	for i := range p {
-		// if p[i].isBlocked() {
-		// 	nodes = append(nodes, p[i])
-		// }
-		nodes = append(nodes, p[i])
+// NodeStatus - state of pool node
+type NodeStatus struct {
+	URL       string    `json:"url"`
+	Level     int64     `json:"level"`
+	Latency   int64     `json:"latency_ms"`
+	Failures  int       `json:"failures"`
+	Available bool      `json:"available"`
+	Lagging   bool      `json:"lagging"`
+	LastError string    `json:"last_error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Status - returns states of pool nodes. URLs contain only scheme and host.
+func (p *Pool) Status() []NodeStatus {
+	now := time.Now()
+	maxLevel := p.maxLevel()
+
+	result := make([]NodeStatus, len(p.nodes))
+	for i, node := range p.nodes {
+		node.mx.RLock()
+		result[i] = NodeStatus{
+			URL:       node.host,
+			Level:     node.level,
+			Latency:   node.latency.Milliseconds(),
+			Failures:  node.failures,
+			Available: !now.Before(node.openUntil),
+			Lagging:   node.isLagging(maxLevel, p.maxLag),
+			LastError: node.lastError,
+			UpdatedAt: node.updatedAt,
+		}
+		node.mx.RUnlock()
 	}
+	return result
+}
 
-	if len(nodes) == 0 {
-		return nil, errors.Errorf("No availiable nodes")
+type poolNode struct {
+	node INode
+	uri  string
+	host string
+
+	mx        sync.RWMutex
+	level     int64
+	latency   time.Duration
+	failures  int
+	openUntil time.Time
+	lastError string
+	updatedAt time.Time
+}
+
+func newPoolNode(uri string, node INode) *poolNode {
+	host := uri
+	if u, err := url.Parse(uri); err == nil && u.Host != "" {
+		host = u.Scheme + "://" + u.Host
+	}
+	return &poolNode{
+		node: node,
+		uri:  uri,
+		host: host,
 	}
+}
 
-	return nodes[rand.Intn(len(nodes))], nil
+func (n *poolNode) isLagging(maxLevel, maxLag int64) bool {
+	return n.level > 0 && maxLevel-n.level > maxLag
 }
 
-func (p Pool) call(method string, args ...interface{}) (reflect.Value, error) {
-	node, err := p.getNode()
-	if err != nil {
-		return reflect.Value{}, err
+func (n *poolNode) success(latency time.Duration) {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	if n.latency == 0 {
+		n.latency = latency
+	} else {
+		n.latency = (n.latency*4 + latency) / 5
 	}
-	nodeVal := reflect.ValueOf(&node.node)
-	if nodeVal.Kind() == reflect.Ptr {
-		nodeVal = nodeVal.Elem()
+	n.failures = 0
+	n.openUntil = time.Time{}
+	n.lastError = ""
+	n.updatedAt = time.Now()
+}
+
+func (n *poolNode) fail(err error, threshold int, cooldown time.Duration) {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	n.failures++
+	n.lastError = err.Error()
+	n.updatedAt = time.Now()
+	if n.failures >= threshold {
+		n.openUntil = n.updatedAt.Add(cooldown)
+		logger.Warning().Str("node", n.host).Int("failures", n.failures).Msg("node is disabled")
+	}
+}
+
+func (n *poolNode) setLevel(level int64) {
+	n.mx.Lock()
+	n.level = level
+	n.mx.Unlock()
+}
+
+func (p *Pool) maxLevel() int64 {
+	var level int64
+	for _, node := range p.nodes {
+		node.mx.RLock()
+		if node.level > level {
+			level = node.level
+		}
+		node.mx.RUnlock()
 	}
+	return level
+}
 
-	mthd := nodeVal.MethodByName(method)
-	numIn := mthd.Type().NumIn()
-	if numIn != len(args) {
-		return reflect.Value{}, errors.Errorf("Invalid args count: wait %d got %d", numIn, len(args))
+func (p *Pool) hasAvailable() bool {
+	for _, node := range p.nodes {
+		node.mx.RLock()
+		available := node.failures == 0
+		node.mx.RUnlock()
+		if available {
+			return true
+		}
 	}
+	return false
+}
 
-	in := make([]reflect.Value, numIn)
-	for i := range args {
-		in[i] = reflect.ValueOf(args[i])
+func (p *Pool) hosts() []string {
+	hosts := make([]string, len(p.nodes))
+	for i := range p.nodes {
+		hosts[i] = p.nodes[i].host
 	}
+	return hosts
+}
 
-	response := mthd.Call(in)
-	if len(response) != 2 {
-		node.block()
-		return reflect.Value{}, errors.Errorf("Invalid response length: %d", len(response))
+// URL - returns URL of the preferred node: the fastest one which is not lagging and not disabled.
+// It's used for requests which can't be sent through the pool, e.g. streams.
+func (p *Pool) URL() (string, error) {
+	candidates := p.candidates()
+	if len(candidates) == 0 {
+		return "", ErrNoAvailableNodes
 	}
+	return candidates[0].uri, nil
+}
 
-	if !response[1].IsNil() {
-		if IsNodeUnavailiableError(response[1].Interface().(error)) {
-			node.block()
-			return p.call(method, args...)
+// candidates - returns nodes in order of requesting: healthy nodes sorted by latency,
+// then lagging and half-open nodes. Disabled nodes are returned only if there are no others.
+func (p *Pool) candidates() []*poolNode {
+	p.scheduleHealthCheck()
+
+	now := time.Now()
+	maxLevel := p.maxLevel()
+
+	var healthy, reserve, disabled []*poolNode
+	latencies := make(map[*poolNode]time.Duration, len(p.nodes))
+	for _, node := range p.nodes {
+		node.mx.RLock()
+		latencies[node] = node.latency
+		switch {
+		case now.Before(node.openUntil):
+			disabled = append(disabled, node)
+		case node.failures >= p.failureThreshold, node.isLagging(maxLevel, p.maxLag):
+			reserve = append(reserve, node)
+		default:
+			healthy = append(healthy, node)
 		}
-		return response[0], response[1].Interface().(error)
+		node.mx.RUnlock()
 	}
-	return response[0], nil
+
+	sort.SliceStable(healthy, func(i, j int) bool {
+		return latencies[healthy[i]] < latencies[healthy[j]]
+	})
+
+	result := append(healthy, reserve...)
+	if len(result) == 0 {
+		return disabled
+	}
+	return result
 }
 
-// GetHead -
-func (p Pool) GetHead() (Header, error) {
-	data, err := p.call("GetHead")
-	if err != nil {
-		return Header{}, err
+func (p *Pool) scheduleHealthCheck() {
+	if p.healthCheckPeriod <= 0 {
+		return
 	}
-	return data.Interface().(Header), nil
+	last := atomic.LoadInt64(&p.lastCheck)
+	if time.Since(time.Unix(0, last)) < p.healthCheckPeriod {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&p.checking, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&p.checking, 0)
+		p.checkHealth()
+	}()
 }
 
-// GetHeader -
-func (p Pool) GetHeader(block int64) (Header, error) {
-	data, err := p.call("GetHeader", block)
-	if err != nil {
-		return Header{}, err
+// checkHealth - requests heads of all nodes
+func (p *Pool) checkHealth() {
+	var wg sync.WaitGroup
+	for _, node := range p.nodes {
+		wg.Add(1)
+		go func(node *poolNode) {
+			defer wg.Done()
+
+			start := time.Now()
+			head, err := node.node.GetHead()
+			if err != nil {
+				node.fail(err, p.failureThreshold, p.cooldown)
+				return
+			}
+			node.success(time.Since(start))
+			node.setLevel(head.Level)
+		}(node)
 	}
-	return data.Interface().(Header), nil
+	wg.Wait()
+	atomic.StoreInt64(&p.lastCheck, time.Now().UnixNano())
 }
 
-// GetLevel -
-func (p Pool) GetLevel() (int64, error) {
-	data, err := p.call("GetLevel")
-	if err != nil {
-		return 0, err
+// isNodeError - error is caused by node state, so request may succeed on another node
+func isNodeError(err error) bool {
+	return IsNodeUnavailiableError(errors.Cause(err))
+}
+
+// call - calls `fn` on candidate nodes. Idempotent calls are repeated on next node while node is unavailable.
+// Every attempt is observed in `method` metrics of the node.
+func (p *Pool) call(method string, idempotent bool, fn func(node *poolNode) error) error {
+	candidates := p.candidates()
+	if len(candidates) == 0 {
+		return ErrNoAvailableNodes
 	}
-	return data.Int(), nil
+
+	var err error
+	for _, node := range candidates {
+		start := time.Now()
+		err = fn(node)
+		telemetry.ObserveRPC(method, node.host, start, err)
+		if err == nil || !isNodeError(err) {
+			node.success(time.Since(start))
+			return err
+		}
+
+		node.fail(err, p.failureThreshold, p.cooldown)
+		if !idempotent {
+			return err
+		}
+		logger.Warning().Str("node", node.host).Err(err).Msg("request is retried on another node")
+	}
+	return err
+}
+
+// GetHead -
+func (p *Pool) GetHead() (head Header, err error) {
+	err = p.call("GetHead", true, func(node *poolNode) (err error) {
+		head, err = node.node.GetHead()
+		if err == nil {
+			node.setLevel(head.Level)
+		}
+		return
+	})
+	return
+}
+
+// GetHeader -
+func (p *Pool) GetHeader(level int64) (header Header, err error) {
+	err = p.call("GetHeader", true, func(node *poolNode) (err error) {
+		header, err = node.node.GetHeader(level)
+		return
+	})
+	return
 }
 
 // GetScriptJSON -
-func (p Pool) GetScriptJSON(address string, level int64) (Script, error) {
-	data, err := p.call("GetScriptJSON", address, level)
-	if err != nil {
-		return Script{}, err
-	}
-	return data.Interface().(Script), nil
+func (p *Pool) GetScriptJSON(address string, level int64) (script Script, err error) {
+	err = p.call("GetScriptJSON", true, func(node *poolNode) (err error) {
+		script, err = node.node.GetScriptJSON(address, level)
+		return
+	})
+	return
+}
+
+// GetRawScript -
+func (p *Pool) GetRawScript(address string, level int64) (script []byte, err error) {
+	err = p.call("GetRawScript", true, func(node *poolNode) (err error) {
+		script, err = node.node.GetRawScript(address, level)
+		return
+	})
+	return
 }
 
 // GetScriptStorageRaw -
-func (p Pool) GetScriptStorageRaw(address string, level int64) ([]byte, error) {
-	data, err := p.call("GetScriptStorageRaw", address, level)
-	if err != nil {
-		return nil, err
-	}
-	return data.Interface().([]byte), nil
+func (p *Pool) GetScriptStorageRaw(address string, level int64) (storage []byte, err error) {
+	err = p.call("GetScriptStorageRaw", true, func(node *poolNode) (err error) {
+		storage, err = node.node.GetScriptStorageRaw(address, level)
+		return
+	})
+	return
 }
 
 // GetContractBalance -
-func (p Pool) GetContractBalance(address string, level int64) (int64, error) {
-	data, err := p.call("GetContractBalance", address, level)
-	if err != nil {
-		return 0, err
-	}
-	return data.Int(), nil
+func (p *Pool) GetContractBalance(address string, level int64) (balance int64, err error) {
+	err = p.call("GetContractBalance", true, func(node *poolNode) (err error) {
+		balance, err = node.node.GetContractBalance(address, level)
+		return
+	})
+	return
 }
 
 // GetContractData -
-func (p Pool) GetContractData(address string, level int64) (ContractData, error) {
-	data, err := p.call("GetContractData", address, level)
-	if err != nil {
-		return ContractData{}, err
-	}
-	return data.Interface().(ContractData), nil
+func (p *Pool) GetContractData(address string, level int64) (data ContractData, err error) {
+	err = p.call("GetContractData", true, func(node *poolNode) (err error) {
+		data, err = node.node.GetContractData(address, level)
+		return
+	})
+	return
 }
 
 // GetOPG -
-func (p Pool) GetOPG(block int64) ([]OperationGroup, error) {
-	data, err := p.call("GetOPG", block)
-	if err != nil {
-		return nil, err
-	}
-	return data.Interface().([]OperationGroup), nil
+func (p *Pool) GetOPG(block int64) (groups []OperationGroup, err error) {
+	err = p.call("GetOPG", true, func(node *poolNode) (err error) {
+		groups, err = node.node.GetOPG(block)
+		return
+	})
+	return
 }
 
 // GetLightOPG -
-func (p Pool) GetLightOPG(block int64) ([]LightOperationGroup, error) {
-	data, err := p.call("GetLightOPG", block)
-	if err != nil {
-		return nil, err
-	}
-	return data.Interface().([]LightOperationGroup), nil
+func (p *Pool) GetLightOPG(block int64) (groups []LightOperationGroup, err error) {
+	err = p.call("GetLightOPG", true, func(node *poolNode) (err error) {
+		groups, err = node.node.GetLightOPG(block)
+		return
+	})
+	return
 }
 
 // GetContractsByBlock -
-func (p Pool) GetContractsByBlock(block int64) ([]string, error) {
-	data, err := p.call("GetContractsByBlock", block)
-	if err != nil {
-		return nil, err
-	}
-	return data.Interface().([]string), nil
+func (p *Pool) GetContractsByBlock(block int64) (contracts []string, err error) {
+	err = p.call("GetContractsByBlock", true, func(node *poolNode) (err error) {
+		contracts, err = node.node.GetContractsByBlock(block)
+		return
+	})
+	return
 }
 
 // GetNetworkConstants -
-func (p Pool) GetNetworkConstants(level int64) (res Constants, err error) {
-	data, err := p.call("GetNetworkConstants", level)
-	if err != nil {
-		return res, err
-	}
-	return data.Interface().(Constants), nil
+func (p *Pool) GetNetworkConstants(level int64) (constants Constants, err error) {
+	err = p.call("GetNetworkConstants", true, func(node *poolNode) (err error) {
+		constants, err = node.node.GetNetworkConstants(level)
+		return
+	})
+	return
 }
 
-// RunCode -
-func (p Pool) RunCode(script, storage, input []byte, chainID, source, payer, entrypoint, proto string, amount, gas int64) (RunCodeResponse, error) {
-	data, err := p.call("RunCode", script, storage, input, chainID, source, payer, entrypoint, proto, amount, gas)
-	if err != nil {
-		return RunCodeResponse{}, err
-	}
-	return data.Interface().(RunCodeResponse), nil
+// RunCode - POST request isn't repeated on another node
+func (p *Pool) RunCode(script, storage, input []byte, chainID, source, payer, entrypoint, proto string, amount, gas int64) (response RunCodeResponse, err error) {
+	err = p.call("RunCode", false, func(node *poolNode) (err error) {
+		response, err = node.node.RunCode(script, storage, input, chainID, source, payer, entrypoint, proto, amount, gas)
+		return
+	})
+	return
 }
 
-// RunOperation -
-func (p Pool) RunOperation(chainID, branch, source, destination string, fee, gasLimit, storageLimit, counter, amount int64, parameters []byte) (OperationGroup, error) {
-	data, err := p.call("RunOperation", chainID, branch, source, destination, fee, gasLimit, storageLimit, counter, amount, parameters)
-	if err != nil {
-		return OperationGroup{}, err
-	}
-	return data.Interface().(OperationGroup), nil
+// RunScriptView - POST request isn't repeated on another node
+func (p *Pool) RunScriptView(contract, view string, input []byte, chainID, source, payer string, gas int64) (response RunScriptViewResponse, err error) {
+	err = p.call("RunScriptView", false, func(node *poolNode) (err error) {
+		response, err = node.node.RunScriptView(contract, view, input, chainID, source, payer, gas)
+		return
+	})
+	return
 }
 
-// RunOperationLight -
-func (p Pool) RunOperationLight(chainID, branch, source, destination string, fee, gasLimit, storageLimit, counter, amount int64, parameters []byte) (LightOperationGroup, error) {
-	data, err := p.call("RunOperationLight", chainID, branch, source, destination, fee, gasLimit, storageLimit, counter, amount, parameters)
-	if err != nil {
-		return LightOperationGroup{}, err
-	}
-	return data.Interface().(LightOperationGroup), nil
+// RunOperation - POST request isn't repeated on another node
+func (p *Pool) RunOperation(chainID, branch, source, destination string, fee, gasLimit, storageLimit, counter, amount int64, parameters []byte) (group OperationGroup, err error) {
+	err = p.call("RunOperation", false, func(node *poolNode) (err error) {
+		group, err = node.node.RunOperation(chainID, branch, source, destination, fee, gasLimit, storageLimit, counter, amount, parameters)
+		return
+	})
+	return
+}
+
+// RunOperationLight - POST request isn't repeated on another node
+func (p *Pool) RunOperationLight(chainID, branch, source, destination string, fee, gasLimit, storageLimit, counter, amount int64, parameters []byte) (group LightOperationGroup, err error) {
+	err = p.call("RunOperationLight", false, func(node *poolNode) (err error) {
+		group, err = node.node.RunOperationLight(chainID, branch, source, destination, fee, gasLimit, storageLimit, counter, amount, parameters)
+		return
+	})
+	return
+}
+
+// RunOperationGroup - POST request isn't repeated on another node
+func (p *Pool) RunOperationGroup(chainID, branch string, contents []RunOperationContent) (group LightOperationGroup, err error) {
+	err = p.call("RunOperationGroup", false, func(node *poolNode) (err error) {
+		group, err = node.node.RunOperationGroup(chainID, branch, contents)
+		return
+	})
+	return
+}
+
+// TypeCheckData -
+func (p *Pool) TypeCheckData(data, typ []byte) error {
+	return p.call("TypeCheckData", true, func(node *poolNode) error {
+		return node.node.TypeCheckData(data, typ)
+	})
 }
 
 // GetCounter -
-func (p Pool) GetCounter(address string) (int64, error) {
-	data, err := p.call("GetCounter", address)
-	if err != nil {
-		return 0, err
-	}
-	return data.Int(), nil
+func (p *Pool) GetCounter(address string) (counter int64, err error) {
+	err = p.call("GetCounter", true, func(node *poolNode) (err error) {
+		counter, err = node.node.GetCounter(address)
+		return
+	})
+	return
 }
 
 // GetBigMapType -
-func (p Pool) GetBigMapType(ptr, level int64) (BigMap, error) {
-	data, err := p.call("GetBigMapType", ptr, level)
-	if err != nil {
-		return BigMap{}, err
-	}
-	return data.Interface().(BigMap), nil
+func (p *Pool) GetBigMapType(ptr, level int64) (bm BigMap, err error) {
+	err = p.call("GetBigMapType", true, func(node *poolNode) (err error) {
+		bm, err = node.node.GetBigMapType(ptr, level)
+		return
+	})
+	return
 }
 
-// GetBlockMetadata -
-func (p Pool) GetBlockMetadata(level int64) (Metadata, error) {
-	data, err := p.call("GetBlockMetadata", level)
-	if err != nil {
-		return Metadata{}, err
-	}
-	return data.Interface().(Metadata), nil
+// GetBigMapValue -
+func (p *Pool) GetBigMapValue(ptr int64, keyHash string, level int64) (value []byte, err error) {
+	err = p.call("GetBigMapValue", true, func(node *poolNode) (err error) {
+		value, err = node.node.GetBigMapValue(ptr, keyHash, level)
+		return
+	})
+	return
 }
 
-// GetRawScript -
-func (p Pool) GetRawScript(address string, level int64) ([]byte, error) {
-	data, err := p.call("GetRawScript", address, level)
-	if err != nil {
-		return nil, err
-	}
-	return data.Interface().([]byte), nil
+// GetBlockMetadata -
+func (p *Pool) GetBlockMetadata(level int64) (metadata Metadata, err error) {
+	err = p.call("GetBlockMetadata", true, func(node *poolNode) (err error) {
+		metadata, err = node.node.GetBlockMetadata(level)
+		return
+	})
+	return
 }
@@ -15,6 +15,18 @@ type runCodeRequest struct {
 	Entrypoint string             `json:"entrypoint,omitempty"`
 }
 
+type runScriptViewRequest struct {
+	Contract     string             `json:"contract"`
+	View         string             `json:"view"`
+	Input        stdJSON.RawMessage `json:"input"`
+	ChainID      string             `json:"chain_id"`
+	Source       string             `json:"source,omitempty"`
+	Payer        string             `json:"payer,omitempty"`
+	Gas          int64              `json:"gas,string,omitempty"`
+	UnlimitedGas bool               `json:"unlimited_gas"`
+	Mode         string             `json:"unparsing_mode"`
+}
+
 type runOperationRequest struct {
 	ChainID   string           `json:"chain_id"`
 	Operation runOperationItem `json:"operation"`
@@ -37,3 +49,35 @@ type runOperationItemContent struct {
 	Amount       int64              `json:"amount,string"`
 	Parameters   stdJSON.RawMessage `json:"parameters"`
 }
+
+type typeCheckDataRequest struct {
+	Data stdJSON.RawMessage `json:"data"`
+	Type stdJSON.RawMessage `json:"type"`
+}
+
+type runOperationGroupRequest struct {
+	ChainID   string                `json:"chain_id"`
+	Operation runOperationGroupItem `json:"operation"`
+}
+
+type runOperationGroupItem struct {
+	Branch    string                `json:"branch"`
+	Signature string                `json:"signature"`
+	Contents  []RunOperationContent `json:"contents"`
+}
+
+// RunOperationContent - content of simulated operation group. Transactions require `amount` and `destination`, originations require `balance` and `script`.
+type RunOperationContent struct {
+	Kind         string             `json:"kind"`
+	Source       string             `json:"source"`
+	Fee          int64              `json:"fee,string"`
+	Counter      int64              `json:"counter,string"`
+	GasLimit     int64              `json:"gas_limit,string"`
+	StorageLimit int64              `json:"storage_limit,string"`
+	Amount       *int64             `json:"amount,omitempty,string"`
+	Destination  string             `json:"destination,omitempty"`
+	Parameters   stdJSON.RawMessage `json:"parameters,omitempty"`
+	Balance      *int64             `json:"balance,omitempty,string"`
+	Delegate     string             `json:"delegate,omitempty"`
+	Script       stdJSON.RawMessage `json:"script,omitempty"`
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/pkg/errors"
 )
 
 // Header is a header in a block returned by the Tezos RPC API.
@@ -42,6 +43,7 @@ func (slice *Int64StringSlice) UnmarshalJSON(data []byte) error {
 type Constants struct {
 	CostPerByte                  int64            `json:"cost_per_byte,string"`
 	HardGasLimitPerOperation     int64            `json:"hard_gas_limit_per_operation,string"`
+	HardGasLimitPerBlock         int64            `json:"hard_gas_limit_per_block,string"`
 	HardStorageLimitPerOperation int64            `json:"hard_storage_limit_per_operation,string"`
 	TimeBetweenBlocks            Int64StringSlice `json:"time_between_blocks"`
 	MinimalBlockDelay            *int64           `json:"minimal_block_delay,omitempty,string"`
@@ -175,6 +177,7 @@ type OperationResult struct {
 	PaidStorageSizeDiff          *int64             `json:"paid_storage_size_diff,omitempty,string"`
 	AllocatedDestinationContract *bool              `json:"allocated_destination_contract,omitempty"`
 	BigMapDiffs                  []BigMapDiff       `json:"big_map_diff,omitempty"`
+	LazyStorageDiff              []LazyStorageDiff  `json:"lazy_storage_diff,omitempty"`
 	Errors                       stdJSON.RawMessage `json:"errors,omitempty"`
 	GlobalAddress                string             `json:"global_address,omitempty"`
 }
@@ -192,6 +195,46 @@ type BigMapDiff struct {
 	ValueType    stdJSON.RawMessage `json:"value_type,omitempty"`
 }
 
+// LazyStorageDiff -
+type LazyStorageDiff struct {
+	Kind string             `json:"kind"`
+	ID   int64              `json:"id,string"`
+	Diff stdJSON.RawMessage `json:"diff"`
+}
+
+// SaplingStateDiff - `diff` of lazy storage diff with `sapling_state` kind
+type SaplingStateDiff struct {
+	Action   string               `json:"action"`
+	Source   *int64               `json:"source,omitempty,string"`
+	MemoSize *int64               `json:"memo_size,omitempty"`
+	Updates  *SaplingStateUpdates `json:"updates,omitempty"`
+}
+
+// SaplingStateUpdates -
+type SaplingStateUpdates struct {
+	CommitmentsAndCiphertexts []SaplingCommitment `json:"commitments_and_ciphertexts"`
+	Nullifiers                []string            `json:"nullifiers"`
+}
+
+// SaplingCommitment - pair of commitment and its ciphertext
+type SaplingCommitment struct {
+	Commitment string
+	Ciphertext stdJSON.RawMessage
+}
+
+// UnmarshalJSON -
+func (c *SaplingCommitment) UnmarshalJSON(data []byte) error {
+	var pair []stdJSON.RawMessage
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return errors.Errorf("invalid commitment and ciphertext pair length: %d", len(pair))
+	}
+	c.Ciphertext = pair[1]
+	return json.Unmarshal(pair[0], &c.Commitment)
+}
+
 // RunCodeResponse -
 type RunCodeResponse struct {
 	Operations  []Operation        `json:"operations"`
@@ -199,6 +242,11 @@ type RunCodeResponse struct {
 	BigMapDiffs []BigMapDiff       `json:"big_map_diff,omitempty"`
 }
 
+// RunScriptViewResponse -
+type RunScriptViewResponse struct {
+	Data stdJSON.RawMessage `json:"data"`
+}
+
 // RunCodeError -
 type RunCodeError struct {
 	ID string `json:"id"`
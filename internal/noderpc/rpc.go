@@ -35,6 +35,7 @@ type NodeRPC struct {
 
 	timeout    time.Duration
 	retryCount int
+	archive    *Archive
 }
 
 // NewNodeRPC -
@@ -58,6 +59,13 @@ func NewNodeRPC(baseURL string, opts ...NodeOption) *NodeRPC {
 		Timeout:   node.timeout,
 		Transport: t,
 	}
+	if node.archive != nil {
+		node.client.Transport = &recorder{
+			next:     t,
+			archive:  node.archive,
+			basePath: basePath(baseURL),
+		}
+	}
 
 	return node
 }
@@ -95,7 +103,7 @@ func (rpc *NodeRPC) checkStatusCode(resp *http.Response, checkStatusCode bool) e
 	case resp.StatusCode > http.StatusInternalServerError:
 		return NewNodeUnavailiableError(rpc.baseURL, resp.StatusCode)
 	case checkStatusCode:
-		return errors.Wrap(ErrInvalidStatusCode, fmt.Sprintf("%d", resp.StatusCode))
+		return InvalidStatusCodeError{Code: resp.StatusCode}
 	default:
 		return nil
 	}
@@ -114,6 +122,9 @@ func (rpc *NodeRPC) makeRequest(req *http.Request) (*http.Response, error) {
 	for ; count < rpc.retryCount; count++ {
 		resp, err := rpc.client.Do(req)
 		if err != nil {
+			if errors.Is(err, ErrNotRecorded) {
+				return nil, err
+			}
 			logger.Warning().Msgf("Attempt #%d: %s", count+1, err.Error())
 			continue
 		}
@@ -174,6 +185,9 @@ func (rpc *NodeRPC) getRaw(uri string) ([]byte, error) {
 func (rpc *NodeRPC) post(uri string, data interface{}, checkStatusCode bool, response interface{}) error {
 	resp, err := rpc.makePostRequest(uri, data)
 	if err != nil {
+		if errors.Is(err, ErrNotRecorded) {
+			return err
+		}
 		return NewMaxRetryExceededError(rpc.baseURL)
 	}
 	defer resp.Body.Close()
@@ -299,6 +313,24 @@ func (rpc *NodeRPC) RunCode(script, storage, input []byte, chainID, source, paye
 	return
 }
 
+// RunScriptView - executes on-chain view of contract
+func (rpc *NodeRPC) RunScriptView(contract, view string, input []byte, chainID, source, payer string, gas int64) (response RunScriptViewResponse, err error) {
+	request := runScriptViewRequest{
+		Contract:     contract,
+		View:         view,
+		Input:        input,
+		ChainID:      chainID,
+		Source:       source,
+		Payer:        payer,
+		Gas:          gas,
+		UnlimitedGas: gas == 0,
+		Mode:         "Readable",
+	}
+
+	err = rpc.post("chains/main/blocks/head/helpers/scripts/run_script_view", request, true, &response)
+	return
+}
+
 // RunOperation -
 func (rpc *NodeRPC) RunOperation(chainID, branch, source, destination string, fee, gasLimit, storageLimit, counter, amount int64, parameters []byte) (group OperationGroup, err error) {
 	request := runOperationRequest{
@@ -353,6 +385,31 @@ func (rpc *NodeRPC) RunOperationLight(chainID, branch, source, destination strin
 	return
 }
 
+// RunOperationGroup - simulates operation group with several contents. Contents are applied in order, so each of them sees results of the previous ones.
+func (rpc *NodeRPC) RunOperationGroup(chainID, branch string, contents []RunOperationContent) (group LightOperationGroup, err error) {
+	request := runOperationGroupRequest{
+		ChainID: chainID,
+		Operation: runOperationGroupItem{
+			Branch:    branch,
+			Signature: "sigUHx32f9wesZ1n2BWpixXz4AQaZggEtchaQNHYGRCoWNAXx45WGW2ua3apUUUAGMLPwAU41QoaFCzVSL61VaessLg4YbbP", // base58_encode(b'0' * 64, b'sig').decode()
+			Contents:  contents,
+		},
+	}
+
+	err = rpc.post("chains/main/blocks/head/helpers/scripts/run_operation", request, true, &group)
+	return
+}
+
+// TypeCheckData - checks that `data` is well-typed value of `typ`. Node errors are returned as `InvalidNodeResponse`.
+func (rpc *NodeRPC) TypeCheckData(data, typ []byte) error {
+	request := typeCheckDataRequest{
+		Data: data,
+		Type: typ,
+	}
+	var response stdJSON.RawMessage
+	return rpc.post("chains/main/blocks/head/helpers/scripts/typecheck_data", request, true, &response)
+}
+
 // GetCounter -
 func (rpc *NodeRPC) GetCounter(address string) (int64, error) {
 	var counter string
@@ -368,6 +425,19 @@ func (rpc *NodeRPC) GetBigMapType(ptr, level int64) (bm BigMap, err error) {
 	return
 }
 
+// GetBigMapValue - returns value of big map key at the end of `level`. Value is nil if the key is absent.
+func (rpc *NodeRPC) GetBigMapValue(ptr int64, keyHash string, level int64) ([]byte, error) {
+	data, err := rpc.getRaw(fmt.Sprintf("chains/main/blocks/%s/context/big_maps/%d/%s", getBlockString(level), ptr, keyHash))
+	if err != nil {
+		var statusErr InvalidStatusCodeError
+		if errors.As(err, &statusErr) && statusErr.Code == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
 // GetBlockMetadata -
 func (rpc *NodeRPC) GetBlockMetadata(level int64) (metadata Metadata, err error) {
 	err = rpc.get(fmt.Sprintf("chains/main/blocks/%s/metadata", getBlockString(level)), &metadata)
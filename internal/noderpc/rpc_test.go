@@ -0,0 +1,173 @@
+package noderpc
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeRPC_RunOperationGroup(t *testing.T) {
+	amount := int64(0)
+	balance := int64(100)
+	contents := []RunOperationContent{
+		{
+			Kind:         "transaction",
+			Source:       "tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6",
+			Counter:      11,
+			GasLimit:     1040000,
+			StorageLimit: 60000,
+			Amount:       &amount,
+			Destination:  "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn",
+			Parameters:   []byte(`{"entrypoint":"approve","value":{"int":"1"}}`),
+		}, {
+			Kind:         "origination",
+			Source:       "tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6",
+			Counter:      12,
+			GasLimit:     1040000,
+			StorageLimit: 60000,
+			Balance:      &balance,
+			Script:       []byte(`{"code":[],"storage":{"int":"0"}}`),
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/chains/main/blocks/head/helpers/scripts/run_operation", r.URL.Path)
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"chain_id": "NetXdQprcVkpaWU",
+			"operation": {
+				"branch": "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2",
+				"signature": "sigUHx32f9wesZ1n2BWpixXz4AQaZggEtchaQNHYGRCoWNAXx45WGW2ua3apUUUAGMLPwAU41QoaFCzVSL61VaessLg4YbbP",
+				"contents": [
+					{
+						"kind": "transaction",
+						"source": "tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6",
+						"fee": "0",
+						"counter": "11",
+						"gas_limit": "1040000",
+						"storage_limit": "60000",
+						"amount": "0",
+						"destination": "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn",
+						"parameters": {"entrypoint":"approve","value":{"int":"1"}}
+					}, {
+						"kind": "origination",
+						"source": "tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6",
+						"fee": "0",
+						"counter": "12",
+						"gas_limit": "1040000",
+						"storage_limit": "60000",
+						"balance": "100",
+						"script": {"code":[],"storage":{"int":"0"}}
+					}
+				]
+			}
+		}`, string(body))
+
+		_, _ = w.Write([]byte(`{"contents":[{"kind":"transaction","source":"tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6"},{"kind":"origination","source":"tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6"}]}`))
+	}))
+	defer server.Close()
+
+	group, err := NewNodeRPC(server.URL).RunOperationGroup("NetXdQprcVkpaWU", "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2", contents)
+	require.NoError(t, err)
+	if assert.Len(t, group.Contents, 2) {
+		assert.Equal(t, "origination", group.Contents[1].Kind)
+	}
+}
+
+func TestNodeRPC_TypeCheckData(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		status   int
+		response string
+		wantErr  string
+	}{
+		{
+			name:     "well-typed",
+			data:     `{"int":"1"}`,
+			status:   http.StatusOK,
+			response: `{"gas":"1000"}`,
+		}, {
+			name:     "ill-typed",
+			data:     `{"string":"a"}`,
+			status:   http.StatusInternalServerError,
+			response: `[{"kind":"permanent","id":"proto.012-Psithaca.michelson_v1.invalid_constant"}]`,
+			wantErr:  "proto.012-Psithaca.michelson_v1.invalid_constant",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/chains/main/blocks/head/helpers/scripts/typecheck_data", r.URL.Path)
+
+				body, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, `{"data":`+tt.data+`,"type":{"prim":"nat"}}`, string(body))
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			err := NewNodeRPC(server.URL).TypeCheckData([]byte(tt.data), []byte(`{"prim":"nat"}`))
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			var e InvalidNodeResponse
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, tt.wantErr, e.Error())
+		})
+	}
+}
+
+func TestNodeRPC_GetBigMapValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		want     []byte
+		wantErr  bool
+	}{
+		{
+			name:     "existing key",
+			status:   http.StatusOK,
+			response: `{"int":"10"}`,
+			want:     []byte(`{"int":"10"}`),
+		}, {
+			name:   "absent key",
+			status: http.StatusNotFound,
+		}, {
+			name:     "node error",
+			status:   http.StatusInternalServerError,
+			response: `[{"kind":"permanent","id":"failure"}]`,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/chains/main/blocks/100/context/big_maps/15/exprtgHvpVEPbFDDJRZg2JfXSiYjMRu5ck2TKzRxsxhDjGQmrbPBD5", r.URL.Path)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			got, err := NewNodeRPC(server.URL).GetBigMapValue(15, "exprtgHvpVEPbFDDJRZg2JfXSiYjMRu5ck2TKzRxsxhDjGQmrbPBD5", 100)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
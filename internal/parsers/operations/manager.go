@@ -0,0 +1,79 @@
+package operations
+
+import (
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/parsers"
+)
+
+// Manager - parser of manager operations which don't touch contract's code: delegation, reveal, set_deposits_limit
+type Manager struct {
+	*ParseParams
+}
+
+// NewManager -
+func NewManager(params *ParseParams) Manager {
+	return Manager{params}
+}
+
+// Parse -
+func (p Manager) Parse(data noderpc.Operation, result *parsers.Result) error {
+	proto, err := p.ctx.Cache.ProtocolByHash(p.network, p.head.Protocol)
+	if err != nil {
+		return err
+	}
+
+	source := account.Account{
+		Network: p.network,
+		Address: data.Source,
+		Type:    types.NewAccountType(data.Source),
+	}
+
+	op := operation.Operation{
+		Network:      p.network,
+		Hash:         p.hash,
+		ProtocolID:   proto.ID,
+		Level:        p.head.Level,
+		Timestamp:    p.head.Timestamp,
+		Kind:         types.NewOperationKind(data.Kind),
+		Initiator:    source,
+		Source:       source,
+		Fee:          data.Fee,
+		Counter:      data.Counter,
+		GasLimit:     data.GasLimit,
+		StorageLimit: data.StorageLimit,
+		Nonce:        data.Nonce,
+		ContentIndex: p.contentIdx,
+	}
+
+	if data.Delegate != "" {
+		op.Delegate = account.Account{
+			Network: p.network,
+			Address: data.Delegate,
+			Type:    types.NewAccountType(data.Delegate),
+		}
+	}
+
+	p.fillInternal(&op)
+
+	parseOperationResult(data, &op)
+	p.stackTrace.Add(op)
+
+	result.Operations = append(result.Operations, &op)
+	return nil
+}
+
+func (p Manager) fillInternal(tx *operation.Operation) {
+	if p.main == nil {
+		return
+	}
+
+	tx.Counter = p.main.Counter
+	tx.Hash = p.main.Hash
+	tx.Level = p.main.Level
+	tx.Timestamp = p.main.Timestamp
+	tx.Internal = true
+	tx.Initiator = p.main.Source
+}
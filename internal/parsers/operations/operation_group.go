@@ -56,7 +56,8 @@ func (Group) needParse(item noderpc.LightOperation) bool {
 	transactionCondition := item.Kind == consts.Transaction && prefixCondition
 	originationCondition := (item.Kind == consts.Origination || item.Kind == consts.OriginationNew)
 	registerGlobalConstantCondition := item.Kind == consts.RegisterGlobalConstant
-	return originationCondition || transactionCondition || registerGlobalConstantCondition
+	managerCondition := item.Kind == consts.Delegation || item.Kind == consts.Reveal || item.Kind == consts.SetDepositsLimit
+	return originationCondition || transactionCondition || registerGlobalConstantCondition || managerCondition
 }
 
 // Content -
@@ -84,6 +85,10 @@ func (content Content) Parse(data noderpc.Operation, result *parsers.Result) err
 		if err := NewRegisterGlobalConstant(content.ParseParams).Parse(data, result); err != nil {
 			return err
 		}
+	case consts.Delegation, consts.Reveal, consts.SetDepositsLimit:
+		if err := NewManager(content.ParseParams).Parse(data, result); err != nil {
+			return err
+		}
 	default:
 		return nil
 	}
@@ -368,7 +368,34 @@ func TestGroup_Parse(t *testing.T) {
 				WithNetwork(types.Mainnet),
 			},
 			filename: "./data/rpc/opg/opToHHcqFhRTQWJv2oTGAtywucj9KM1nDnk5eHsEETYJyvJLsa5.json",
-			want:     parsers.NewResult(),
+			want: &parsers.Result{
+				Operations: []*operation.Operation{
+					{
+						Kind: types.OperationKindReveal,
+						Source: account.Account{
+							Network: types.Mainnet,
+							Address: "tz1RH7Zy2aJtxBvwCGWWvRzVToNqH7i9pLGK",
+							Type:    types.AccountTypeTz,
+						},
+						Initiator: account.Account{
+							Network: types.Mainnet,
+							Address: "tz1RH7Zy2aJtxBvwCGWWvRzVToNqH7i9pLGK",
+							Type:    types.AccountTypeTz,
+						},
+						Fee:          1420,
+						Counter:      7062180,
+						GasLimit:     10600,
+						StorageLimit: 0,
+						ConsumedGas:  10000,
+						Status:       types.OperationStatusApplied,
+						Level:        1068669,
+						Network:      types.Mainnet,
+						Hash:         "opToHHcqFhRTQWJv2oTGAtywucj9KM1nDnk5eHsEETYJyvJLsa5",
+						Timestamp:    timestamp,
+						ProtocolID:   1,
+					},
+				},
+			},
 		}, {
 			name: "opJXaAMkBrAbd1XFd23kS8vXiw63tU4rLUcLrZgqUCpCbhT1Pn9",
 			rpc:  rpc,
@@ -102,7 +102,7 @@ func NewParseParams(rpc noderpc.INode, ctx *config.Context, opts ...ParseParamsO
 	params.transferParser = transferParser
 
 	params.contractParser = contract.NewParser(params.ctx)
-	storageParser, err := NewRichStorage(ctx.BigMapDiffs, rpc, params.head.Protocol)
+	storageParser, err := NewRichStorage(ctx.BigMapDiffs, ctx.SaplingDiffs, rpc, params.head.Protocol)
 	if err != nil {
 		return nil, err
 	}
@@ -3,6 +3,7 @@ package operations
 import (
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/saplingdiff"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/noderpc"
 	"github.com/baking-bad/bcdhub/internal/parsers"
@@ -18,8 +19,8 @@ type RichStorage struct {
 }
 
 // NewRichStorage -
-func NewRichStorage(repo bigmapdiff.Repository, rpc noderpc.INode, protocol string) (*RichStorage, error) {
-	storageParser, err := storage.MakeStorageParser(repo, rpc, protocol)
+func NewRichStorage(repo bigmapdiff.Repository, saplingRepo saplingdiff.Repository, rpc noderpc.INode, protocol string) (*RichStorage, error) {
+	storageParser, err := storage.MakeStorageParser(repo, saplingRepo, rpc, protocol)
 	if err != nil {
 		return nil, err
 	}
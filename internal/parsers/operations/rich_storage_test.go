@@ -10,6 +10,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/bigmapaction"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	mock_bmd "github.com/baking-bad/bcdhub/internal/models/mock/bigmapdiff"
+	mock_sapling "github.com/baking-bad/bcdhub/internal/models/mock/saplingdiff"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/noderpc"
@@ -25,6 +26,10 @@ func TestRichStorage_Parse(t *testing.T) {
 	defer ctrlBmdRepo.Finish()
 	bmdRepo := mock_bmd.NewMockRepository(ctrlBmdRepo)
 
+	ctrlSaplingRepo := gomock.NewController(t)
+	defer ctrlSaplingRepo.Finish()
+	saplingRepo := mock_sapling.NewMockRepository(ctrlSaplingRepo)
+
 	ctrlRPC := gomock.NewController(t)
 	defer ctrlRPC.Finish()
 	rpc := noderpc.NewMockINode(ctrlRPC)
@@ -290,7 +295,7 @@ func TestRichStorage_Parse(t *testing.T) {
 				return
 			}
 
-			parser, err := NewRichStorage(bmdRepo, rpc, proto)
+			parser, err := NewRichStorage(bmdRepo, saplingRepo, rpc, proto)
 			if err != nil {
 				t.Errorf(`NewRichStorage = error %v`, err)
 				return
@@ -93,10 +93,6 @@ func (p Transaction) Parse(data noderpc.Operation, result *parsers.Result) error
 
 	script, err := p.ctx.Cache.ScriptBytes(tx.Network, tx.Destination.Address, proto.SymLink)
 	if err != nil {
-		if !tx.Internal {
-			return nil
-		}
-
 		for i := range result.Contracts {
 			if tx.Destination.Address == result.Contracts[i].Account.Address {
 				switch proto.SymLink {
@@ -116,6 +112,9 @@ func (p Transaction) Parse(data noderpc.Operation, result *parsers.Result) error
 			}
 		}
 		if script == nil {
+			if !tx.Internal {
+				return nil
+			}
 			return err
 		}
 	}
@@ -0,0 +1,145 @@
+package operations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/cache"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	modelContract "github.com/baking-bad/bcdhub/internal/models/contract"
+	cm "github.com/baking-bad/bcdhub/internal/models/contract_metadata"
+	mock_general "github.com/baking-bad/bcdhub/internal/models/mock"
+	mock_bmd "github.com/baking-bad/bcdhub/internal/models/mock/bigmapdiff"
+	mock_contract "github.com/baking-bad/bcdhub/internal/models/mock/contract"
+	mock_cm "github.com/baking-bad/bcdhub/internal/models/mock/contract_metadata"
+	mock_proto "github.com/baking-bad/bcdhub/internal/models/mock/protocol"
+	mock_token_balance "github.com/baking-bad/bcdhub/internal/models/mock/tokenbalance"
+	"github.com/baking-bad/bcdhub/internal/models/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/parsers"
+	"github.com/go-pg/pg/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction_Parse_ContractOfGroup(t *testing.T) {
+	const (
+		source      = "tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6"
+		destination = "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn"
+		protoHash   = "PtHangzHogokSuiMHemCuowEavgYTP8J5qQ9fQS793MHYFpCY3r"
+	)
+
+	// contract originated by the previous operation of the group (e.g. in simulation), so it isn't stored yet
+	originated := &modelContract.Contract{
+		Account: account.Account{
+			Network: types.Hangzhounet,
+			Address: destination,
+			Type:    types.AccountTypeContract,
+		},
+		Babylon: modelContract.Script{
+			Code:      []byte(`[{"prim":"CDR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]`),
+			Parameter: []byte(`[{"prim":"nat"}]`),
+			Storage:   []byte(`[{"prim":"nat"}]`),
+		},
+	}
+
+	tests := []struct {
+		name       string
+		contracts  []*modelContract.Contract
+		wantScript bool
+	}{
+		{
+			name:       "destination is originated in the group",
+			contracts:  []*modelContract.Contract{originated},
+			wantScript: true,
+		}, {
+			name: "unknown destination",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			generalRepo := mock_general.NewMockGeneralRepository(ctrl)
+			contractRepo := mock_contract.NewMockRepository(ctrl)
+			protoRepo := mock_proto.NewMockRepository(ctrl)
+			cmRepo := mock_cm.NewMockRepository(ctrl)
+			bmdRepo := mock_bmd.NewMockRepository(ctrl)
+			tbRepo := mock_token_balance.NewMockRepository(ctrl)
+			rpc := noderpc.NewMockINode(ctrl)
+
+			protoRepo.EXPECT().Get(types.Hangzhounet, protoHash, int64(-1)).Return(protocol.Protocol{
+				ID:      5,
+				Hash:    protoHash,
+				Network: types.Hangzhounet,
+				SymLink: bcd.SymLinkBabylon,
+			}, nil).AnyTimes()
+			contractRepo.EXPECT().Script(types.Hangzhounet, destination, bcd.SymLinkBabylon).Return(modelContract.Script{}, pg.ErrNoRows).AnyTimes()
+			contractRepo.EXPECT().Get(types.Hangzhounet, destination).Return(modelContract.Contract{}, pg.ErrNoRows).AnyTimes()
+			generalRepo.EXPECT().IsRecordNotFound(pg.ErrNoRows).Return(true).AnyTimes()
+			cmRepo.EXPECT().GetWithEvents(gomock.Any()).Return(make([]cm.ContractMetadata, 0), nil).AnyTimes()
+
+			ctx := &config.Context{
+				Storage:          generalRepo,
+				Contracts:        contractRepo,
+				BigMapDiffs:      bmdRepo,
+				Protocols:        protoRepo,
+				ContractMetadata: cmRepo,
+				TokenBalances:    tbRepo,
+				Cache:            cache.NewCache(nil, nil, contractRepo, protoRepo, cmRepo, nil),
+			}
+			params, err := NewParseParams(rpc, ctx,
+				WithHead(noderpc.Header{
+					Timestamp: time.Now(),
+					Protocol:  protoHash,
+					Level:     100,
+					ChainID:   "NetXuXoGoLxNK6o",
+				}),
+				WithNetwork(types.Hangzhounet),
+			)
+			require.NoError(t, err)
+
+			var data noderpc.Operation
+			require.NoError(t, json.Unmarshal([]byte(`{
+				"kind": "transaction",
+				"source": "`+source+`",
+				"fee": "1000",
+				"counter": "10",
+				"gas_limit": "10000",
+				"storage_limit": "0",
+				"amount": "0",
+				"destination": "`+destination+`",
+				"parameters": {"entrypoint": "default", "value": {"int": "7"}},
+				"metadata": {
+					"operation_result": {
+						"status": "applied",
+						"storage": {"int": "7"},
+						"consumed_gas": "1000"
+					}
+				}
+			}`), &data))
+
+			result := parsers.NewResult()
+			result.Contracts = append(result.Contracts, tt.contracts...)
+
+			require.NoError(t, NewTransaction(params).Parse(data, result))
+			require.Len(t, result.Operations, 1)
+
+			tx := result.Operations[0]
+			assert.False(t, tx.Internal)
+			if !tt.wantScript {
+				assert.Nil(t, tx.Script)
+				assert.Nil(t, tx.AST)
+				return
+			}
+			assert.NotNil(t, tx.Script)
+			assert.NotNil(t, tx.AST)
+			assert.JSONEq(t, `{"int":"7"}`, string(tx.DeffatedStorage))
+		})
+	}
+}
@@ -144,6 +144,9 @@ func (result *Result) saveOperations(tx pg.DBI) error {
 		for j := range result.Operations[i].BigMapActions {
 			result.Operations[i].BigMapActions[j].OperationID = result.Operations[i].ID
 		}
+		for j := range result.Operations[i].SaplingDiffs {
+			result.Operations[i].SaplingDiffs[j].OperationID = result.Operations[i].ID
+		}
 
 		if len(result.Operations[i].BigMapDiffs) > 0 {
 			if _, err := tx.Model(&result.Operations[i].BigMapDiffs).Returning("id").Insert(); err != nil {
@@ -160,6 +163,12 @@ func (result *Result) saveOperations(tx pg.DBI) error {
 				return err
 			}
 		}
+
+		if len(result.Operations[i].SaplingDiffs) > 0 {
+			if _, err := tx.Model(&result.Operations[i].SaplingDiffs).Returning("id").Insert(); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/bigmapaction"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/saplingdiff"
 	"github.com/baking-bad/bcdhub/internal/models/types"
 	"github.com/baking-bad/bcdhub/internal/noderpc"
 	"github.com/baking-bad/bcdhub/internal/parsers"
@@ -15,21 +16,25 @@ import (
 
 // Babylon -
 type Babylon struct {
-	repo bigmapdiff.Repository
-	rpc  noderpc.INode
+	repo        bigmapdiff.Repository
+	saplingRepo saplingdiff.Repository
+	rpc         noderpc.INode
 
-	ptrMap            map[int64]int64
-	temporaryPointers map[int64]*ast.BigMap
+	ptrMap                 map[int64]int64
+	temporaryPointers      map[int64]*ast.BigMap
+	temporarySaplingStates map[int64][]*saplingdiff.SaplingDiff
 }
 
 // NewBabylon -
-func NewBabylon(repo bigmapdiff.Repository, rpc noderpc.INode) *Babylon {
+func NewBabylon(repo bigmapdiff.Repository, saplingRepo saplingdiff.Repository, rpc noderpc.INode) *Babylon {
 	return &Babylon{
-		repo: repo,
-		rpc:  rpc,
+		repo:        repo,
+		saplingRepo: saplingRepo,
+		rpc:         rpc,
 
-		ptrMap:            make(map[int64]int64),
-		temporaryPointers: make(map[int64]*ast.BigMap),
+		ptrMap:                 make(map[int64]int64),
+		temporaryPointers:      make(map[int64]*ast.BigMap),
+		temporarySaplingStates: make(map[int64][]*saplingdiff.SaplingDiff),
 	}
 }
 
@@ -41,7 +46,15 @@ func (b *Babylon) ParseTransaction(content noderpc.Operation, operation *operati
 	}
 	operation.DeffatedStorage = result.Storage
 
-	return b.handleBigMapDiff(result, *content.Destination, operation, result.Storage)
+	res, err := b.handleBigMapDiff(result, *content.Destination, operation, result.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.handleSaplingDiffs(result, *content.Destination, operation); err != nil {
+		return nil, err
+	}
+	return res, nil
 }
 
 // ParseOrigination -
@@ -60,7 +73,15 @@ func (b *Babylon) ParseOrigination(content noderpc.Operation, operation *operati
 
 	operation.DeffatedStorage = scriptData.Storage
 
-	return b.handleBigMapDiff(result, result.Originated[0], operation, scriptData.Storage)
+	res, err := b.handleBigMapDiff(result, result.Originated[0], operation, scriptData.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.handleSaplingDiffs(result, result.Originated[0], operation); err != nil {
+		return nil, err
+	}
+	return res, nil
 }
 
 func (b *Babylon) initPointersTypes(result *noderpc.OperationResult, operation *operation.Operation, data []byte) error {
@@ -4,6 +4,7 @@ import (
 	"github.com/baking-bad/bcdhub/internal/bcd"
 	"github.com/baking-bad/bcdhub/internal/bcd/ast"
 	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/saplingdiff"
 	"github.com/baking-bad/bcdhub/internal/noderpc"
 	"github.com/pkg/errors"
 )
@@ -42,7 +43,7 @@ func EnrichFromState(storage *ast.TypedAst, bmd []bigmapdiff.BigMapState, skipEm
 }
 
 // MakeStorageParser -
-func MakeStorageParser(repo bigmapdiff.Repository, rpc noderpc.INode, protocol string) (Parser, error) {
+func MakeStorageParser(repo bigmapdiff.Repository, saplingRepo saplingdiff.Repository, rpc noderpc.INode, protocol string) (Parser, error) {
 	protoSymLink, err := bcd.GetProtoSymLink(protocol)
 	if err != nil {
 		return nil, err
@@ -50,7 +51,7 @@ func MakeStorageParser(repo bigmapdiff.Repository, rpc noderpc.INode, protocol s
 
 	switch protoSymLink {
 	case bcd.SymLinkBabylon:
-		return NewBabylon(repo, rpc), nil
+		return NewBabylon(repo, saplingRepo, rpc), nil
 	case bcd.SymLinkAlpha:
 		return NewAlpha(), nil
 	default:
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/saplingdiff"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/pkg/errors"
+)
+
+func (b *Babylon) handleSaplingDiffs(result *noderpc.OperationResult, address string, operation *operation.Operation) error {
+	for i := range result.LazyStorageDiff {
+		if result.LazyStorageDiff[i].Kind != consts.SAPLINGSTATE {
+			continue
+		}
+
+		var diff noderpc.SaplingStateDiff
+		if err := json.Unmarshal(result.LazyStorageDiff[i].Diff, &diff); err != nil {
+			return err
+		}
+		ptr := result.LazyStorageDiff[i].ID
+
+		switch diff.Action {
+		case types.BigMapActionStringAlloc:
+			if diff.MemoSize == nil {
+				return errors.Errorf("empty memo size of sapling state %d", ptr)
+			}
+			b.addSaplingDiff(&saplingdiff.SaplingDiff{
+				Kind:     types.SaplingDiffKindAlloc,
+				MemoSize: *diff.MemoSize,
+			}, ptr, address, operation)
+		case types.BigMapActionStringCopy:
+			if diff.Source == nil {
+				return errors.Errorf("empty source of sapling state %d copy", ptr)
+			}
+			source, err := b.getSaplingState(*diff.Source, operation.Network)
+			if err != nil {
+				return err
+			}
+			for j := range source {
+				copied := *source[j]
+				copied.ID = 0
+				b.addSaplingDiff(&copied, ptr, address, operation)
+			}
+		case types.BigMapActionStringRemove:
+			delete(b.temporarySaplingStates, ptr)
+			continue
+		}
+
+		if diff.Updates == nil {
+			continue
+		}
+		for _, update := range diff.Updates.CommitmentsAndCiphertexts {
+			b.addSaplingDiff(&saplingdiff.SaplingDiff{
+				Kind:       types.SaplingDiffKindCommitment,
+				Commitment: update.Commitment,
+				Ciphertext: types.Bytes(update.Ciphertext),
+			}, ptr, address, operation)
+		}
+		for _, nullifier := range diff.Updates.Nullifiers {
+			b.addSaplingDiff(&saplingdiff.SaplingDiff{
+				Kind:      types.SaplingDiffKindNullifier,
+				Nullifier: nullifier,
+			}, ptr, address, operation)
+		}
+	}
+	return nil
+}
+
+func (b *Babylon) addSaplingDiff(diff *saplingdiff.SaplingDiff, ptr int64, address string, operation *operation.Operation) {
+	diff.Ptr = ptr
+	diff.Contract = address
+	diff.Network = operation.Network
+	diff.Level = operation.Level
+	diff.Timestamp = operation.Timestamp
+	diff.ProtocolID = operation.ProtocolID
+	diff.OperationID = operation.ID
+
+	if ptr < 0 {
+		b.temporarySaplingStates[ptr] = append(b.temporarySaplingStates[ptr], diff)
+	} else {
+		operation.SaplingDiffs = append(operation.SaplingDiffs, diff)
+	}
+}
+
+func (b *Babylon) getSaplingState(ptr int64, network types.Network) ([]*saplingdiff.SaplingDiff, error) {
+	if ptr < 0 {
+		state, ok := b.temporarySaplingStates[ptr]
+		if !ok {
+			return nil, errors.Wrapf(ErrUnknownTemporaryPointer, "%d", ptr)
+		}
+		return state, nil
+	}
+
+	diffs, err := b.saplingRepo.GetByPtr(network, ptr)
+	if err != nil {
+		return nil, err
+	}
+	state := make([]*saplingdiff.SaplingDiff, len(diffs))
+	for i := range diffs {
+		state[i] = &diffs[i]
+	}
+	return state, nil
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	mock_sapling "github.com/baking-bad/bcdhub/internal/models/mock/saplingdiff"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/saplingdiff"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBabylon_handleSaplingDiffs(t *testing.T) {
+	timestamp := time.Now()
+	address := "KT1UDc2ZUoAAvv8amw2DqVuQK1fKjb1HmTMp"
+
+	tests := []struct {
+		name    string
+		diffs   string
+		prepare func(repo *mock_sapling.MockRepository)
+		want    []*saplingdiff.SaplingDiff
+		wantErr bool
+	}{
+		{
+			name:  "alloc with updates",
+			diffs: `[{"kind":"sapling_state","id":"15","diff":{"action":"alloc","updates":{"commitments_and_ciphertexts":[["cm1",{"cv":"cv1"}]],"nullifiers":["nf1"]},"memo_size":8}}]`,
+			want: []*saplingdiff.SaplingDiff{
+				{Kind: types.SaplingDiffKindAlloc, MemoSize: 8, Ptr: 15},
+				{Kind: types.SaplingDiffKindCommitment, Commitment: "cm1", Ciphertext: types.Bytes(`{"cv":"cv1"}`), Ptr: 15},
+				{Kind: types.SaplingDiffKindNullifier, Nullifier: "nf1", Ptr: 15},
+			},
+		}, {
+			name:  "copy of temporary state",
+			diffs: `[{"kind":"sapling_state","id":"-1","diff":{"action":"alloc","updates":{"commitments_and_ciphertexts":[["cm1",{"cv":"cv1"}]],"nullifiers":[]},"memo_size":8}},{"kind":"sapling_state","id":"16","diff":{"action":"copy","source":"-1","updates":{"commitments_and_ciphertexts":[],"nullifiers":["nf1"]}}},{"kind":"sapling_state","id":"-1","diff":{"action":"remove"}}]`,
+			want: []*saplingdiff.SaplingDiff{
+				{Kind: types.SaplingDiffKindAlloc, MemoSize: 8, Ptr: 16},
+				{Kind: types.SaplingDiffKindCommitment, Commitment: "cm1", Ciphertext: types.Bytes(`{"cv":"cv1"}`), Ptr: 16},
+				{Kind: types.SaplingDiffKindNullifier, Nullifier: "nf1", Ptr: 16},
+			},
+		}, {
+			name:  "copy of existing state",
+			diffs: `[{"kind":"sapling_state","id":"17","diff":{"action":"copy","source":"15","updates":{"commitments_and_ciphertexts":[],"nullifiers":[]}}}]`,
+			prepare: func(repo *mock_sapling.MockRepository) {
+				repo.EXPECT().GetByPtr(types.Edo2net, int64(15)).Return([]saplingdiff.SaplingDiff{
+					{ID: 1, Kind: types.SaplingDiffKindAlloc, MemoSize: 8, Ptr: 15},
+				}, nil).Times(1)
+			},
+			want: []*saplingdiff.SaplingDiff{
+				{Kind: types.SaplingDiffKindAlloc, MemoSize: 8, Ptr: 17},
+			},
+		}, {
+			name:    "unknown temporary pointer",
+			diffs:   `[{"kind":"sapling_state","id":"17","diff":{"action":"copy","source":"-5","updates":{"commitments_and_ciphertexts":[],"nullifiers":[]}}}]`,
+			wantErr: true,
+		}, {
+			name:  "big map diffs are skipped",
+			diffs: `[{"kind":"big_map","id":"10","diff":{"action":"alloc","updates":[],"key_type":{"prim":"nat"},"value_type":{"prim":"nat"}}}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_sapling.NewMockRepository(ctrl)
+			if tt.prepare != nil {
+				tt.prepare(repo)
+			}
+
+			var result noderpc.OperationResult
+			if err := json.Unmarshal([]byte(`{"lazy_storage_diff":`+tt.diffs+`}`), &result); err != nil {
+				t.Fatal(err)
+			}
+
+			op := &operation.Operation{
+				ID:         100,
+				Network:    types.Edo2net,
+				Level:      1000,
+				Timestamp:  timestamp,
+				ProtocolID: 3,
+			}
+			b := NewBabylon(nil, repo, nil)
+			if err := b.handleSaplingDiffs(&result, address, op); (err != nil) != tt.wantErr {
+				t.Errorf("handleSaplingDiffs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			for i := range tt.want {
+				tt.want[i].Contract = address
+				tt.want[i].Network = op.Network
+				tt.want[i].Level = op.Level
+				tt.want[i].Timestamp = op.Timestamp
+				tt.want[i].ProtocolID = op.ProtocolID
+				tt.want[i].OperationID = op.ID
+			}
+			assert.Equal(t, tt.want, op.SaplingDiffs)
+		})
+	}
+}
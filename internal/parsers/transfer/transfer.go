@@ -34,6 +34,7 @@ type Parser struct {
 
 	rpc        noderpc.INode
 	stackTrace *stacktrace.StackTrace
+	events     *TokenEvents
 
 	network  modelTypes.Network
 	chainID  string
@@ -43,7 +44,10 @@ type Parser struct {
 	withoutViews bool
 }
 
-var globalEvents *TokenEvents
+var (
+	globalEvents   *TokenEvents
+	globalEventsMx sync.Mutex
+)
 
 // NewParser -
 func NewParser(rpc noderpc.INode, cmRepo contract_metadata.Repository, blocks block.Repository, tokenBalances tokenbalance.Repository, accounts account.Repository, opts ...ParserOption) (*Parser, error) {
@@ -67,6 +71,7 @@ func NewParser(rpc noderpc.INode, cmRepo contract_metadata.Repository, blocks bl
 }
 
 func (p *Parser) initialize() {
+	globalEventsMx.Lock()
 	switch {
 	case p.withoutViews && globalEvents == nil:
 		globalEvents = EmptyTokenEvents()
@@ -82,6 +87,9 @@ func (p *Parser) initialize() {
 			logger.Err(err)
 		}
 	}
+	p.events = globalEvents
+	globalEventsMx.Unlock()
+
 	if p.network != modelTypes.Empty && p.chainID == "" {
 		state, err := p.blocks.Last(p.network)
 		if err != nil {
@@ -99,7 +107,7 @@ func (p *Parser) Parse(diffs []*bigmapdiff.BigMapDiff, protocol string, operatio
 
 	p.init.Do(p.initialize)
 
-	if impl, name, ok := globalEvents.GetByOperation(*operation); ok {
+	if impl, name, ok := p.events.GetByOperation(*operation); ok {
 		return p.executeEvents(impl, name, protocol, diffs, operation)
 	}
 
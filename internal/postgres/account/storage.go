@@ -26,6 +26,15 @@ func (storage *Storage) Get(network types.Network, address string) (account acco
 	return
 }
 
+// GetByIDs -
+func (storage *Storage) GetByIDs(ids ...int64) (accounts []account.Account, err error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	err = storage.DB.Model((*account.Account)(nil)).WhereIn("id IN (?)", ids).Select(&accounts)
+	return
+}
+
 // Alias -
 func (storage *Storage) Alias(network types.Network, address string) (alias string, err error) {
 	err = storage.DB.Model((*account.Account)(nil)).
@@ -30,6 +30,12 @@ func (storage *Storage) buildGetContext(ctx bigmapdiff.GetContext) *orm.Query {
 	if ctx.CurrentLevel != nil {
 		query.Where("level = ?", *ctx.CurrentLevel)
 	}
+	if ctx.AtLevel != nil {
+		query.Where("level <= ?", *ctx.AtLevel)
+	}
+	if ctx.LastID != nil {
+		query.Having("max(id) < ?", *ctx.LastID)
+	}
 	if ctx.Query != "" {
 		query.Where("(key_hash LIKE @reg OR array_to_string(key_strings, '|') LIKE @reg)", sql.Named("reg", fmt.Sprintf("%%%s%%", ctx.Query)))
 	}
@@ -67,6 +73,9 @@ func (storage *Storage) buildGetContextForState(ctx bigmapdiff.GetContext) *orm.
 	if ctx.Query != "" {
 		query.Where("(key_hash LIKE @reg)", sql.Named("reg", fmt.Sprintf("%%%s%%", ctx.Query)))
 	}
+	if ctx.LastID != nil {
+		query.Where("id < ?", *ctx.LastID)
+	}
 
 	query.Limit(storage.GetPageSize(ctx.Size))
 
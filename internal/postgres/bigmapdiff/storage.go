@@ -49,6 +49,61 @@ func (storage *Storage) GetForAddress(network types.Network, address string) (re
 	return
 }
 
+// GetForAddressAtLevel -
+func (storage *Storage) GetForAddressAtLevel(network types.Network, address string, level int64) ([]bigmapdiff.BigMapState, error) {
+	var buckets []bigmapdiff.Bucket
+	query := storage.DB.Model().Table(models.DocBigMapDiff).
+		DistinctOn("ptr, key_hash").
+		ColumnExpr("*, count(*) over (partition by ptr, key_hash) as keys_count")
+	core.NetworkAndContract(network, address)(query)
+	if err := query.Where("level <= ?", level).Order("ptr", "key_hash", "id desc").Select(&buckets); err != nil {
+		return nil, err
+	}
+	return bucketsToStates(buckets), nil
+}
+
+// Changes -
+func (storage *Storage) Changes(network types.Network, ptr, fromLevel, toLevel, size, offset int64) (before, after []bigmapdiff.BigMapState, total int64, err error) {
+	inRange := func() *orm.Query {
+		return storage.DB.Model().Table(models.DocBigMapDiff).
+			Where("network = ?", network).
+			Where("ptr = ?", ptr).
+			Where("level > ?", fromLevel).
+			Where("level <= ?", toLevel)
+	}
+
+	if err = inRange().ColumnExpr("count(distinct key_hash)").Select(&total); err != nil || total == 0 {
+		return
+	}
+
+	changed := inRange().
+		Distinct().
+		Column("key_hash").
+		Order("key_hash").
+		Limit(storage.GetPageSize(size)).
+		Offset(int(offset))
+
+	lastDiffs := func(level int64) ([]bigmapdiff.BigMapState, error) {
+		var buckets []bigmapdiff.Bucket
+		err := storage.DB.Model().Table(models.DocBigMapDiff).
+			DistinctOn("key_hash").
+			ColumnExpr("*, count(*) over (partition by key_hash) as keys_count").
+			Where("network = ?", network).
+			Where("ptr = ?", ptr).
+			Where("level <= ?", level).
+			Where("key_hash IN (?)", changed).
+			Order("key_hash", "id desc").
+			Select(&buckets)
+		return bucketsToStates(buckets), err
+	}
+
+	if before, err = lastDiffs(fromLevel); err != nil {
+		return
+	}
+	after, err = lastDiffs(toLevel)
+	return
+}
+
 // GetByAddress -
 func (storage *Storage) GetByAddress(network types.Network, address string) (response []bigmapdiff.BigMapDiff, err error) {
 	query := storage.DB.Model().Table(models.DocBigMapDiff)
@@ -253,19 +308,26 @@ func (storage *Storage) LastDiff(network types.Network, ptr int64, keyHash strin
 
 // Keys -
 func (storage *Storage) Keys(ctx bigmapdiff.GetContext) (states []bigmapdiff.BigMapState, err error) {
-	if ctx.Query == "" {
+	if ctx.Query == "" && ctx.AtLevel == nil {
 		err = storage.buildGetContextForState(ctx).Select(&states)
 	} else {
-		query := storage.DB.Model().ColumnExpr("bmd.*, diff.keys_count").TableExpr("(?) as diff", storage.buildGetContext(ctx)).Join("left join big_map_diffs as bmd on bmd.id  = diff.id")
+		query := storage.DB.Model().ColumnExpr("bmd.*, diff.keys_count").TableExpr("(?) as diff", storage.buildGetContext(ctx)).Join("left join big_map_diffs as bmd on bmd.id  = diff.id").Order("bmd.id desc")
 
 		var bmd []bigmapdiff.Bucket
 		if err := query.Select(&bmd); err != nil {
 			return states, err
 		}
-		states = make([]bigmapdiff.BigMapState, len(bmd))
-		for i := range bmd {
-			states[i] = *bmd[i].ToState()
-		}
+		states = bucketsToStates(bmd)
 	}
 	return
 }
+
+func bucketsToStates(buckets []bigmapdiff.Bucket) []bigmapdiff.BigMapState {
+	states := make([]bigmapdiff.BigMapState, len(buckets))
+	for i := range buckets {
+		states[i] = *buckets[i].ToState()
+		states[i].ID = buckets[i].ID
+		states[i].Count = buckets[i].KeysCount
+	}
+	return states
+}
@@ -53,9 +53,12 @@ func (storage *Storage) LastByNetworks() (response []block.Block, err error) {
 	return
 }
 
-// GetNetworkAlias -
+// GetNetworkAlias - returns name of network by its chain id. Network registry is checked first, indexed blocks are used as fallback.
 func (storage *Storage) GetNetworkAlias(chainID string) (string, error) {
-	var network types.Network
+	network, ok := types.NetworkByChainID(chainID)
+	if ok {
+		return network.String(), nil
+	}
 	err := storage.DB.Model((*block.Block)(nil)).
 		Column("block.network").
 		Where("block.chain_id = ?", chainID).
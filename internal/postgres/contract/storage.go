@@ -300,6 +300,8 @@ func (storage *Storage) ScriptPart(network types.Network, address string, symLin
 			query.Column("alpha.code").Relation("Alpha._")
 		case "storage":
 			query.Column("alpha.storage").Relation("Alpha._")
+		case "views":
+			query.Column("alpha.views").Relation("Alpha._")
 		default:
 			return nil, errors.Errorf("unknown script part name: %s", part)
 		}
@@ -311,6 +313,8 @@ func (storage *Storage) ScriptPart(network types.Network, address string, symLin
 			query.Column("babylon.code").Relation("Babylon._")
 		case "storage":
 			query.Column("babylon.storage").Relation("Babylon._")
+		case "views":
+			query.Column("babylon.views").Relation("Babylon._")
 		default:
 			return nil, errors.Errorf("unknown script part name: %s", part)
 		}
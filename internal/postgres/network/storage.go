@@ -0,0 +1,101 @@
+package network
+
+import (
+	"github.com/baking-bad/bcdhub/internal/models/network"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/pkg/errors"
+)
+
+// Storage -
+type Storage struct {
+	*core.Postgres
+}
+
+// NewStorage -
+func NewStorage(pg *core.Postgres) *Storage {
+	return &Storage{pg}
+}
+
+// CreateTable - creates `networks` table if it does not exist. Registry is read before indexer creates other tables.
+func (storage *Storage) CreateTable() error {
+	return storage.DB.Model((*network.Network)(nil)).CreateTable(&orm.CreateTableOptions{
+		IfNotExists: true,
+	})
+}
+
+// All -
+func (storage *Storage) All() (response []network.Network, err error) {
+	err = storage.DB.Model((*network.Network)(nil)).Order("id asc").Select(&response)
+	return
+}
+
+// GetByName -
+func (storage *Storage) GetByName(name string) (response network.Network, err error) {
+	err = storage.DB.Model(&response).Where("name = ?", name).Limit(1).Select()
+	return
+}
+
+// Register - returns network with `name` and assigns new identifier to it if it's not registered yet.
+// Networks known at build time keep their identifiers, other networks get identifiers from the runtime range (see `types.RuntimeNetworksStart`).
+// Chain id is updated if it was empty.
+func (storage *Storage) Register(name, chainID string) (response network.Network, err error) {
+	err = storage.DB.RunInTransaction(storage.DB.Context(), func(tx *pg.Tx) error {
+		if _, err := tx.Exec("LOCK TABLE networks IN EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+
+		err := tx.Model(&response).Where("name = ?", name).Limit(1).Select()
+		switch {
+		case err == nil:
+			if chainID == "" || response.ChainID == chainID {
+				return nil
+			}
+			response.ChainID = chainID
+			_, err = tx.Model(&response).Set("chain_id = ?", chainID).WherePK().Update()
+			return err
+		case storage.IsRecordNotFound(err):
+		default:
+			return err
+		}
+
+		id, err := storage.nextID(tx, name)
+		if err != nil {
+			return err
+		}
+		response = network.Network{
+			ID:      id,
+			Name:    name,
+			ChainID: chainID,
+		}
+		_, err = tx.Model(&response).Insert()
+		return err
+	})
+	return
+}
+
+func (storage *Storage) nextID(tx *pg.Tx, name string) (int64, error) {
+	for _, info := range types.DefaultNetworks() {
+		if info.Name != name {
+			continue
+		}
+		var taken network.Network
+		err := tx.Model(&taken).Where("id = ?", int64(info.ID)).Limit(1).Select()
+		switch {
+		case err == nil:
+			return 0, errors.Errorf("identifier %d of network %s is already taken by %s", info.ID, name, taken.Name)
+		case storage.IsRecordNotFound(err):
+			return int64(info.ID), nil
+		default:
+			return 0, err
+		}
+	}
+
+	var maxID int64
+	if err := tx.Model((*network.Network)(nil)).ColumnExpr("COALESCE(MAX(id), 0)").Select(&maxID); err != nil {
+		return 0, err
+	}
+	return int64(types.NextRuntimeNetwork(types.Network(maxID))), nil
+}
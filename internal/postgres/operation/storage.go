@@ -35,7 +35,7 @@ func (storage *Storage) getContractOPG(accountID int64, size uint64, filters map
 	subQuery := storage.DB.Model().Table(models.DocOperations).Column("hash", "counter", "id")
 
 	if _, ok := filters["entrypoints"]; !ok {
-		subQuery.Where("source_id = ? OR destination_id = ?", accountID, accountID)
+		subQuery.Where("source_id = ? OR destination_id = ? OR delegate_id = ?", accountID, accountID, accountID)
 	} else {
 		subQuery.Where("destination_id = ?", accountID)
 	}
@@ -68,6 +68,8 @@ func prepareOperationFilters(query *orm.Query, filters map[string]interface{}) e
 				query.Where("id < ?", v)
 			case "status":
 				query.WhereIn("status IN (?)", v)
+			case "kind":
+				query.WhereIn("kind IN (?)", v)
 			default:
 				return errors.Errorf("Unknown operation filter: %s %v", k, v)
 			}
@@ -218,6 +220,22 @@ func (storage *Storage) GetTokensStats(network types.Network, addresses, entrypo
 	return usageStats, nil
 }
 
+// LastAtLevel -
+func (storage *Storage) LastAtLevel(network types.Network, address string, level int64) (op operation.Operation, err error) {
+	err = storage.DB.Model((*operation.Operation)(nil)).
+		Relation("Source.address").
+		Relation("Destination.address").
+		Where("operation.network = ?", network).
+		Where("destination.address = ?", address).
+		Where("operation.level <= ?", level).
+		Where("operation.status = ?", types.OperationStatusApplied).
+		Where("deffated_storage != ''").
+		Order("operation.id desc").
+		Limit(1).
+		Select(&op)
+	return
+}
+
 // GetByIDs -
 func (storage *Storage) GetByIDs(ids ...int64) (result []operation.Operation, err error) {
 	err = storage.DB.Model().Table(models.DocOperations).Where("id IN (?)", pg.In(ids)).Order("id asc").Select(&result)
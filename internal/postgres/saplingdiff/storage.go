@@ -0,0 +1,76 @@
+package saplingdiff
+
+import (
+	"github.com/baking-bad/bcdhub/internal/models"
+	"github.com/baking-bad/bcdhub/internal/models/saplingdiff"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/postgres/consts"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	"github.com/pkg/errors"
+)
+
+// Storage -
+type Storage struct {
+	*core.Postgres
+}
+
+// NewStorage -
+func NewStorage(pg *core.Postgres) *Storage {
+	return &Storage{pg}
+}
+
+// Get - returns page of sapling state's diffs of the kind ordered by appearance
+func (storage *Storage) Get(network types.Network, ptr int64, kind types.SaplingDiffKind, size, offset int64) (response []saplingdiff.SaplingDiff, err error) {
+	if ptr < 0 {
+		err = errors.Wrapf(consts.ErrInvalidPointer, "%d", ptr)
+		return
+	}
+
+	query := storage.DB.Model().Table(models.DocSaplingDiffs).
+		Where("ptr = ?", ptr).
+		Where("kind = ?", kind)
+	core.Network(network)(query)
+
+	err = query.
+		Order("id asc").
+		Limit(storage.GetPageSize(size)).
+		Offset(int(offset)).
+		Select(&response)
+	return
+}
+
+// GetByPtr - returns all diffs of sapling state
+func (storage *Storage) GetByPtr(network types.Network, ptr int64) (response []saplingdiff.SaplingDiff, err error) {
+	if ptr < 0 {
+		err = errors.Wrapf(consts.ErrInvalidPointer, "%d", ptr)
+		return
+	}
+
+	query := storage.DB.Model().Table(models.DocSaplingDiffs).Where("ptr = ?", ptr)
+	core.Network(network)(query)
+
+	err = query.Order("id asc").Select(&response)
+	return
+}
+
+// Count -
+func (storage *Storage) Count(network types.Network, ptr int64, kind types.SaplingDiffKind) (int64, error) {
+	query := storage.DB.Model().Table(models.DocSaplingDiffs).
+		Where("ptr = ?", ptr).
+		Where("kind = ?", kind)
+	core.Network(network)(query)
+
+	count, err := query.Count()
+	return int64(count), err
+}
+
+// Alloc - returns allocation of sapling state
+func (storage *Storage) Alloc(network types.Network, ptr int64) (response saplingdiff.SaplingDiff, err error) {
+	query := storage.DB.Model(&response).
+		Where("ptr = ?", ptr).
+		Where("kind = ?", types.SaplingDiffKindAlloc)
+	core.Network(network)(query)
+
+	err = query.Order("id desc").Limit(1).Select()
+	return
+}
@@ -0,0 +1,169 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/models"
+)
+
+// tableAlias - placeholder of table alias in field expressions. It's replaced by alias in queries and removed in indexes.
+const tableAlias = "{t}"
+
+// source - table of search index
+type source struct {
+	table string
+	alias string
+}
+
+var sources = map[string]source{
+	models.DocContracts:        {table: "contracts", alias: "c"},
+	models.DocOperations:       {table: "operations", alias: "o"},
+	models.DocBigMapDiff:       {table: "big_map_diffs", alias: "b"},
+	models.DocTokenMetadata:    {table: "token_metadata", alias: "t"},
+	models.DocContractMetadata: {table: "contract_metadata", alias: "m"},
+}
+
+// searchField - searchable field of index. Its expression is used both by search query and by expression indexes of the table, so they have to be the same.
+type searchField struct {
+	index  string
+	name   string
+	table  string
+	alias  string
+	column string
+	expr   string
+	join   string
+}
+
+// from - source of documents of the field with the table of field expression
+func (f searchField) from() string {
+	src := sources[f.index]
+	if f.join == "" {
+		return fmt.Sprintf("%s as %s", src.table, src.alias)
+	}
+	return fmt.Sprintf("%s as %s %s", src.table, src.alias, f.join)
+}
+
+// queryExpr - field expression with table alias
+func (f searchField) queryExpr() string {
+	alias := f.alias
+	if alias == "" {
+		alias = sources[f.index].alias
+	}
+	return strings.ReplaceAll(f.expr, tableAlias, alias+".")
+}
+
+// indexExpr - field expression without table alias
+func (f searchField) indexExpr() string {
+	return strings.ReplaceAll(f.expr, tableAlias, "")
+}
+
+func (f searchField) tableName() string {
+	if f.table == "" {
+		return sources[f.index].table
+	}
+	return f.table
+}
+
+// `tags` of contracts are stored as bit mask, so they are matched by names of bits in `prepare` and aren't listed here.
+var searchFields = []searchField{
+	{index: models.DocContracts, name: "address", table: "accounts", alias: "a", column: "address", expr: "{t}address", join: "join accounts as a on a.id = c.account_id"},
+	{index: models.DocContracts, name: "alias", table: "accounts", alias: "a", column: "alias", expr: "{t}alias", join: "join accounts as a on a.id = c.account_id"},
+	{index: models.DocContracts, name: "fail_strings", table: "scripts", alias: "s", column: "fail_strings", expr: "search_array({t}fail_strings)", join: "join scripts as s on s.id = coalesce(nullif(c.babylon_id, 0), c.alpha_id)"},
+	{index: models.DocContracts, name: "annotations", table: "scripts", alias: "s", column: "annotations", expr: "search_array({t}annotations)", join: "join scripts as s on s.id = coalesce(nullif(c.babylon_id, 0), c.alpha_id)"},
+	{index: models.DocContracts, name: "hardcoded", table: "scripts", alias: "s", column: "hardcoded", expr: "search_array({t}hardcoded)", join: "join scripts as s on s.id = coalesce(nullif(c.babylon_id, 0), c.alpha_id)"},
+
+	{index: models.DocOperations, name: "hash", column: "hash", expr: "{t}hash"},
+	{index: models.DocOperations, name: "entrypoint", column: "entrypoint", expr: "{t}entrypoint"},
+	{index: models.DocOperations, name: "errors.with", column: "errors_with", expr: "search_errors({t}errors, 'with')"},
+	{index: models.DocOperations, name: "errors.id", column: "errors_id", expr: "search_errors({t}errors, 'id')"},
+	{index: models.DocOperations, name: "source_alias", table: "accounts", alias: "a", column: "alias", expr: "{t}alias", join: "join accounts as a on a.id = o.source_id"},
+
+	{index: models.DocBigMapDiff, name: "key_hash", column: "key_hash", expr: "{t}key_hash"},
+	{index: models.DocBigMapDiff, name: "key_strings", column: "key_strings", expr: "search_array({t}key_strings)"},
+
+	{index: models.DocTokenMetadata, name: "name", column: "name", expr: "{t}name"},
+	{index: models.DocTokenMetadata, name: "symbol", column: "symbol", expr: "{t}symbol"},
+
+	{index: models.DocContractMetadata, name: "name", column: "name", expr: "{t}name"},
+	{index: models.DocContractMetadata, name: "homepage", column: "homepage", expr: "{t}homepage"},
+	{index: models.DocContractMetadata, name: "description", column: "description", expr: "{t}description"},
+	{index: models.DocContractMetadata, name: "authors", column: "authors", expr: "search_array({t}authors)"},
+}
+
+// documents - selects of search documents by scored hits. Bodies have the same keys as search documents of Elastic, values which are stored as numbers are replaced in `prepareBody`.
+var documents = map[string]string{
+	models.DocContracts: `select h.index, h.id, h.score, c.timestamp, s.hash as group_key,
+		json_build_object('network', c.network, 'level', c.level, 'timestamp', c.timestamp, 'hash', s.hash, 'tags', c.tags,
+			'hardcoded', s.hardcoded, 'fail_strings', s.fail_strings, 'annotations', s.annotations, 'entrypoints', s.entrypoints,
+			'address', a.address, 'manager', mng.address, 'delegate', dlg.address, 'alias', a.alias, 'delegate_alias', dlg.alias,
+			'tx_count', c.tx_count, 'last_action', c.last_action)::text as body
+		from scored as h
+		join contracts as c on c.id = h.id
+		join accounts as a on a.id = c.account_id
+		left join scripts as s on s.id = coalesce(nullif(c.babylon_id, 0), c.alpha_id)
+		left join accounts as mng on mng.id = c.manager_id
+		left join accounts as dlg on dlg.id = c.delegate_id
+		where h.index = 'contracts'`,
+	models.DocOperations: `select h.index, h.id, h.score, o.timestamp, o.hash as group_key,
+		json_build_object('network', o.network, 'hash', o.hash, 'internal', o.internal, 'status', o.status, 'timestamp', o.timestamp,
+			'level', o.level, 'kind', o.kind, 'initiator', ini.address, 'source', src.address, 'destination', dst.address,
+			'delegate', dlg.address, 'entrypoint', o.entrypoint, 'source_alias', src.alias, 'destination_alias', dst.alias,
+			'delegate_alias', dlg.alias, 'errors', convert_from(o.errors, 'UTF8')::jsonb)::text as body
+		from scored as h
+		join operations as o on o.id = h.id
+		left join accounts as ini on ini.id = o.initiator_id
+		left join accounts as src on src.id = o.source_id
+		left join accounts as dst on dst.id = o.destination_id
+		left join accounts as dlg on dlg.id = o.delegate_id
+		where h.index = 'operations'`,
+	models.DocBigMapDiff: `select h.index, h.id, h.score, b.timestamp, b.key_hash as group_key,
+		json_build_object('ptr', b.ptr, 'key', convert_from(b.key, 'UTF8'), 'key_hash', b.key_hash, 'level', b.level,
+			'address', b.contract, 'network', b.network, 'timestamp', b.timestamp, 'key_strings', b.key_strings,
+			'value_strings', b.value_strings)::text as body
+		from scored as h
+		join big_map_diffs as b on b.id = h.id
+		where h.index = 'big_map_diffs'`,
+	models.DocTokenMetadata: `select h.index, h.id, h.score, t.timestamp, concat_ws('|', t.network, t.contract, t.token_id) as group_key,
+		json_build_object('name', t.name, 'symbol', t.symbol, 'token_id', t.token_id, 'network', t.network, 'contract', t.contract,
+			'level', t.level, 'timestamp', t.timestamp, 'decimals', t.decimals, 'extras', t.extras)::text as body
+		from scored as h
+		join token_metadata as t on t.id = h.id
+		where h.index = 'token_metadata'`,
+	models.DocContractMetadata: `select h.index, h.id, h.score, m.timestamp, concat_ws('|', m.network, m.address) as group_key,
+		json_build_object('level', m.level, 'timestamp', m.timestamp, 'address', m.address, 'network', m.network, 'name', m.name,
+			'description', m.description, 'homepage', m.homepage, 'authors', m.authors)::text as body
+		from scored as h
+		join contract_metadata as m on m.id = h.id
+		where h.index = 'contract_metadata'`,
+}
+
+// functions - immutable helpers which are used in expression indexes. `array_to_string` and `convert_from` are only stable, so they can't be used in indexes directly.
+var functions = []string{
+	`create or replace function search_array(text[]) returns text as $$
+		select array_to_string($1, ' ')
+	$$ language sql immutable parallel safe`,
+	`create or replace function search_errors(bytea, text) returns text as $$
+		select string_agg(e->>$2, ' ') from jsonb_array_elements(convert_from($1, 'UTF8')::jsonb) as e
+	$$ language sql immutable parallel safe`,
+}
+
+// indexRequests - tsvector indexes for word search and trigram indexes for substring search of searchable fields
+func indexRequests() []string {
+	requests := make([]string, 0, len(searchFields)*2)
+	created := make(map[string]struct{})
+	for _, f := range searchFields {
+		table := f.tableName()
+		name := fmt.Sprintf("search_%s_%s", table, f.column)
+		if _, ok := created[name]; ok {
+			continue
+		}
+		created[name] = struct{}{}
+
+		requests = append(requests,
+			fmt.Sprintf("create index concurrently if not exists %s_vector_idx on %s using gin (to_tsvector('simple', %s))", name, table, f.indexExpr()),
+			fmt.Sprintf("create index concurrently if not exists %s_trgm_idx on %s using gin (lower(%s) gin_trgm_ops)", name, table, f.indexExpr()),
+		)
+	}
+	return requests
+}
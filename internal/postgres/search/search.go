@@ -0,0 +1,550 @@
+package search
+
+import (
+	stdJSON "encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/helpers"
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/models"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/search"
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultSize = 10
+	groupTop    = 3
+)
+
+var wordRegexp = regexp.MustCompile(`^\w*$`)
+
+var typeMap = map[string]string{
+	models.DocContracts:        "contract",
+	models.DocOperations:       "operation",
+	models.DocBigMapDiff:       "bigmapdiff",
+	models.DocTokenMetadata:    "token_metadata",
+	models.DocContractMetadata: "contract_metadata",
+}
+
+// searchContext - SQL part which selects matched documents with their scores and highlighting settings
+type searchContext struct {
+	Hits   string
+	Params []interface{}
+
+	Text   string
+	Word   bool
+	Scores map[string]int
+}
+
+type hit struct {
+	ID         int64
+	Index      string
+	Body       string
+	GroupKey   string
+	GroupCount int64
+}
+
+// ByText - searches documents by words prefix or by substring if text is not a single word. Score of document is the max boost of matched fields like in `query_string` of Elastic.
+func (storage *Storage) ByText(text string, offset int64, fields []string, filters map[string]interface{}, group bool) (search.Result, error) {
+	if text == "" {
+		return search.Result{}, errors.Errorf("Empty search string. Please query something")
+	}
+	start := time.Now()
+
+	ctx, err := prepare(text, filters, fields)
+	if err != nil {
+		return search.Result{}, err
+	}
+
+	var count int64
+	if _, err := storage.DB.QueryOne(&count, fmt.Sprintf("with hits as (%s) select count(*) from hits", ctx.Hits), ctx.Params...); err != nil {
+		return search.Result{}, err
+	}
+
+	var hits []hit
+	if group {
+		query := fmt.Sprintf(`with hits as (%s),
+			groups as (
+				select group_key, row_number() over (order by max(score) desc, max(timestamp) desc, group_key) as position
+				from hits group by group_key
+			),
+			ranked as (
+				select hits.*, count(*) over (partition by hits.group_key) as group_count,
+					row_number() over (partition by hits.group_key order by score desc, timestamp desc, id) as rank
+				from hits
+			)
+			select ranked.id, ranked.index, ranked.body, ranked.group_key, ranked.group_count from ranked
+			join groups on groups.group_key = ranked.group_key
+			where ranked.rank <= ? and groups.position > ? and groups.position <= ?
+			order by groups.position, ranked.rank`, ctx.Hits)
+		params := append(ctx.Params, groupTop, offset, offset+defaultSize)
+		_, err = storage.DB.Query(&hits, query, params...)
+	} else {
+		query := fmt.Sprintf(`with hits as (%s)
+			select id, index, body from hits
+			order by score desc, timestamp desc, id
+			offset ? limit ?`, ctx.Hits)
+		params := append(ctx.Params, offset, defaultSize)
+		_, err = storage.DB.Query(&hits, query, params...)
+	}
+	if err != nil {
+		return search.Result{}, err
+	}
+
+	for i := range hits {
+		if hits[i].Body, err = prepareBody(hits[i].Index, hits[i].Body); err != nil {
+			logger.Err(err)
+			return search.Result{}, nil
+		}
+	}
+
+	var items []*search.Item
+	if group {
+		items, err = parseGroups(ctx, hits)
+	} else {
+		items, err = parseHits(ctx, hits)
+	}
+	if err != nil {
+		logger.Err(err)
+		return search.Result{}, nil
+	}
+
+	return search.Result{
+		Items: items,
+		Time:  time.Since(start).Milliseconds(),
+		Count: count,
+	}, nil
+}
+
+func parseHits(ctx searchContext, hits []hit) ([]*search.Item, error) {
+	items := make([]*search.Item, 0)
+	for i := range hits {
+		val, err := search.Parse(hits[i].Index, ctx.highlight(hits[i]), []byte(hits[i].Body))
+		if err != nil {
+			return nil, err
+		}
+		if val == nil {
+			continue
+		}
+
+		switch t := val.(type) {
+		case *search.Item:
+			items = append(items, t)
+		case []*search.Item:
+			items = append(items, t...)
+		}
+	}
+	return items, nil
+}
+
+// parseGroups - hits are ordered by group and by rank inside group
+func parseGroups(ctx searchContext, hits []hit) ([]*search.Item, error) {
+	items := make([]*search.Item, 0)
+	var searchItem *search.Item
+	for i := range hits {
+		highlight := ctx.highlight(hits[i])
+		val, err := search.Parse(hits[i].Index, highlight, []byte(hits[i].Body))
+		if err != nil {
+			return nil, err
+		}
+		valItem, ok := val.(*search.Item)
+		if !ok {
+			continue
+		}
+
+		if i == 0 || hits[i-1].GroupKey != hits[i].GroupKey {
+			searchItem = &search.Item{
+				Type:       typeMap[valItem.Type],
+				Body:       valItem.Body,
+				Value:      valItem.Value,
+				Highlights: highlight,
+			}
+			if hits[i].GroupCount > 1 {
+				searchItem.Group = search.NewGroup(uint64(hits[i].GroupCount))
+			}
+			items = append(items, searchItem)
+		} else if searchItem.Group != nil {
+			searchItem.Group.Top = append(searchItem.Group.Top, search.Top{
+				Key:     valItem.Value,
+				Network: valItem.Network,
+			})
+		}
+	}
+	return items, nil
+}
+
+func prepare(text string, filters map[string]interface{}, fields []string) (searchContext, error) {
+	var indices []string
+	searchFilters := make(map[string]interface{}, len(filters))
+	for key, value := range filters {
+		if key == "indices" {
+			indices = value.([]string)
+			continue
+		}
+		searchFilters[key] = value
+	}
+
+	filterConditions, filterParams, err := prepareSearchFilters(searchFilters)
+	if err != nil {
+		return searchContext{}, err
+	}
+
+	ctx := searchContext{
+		Text:   text,
+		Word:   wordRegexp.MatchString(text),
+		Scores: make(map[string]int),
+	}
+
+	if search.IsPtrSearch(text) {
+		ptr, err := strconv.ParseInt(strings.TrimPrefix(text, "ptr:"), 10, 64)
+		if err != nil {
+			return ctx, err
+		}
+		var q matchQuery
+		q.add(models.DocBigMapDiff, sources[models.DocBigMapDiff].table+" as b", 1, "b.ptr = ?", ptr, filterConditions, filterParams)
+		ctx.Hits, ctx.Params = q.hits()
+		return ctx, nil
+	}
+
+	scores, err := search.GetScores(text, fields, indices...)
+	if err != nil {
+		return ctx, err
+	}
+	if len(scores.Scores) == 0 || len(scores.Indices) == 0 {
+		return ctx, errors.Errorf("There are no fields to search: %s", strings.Join(fields, ","))
+	}
+
+	for _, score := range scores.Scores {
+		s := strings.Split(score, "^")
+		boost, err := strconv.Atoi(s[1])
+		if err != nil {
+			return ctx, err
+		}
+		ctx.Scores[s[0]] = boost
+	}
+
+	var param interface{}
+	if ctx.Word {
+		param = fmt.Sprintf("%s:*", text)
+	} else {
+		param = fmt.Sprintf("%%%s%%", escapeLike(strings.ToLower(text)))
+	}
+
+	var q matchQuery
+	for _, f := range searchFields {
+		boost, ok := ctx.Scores[f.name]
+		if !ok || !helpers.StringInArray(f.index, scores.Indices) {
+			continue
+		}
+		var match string
+		if ctx.Word {
+			match = fmt.Sprintf("to_tsvector('simple', %s) @@ to_tsquery('simple', ?)", f.queryExpr())
+		} else {
+			match = fmt.Sprintf("lower(%s) like ? escape '\\'", f.queryExpr())
+		}
+		q.add(f.index, f.from(), boost, match, param, filterConditions, filterParams)
+	}
+
+	if boost, ok := ctx.Scores["tags"]; ok && helpers.StringInArray(models.DocContracts, scores.Indices) {
+		if mask := matchTags(text, ctx.Word); mask != 0 {
+			q.add(models.DocContracts, sources[models.DocContracts].table+" as c", boost, "c.tags & ? > 0", int64(mask), filterConditions, filterParams)
+		}
+	}
+
+	ctx.Hits, ctx.Params = q.hits()
+	return ctx, nil
+}
+
+// matchQuery - union of selects of documents which match the search text by one field
+type matchQuery struct {
+	indices []string
+	selects []string
+	params  []interface{}
+}
+
+func (q *matchQuery) add(index, from string, boost int, match string, param interface{}, filterConditions []string, filterParams []interface{}) {
+	alias := sources[index].alias
+	conditions := []string{match}
+	for i := range filterConditions {
+		conditions = append(conditions, strings.ReplaceAll(filterConditions[i], tableAlias, alias+"."))
+	}
+	q.selects = append(q.selects, fmt.Sprintf("select '%s' as index, %s.id, %d as boost from %s where %s", index, alias, boost, from, strings.Join(conditions, " and ")))
+	q.params = append(q.params, param)
+	q.params = append(q.params, filterParams...)
+	if !helpers.StringInArray(index, q.indices) {
+		q.indices = append(q.indices, index)
+	}
+}
+
+// hits - documents with the max boost of matched fields as score
+func (q *matchQuery) hits() (string, []interface{}) {
+	if len(q.selects) == 0 {
+		return "select null::text as index, null::bigint as id, 0 as score, null::timestamptz as timestamp, null::text as group_key, null::text as body where false", nil
+	}
+	docs := make([]string, 0, len(q.indices))
+	for _, index := range q.indices {
+		docs = append(docs, documents[index])
+	}
+	return fmt.Sprintf(`with matches as (%s),
+		scored as (select index, id, max(boost) as score from matches group by index, id)
+		%s`, strings.Join(q.selects, " union all "), strings.Join(docs, " union all ")), q.params
+}
+
+// matchTags - returns mask of contract tags which names match text
+func matchTags(text string, word bool) types.Tags {
+	text = strings.ToLower(text)
+	var mask types.Tags
+	for i := 0; i < 64; i++ {
+		tag := types.Tags(1) << i
+		for _, name := range tag.ToArray() {
+			name = strings.ToLower(name)
+			if (word && strings.HasPrefix(name, text)) || (!word && strings.Contains(name, text)) {
+				mask |= tag
+			}
+		}
+	}
+	return mask
+}
+
+// prepareSearchFilters - conditions contain `{t}` placeholder of table alias
+func prepareSearchFilters(filters map[string]interface{}) ([]string, []interface{}, error) {
+	conditions := make([]string, 0)
+	params := make([]interface{}, 0)
+
+	for k, v := range filters {
+		switch k {
+		case "from":
+			val, ok := v.(string)
+			if !ok {
+				return nil, nil, errors.Errorf("Invalid type for 'from' filter (wait string): %T", v)
+			}
+			if val != "" {
+				conditions = append(conditions, "{t}timestamp > ?")
+				params = append(params, val)
+			}
+		case "to":
+			val, ok := v.(string)
+			if !ok {
+				return nil, nil, errors.Errorf("Invalid type for 'to' filter (wait string): %T", v)
+			}
+			if val != "" {
+				conditions = append(conditions, "{t}timestamp < ?")
+				params = append(params, val)
+			}
+		case "networks":
+			val, ok := v.([]string)
+			if !ok {
+				return nil, nil, errors.Errorf("Invalid type for 'network' filter (wait []string): %T", v)
+			}
+			if len(val) == 0 {
+				continue
+			}
+			networks := make([]int64, 0, len(val))
+			for i := range val {
+				networks = append(networks, int64(types.NewNetwork(val[i])))
+			}
+			conditions = append(conditions, "{t}network in (?)")
+			params = append(params, pg.In(networks))
+		default:
+			return nil, nil, errors.Errorf("Unknown search filter: %s", k)
+		}
+	}
+	return conditions, params, nil
+}
+
+// prepareBody - replaces values which are stored in tables as numbers by their names like in search documents
+func prepareBody(index string, body string) (string, error) {
+	decoder := stdJSON.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+
+	var data map[string]interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return "", err
+	}
+
+	if value, ok := bodyInt(data, "network"); ok {
+		data["network"] = types.Network(value).String()
+	}
+
+	switch index {
+	case models.DocContracts:
+		if value, ok := bodyInt(data, "tags"); ok {
+			data["tags"] = types.Tags(value).ToArray()
+		}
+	case models.DocOperations:
+		if value, ok := bodyInt(data, "status"); ok {
+			data["status"] = types.OperationStatus(value).String()
+		}
+		if value, ok := bodyInt(data, "kind"); ok {
+			data["kind"] = types.OperationKind(value).String()
+		}
+	case models.DocBigMapDiff:
+		if key, ok := data["key"].(string); ok {
+			var tree ast.UntypedAST
+			if err := json.UnmarshalFromString(key, &tree); err != nil {
+				return "", err
+			}
+			str, err := tree.Stringify()
+			if err != nil {
+				return "", err
+			}
+			data["key"] = str
+		}
+	}
+
+	result, err := stdJSON.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+func bodyInt(data map[string]interface{}, key string) (int64, bool) {
+	number, ok := data[key].(stdJSON.Number)
+	if !ok {
+		return 0, false
+	}
+	value, err := number.Int64()
+	return value, err == nil
+}
+
+// highlight - wraps matched parts of searchable fields into `<em>` tags like Elastic highlighter
+func (ctx searchContext) highlight(h hit) map[string][]string {
+	if len(ctx.Scores) == 0 {
+		return nil
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(h.Body), &body); err != nil {
+		return nil
+	}
+
+	result := make(map[string][]string)
+	for field := range ctx.Scores {
+		for _, value := range fieldValues(body, field) {
+			var highlighted string
+			if ctx.Word {
+				highlighted = highlightPrefix(value, ctx.Text)
+			} else {
+				highlighted = highlightSubstring(value, ctx.Text)
+			}
+			if highlighted != value {
+				result[field] = append(result[field], highlighted)
+			}
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// highlightPrefix - wraps words of value which start with prefix
+func highlightPrefix(value, prefix string) string {
+	prefix = strings.ToLower(prefix)
+
+	var builder strings.Builder
+	runes := []rune(value)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			builder.WriteRune(runes[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		if strings.HasPrefix(strings.ToLower(word), prefix) {
+			builder.WriteString("<em>")
+			builder.WriteString(word)
+			builder.WriteString("</em>")
+		} else {
+			builder.WriteString(word)
+		}
+		i = j
+	}
+	return builder.String()
+}
+
+// highlightSubstring - wraps case-insensitive occurrences of substring
+func highlightSubstring(value, substr string) string {
+	if substr == "" {
+		return value
+	}
+	lowerValue := strings.ToLower(value)
+	lowerSubstr := strings.ToLower(substr)
+	if len(lowerValue) != len(value) {
+		return value
+	}
+
+	var builder strings.Builder
+	for {
+		idx := strings.Index(lowerValue, lowerSubstr)
+		if idx < 0 {
+			builder.WriteString(value)
+			break
+		}
+		end := idx + len(lowerSubstr)
+		builder.WriteString(value[:idx])
+		builder.WriteString("<em>")
+		builder.WriteString(value[idx:end])
+		builder.WriteString("</em>")
+		value = value[end:]
+		lowerValue = lowerValue[end:]
+	}
+	return builder.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func escapeLike(str string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(str)
+}
+
+// fieldValues - returns non-empty values of field by dotted path. Arrays are flattened.
+func fieldValues(body map[string]interface{}, path string) []string {
+	var value interface{} = body
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value, ok = obj[key]; !ok {
+			return nil
+		}
+	}
+	return flattenValue(value)
+}
+
+func flattenValue(value interface{}) []string {
+	switch typ := value.(type) {
+	case nil:
+		return nil
+	case string:
+		if typ == "" {
+			return nil
+		}
+		return []string{typ}
+	case []interface{}:
+		result := make([]string, 0, len(typ))
+		for i := range typ {
+			result = append(result, flattenValue(typ[i])...)
+		}
+		return result
+	case map[string]interface{}:
+		return nil
+	default:
+		return []string{fmt.Sprintf("%v", typ)}
+	}
+}
@@ -0,0 +1,229 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/models"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_highlightPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		prefix string
+		want   string
+	}{
+		{
+			name:   "address",
+			value:  "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn",
+			prefix: "kt1pwx",
+			want:   "<em>KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn</em>",
+		}, {
+			name:   "several words",
+			value:  "Tezos Domains: tezos name service",
+			prefix: "tez",
+			want:   "<em>Tezos</em> Domains: <em>tezos</em> name service",
+		}, {
+			name:   "no match",
+			value:  "Domains",
+			prefix: "tez",
+			want:   "Domains",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, highlightPrefix(tt.value, tt.prefix))
+		})
+	}
+}
+
+func Test_highlightSubstring(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		substr string
+		want   string
+	}{
+		{
+			name:   "phrase",
+			value:  "Tezos Domains Token",
+			substr: "domains tok",
+			want:   "Tezos <em>Domains Tok</em>en",
+		}, {
+			name:   "several occurrences",
+			value:  "a-b a-b",
+			substr: "a-b",
+			want:   "<em>a-b</em> <em>a-b</em>",
+		}, {
+			name:   "no match",
+			value:  "Tezos",
+			substr: "a-b",
+			want:   "Tezos",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, highlightSubstring(tt.value, tt.substr))
+		})
+	}
+}
+
+func Test_prepareBody(t *testing.T) {
+	tests := []struct {
+		name  string
+		index string
+		body  string
+		want  string
+	}{
+		{
+			name:  "contract",
+			index: models.DocContracts,
+			body:  fmt.Sprintf(`{"network": 1, "level": 100, "address": "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn", "alias": "tzBTC", "tags": %d}`, types.FA12Tag|types.LedgerTag),
+			want:  `{"address":"KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn","alias":"tzBTC","level":100,"network":"mainnet","tags":["fa1-2","ledger"]}`,
+		}, {
+			name:  "operation",
+			index: models.DocOperations,
+			body:  `{"network": 1, "hash": "ooy5ae4JSa6PzSo6TdLpB6cgtkQjH8ovy3H3UQFmnwnPbv1sQUn", "status": 1, "kind": 1, "entrypoint": null}`,
+			want:  `{"entrypoint":null,"hash":"ooy5ae4JSa6PzSo6TdLpB6cgtkQjH8ovy3H3UQFmnwnPbv1sQUn","kind":"transaction","network":"mainnet","status":"applied"}`,
+		}, {
+			name:  "big map diff",
+			index: models.DocBigMapDiff,
+			body:  `{"ptr": 31, "network": 1, "key": "{\"string\": \"tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb\"}"}`,
+			want:  `{"key":"tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb","network":"mainnet","ptr":31}`,
+		}, {
+			name:  "token metadata",
+			index: models.DocTokenMetadata,
+			body:  `{"name": "Wrapped Tezos", "token_id": 18446744073709551615, "network": 1}`,
+			want:  `{"name":"Wrapped Tezos","network":"mainnet","token_id":18446744073709551615}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := prepareBody(tt.index, tt.body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, got)
+		})
+	}
+}
+
+func Test_matchTags(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		word bool
+		want types.Tags
+	}{
+		{
+			name: "prefix",
+			text: "FA1",
+			word: true,
+			want: types.FA1Tag | types.FA12Tag,
+		}, {
+			name: "substring",
+			text: "_bal",
+			want: types.ViewBalanceOfTag,
+		}, {
+			name: "no match",
+			text: "tzBTC",
+			word: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchTags(tt.text, tt.word))
+		})
+	}
+}
+
+func Test_indexRequests(t *testing.T) {
+	requests := indexRequests()
+	assert.Contains(t, requests, "create index concurrently if not exists search_scripts_fail_strings_vector_idx on scripts using gin (to_tsvector('simple', search_array(fail_strings)))")
+	assert.Contains(t, requests, "create index concurrently if not exists search_operations_errors_with_trgm_idx on operations using gin (lower(search_errors(errors, 'with')) gin_trgm_ops)")
+
+	names := make(map[string]struct{})
+	for _, request := range requests {
+		assert.NotContains(t, request, tableAlias)
+		name := strings.Fields(request)[6]
+		assert.NotContains(t, names, name, "duplicated index")
+		names[name] = struct{}{}
+	}
+}
+
+func Test_prepare(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		filters    map[string]interface{}
+		fields     []string
+		wantWord   bool
+		wantScores map[string]int
+		wantParams int
+		wantErr    bool
+	}{
+		{
+			name:       "word in contracts",
+			text:       "tzBTC",
+			filters:    map[string]interface{}{"indices": []string{models.DocContracts}},
+			fields:     []string{"alias", "address"},
+			wantWord:   true,
+			wantScores: map[string]int{"alias": 8, "address": 10},
+			wantParams: 2,
+		}, {
+			name:       "tags in contracts",
+			text:       "fa1",
+			filters:    map[string]interface{}{"indices": []string{models.DocContracts}},
+			fields:     []string{"tags"},
+			wantWord:   true,
+			wantScores: map[string]int{"tags": 6},
+			wantParams: 1,
+		}, {
+			name:       "unknown tag",
+			text:       "tzBTC",
+			filters:    map[string]interface{}{"indices": []string{models.DocContracts}},
+			fields:     []string{"tags"},
+			wantWord:   true,
+			wantScores: map[string]int{"tags": 6},
+		}, {
+			name: "phrase with networks",
+			text: "Wrapped Tez",
+			filters: map[string]interface{}{
+				"indices":  []string{models.DocTokenMetadata},
+				"networks": []string{"mainnet"},
+			},
+			wantScores: map[string]int{"name": 8, "symbol": 8},
+			wantParams: 4,
+		}, {
+			name:       "pointer",
+			text:       "ptr:123",
+			filters:    map[string]interface{}{},
+			wantScores: map[string]int{},
+			wantParams: 1,
+		}, {
+			name:    "unknown filter",
+			text:    "tzBTC",
+			filters: map[string]interface{}{"unknown": "value"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtersCount := len(tt.filters)
+			ctx, err := prepare(tt.text, tt.filters, tt.fields)
+			assert.Len(t, tt.filters, filtersCount, "filters of caller are changed")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantWord, ctx.Word)
+			assert.Equal(t, tt.wantScores, ctx.Scores)
+			assert.Len(t, ctx.Params, tt.wantParams)
+			assert.NotEmpty(t, ctx.Hits)
+		})
+	}
+}
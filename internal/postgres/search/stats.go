@@ -0,0 +1,272 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models"
+	"github.com/baking-bad/bcdhub/internal/models/dapp"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/pkg/errors"
+)
+
+// NetworkCountStats -
+func (storage *Storage) NetworkCountStats(network types.Network) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	for _, index := range []string{models.DocContracts, models.DocOperations} {
+		count, err := storage.DB.Model().Table(index).Where("network = ?", network).Count()
+		if err != nil {
+			return nil, err
+		}
+		counts[index] = int64(count)
+	}
+	return counts, nil
+}
+
+// ContractStats -
+func (storage *Storage) ContractStats(network types.Network, address string) (models.ContractStats, error) {
+	var stats struct {
+		Count      int64
+		LastAction *time.Time
+	}
+	if _, err := storage.DB.QueryOne(&stats, `
+		with acc as (select id from accounts where network = ? and address = ?)
+		select count(*) as count, max(timestamp) as last_action from operations
+		where network = ? and (source_id in (select id from acc) or destination_id in (select id from acc))`,
+		network, address, network,
+	); err != nil {
+		return models.ContractStats{}, err
+	}
+
+	result := models.ContractStats{
+		Count: stats.Count,
+	}
+	if stats.LastAction != nil {
+		result.LastAction = stats.LastAction.UTC()
+	}
+	return result, nil
+}
+
+type networkStatsRow struct {
+	Network types.Network
+	Count   uint64
+	Same    uint64
+}
+
+// NetworkStats -
+func (storage *Storage) NetworkStats(network types.Network) (map[string]*models.NetworkStats, error) {
+	var contracts []networkStatsRow
+	query := storage.DB.Model().Table(models.DocContracts).
+		ColumnExpr("network, count(*) as count, count(distinct (case when babylon_id > 0 then babylon_id else alpha_id end)) as same").
+		Group("network")
+	if network != types.Empty {
+		query.Where("network = ?", network)
+	}
+	if err := query.Select(&contracts); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]*models.NetworkStats)
+	get := func(network types.Network) *models.NetworkStats {
+		stats, ok := counts[network.String()]
+		if !ok {
+			stats = new(models.NetworkStats)
+			counts[network.String()] = stats
+		}
+		return stats
+	}
+
+	for i := range contracts {
+		stats := get(contracts[i].Network)
+		stats.ContractsCount = contracts[i].Count
+		stats.UniqueContractsCount = contracts[i].Same
+	}
+
+	var faCount []networkStatsRow
+	query = storage.DB.Model().Table(models.DocContracts).
+		ColumnExpr("network, count(*) as count").
+		Where("(tags & ?) > 0", types.FA1Tag|types.FA12Tag).
+		Group("network")
+	if network != types.Empty {
+		query.Where("network = ?", network)
+	}
+	if err := query.Select(&faCount); err != nil {
+		return nil, err
+	}
+	for i := range faCount {
+		get(faCount[i].Network).FACount = faCount[i].Count
+	}
+
+	var callsCount []networkStatsRow
+	query = storage.DB.Model().Table(models.DocOperations).
+		ColumnExpr("network, count(*) as count").
+		Where("entrypoint is not null").
+		Where("entrypoint != ''").
+		Group("network")
+	if network != types.Empty {
+		query.Where("network = ?", network)
+	}
+	if err := query.Select(&callsCount); err != nil {
+		return nil, err
+	}
+	for i := range callsCount {
+		get(callsCount[i].Network).CallsCount = callsCount[i].Count
+	}
+
+	return counts, nil
+}
+
+const histogramRequestTemplate = `
+	with b as (
+		select date_trunc(?period, timestamp) as bucket, ?value as value
+		from ?table
+		where ?conditions
+		group by 1
+	)
+	select extract(epoch from f.val) as date_part, coalesce(b.value, 0) as value
+	from generate_series((select min(bucket) from b), (select max(bucket) from b), ?interval ::interval) as f (val)
+	left join b on b.bucket = f.val
+	order by 1
+`
+
+// Histogram - returns series from the first to the last bucket with documents like `date_histogram` of Elastic
+func (storage *Storage) Histogram(period string, opts ...models.HistogramOption) ([][]float64, error) {
+	if err := core.ValidateHistogramPeriod(period); err != nil {
+		return nil, err
+	}
+	if period == "all" {
+		return nil, errors.Errorf("Invalid period: %s", period)
+	}
+
+	ctx := models.HistogramContext{
+		Period: period,
+	}
+	for _, opt := range opts {
+		opt(&ctx)
+	}
+
+	switch ctx.Index {
+	case models.DocContracts, models.DocOperations, models.DocTransfers:
+	default:
+		return nil, errors.Errorf("Unknown histogram index: %s", ctx.Index)
+	}
+
+	value := "count(*)"
+	if ctx.HasFunction() {
+		column, err := histogramColumn(ctx.Index, ctx.Function.Field)
+		if err != nil {
+			return nil, err
+		}
+		switch ctx.Function.Name {
+		case "sum":
+			value = fmt.Sprintf("sum(%s)", column)
+		case "cardinality":
+			value = fmt.Sprintf("count(distinct %s)", column)
+		default:
+			return nil, errors.Errorf("Unknown histogram function: %s", ctx.Function.Name)
+		}
+	}
+
+	conditions, err := histogramConditions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []core.HistogramResponse
+	if _, err := storage.DB.
+		WithParam("period", period).
+		WithParam("value", pg.Safe(value)).
+		WithParam("table", pg.Ident(ctx.Index)).
+		WithParam("conditions", pg.Safe(conditions)).
+		WithParam("interval", fmt.Sprintf("1 %s", period)).
+		Query(&resp, histogramRequestTemplate); err != nil {
+		return nil, err
+	}
+
+	histogram := make([][]float64, 0, len(resp))
+	for i := range resp {
+		histogram = append(histogram, []float64{resp[i].DatePart * 1000, resp[i].Value})
+	}
+	return histogram, nil
+}
+
+func histogramColumn(index, field string) (string, error) {
+	switch field {
+	case "amount", "consumed_gas", "paid_storage_size_diff":
+		return field, nil
+	case "initiator", "destination", "source", "from", "to":
+		return fmt.Sprintf("%s_id", field), nil
+	default:
+		return "", errors.Errorf("Unknown histogram field of %s: %s", index, field)
+	}
+}
+
+func histogramConditions(ctx models.HistogramContext) (string, error) {
+	conditions := []string{"true"}
+	for _, fltr := range ctx.Filters {
+		switch fltr.Kind {
+		case models.HistogramFilterKindExists:
+			conditions = append(conditions, formatQuery("? is not null", pg.Ident(fltr.Field)))
+			if fltr.Field == "entrypoint" {
+				conditions = append(conditions, "entrypoint != ''")
+			}
+		case models.HistogramFilterKindMatch:
+			condition, err := histogramMatch(fltr)
+			if err != nil {
+				return "", err
+			}
+			conditions = append(conditions, condition)
+		case models.HistogramFilterKindAddresses:
+			if value, ok := fltr.Value.([]string); ok && len(value) > 0 {
+				column, err := histogramColumn(ctx.Index, fltr.Field)
+				if err != nil {
+					return "", err
+				}
+				conditions = append(conditions, formatQuery("? in (select id from accounts where address in (?))", pg.Ident(column), pg.In(value)))
+			}
+		case models.HistogramFilterDexEnrtypoints:
+			if value, ok := fltr.Value.([]dapp.DAppContract); ok {
+				entrypoints := make([]string, 0)
+				for i := range value {
+					for j := range value[i].Entrypoint {
+						entrypoints = append(entrypoints, formatQuery(
+							"(initiator_id in (select id from accounts where address = ?) and parent = ?)",
+							value[i].Address, value[i].Entrypoint[j],
+						))
+					}
+				}
+				if len(entrypoints) > 0 {
+					conditions = append(conditions, fmt.Sprintf("(%s)", strings.Join(entrypoints, " or ")))
+				}
+			}
+		}
+	}
+	return strings.Join(conditions, " and "), nil
+}
+
+func histogramMatch(fltr models.HistogramFilter) (string, error) {
+	switch fltr.Field {
+	case "network":
+		switch val := fltr.Value.(type) {
+		case types.Network:
+			return formatQuery("network = ?", val), nil
+		case string:
+			return formatQuery("network = ?", types.NewNetwork(val)), nil
+		}
+	case "status":
+		if val, ok := fltr.Value.(string); ok {
+			return formatQuery("status = ?", types.NewOperationStatus(val)), nil
+		}
+	default:
+		return formatQuery("? = ?", pg.Ident(fltr.Field), fltr.Value), nil
+	}
+	return "", errors.Errorf("Invalid value of histogram filter %s: %v", fltr.Field, fltr.Value)
+}
+
+func formatQuery(query string, params ...interface{}) string {
+	return string(orm.NewFormatter().FormatQuery(nil, query, params...))
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"context"
+	"io"
+
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	"github.com/baking-bad/bcdhub/internal/search"
+	"github.com/go-pg/pg/v10"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// ErrNotSupported -
+var ErrNotSupported = errors.New("operation is not supported by postgres search backend")
+
+// Storage - search and statistics backend over PostgreSQL. Documents are searched right in the tables of indexer by expression indexes, so they aren't copied anywhere.
+type Storage struct {
+	*core.Postgres
+}
+
+// NewStorage -
+func NewStorage(pg *core.Postgres) *Storage {
+	return &Storage{pg}
+}
+
+// CreateIndexes - creates functions and expression indexes of searchable fields. Indexes are created concurrently, so it may take a while on a big database.
+func (storage *Storage) CreateIndexes() error {
+	if err := storage.createTrigramExtension(); err != nil {
+		return err
+	}
+	for _, request := range functions {
+		if _, err := storage.DB.Exec(request); err != nil {
+			return err
+		}
+	}
+	for _, request := range indexRequests() {
+		if _, err := storage.DB.Exec(request); err != nil {
+			return errors.Wrap(err, request)
+		}
+	}
+	return nil
+}
+
+// createTrigramExtension - `pg_trgm` can be created only by privileged user, so it's created only if it's missing.
+func (storage *Storage) createTrigramExtension() error {
+	var exists bool
+	if _, err := storage.DB.QueryOne(pg.Scan(&exists), `select exists(select 1 from pg_extension where extname = 'pg_trgm')`); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	if _, err := storage.DB.Exec(`create extension if not exists pg_trgm`); err != nil {
+		return errors.Wrap(err, "postgres search backend requires pg_trgm extension, create it by superuser: CREATE EXTENSION pg_trgm")
+	}
+	return nil
+}
+
+// Save - documents are searched in the tables of indexer, so there is nothing to save
+func (storage *Storage) Save(ctx context.Context, items []search.Data) error {
+	return nil
+}
+
+// Rollback - documents are removed from the tables of indexer by its rollback
+func (storage *Storage) Rollback(network string, level int64) error {
+	return nil
+}
+
+// CreateAWSRepository -
+func (storage *Storage) CreateAWSRepository(string, string, string) error {
+	return ErrNotSupported
+}
+
+// ListRepositories -
+func (storage *Storage) ListRepositories() ([]search.Repository, error) {
+	return nil, ErrNotSupported
+}
+
+// CreateSnapshots -
+func (storage *Storage) CreateSnapshots(string, string, []string) error {
+	return ErrNotSupported
+}
+
+// RestoreSnapshots -
+func (storage *Storage) RestoreSnapshots(string, string, []string) error {
+	return ErrNotSupported
+}
+
+// ListSnapshots -
+func (storage *Storage) ListSnapshots(string) (string, error) {
+	return "", ErrNotSupported
+}
+
+// SetSnapshotPolicy -
+func (storage *Storage) SetSnapshotPolicy(string, string, string, string, int64) error {
+	return ErrNotSupported
+}
+
+// GetAllPolicies -
+func (storage *Storage) GetAllPolicies() ([]string, error) {
+	return nil, ErrNotSupported
+}
+
+// GetMappings -
+func (storage *Storage) GetMappings([]string) (map[string]string, error) {
+	return nil, ErrNotSupported
+}
+
+// CreateMapping -
+func (storage *Storage) CreateMapping(string, io.Reader) error {
+	return ErrNotSupported
+}
+
+// ReloadSecureSettings -
+func (storage *Storage) ReloadSecureSettings() error {
+	return ErrNotSupported
+}
@@ -20,7 +20,7 @@ func (storage *Storage) buildGetContext(query *orm.Query, ctx transfer.GetContex
 	}
 	if ctx.AccountID > -1 {
 		query.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
-			q = q.WhereOr(`from_id = ?`, ctx.AccountID).WhereOr(`to = ?`, ctx.AccountID)
+			q = q.WhereOr(`from_id = ?`, ctx.AccountID).WhereOr(`to_id = ?`, ctx.AccountID)
 			return q, nil
 		})
 	}
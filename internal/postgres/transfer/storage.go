@@ -57,8 +57,27 @@ func (storage *Storage) Get(ctx transfer.GetContext) (po transfer.Pageable, err
 func (storage *Storage) GetAll(network types.Network, level int64) ([]transfer.Transfer, error) {
 	var transfers []transfer.Transfer
 	err := storage.DB.Model(&transfers).
-		Where("network = ?", network).
-		Where("level = ?", level).
+		Relation("Initiator").
+		Relation("From").
+		Relation("To").
+		Where("transfer.network = ?", network).
+		Where("transfer.level = ?", level).
+		Select(&transfers)
+	return transfers, err
+}
+
+// GetForOperations -
+func (storage *Storage) GetForOperations(ids ...int64) ([]transfer.Transfer, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var transfers []transfer.Transfer
+	err := storage.DB.Model(&transfers).
+		Relation("Initiator").
+		Relation("From").
+		Relation("To").
+		WhereIn("transfer.operation_id IN (?)", ids).
+		Order("transfer.id asc").
 		Select(&transfers)
 	return transfers, err
 }
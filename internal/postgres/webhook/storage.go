@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/models/webhook"
+	"github.com/baking-bad/bcdhub/internal/postgres/core"
+	"github.com/go-pg/pg/v10"
+)
+
+// Storage -
+type Storage struct {
+	*core.Postgres
+}
+
+// NewStorage -
+func NewStorage(pg *core.Postgres) *Storage {
+	return &Storage{pg}
+}
+
+// Get -
+func (storage *Storage) Get(id int64) (response webhook.Subscription, err error) {
+	err = storage.DB.Model(&response).Where("id = ?", id).Limit(1).Select()
+	return
+}
+
+// List -
+func (storage *Storage) List(network types.Network, address string, size, offset int64) (response []webhook.Subscription, err error) {
+	query := storage.DB.Model((*webhook.Subscription)(nil))
+	if network != types.Empty {
+		query.Where("network = ?", network)
+	}
+	if address != "" {
+		query.Where("address = ?", address)
+	}
+	if offset > 0 {
+		query.Offset(int(offset))
+	}
+	err = query.Limit(storage.GetPageSize(size)).Order("id asc").Select(&response)
+	return
+}
+
+// ByNetwork -
+func (storage *Storage) ByNetwork(network types.Network) (response []webhook.Subscription, err error) {
+	err = storage.DB.Model((*webhook.Subscription)(nil)).Where("network = ?", network).Where("watch_mask > 0").Select(&response)
+	return
+}
+
+// Create -
+func (storage *Storage) Create(subscription *webhook.Subscription) error {
+	_, err := storage.DB.Model(subscription).Returning("*").Insert()
+	return err
+}
+
+// Update -
+func (storage *Storage) Update(subscription *webhook.Subscription) error {
+	_, err := storage.DB.Model(subscription).
+		Column("network", "address", "url", "secret", "alias", "watch_mask").
+		WherePK().
+		Update()
+	return err
+}
+
+// Delete - removes subscription, its pending deliveries and dead letters
+func (storage *Storage) Delete(id int64) error {
+	return storage.DB.RunInTransaction(storage.DB.Context(), func(tx *pg.Tx) error {
+		if _, err := tx.Model((*webhook.Delivery)(nil)).Where("subscription_id = ?", id).Delete(); err != nil {
+			return err
+		}
+		if _, err := tx.Model((*webhook.DeadLetter)(nil)).Where("subscription_id = ?", id).Delete(); err != nil {
+			return err
+		}
+		_, err := tx.Model((*webhook.Subscription)(nil)).Where("id = ?", id).Delete()
+		return err
+	})
+}
+
+// SaveDeadLetter -
+func (storage *Storage) SaveDeadLetter(letter *webhook.DeadLetter) error {
+	return letter.Save(storage.DB)
+}
+
+// DeadLetters -
+func (storage *Storage) DeadLetters(subscriptionID, size, offset int64) (response []webhook.DeadLetter, err error) {
+	query := storage.DB.Model((*webhook.DeadLetter)(nil)).Where("subscription_id = ?", subscriptionID)
+	if offset > 0 {
+		query.Offset(int(offset))
+	}
+	err = query.Limit(storage.GetPageSize(size)).Order("id desc").Select(&response)
+	return
+}
+
+// SaveDeliveries - queues notifications. Identifiers are assigned in order of `deliveries`.
+func (storage *Storage) SaveDeliveries(deliveries []webhook.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	_, err := storage.DB.Model(&deliveries).Returning("id").Insert()
+	return err
+}
+
+// DueDeliveries - returns the first queued delivery of each subscription if it's time to send it
+func (storage *Storage) DueDeliveries(limit int64) (response []webhook.Delivery, err error) {
+	heads := storage.DB.Model((*webhook.Delivery)(nil)).
+		DistinctOn("subscription_id").
+		Order("subscription_id asc", "id asc")
+
+	_, err = storage.DB.Query(&response, `
+		SELECT * FROM (?) AS heads
+		WHERE next_attempt_at <= now()
+		ORDER BY id ASC
+		LIMIT ?
+	`, heads, storage.GetPageSize(limit))
+	return
+}
+
+// Delivered - removes delivery from queue
+func (storage *Storage) Delivered(id int64) error {
+	_, err := storage.DB.Model((*webhook.Delivery)(nil)).Where("id = ?", id).Delete()
+	return err
+}
+
+// Postpone - saves result of failed attempt and time of the next one
+func (storage *Storage) Postpone(delivery *webhook.Delivery) error {
+	_, err := storage.DB.Model(delivery).
+		Column("error", "attempts", "next_attempt_at").
+		WherePK().
+		Update()
+	return err
+}
+
+// MoveToDeadLetters - removes delivery from queue and saves it to dead letters
+func (storage *Storage) MoveToDeadLetters(delivery webhook.Delivery) error {
+	return storage.DB.RunInTransaction(storage.DB.Context(), func(tx *pg.Tx) error {
+		if _, err := tx.Model((*webhook.Delivery)(nil)).Where("id = ?", delivery.ID).Delete(); err != nil {
+			return err
+		}
+		letter := webhook.DeadLetter{
+			SubscriptionID: delivery.SubscriptionID,
+			Event:          delivery.Event,
+			Payload:        delivery.Payload,
+			Error:          delivery.Error,
+			Attempts:       delivery.Attempts,
+		}
+		return letter.Save(tx)
+	})
+}
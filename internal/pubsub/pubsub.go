@@ -0,0 +1,171 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/cenkalti/backoff"
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+)
+
+// Channel - name of Postgres notification channel which is used to deliver indexer events to API
+const Channel = "bcd_events"
+
+// maxReconnectInterval - maximum delay between attempts to restore LISTEN connection
+const maxReconnectInterval = 30 * time.Second
+
+// Event kinds
+const (
+	KindBlock    = "block"
+	KindRollback = "rollback"
+)
+
+// Event - indexer event. Payload is kept small because of Postgres limit on notification size:
+// consumers load indexed data by network and level.
+type Event struct {
+	Kind    string        `json:"kind"`
+	Network types.Network `json:"network"`
+	Level   int64         `json:"level"`
+}
+
+// NewBlock - event which is published when block is committed
+func NewBlock(network types.Network, level int64) Event {
+	return Event{
+		Kind:    KindBlock,
+		Network: network,
+		Level:   level,
+	}
+}
+
+// NewRollback - event which is published when network state is rolled back to `level`
+func NewRollback(network types.Network, level int64) Event {
+	return Event{
+		Kind:    KindRollback,
+		Network: network,
+		Level:   level,
+	}
+}
+
+// Publish - sends event to channel. If `db` is transaction, event is delivered after commit.
+func Publish(db pg.DBI, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("SELECT pg_notify(?, ?)", Channel, string(payload))
+	return err
+}
+
+// Listen - receives events from channel until `ctx` is done. If connection is lost listener reconnects with exponential backoff and issues LISTEN again.
+// Events published while connection was lost are not delivered.
+func Listen(ctx context.Context, db *pg.DB) <-chan Event {
+	connect := func(ctx context.Context) (receiver, error) {
+		ln := db.Listen(ctx)
+		if err := ln.Listen(ctx, Channel); err != nil {
+			ln.Close()
+			return nil, err
+		}
+		return ln, nil
+	}
+	return listen(ctx, connect, newReconnectBackOff())
+}
+
+// receiver - notification source. It's implemented by `*pg.Listener`.
+type receiver interface {
+	Receive(ctx context.Context) (channel string, payload string, err error)
+	Close() error
+}
+
+type connector func(ctx context.Context) (receiver, error)
+
+func newReconnectBackOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.MaxInterval = maxReconnectInterval
+	b.MaxElapsedTime = 0
+	return b
+}
+
+func listen(ctx context.Context, connect connector, reconnect backoff.BackOff) <-chan Event {
+	output := make(chan Event, 1024)
+
+	go func() {
+		defer close(output)
+
+		for {
+			err := receive(ctx, connect, reconnect, output)
+			if ctx.Err() != nil {
+				return
+			}
+
+			delay := reconnect.NextBackOff()
+			logger.Warning().Err(err).Msgf("pubsub: connection is lost, reconnecting in %s", delay)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+	}()
+
+	return output
+}
+
+// receive - sends events to `output` until connection fails or `ctx` is done
+func receive(ctx context.Context, connect connector, reconnect backoff.BackOff, output chan<- Event) error {
+	ln, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	reconnect.Reset()
+
+	// Receive is not interrupted by context, so listener is closed to stop waiting
+	done := make(chan struct{})
+	defer close(done)
+	defer ln.Close()
+	go func() {
+		select {
+		case <-ctx.Done():
+			ln.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		channel, payload, err := ln.Receive(ctx)
+		if err != nil {
+			return err
+		}
+		if channel != Channel {
+			continue
+		}
+
+		event, err := decodeEvent(payload)
+		if err != nil {
+			logger.Err(err)
+			continue
+		}
+
+		select {
+		case output <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func decodeEvent(payload string) (event Event, err error) {
+	if err = json.Unmarshal([]byte(payload), &event); err != nil {
+		return event, errors.Wrapf(err, "invalid event payload: %s", payload)
+	}
+	switch event.Kind {
+	case KindBlock, KindRollback:
+		return event, nil
+	default:
+		return event, errors.Errorf("unknown event kind: %s", event.Kind)
+	}
+}
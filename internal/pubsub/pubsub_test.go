@@ -0,0 +1,159 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/cenkalti/backoff"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    Event
+		wantErr bool
+	}{
+		{
+			name:    "block",
+			payload: `{"kind":"block","network":"mainnet","level":100}`,
+			want:    NewBlock(types.Mainnet, 100),
+		}, {
+			name:    "rollback",
+			payload: `{"kind":"rollback","network":"hangzhou2net","level":10}`,
+			want:    NewRollback(types.Hangzhou2net, 10),
+		}, {
+			name:    "unknown network",
+			payload: `{"kind":"block","network":"unknownnet","level":1}`,
+			wantErr: true,
+		}, {
+			name:    "unknown kind",
+			payload: `{"kind":"head","network":"mainnet","level":1}`,
+			wantErr: true,
+		}, {
+			name:    "invalid json",
+			payload: `{"kind":`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeEvent(tt.payload)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type notification struct {
+	channel string
+	payload string
+}
+
+// testReceiver - returns queued notifications and then fails like dropped connection
+type testReceiver struct {
+	notifications []notification
+	closed        chan struct{}
+	once          sync.Once
+}
+
+func newTestReceiver(notifications ...notification) *testReceiver {
+	return &testReceiver{
+		notifications: notifications,
+		closed:        make(chan struct{}),
+	}
+}
+
+func (r *testReceiver) Receive(ctx context.Context) (string, string, error) {
+	if len(r.notifications) == 0 {
+		return "", "", errors.New("connection reset by peer")
+	}
+	n := r.notifications[0]
+	r.notifications = r.notifications[1:]
+	return n.channel, n.payload, nil
+}
+
+func (r *testReceiver) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return nil
+}
+
+// blockingReceiver - waits for notifications until it's closed
+type blockingReceiver struct {
+	*testReceiver
+}
+
+func (r blockingReceiver) Receive(ctx context.Context) (string, string, error) {
+	<-r.closed
+	return "", "", errors.New("listener is closed")
+}
+
+func TestListen_Reconnect(t *testing.T) {
+	first := newTestReceiver(
+		notification{Channel, `{"kind":"block","network":"mainnet","level":1}`},
+		notification{"gopg:ping", ""},
+		notification{Channel, `invalid`},
+	)
+	second := newTestReceiver(
+		notification{Channel, `{"kind":"rollback","network":"mainnet","level":1}`},
+	)
+	last := blockingReceiver{newTestReceiver()}
+
+	var attempts int
+	connect := func(ctx context.Context) (receiver, error) {
+		attempts++
+		switch attempts {
+		case 1:
+			return first, nil
+		case 2:
+			return nil, errors.New("connection refused")
+		case 3:
+			return second, nil
+		default:
+			return last, nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := listen(ctx, connect, &backoff.ZeroBackOff{})
+
+	want := []Event{
+		NewBlock(types.Mainnet, 1),
+		NewRollback(types.Mainnet, 1),
+	}
+	for i := range want {
+		select {
+		case event := <-events:
+			assert.Equal(t, want[i], event)
+		case <-time.After(time.Second):
+			t.Fatalf("event %d was not received", i)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok, "channel should be closed")
+	case <-time.After(time.Second):
+		t.Fatal("listener is not stopped by context")
+	}
+
+	assert.Equal(t, 4, attempts)
+	for _, r := range []*testReceiver{first, second, last.testReceiver} {
+		select {
+		case <-r.closed:
+		default:
+			t.Error("receiver is not closed")
+		}
+	}
+}
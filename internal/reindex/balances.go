@@ -0,0 +1,485 @@
+package reindex
+
+import (
+	"context"
+	"sort"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/tokenbalance"
+	"github.com/baking-bad/bcdhub/internal/models/transfer"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+var errNotSupported = errors.New("is not supported while reindexing")
+
+type balanceKey struct {
+	contract  string
+	accountID int64
+	tokenID   uint64
+}
+
+type accountBalance struct {
+	AccountID int64
+	Address   string
+	TokenID   uint64
+	Balance   decimal.Decimal
+}
+
+// runningBalances - token balances which are replayed in memory while transfers are parsed again.
+// Parsers of ledgers and balance events calculate transfers from the current balance of holder,
+// so balance has to be the one before the parsed operation instead of the stored one.
+// It implements `tokenbalance.Repository` for parsers.
+type runningBalances struct {
+	db       pg.DBI
+	accounts account.Repository
+	network  types.Network
+	level    int64
+	// scope - subquery of reindexed operations, their stored transfers are ignored
+	scope *orm.Query
+
+	loaded    map[string]struct{}
+	balances  map[balanceKey]decimal.Decimal
+	addresses map[int64]string
+	ids       map[string]int64
+
+	lastTemporaryID int64
+}
+
+func newRunningBalances(db pg.DBI, accounts account.Repository, network types.Network, level int64, scope *orm.Query) *runningBalances {
+	return &runningBalances{
+		db:        db,
+		accounts:  accounts,
+		network:   network,
+		level:     level,
+		scope:     scope,
+		loaded:    make(map[string]struct{}),
+		balances:  make(map[balanceKey]decimal.Decimal),
+		addresses: make(map[int64]string),
+		ids:       make(map[string]int64),
+	}
+}
+
+// load - initializes balances of contract by stored transfers which are not reindexed
+func (rb *runningBalances) load(contract string) error {
+	if _, ok := rb.loaded[contract]; ok {
+		return nil
+	}
+
+	balances, err := calcBalances(rb.db, rb.network, contract, func(query *orm.Query) {
+		query.Where("transfer.level <= ?", rb.level).Where("transfer.operation_id NOT IN (?)", rb.scope)
+	})
+	if err != nil {
+		return err
+	}
+	for _, balance := range balances {
+		rb.set(contract, balance.AccountID, balance.Address, balance.TokenID, balance.Balance)
+	}
+	rb.loaded[contract] = struct{}{}
+	return nil
+}
+
+func (rb *runningBalances) set(contract string, accountID int64, address string, tokenID uint64, value decimal.Decimal) {
+	rb.balances[balanceKey{contract, accountID, tokenID}] = value
+	rb.addresses[accountID] = address
+	rb.ids[address] = accountID
+}
+
+func (rb *runningBalances) accountID(address string) (int64, error) {
+	if id, ok := rb.ids[address]; ok {
+		return id, nil
+	}
+	acc, err := rb.accounts.Get(rb.network, address)
+	switch {
+	case err == nil:
+	case errors.Is(err, pg.ErrNoRows):
+		// account isn't saved yet: it gets temporary negative identity until transfers are saved
+		rb.lastTemporaryID--
+		acc.ID = rb.lastTemporaryID
+	default:
+		return 0, errors.Wrap(err, address)
+	}
+	rb.ids[address] = acc.ID
+	rb.addresses[acc.ID] = address
+	return acc.ID, nil
+}
+
+// apply - applies transfers of parsed operation
+func (rb *runningBalances) apply(transfers []*transfer.Transfer) error {
+	for _, t := range transfers {
+		if t.Status != types.OperationStatusApplied {
+			continue
+		}
+		if err := rb.load(t.Contract); err != nil {
+			return err
+		}
+		if t.From.Address != "" {
+			if err := rb.add(t.Contract, t.From.Address, t.TokenID, t.Amount.Neg()); err != nil {
+				return err
+			}
+		}
+		if t.To.Address != "" {
+			if err := rb.add(t.Contract, t.To.Address, t.TokenID, t.Amount); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (rb *runningBalances) add(contract, address string, tokenID uint64, value decimal.Decimal) error {
+	id, err := rb.accountID(address)
+	if err != nil {
+		return err
+	}
+	key := balanceKey{contract, id, tokenID}
+	rb.balances[key] = rb.balances[key].Add(value)
+	return nil
+}
+
+// Get -
+func (rb *runningBalances) Get(network types.Network, contract string, accountID int64, tokenID uint64) (tokenbalance.TokenBalance, error) {
+	if err := rb.load(contract); err != nil {
+		return tokenbalance.TokenBalance{}, err
+	}
+	return tokenbalance.TokenBalance{
+		Network:   network,
+		Contract:  contract,
+		AccountID: accountID,
+		TokenID:   tokenID,
+		Balance:   rb.balances[balanceKey{contract, accountID, tokenID}],
+		Account: account.Account{
+			ID:      accountID,
+			Network: network,
+			Address: rb.addresses[accountID],
+			Type:    types.NewAccountType(rb.addresses[accountID]),
+		},
+	}, nil
+}
+
+// GetHolders -
+func (rb *runningBalances) GetHolders(network types.Network, contract string, tokenID uint64) ([]tokenbalance.TokenBalance, error) {
+	if err := rb.load(contract); err != nil {
+		return nil, err
+	}
+	holders := make([]tokenbalance.TokenBalance, 0)
+	for key, value := range rb.balances {
+		if key.contract != contract || key.tokenID != tokenID || value.IsZero() {
+			continue
+		}
+		balance, err := rb.Get(network, contract, key.accountID, tokenID)
+		if err != nil {
+			return nil, err
+		}
+		holders = append(holders, balance)
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		return holders[i].AccountID < holders[j].AccountID
+	})
+	return holders, nil
+}
+
+// Batch -
+func (rb *runningBalances) Batch(network types.Network, accountIDs []int64) (map[string][]tokenbalance.TokenBalance, error) {
+	return nil, errors.Wrap(errNotSupported, "Batch")
+}
+
+// CountByContract -
+func (rb *runningBalances) CountByContract(network types.Network, accountID int64, hideEmpty bool) (map[string]int64, error) {
+	return nil, errors.Wrap(errNotSupported, "CountByContract")
+}
+
+// TokenSupply -
+func (rb *runningBalances) TokenSupply(network types.Network, contract string, tokenID uint64) (string, error) {
+	return "", errors.Wrap(errNotSupported, "TokenSupply")
+}
+
+// calcBalances - sums applied transfers of contract by holders and tokens
+func calcBalances(db pg.DBI, network types.Network, contract string, filter func(query *orm.Query)) ([]accountBalance, error) {
+	sum := func(column string, sign int64) ([]accountBalance, error) {
+		var balances []accountBalance
+		query := db.Model((*transfer.Transfer)(nil)).
+			ColumnExpr("transfer.? as account_id, account.address, transfer.token_id, sum(transfer.amount) as balance", pg.Ident(column)).
+			Join("JOIN accounts AS account ON account.id = transfer.?", pg.Ident(column)).
+			Where("transfer.network = ?", network).
+			Where("transfer.contract = ?", contract).
+			Where("transfer.status = ?", types.OperationStatusApplied).
+			Group("transfer."+column, "account.address", "transfer.token_id")
+		if filter != nil {
+			filter(query)
+		}
+		if err := query.Select(&balances); err != nil {
+			return nil, err
+		}
+		if sign < 0 {
+			for i := range balances {
+				balances[i].Balance = balances[i].Balance.Neg()
+			}
+		}
+		return balances, nil
+	}
+
+	incomes, err := sum("to_id", 1)
+	if err != nil {
+		return nil, err
+	}
+	outcomes, err := sum("from_id", -1)
+	if err != nil {
+		return nil, err
+	}
+	return mergeBalances(append(incomes, outcomes...)), nil
+}
+
+// mergeBalances - sums balances of the same holder and token. Result is sorted by account and token.
+func mergeBalances(items []accountBalance) []accountBalance {
+	type key struct {
+		accountID int64
+		tokenID   uint64
+	}
+	index := make(map[key]int)
+	result := make([]accountBalance, 0, len(items))
+	for _, item := range items {
+		k := key{item.AccountID, item.TokenID}
+		if i, ok := index[k]; ok {
+			result[i].Balance = result[i].Balance.Add(item.Balance)
+			continue
+		}
+		index[k] = len(result)
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].AccountID == result[j].AccountID {
+			return result[i].TokenID < result[j].TokenID
+		}
+		return result[i].AccountID < result[j].AccountID
+	})
+	return result
+}
+
+// recalcBalances - replaces token balances of contracts by sums of their transfers.
+// Balances up to the reindexed level are staged first, then they are swapped in per contract.
+// `pending` are changes of transfers which are not saved yet (dry-run mode).
+func (m *Manager) recalcBalances(ctx context.Context, report *Report, contracts []string, pending map[string]*tokenbalance.TokenBalance) error {
+	db := m.ctx.StorageDB.DB
+
+	if m.params.DryRun {
+		for _, contract := range contracts {
+			staged, err := m.calcStagedBalances(db, contract, pending)
+			if err != nil {
+				return err
+			}
+			if err := m.replaceBalances(db, report, contract, staged); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := m.prepareStaging(db, (*stagedBalance)(nil)); err != nil {
+		return err
+	}
+	for _, contract := range contracts {
+		staged, err := m.calcStagedBalances(db, contract, nil)
+		if err != nil {
+			return err
+		}
+		if err := m.stageBalances(db, staged); err != nil {
+			return err
+		}
+	}
+
+	for _, contract := range contracts {
+		if err := db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+			if _, err := tx.Exec("LOCK TABLE token_balances IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+				return err
+			}
+			staged, err := m.stagedBalances(tx, contract)
+			if err != nil {
+				return err
+			}
+			if err := m.replaceBalances(tx, report, contract, staged); err != nil {
+				return err
+			}
+			return m.dropStaged(tx, (*stagedBalance)(nil), contract)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// calcStagedBalances - sums transfers of contract up to the reindexed level
+func (m *Manager) calcStagedBalances(db pg.DBI, contract string, pending map[string]*tokenbalance.TokenBalance) ([]*tokenbalance.TokenBalance, error) {
+	calculated, err := calcBalances(db, m.params.Network, contract, func(query *orm.Query) {
+		query.Where("transfer.level <= ?", m.level)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return applyDeltas(m.tokenBalances(contract, calculated), pending, contract), nil
+}
+
+// replaceBalances - replaces stored token balances of contract by staged ones and transfers above the reindexed level
+// which were saved by the indexer meanwhile. Nothing is written in dry-run mode.
+func (m *Manager) replaceBalances(db pg.DBI, report *Report, contract string, staged []*tokenbalance.TokenBalance) error {
+	var current []tokenbalance.TokenBalance
+	if err := db.Model(&current).
+		Relation("Account").
+		Where("token_balance.network = ?", m.params.Network).
+		Where("token_balance.contract = ?", contract).
+		Select(); err != nil {
+		return err
+	}
+
+	above, err := calcBalances(db, m.params.Network, contract, func(query *orm.Query) {
+		query.Where("transfer.level > ?", m.level)
+	})
+	if err != nil {
+		return err
+	}
+	increments := make(map[string]*tokenbalance.TokenBalance, len(above))
+	for _, balance := range m.tokenBalances(contract, above) {
+		increments[tokenBalanceID(balance)] = balance
+	}
+	expected := applyDeltas(staged, increments, contract)
+
+	changes := balancesDiff(current, expected)
+	report.Balances = append(report.Balances, changes...)
+
+	if m.params.DryRun || len(changes) == 0 {
+		return nil
+	}
+
+	if _, err := db.Model((*tokenbalance.TokenBalance)(nil)).
+		Where("network = ?", m.params.Network).
+		Where("contract = ?", contract).
+		Delete(); err != nil {
+		return err
+	}
+	for i := range expected {
+		if expected[i].Balance.IsZero() {
+			continue
+		}
+		if expected[i].AccountID == 0 {
+			if err := expected[i].Account.Save(db); err != nil {
+				return err
+			}
+			expected[i].AccountID = expected[i].Account.ID
+		}
+		expected[i].IsLedger = true
+		if err := expected[i].Save(db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) tokenBalances(contract string, balances []accountBalance) []*tokenbalance.TokenBalance {
+	result := make([]*tokenbalance.TokenBalance, 0, len(balances))
+	for i := range balances {
+		result = append(result, &tokenbalance.TokenBalance{
+			Network:   m.params.Network,
+			Contract:  contract,
+			AccountID: balances[i].AccountID,
+			TokenID:   balances[i].TokenID,
+			Balance:   balances[i].Balance,
+			Account: account.Account{
+				ID:      balances[i].AccountID,
+				Network: m.params.Network,
+				Address: balances[i].Address,
+				Type:    types.NewAccountType(balances[i].Address),
+			},
+		})
+	}
+	return result
+}
+
+// applyDeltas - adds pending changes of contract to balances
+func applyDeltas(balances []*tokenbalance.TokenBalance, deltas map[string]*tokenbalance.TokenBalance, contract string) []*tokenbalance.TokenBalance {
+	if len(deltas) == 0 {
+		return balances
+	}
+	index := make(map[string]*tokenbalance.TokenBalance, len(balances))
+	for i := range balances {
+		index[tokenBalanceID(balances[i])] = balances[i]
+	}
+	ids := make([]string, 0, len(deltas))
+	for id := range deltas {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		delta := deltas[id]
+		if delta.Contract != contract {
+			continue
+		}
+		if balance, ok := index[id]; ok {
+			balance.Balance = balance.Balance.Add(delta.Balance)
+			continue
+		}
+		balance := *delta
+		balances = append(balances, &balance)
+		index[id] = &balance
+	}
+	return balances
+}
+
+// applyBalanceDeltas - changes stored token balances by differences between old and new transfers
+func (m *Manager) applyBalanceDeltas(tx pg.DBI, transfers *transfersResult) error {
+	for _, delta := range sortedDeltas(transfers.deltas()) {
+		if delta.Balance.IsZero() {
+			continue
+		}
+		if delta.AccountID == 0 {
+			if err := delta.Account.Save(tx); err != nil {
+				return err
+			}
+			delta.AccountID = delta.Account.ID
+		}
+		if err := delta.Save(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// balanceContracts - returns contracts which balances are recalculated
+func (m *Manager) balanceContracts(db pg.DBI, transfers *transfersResult) ([]string, error) {
+	contracts := make(map[string]struct{})
+	if m.params.Address != "" {
+		contracts[m.params.Address] = struct{}{}
+	}
+
+	var stored []string
+	if err := db.Model((*transfer.Transfer)(nil)).
+		ColumnExpr("DISTINCT contract").
+		Where("network = ?", m.params.Network).
+		Where("operation_id IN (?)", m.operationIDs(db)).
+		Select(&stored); err != nil {
+		return nil, err
+	}
+	for i := range stored {
+		contracts[stored[i]] = struct{}{}
+	}
+
+	if transfers != nil {
+		for _, t := range transfers.added() {
+			contracts[t.Contract] = struct{}{}
+		}
+	}
+
+	return sortedKeys(contracts), nil
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
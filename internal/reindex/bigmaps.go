@@ -0,0 +1,302 @@
+package reindex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+)
+
+// bigMapContracts - returns contracts which big map states are rebuilt
+func (m *Manager) bigMapContracts(db pg.DBI) ([]string, error) {
+	if m.params.Address != "" {
+		return []string{m.params.Address}, nil
+	}
+
+	var contracts []string
+	query := db.Model((*bigmapdiff.BigMapDiff)(nil)).
+		ColumnExpr("DISTINCT contract").
+		Where("network = ?", m.params.Network).
+		Where("level <= ?", m.level)
+	if m.params.FromLevel > 0 {
+		query.Where("level >= ?", m.params.FromLevel)
+	}
+	if err := query.Select(&contracts); err != nil {
+		return nil, err
+	}
+	sort.Strings(contracts)
+	return contracts, nil
+}
+
+// rebuildBigMapStates - replaces big map states of contracts by the last diffs of their keys.
+// States at the reindexed level are staged first, then they are swapped in per contract.
+func (m *Manager) rebuildBigMapStates(ctx context.Context, report *Report, contracts []string) error {
+	db := m.ctx.StorageDB.DB
+
+	var rpc noderpc.INode
+	if m.params.CheckNode {
+		node, err := m.ctx.GetRPC(m.params.Network)
+		if err != nil {
+			return err
+		}
+		rpc = node
+	}
+
+	if m.params.DryRun {
+		for _, contract := range contracts {
+			staged, err := m.calcStates(rpc, contract)
+			if err != nil {
+				return err
+			}
+			if err := m.replaceStates(db, report, contract, staged); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := m.prepareStaging(db, (*stagedState)(nil)); err != nil {
+		return err
+	}
+	for _, contract := range contracts {
+		staged, err := m.calcStates(rpc, contract)
+		if err != nil {
+			return err
+		}
+		if err := m.stageStates(db, staged); err != nil {
+			return err
+		}
+	}
+
+	for _, contract := range contracts {
+		if err := db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+			if _, err := tx.Exec("LOCK TABLE big_map_states IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+				return err
+			}
+			staged, err := m.stagedStates(tx, contract)
+			if err != nil {
+				return err
+			}
+			if err := m.replaceStates(tx, report, contract, staged); err != nil {
+				return err
+			}
+			return m.dropStaged(tx, (*stagedState)(nil), contract)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// calcStates - returns states of contract at the reindexed level built from the last diffs of keys.
+// If `rpc` is set, they are checked by the node's context.
+func (m *Manager) calcStates(rpc noderpc.INode, contract string) ([]bigmapdiff.BigMapState, error) {
+	states, err := m.ctx.BigMapDiffs.GetForAddressAtLevel(m.params.Network, contract, m.level)
+	if err != nil {
+		return nil, err
+	}
+	if rpc == nil {
+		return states, nil
+	}
+	return decodeStates(rpc, states, m.level)
+}
+
+// decodeStates - checks values of states by the node's context at `level`. Node's value is kept if it differs from the diffs.
+// Temporary and alpha big maps (negative pointers) aren't stored in the context, so their states are left as is.
+// Absent key is a removed one only if the node keeps the context of `level`: pruned block is answered by 404 too.
+func decodeStates(rpc noderpc.INode, states []bigmapdiff.BigMapState, level int64) ([]bigmapdiff.BigMapState, error) {
+	if err := checkContext(rpc, level); err != nil {
+		return nil, err
+	}
+
+	for i := range states {
+		if states[i].Ptr < 0 {
+			continue
+		}
+		raw, err := rpc.GetBigMapValue(states[i].Ptr, states[i].KeyHash, level)
+		if err != nil {
+			return nil, errors.Wrapf(err, "ptr %d key %s", states[i].Ptr, states[i].KeyHash)
+		}
+
+		if raw == nil {
+			if !states[i].Removed {
+				logger.Warning().Str("contract", states[i].Contract).Int64("ptr", states[i].Ptr).Str("key_hash", states[i].KeyHash).Msg("key is absent in the node's context")
+				states[i].Removed = true
+			}
+			continue
+		}
+
+		var value bytes.Buffer
+		if err := json.Compact(&value, raw); err != nil {
+			return nil, errors.Wrapf(err, "ptr %d key %s", states[i].Ptr, states[i].KeyHash)
+		}
+		if states[i].Removed || !bytes.Equal(states[i].Value, value.Bytes()) {
+			logger.Warning().Str("contract", states[i].Contract).Int64("ptr", states[i].Ptr).Str("key_hash", states[i].KeyHash).Msg("value differs from the node's context")
+			states[i].Removed = false
+			states[i].Value = value.Bytes()
+		}
+	}
+	return states, nil
+}
+
+// checkContext - returns error if the node doesn't keep block of `level` and its context
+func checkContext(rpc noderpc.INode, level int64) error {
+	header, err := rpc.GetHeader(level)
+	if err != nil {
+		return errors.Wrapf(err, "node doesn't keep block %d", level)
+	}
+	if header.Level != level {
+		return errors.Errorf("node returned block %d instead of %d", header.Level, level)
+	}
+	if _, err := rpc.GetNetworkConstants(level); err != nil {
+		return errors.Wrapf(err, "node doesn't keep context of block %d", level)
+	}
+	return nil
+}
+
+// replaceStates - replaces stored big map states of contract by staged ones. Keys which were changed by the indexer
+// above the reindexed level are left as is. Nothing is written in dry-run mode.
+func (m *Manager) replaceStates(db pg.DBI, report *Report, contract string, staged []bigmapdiff.BigMapState) error {
+	var current []bigmapdiff.BigMapState
+	if err := db.Model(&current).
+		Where("network = ?", m.params.Network).
+		Where("contract = ?", contract).
+		Select(); err != nil {
+		return err
+	}
+
+	var changed []bigmapdiff.BigMapState
+	if err := db.Model((*bigmapdiff.BigMapDiff)(nil)).
+		ColumnExpr("DISTINCT ptr, key_hash").
+		Where("network = ?", m.params.Network).
+		Where("contract = ?", contract).
+		Where("level > ?", m.level).
+		Select(&changed); err != nil {
+		return err
+	}
+
+	updates, deleted := statesDiff(skipStates(current, changed), skipStates(staged, changed))
+	for i := range updates {
+		report.BigMapStates = append(report.BigMapStates, newStateChange(updates[i].state, updates[i].action))
+	}
+	for i := range deleted {
+		report.BigMapStates = append(report.BigMapStates, newStateChange(deleted[i], StateDeleted))
+	}
+
+	if m.params.DryRun {
+		return nil
+	}
+
+	for i := range updates {
+		if _, err := db.Model(&updates[i].state).
+			OnConflict("(network, contract, ptr, key_hash) DO UPDATE").
+			Set("removed = EXCLUDED.removed, last_update_level = EXCLUDED.last_update_level, last_update_time = EXCLUDED.last_update_time, value = CASE WHEN EXCLUDED.removed THEN big_map_state.value ELSE EXCLUDED.value END").
+			Insert(); err != nil {
+			return err
+		}
+	}
+
+	if len(deleted) > 0 {
+		ids := make([]int64, len(deleted))
+		for i := range deleted {
+			ids[i] = deleted[i].ID
+		}
+		if _, err := db.Model((*bigmapdiff.BigMapState)(nil)).
+			WhereIn("id IN (?)", ids).
+			Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipStates - returns states except keys of `skipped`
+func skipStates(states, skipped []bigmapdiff.BigMapState) []bigmapdiff.BigMapState {
+	if len(skipped) == 0 {
+		return states
+	}
+	type stateKey struct {
+		ptr     int64
+		keyHash string
+	}
+	index := make(map[stateKey]struct{}, len(skipped))
+	for i := range skipped {
+		index[stateKey{skipped[i].Ptr, skipped[i].KeyHash}] = struct{}{}
+	}
+	result := make([]bigmapdiff.BigMapState, 0, len(states))
+	for i := range states {
+		if _, ok := index[stateKey{states[i].Ptr, states[i].KeyHash}]; !ok {
+			result = append(result, states[i])
+		}
+	}
+	return result
+}
+
+type stateUpdate struct {
+	state  bigmapdiff.BigMapState
+	action string
+}
+
+// statesDiff - returns expected states which differ from current ones and current states without diffs
+func statesDiff(current, expected []bigmapdiff.BigMapState) ([]stateUpdate, []bigmapdiff.BigMapState) {
+	type stateKey struct {
+		ptr     int64
+		keyHash string
+	}
+
+	index := make(map[stateKey]bigmapdiff.BigMapState, len(current))
+	for i := range current {
+		index[stateKey{current[i].Ptr, current[i].KeyHash}] = current[i]
+	}
+
+	updates := make([]stateUpdate, 0)
+	for i := range expected {
+		key := stateKey{expected[i].Ptr, expected[i].KeyHash}
+		state, ok := index[key]
+		delete(index, key)
+
+		switch {
+		case !ok:
+			updates = append(updates, stateUpdate{expected[i], StateAdded})
+		case !sameState(state, expected[i]):
+			updates = append(updates, stateUpdate{expected[i], StateUpdated})
+		}
+	}
+
+	deleted := make([]bigmapdiff.BigMapState, 0, len(index))
+	for _, state := range index {
+		deleted = append(deleted, state)
+	}
+
+	sort.Slice(updates, func(i, j int) bool {
+		return lessState(updates[i].state, updates[j].state)
+	})
+	sort.Slice(deleted, func(i, j int) bool {
+		return lessState(deleted[i], deleted[j])
+	})
+	return updates, deleted
+}
+
+// sameState - compares states ignoring counter of updates. Value of removed key is kept in the state, so it's ignored too.
+func sameState(current, expected bigmapdiff.BigMapState) bool {
+	if current.Removed != expected.Removed || current.LastUpdateLevel != expected.LastUpdateLevel || !current.LastUpdateTime.Equal(expected.LastUpdateTime) {
+		return false
+	}
+	if !bytes.Equal(current.Key, expected.Key) {
+		return false
+	}
+	return current.Removed || bytes.Equal(current.Value, expected.Value)
+}
+
+func lessState(a, b bigmapdiff.BigMapState) bool {
+	if a.Ptr == b.Ptr {
+		return a.KeyHash < b.KeyHash
+	}
+	return a.Ptr < b.Ptr
+}
@@ -0,0 +1,202 @@
+package reindex
+
+import (
+	"context"
+
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/config"
+	"github.com/baking-bad/bcdhub/internal/helpers"
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/tokenbalance"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/pkg/errors"
+)
+
+// Entities which can be reindexed
+const (
+	EntityTransfers     = "transfers"
+	EntityTokenBalances = "token_balances"
+	EntityBigMapStates  = "big_map_states"
+)
+
+// Entities - all reindexed entities in order of processing
+var Entities = []string{EntityTransfers, EntityTokenBalances, EntityBigMapStates}
+
+const operationsChunkSize = 1000
+
+// Params - scope of reindexing. At least one of `Address` or levels range has to be set.
+type Params struct {
+	Network   types.Network
+	Address   string
+	FromLevel int64
+	ToLevel   int64
+	Entities  []string
+	DryRun    bool
+	CheckNode bool
+}
+
+// Validate -
+func (params Params) Validate() error {
+	if params.Network == types.Empty {
+		return errors.New("network is required")
+	}
+	if params.Address != "" && !bcd.IsContract(params.Address) {
+		return errors.Errorf("invalid contract address: %s", params.Address)
+	}
+	if params.Address == "" && params.FromLevel == 0 && params.ToLevel == 0 {
+		return errors.New("contract address or levels range is required")
+	}
+	if params.ToLevel > 0 && params.FromLevel > params.ToLevel {
+		return errors.Errorf("from level is greater than to level: %d > %d", params.FromLevel, params.ToLevel)
+	}
+	for _, entity := range params.Entities {
+		if !helpers.StringInArray(entity, Entities) {
+			return errors.Errorf("unknown entity: %s", entity)
+		}
+	}
+	return nil
+}
+
+func (params Params) has(entity string) bool {
+	return len(params.Entities) == 0 || helpers.StringInArray(entity, params.Entities)
+}
+
+// Manager - rebuilds derived data (transfers, token balances and big map states) from stored operations.
+// Transfers are replaced in one transaction and token balances are changed by increments like the indexer does.
+// Token balances and big map states are rebuilt at the reindexed level into staging tables (`reindex_token_balances`
+// and `reindex_big_map_states`) and swapped in per contract by short transactions, so the live indexer waits only for one contract.
+// Values of big map states are checked by the node's context if `CheckNode` is set. Concurrent runs for the same network aren't supported.
+type Manager struct {
+	ctx    *config.Context
+	params Params
+
+	// level - last indexed level at start, operations above it are left to the indexer
+	level         int64
+	chainID       string
+	destinationID int64
+}
+
+// NewManager -
+func NewManager(ctx *config.Context, params Params) *Manager {
+	return &Manager{
+		ctx:    ctx,
+		params: params,
+	}
+}
+
+// Reindex - rebuilds derived data in the scope. In dry-run mode the report is built, but nothing is saved.
+func (m *Manager) Reindex(ctx context.Context) (*Report, error) {
+	if err := m.params.Validate(); err != nil {
+		return nil, err
+	}
+
+	state, err := m.ctx.Blocks.Last(m.params.Network)
+	if err != nil {
+		return nil, err
+	}
+	m.level = state.Level
+	m.chainID = state.ChainID
+	if m.params.ToLevel > 0 && m.params.ToLevel < m.level {
+		m.level = m.params.ToLevel
+	}
+
+	if m.params.Address != "" {
+		acc, err := m.ctx.Accounts.Get(m.params.Network, m.params.Address)
+		if err != nil {
+			return nil, errors.Wrap(err, m.params.Address)
+		}
+		m.destinationID = acc.ID
+	}
+
+	report := &Report{
+		Network:   m.params.Network,
+		Address:   m.params.Address,
+		FromLevel: m.params.FromLevel,
+		ToLevel:   m.level,
+		DryRun:    m.params.DryRun,
+	}
+
+	var transfers *transfersResult
+	if m.params.has(EntityTransfers) {
+		logger.Info().Msg("Parsing transfers...")
+		if transfers, err = m.parseTransfers(report); err != nil {
+			return nil, err
+		}
+	}
+
+	if transfers != nil && !m.params.has(EntityTokenBalances) {
+		if report.Balances, err = m.deltaChanges(transfers.deltas()); err != nil {
+			return nil, err
+		}
+	}
+
+	if transfers != nil && !m.params.DryRun {
+		if err := m.ctx.StorageDB.DB.RunInTransaction(ctx, func(tx *pg.Tx) error {
+			if err := m.replaceTransfers(tx, transfers); err != nil {
+				return err
+			}
+			if !m.params.has(EntityTokenBalances) {
+				return m.applyBalanceDeltas(tx, transfers)
+			}
+			return nil
+		}); err != nil {
+			return report, err
+		}
+	}
+	return report, m.rebuildDerivedStates(ctx, report, transfers)
+}
+
+// rebuildDerivedStates - recalculates token balances and big map states. Nothing is written in dry-run mode.
+func (m *Manager) rebuildDerivedStates(ctx context.Context, report *Report, transfers *transfersResult) error {
+	db := m.ctx.StorageDB.DB
+
+	if m.params.has(EntityTokenBalances) {
+		logger.Info().Msg("Recalculating token balances...")
+		contracts, err := m.balanceContracts(db, transfers)
+		if err != nil {
+			return err
+		}
+		var pending map[string]*tokenbalance.TokenBalance
+		if m.params.DryRun && transfers != nil {
+			pending = transfers.deltas()
+		}
+		if err := m.recalcBalances(ctx, report, contracts, pending); err != nil {
+			return err
+		}
+	}
+
+	if m.params.has(EntityBigMapStates) {
+		logger.Info().Msg("Rebuilding big map states...")
+		contracts, err := m.bigMapContracts(db)
+		if err != nil {
+			return err
+		}
+		if err := m.rebuildBigMapStates(ctx, report, contracts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scope - filters transactions in the scope
+func (m *Manager) scope(query *orm.Query) (*orm.Query, error) {
+	query.
+		Where("operation.network = ?", m.params.Network).
+		Where("operation.kind = ?", types.OperationKindTransaction).
+		Where("operation.level <= ?", m.level)
+	if m.params.FromLevel > 0 {
+		query.Where("operation.level >= ?", m.params.FromLevel)
+	}
+	if m.destinationID > 0 {
+		query.Where("operation.destination_id = ?", m.destinationID)
+	}
+	return query, nil
+}
+
+// operationIDs - returns subquery of identities of transactions in the scope
+func (m *Manager) operationIDs(db pg.DBI) *orm.Query {
+	return db.Model((*operation.Operation)(nil)).Column("operation.id").Apply(m.scope)
+}
@@ -0,0 +1,326 @@
+package reindex
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/tokenbalance"
+	"github.com/baking-bad/bcdhub/internal/models/transfer"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTransfer(from, to string, amount int64, status types.OperationStatus) *transfer.Transfer {
+	return &transfer.Transfer{
+		Network:  types.Mainnet,
+		Contract: "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn",
+		From:     account.Account{Network: types.Mainnet, Address: from, Type: types.NewAccountType(from)},
+		To:       account.Account{Network: types.Mainnet, Address: to, Type: types.NewAccountType(to)},
+		Amount:   decimal.NewFromInt(amount),
+		Status:   status,
+	}
+}
+
+func TestParams_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  Params
+		wantErr bool
+	}{
+		{
+			name:   "contract",
+			params: Params{Network: types.Mainnet, Address: "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn"},
+		}, {
+			name:   "levels range",
+			params: Params{Network: types.Mainnet, FromLevel: 10, ToLevel: 20, Entities: []string{EntityTransfers}},
+		}, {
+			name:    "without network",
+			params:  Params{Address: "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn"},
+			wantErr: true,
+		}, {
+			name:    "empty scope",
+			params:  Params{Network: types.Mainnet},
+			wantErr: true,
+		}, {
+			name:    "not contract",
+			params:  Params{Network: types.Mainnet, Address: "tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6"},
+			wantErr: true,
+		}, {
+			name:    "invalid range",
+			params:  Params{Network: types.Mainnet, FromLevel: 20, ToLevel: 10},
+			wantErr: true,
+		}, {
+			name:    "unknown entity",
+			params:  Params{Network: types.Mainnet, FromLevel: 10, Entities: []string{"operations"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.params.Validate()
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func Test_sameTransfers(t *testing.T) {
+	alice := "tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6"
+	bob := "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb"
+
+	tests := []struct {
+		name string
+		old  []*transfer.Transfer
+		new  []*transfer.Transfer
+		want bool
+	}{
+		{
+			name: "empty",
+			want: true,
+		}, {
+			name: "different order",
+			old:  []*transfer.Transfer{newTransfer(alice, bob, 10, types.OperationStatusApplied), newTransfer("", alice, 5, types.OperationStatusApplied)},
+			new:  []*transfer.Transfer{newTransfer("", alice, 5, types.OperationStatusApplied), newTransfer(alice, bob, 10, types.OperationStatusApplied)},
+			want: true,
+		}, {
+			name: "different amount",
+			old:  []*transfer.Transfer{newTransfer(alice, bob, 10, types.OperationStatusApplied)},
+			new:  []*transfer.Transfer{newTransfer(alice, bob, 11, types.OperationStatusApplied)},
+		}, {
+			name: "duplicate",
+			old:  []*transfer.Transfer{newTransfer(alice, bob, 10, types.OperationStatusApplied), newTransfer(alice, bob, 10, types.OperationStatusApplied)},
+			new:  []*transfer.Transfer{newTransfer(alice, bob, 10, types.OperationStatusApplied), newTransfer(bob, alice, 10, types.OperationStatusApplied)},
+		}, {
+			name: "missing",
+			old:  []*transfer.Transfer{newTransfer(alice, bob, 10, types.OperationStatusApplied)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sameTransfers(tt.old, tt.new))
+		})
+	}
+}
+
+func Test_transfersResult_deltas(t *testing.T) {
+	alice := "tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6"
+	bob := "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb"
+
+	result := transfersResult{
+		changed: []operationTransfers{
+			{
+				old: []*transfer.Transfer{newTransfer(alice, bob, 10, types.OperationStatusApplied)},
+				new: []*transfer.Transfer{newTransfer(alice, bob, 15, types.OperationStatusApplied)},
+			}, {
+				old: []*transfer.Transfer{newTransfer("", alice, 7, types.OperationStatusApplied)},
+				new: []*transfer.Transfer{newTransfer("", alice, 7, types.OperationStatusFailed)},
+			},
+		},
+	}
+
+	want := map[string]int64{
+		alice: -12,
+		bob:   5,
+	}
+
+	deltas := result.deltas()
+	assert.Len(t, deltas, len(want))
+	for _, delta := range deltas {
+		amount, ok := want[delta.Account.Address]
+		if assert.True(t, ok, delta.Account.Address) {
+			assert.Equal(t, decimal.NewFromInt(amount).String(), delta.Balance.String(), delta.Account.Address)
+		}
+	}
+}
+
+func Test_statesDiff(t *testing.T) {
+	ts := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	state := func(id int64, keyHash string, level int64, value string, removed bool) bigmapdiff.BigMapState {
+		s := bigmapdiff.BigMapState{
+			ID:              id,
+			Ptr:             1,
+			KeyHash:         keyHash,
+			Key:             types.Bytes(`{"int":"1"}`),
+			LastUpdateLevel: level,
+			LastUpdateTime:  ts.Add(time.Duration(level) * time.Minute),
+			Removed:         removed,
+		}
+		if value != "" {
+			s.Value = types.Bytes(value)
+		}
+		return s
+	}
+
+	current := []bigmapdiff.BigMapState{
+		state(1, "expru1", 10, `{"int":"1"}`, false),
+		state(2, "expru2", 10, `{"int":"2"}`, false),
+		state(3, "expru3", 10, `{"int":"3"}`, true),
+		state(4, "expru4", 10, `{"int":"4"}`, false),
+	}
+	expected := []bigmapdiff.BigMapState{
+		state(0, "expru1", 10, `{"int":"1"}`, false),
+		state(0, "expru2", 12, `{"int":"5"}`, false),
+		state(0, "expru3", 10, "", true),
+		state(0, "expru5", 11, `{"int":"6"}`, false),
+	}
+
+	updates, deleted := statesDiff(current, expected)
+
+	actions := make(map[string]string)
+	for _, update := range updates {
+		actions[update.state.KeyHash] = update.action
+	}
+	assert.Equal(t, map[string]string{
+		"expru2": StateUpdated,
+		"expru5": StateAdded,
+	}, actions)
+
+	if assert.Len(t, deleted, 1) {
+		assert.Equal(t, int64(4), deleted[0].ID)
+	}
+}
+
+func Test_mergeBalances(t *testing.T) {
+	items := []accountBalance{
+		{AccountID: 2, TokenID: 0, Balance: decimal.NewFromInt(10)},
+		{AccountID: 1, TokenID: 1, Balance: decimal.NewFromInt(3)},
+		{AccountID: 2, TokenID: 0, Balance: decimal.NewFromInt(-4)},
+		{AccountID: 1, TokenID: 0, Balance: decimal.NewFromInt(1)},
+	}
+	want := []accountBalance{
+		{AccountID: 1, TokenID: 0, Balance: decimal.NewFromInt(1)},
+		{AccountID: 1, TokenID: 1, Balance: decimal.NewFromInt(3)},
+		{AccountID: 2, TokenID: 0, Balance: decimal.NewFromInt(6)},
+	}
+
+	got := mergeBalances(items)
+	if assert.Len(t, got, len(want)) {
+		for i := range want {
+			assert.Equal(t, want[i].AccountID, got[i].AccountID)
+			assert.Equal(t, want[i].TokenID, got[i].TokenID)
+			assert.Equal(t, want[i].Balance.String(), got[i].Balance.String())
+		}
+	}
+}
+
+func Test_decodeStates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	states := []bigmapdiff.BigMapState{
+		{Ptr: 1, KeyHash: "expru1", Value: types.Bytes(`{"int":"1"}`)},
+		{Ptr: 1, KeyHash: "expru2", Value: types.Bytes(`{"int":"2"}`)},
+		{Ptr: 1, KeyHash: "expru3", Value: types.Bytes(`{"int":"3"}`)},
+		{Ptr: 1, KeyHash: "expru4", Value: types.Bytes(`{"int":"4"}`), Removed: true},
+		{Ptr: -1, KeyHash: "expru5", Value: types.Bytes(`{"int":"5"}`)},
+	}
+
+	rpc := noderpc.NewMockINode(ctrl)
+	rpc.EXPECT().GetHeader(int64(100)).Return(noderpc.Header{Level: 100}, nil).Times(1)
+	rpc.EXPECT().GetNetworkConstants(int64(100)).Return(noderpc.Constants{}, nil).Times(1)
+	rpc.EXPECT().GetBigMapValue(int64(1), "expru1", int64(100)).Return([]byte("{ \"int\": \"1\" }\n"), nil).Times(1)
+	rpc.EXPECT().GetBigMapValue(int64(1), "expru2", int64(100)).Return([]byte(`{"int":"20"}`), nil).Times(1)
+	rpc.EXPECT().GetBigMapValue(int64(1), "expru3", int64(100)).Return(nil, nil).Times(1)
+	rpc.EXPECT().GetBigMapValue(int64(1), "expru4", int64(100)).Return([]byte(`{"int":"40"}`), nil).Times(1)
+
+	got, err := decodeStates(rpc, states, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []bigmapdiff.BigMapState{
+		{Ptr: 1, KeyHash: "expru1", Value: types.Bytes(`{"int":"1"}`)},
+		{Ptr: 1, KeyHash: "expru2", Value: types.Bytes(`{"int":"20"}`)},
+		{Ptr: 1, KeyHash: "expru3", Value: types.Bytes(`{"int":"3"}`), Removed: true},
+		{Ptr: 1, KeyHash: "expru4", Value: types.Bytes(`{"int":"40"}`)},
+		{Ptr: -1, KeyHash: "expru5", Value: types.Bytes(`{"int":"5"}`)},
+	}, got)
+}
+
+func Test_decodeStates_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rpc := noderpc.NewMockINode(ctrl)
+	rpc.EXPECT().GetHeader(int64(100)).Return(noderpc.Header{Level: 100}, nil).Times(1)
+	rpc.EXPECT().GetNetworkConstants(int64(100)).Return(noderpc.Constants{}, nil).Times(1)
+	rpc.EXPECT().GetBigMapValue(int64(1), "expru1", int64(100)).Return(nil, errors.New("node is unavailable")).Times(1)
+
+	_, err := decodeStates(rpc, []bigmapdiff.BigMapState{{Ptr: 1, KeyHash: "expru1"}}, 100)
+	assert.Error(t, err)
+}
+
+func Test_decodeStates_PrunedContext(t *testing.T) {
+	errNotFound := noderpc.InvalidStatusCodeError{Code: http.StatusNotFound}
+
+	tests := []struct {
+		name   string
+		expect func(rpc *noderpc.MockINode)
+	}{
+		{
+			name: "block is pruned",
+			expect: func(rpc *noderpc.MockINode) {
+				rpc.EXPECT().GetHeader(int64(100)).Return(noderpc.Header{}, errNotFound).Times(1)
+			},
+		}, {
+			name: "context is pruned",
+			expect: func(rpc *noderpc.MockINode) {
+				rpc.EXPECT().GetHeader(int64(100)).Return(noderpc.Header{Level: 100}, nil).Times(1)
+				rpc.EXPECT().GetNetworkConstants(int64(100)).Return(noderpc.Constants{}, errNotFound).Times(1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// keys aren't requested: their 404 would be answered for pruned block too
+			rpc := noderpc.NewMockINode(ctrl)
+			tt.expect(rpc)
+
+			_, err := decodeStates(rpc, []bigmapdiff.BigMapState{
+				{Ptr: 1, KeyHash: "expru1", Value: types.Bytes(`{"int":"1"}`)},
+			}, 100)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_skipStates(t *testing.T) {
+	states := []bigmapdiff.BigMapState{
+		{Ptr: 1, KeyHash: "expru1"},
+		{Ptr: 1, KeyHash: "expru2"},
+		{Ptr: 2, KeyHash: "expru1"},
+	}
+	skipped := []bigmapdiff.BigMapState{
+		{Ptr: 1, KeyHash: "expru1"},
+		{Ptr: 3, KeyHash: "expru3"},
+	}
+	assert.Equal(t, []bigmapdiff.BigMapState{
+		{Ptr: 1, KeyHash: "expru2"},
+		{Ptr: 2, KeyHash: "expru1"},
+	}, skipStates(states, skipped))
+	assert.Equal(t, states, skipStates(states, nil))
+}
+
+func Test_stagedBalance(t *testing.T) {
+	balance := &tokenbalance.TokenBalance{
+		Network:   types.Mainnet,
+		Contract:  "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn",
+		AccountID: 10,
+		TokenID:   1,
+		Balance:   decimal.NewFromInt(100),
+		Account: account.Account{
+			ID:      10,
+			Network: types.Mainnet,
+			Address: "tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6",
+			Type:    types.AccountTypeTz,
+		},
+	}
+	assert.Equal(t, balance, newStagedBalance(balance).tokenBalance())
+}
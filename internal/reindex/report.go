@@ -0,0 +1,262 @@
+package reindex
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/tokenbalance"
+	"github.com/baking-bad/bcdhub/internal/models/transfer"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/shopspring/decimal"
+)
+
+// Actions of big map state change
+const (
+	StateAdded   = "added"
+	StateUpdated = "updated"
+	StateDeleted = "deleted"
+)
+
+// Report - differences between stored and rebuilt data
+type Report struct {
+	Network   types.Network
+	Address   string
+	FromLevel int64
+	ToLevel   int64
+	DryRun    bool
+
+	Operations   int64
+	Transfers    []TransferChange
+	Balances     []BalanceChange
+	BigMapStates []StateChange
+}
+
+// Empty - returns true if stored data is equal to rebuilt one
+func (r *Report) Empty() bool {
+	return len(r.Transfers) == 0 && len(r.Balances) == 0 && len(r.BigMapStates) == 0
+}
+
+// Write - writes report in diff-like format: `-` is stored row, `+` is rebuilt one
+func (r *Report) Write(w io.Writer) error {
+	var b strings.Builder
+
+	scope := fmt.Sprintf("levels %d-%d", r.FromLevel, r.ToLevel)
+	if r.Address != "" {
+		scope = fmt.Sprintf("%s, %s", r.Address, scope)
+	}
+	fmt.Fprintf(&b, "network %s, %s: %d operations parsed\n", r.Network.String(), scope, r.Operations)
+
+	if len(r.Transfers) > 0 {
+		fmt.Fprintf(&b, "\ntransfers of %d operations:\n", len(r.Transfers))
+		for _, change := range r.Transfers {
+			fmt.Fprintf(&b, "  %s (id %d, level %d)\n", change.Hash, change.OperationID, change.Level)
+			for _, item := range change.Removed {
+				fmt.Fprintf(&b, "  - %s\n", item)
+			}
+			for _, item := range change.Added {
+				fmt.Fprintf(&b, "  + %s\n", item)
+			}
+		}
+	}
+
+	if len(r.Balances) > 0 {
+		fmt.Fprintf(&b, "\ntoken balances (%d):\n", len(r.Balances))
+		for _, change := range r.Balances {
+			fmt.Fprintf(&b, "  %s %s #%d: %s -> %s\n", change.Contract, change.Address, change.TokenID, change.Old.String(), change.New.String())
+		}
+	}
+
+	if len(r.BigMapStates) > 0 {
+		fmt.Fprintf(&b, "\nbig map states (%d):\n", len(r.BigMapStates))
+		for _, change := range r.BigMapStates {
+			fmt.Fprintf(&b, "  %-7s %s ptr %d %s (level %d)\n", change.Action, change.Contract, change.Ptr, change.KeyHash, change.Level)
+		}
+	}
+
+	if r.Empty() {
+		b.WriteString("\nno changes\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// TransferItem -
+type TransferItem struct {
+	Contract string
+	From     string
+	To       string
+	TokenID  uint64
+	Amount   decimal.Decimal
+	Status   types.OperationStatus
+	Parent   string
+}
+
+// String -
+func (item TransferItem) String() string {
+	from, to := item.From, item.To
+	if from == "" {
+		from = "mint"
+	}
+	if to == "" {
+		to = "burn"
+	}
+	return fmt.Sprintf("%s #%d %s: %s -> %s [%s, %s]", item.Contract, item.TokenID, item.Amount.String(), from, to, item.Status.String(), item.Parent)
+}
+
+// TransferChange - stored and parsed transfers of operation
+type TransferChange struct {
+	OperationID int64
+	Hash        string
+	Level       int64
+	Removed     []TransferItem
+	Added       []TransferItem
+}
+
+func newTransferChange(op operation.Operation, old, new []*transfer.Transfer) TransferChange {
+	return TransferChange{
+		OperationID: op.ID,
+		Hash:        op.Hash,
+		Level:       op.Level,
+		Removed:     newTransferItems(old),
+		Added:       newTransferItems(new),
+	}
+}
+
+func newTransferItems(transfers []*transfer.Transfer) []TransferItem {
+	items := make([]TransferItem, len(transfers))
+	for i, t := range transfers {
+		items[i] = TransferItem{
+			Contract: t.Contract,
+			From:     t.From.Address,
+			To:       t.To.Address,
+			TokenID:  t.TokenID,
+			Amount:   t.Amount,
+			Status:   t.Status,
+			Parent:   t.Parent.String(),
+		}
+	}
+	return items
+}
+
+// BalanceChange -
+type BalanceChange struct {
+	Contract string
+	Address  string
+	TokenID  uint64
+	Old      decimal.Decimal
+	New      decimal.Decimal
+}
+
+// balancesDiff - compares stored balances with expected ones. Missing balance is zero.
+func balancesDiff(current []tokenbalance.TokenBalance, expected []*tokenbalance.TokenBalance) []BalanceChange {
+	changes := make(map[string]*BalanceChange)
+	for i := range current {
+		id := tokenBalanceID(&current[i])
+		changes[id] = &BalanceChange{
+			Contract: current[i].Contract,
+			Address:  current[i].Account.Address,
+			TokenID:  current[i].TokenID,
+			Old:      current[i].Balance,
+		}
+	}
+	for i := range expected {
+		id := tokenBalanceID(expected[i])
+		change, ok := changes[id]
+		if !ok {
+			change = &BalanceChange{
+				Contract: expected[i].Contract,
+				Address:  expected[i].Account.Address,
+				TokenID:  expected[i].TokenID,
+			}
+			changes[id] = change
+		}
+		change.New = expected[i].Balance
+	}
+
+	result := make([]BalanceChange, 0)
+	for _, change := range changes {
+		if !change.Old.Equal(change.New) {
+			result = append(result, *change)
+		}
+	}
+	sortBalanceChanges(result)
+	return result
+}
+
+// deltaChanges - returns changes of stored balances by deltas
+func (m *Manager) deltaChanges(deltas map[string]*tokenbalance.TokenBalance) ([]BalanceChange, error) {
+	result := make([]BalanceChange, 0)
+	for _, delta := range deltas {
+		if delta.Balance.IsZero() {
+			continue
+		}
+		change := BalanceChange{
+			Contract: delta.Contract,
+			Address:  delta.Account.Address,
+			TokenID:  delta.TokenID,
+			Old:      decimal.Zero,
+		}
+		if delta.AccountID > 0 {
+			balance, err := m.ctx.TokenBalances.Get(delta.Network, delta.Contract, delta.AccountID, delta.TokenID)
+			if err != nil {
+				return nil, err
+			}
+			change.Old = balance.Balance
+		}
+		change.New = change.Old.Add(delta.Balance)
+		result = append(result, change)
+	}
+	sortBalanceChanges(result)
+	return result, nil
+}
+
+// sortedDeltas - returns deltas in stable order to avoid deadlocks on concurrent updates
+func sortedDeltas(deltas map[string]*tokenbalance.TokenBalance) []*tokenbalance.TokenBalance {
+	ids := make([]string, 0, len(deltas))
+	for id := range deltas {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	result := make([]*tokenbalance.TokenBalance, len(ids))
+	for i := range ids {
+		result[i] = deltas[ids[i]]
+	}
+	return result
+}
+
+func sortBalanceChanges(changes []BalanceChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Contract != changes[j].Contract {
+			return changes[i].Contract < changes[j].Contract
+		}
+		if changes[i].Address != changes[j].Address {
+			return changes[i].Address < changes[j].Address
+		}
+		return changes[i].TokenID < changes[j].TokenID
+	})
+}
+
+// StateChange -
+type StateChange struct {
+	Action   string
+	Contract string
+	Ptr      int64
+	KeyHash  string
+	Level    int64
+}
+
+func newStateChange(state bigmapdiff.BigMapState, action string) StateChange {
+	return StateChange{
+		Action:   action,
+		Contract: state.Contract,
+		Ptr:      state.Ptr,
+		KeyHash:  state.KeyHash,
+		Level:    state.LastUpdateLevel,
+	}
+}
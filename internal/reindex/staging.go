@@ -0,0 +1,152 @@
+package reindex
+
+import (
+	"github.com/baking-bad/bcdhub/internal/models/account"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/tokenbalance"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/shopspring/decimal"
+)
+
+const stagingChunkSize = 1000
+
+// stagedBalance - token balance rebuilt from transfers up to the reindexed level
+type stagedBalance struct {
+	// nolint
+	tableName struct{} `pg:"reindex_token_balances"`
+
+	ID        int64
+	Network   types.Network   `pg:",type:SMALLINT,notnull,use_zero"`
+	Contract  string          `pg:",notnull"`
+	AccountID int64           `pg:",use_zero"`
+	Address   string          `pg:",notnull"`
+	TokenID   uint64          `pg:",type:numeric(50,0),use_zero"`
+	Balance   decimal.Decimal `pg:",type:numeric(100,0),use_zero"`
+}
+
+func newStagedBalance(balance *tokenbalance.TokenBalance) stagedBalance {
+	return stagedBalance{
+		Network:   balance.Network,
+		Contract:  balance.Contract,
+		AccountID: balance.AccountID,
+		Address:   balance.Account.Address,
+		TokenID:   balance.TokenID,
+		Balance:   balance.Balance,
+	}
+}
+
+func (sb stagedBalance) tokenBalance() *tokenbalance.TokenBalance {
+	return &tokenbalance.TokenBalance{
+		Network:   sb.Network,
+		Contract:  sb.Contract,
+		AccountID: sb.AccountID,
+		TokenID:   sb.TokenID,
+		Balance:   sb.Balance,
+		Account: account.Account{
+			ID:      sb.AccountID,
+			Network: sb.Network,
+			Address: sb.Address,
+			Type:    types.NewAccountType(sb.Address),
+		},
+	}
+}
+
+// stagedState - big map state rebuilt at the reindexed level
+type stagedState struct {
+	// nolint
+	tableName struct{} `pg:"reindex_big_map_states"`
+
+	bigmapdiff.BigMapState
+}
+
+// prepareStaging - creates staging table of model if it doesn't exist and removes rows of the network left by interrupted run
+func (m *Manager) prepareStaging(db pg.DBI, model interface{}) error {
+	if err := db.Model(model).CreateTable(&orm.CreateTableOptions{
+		IfNotExists: true,
+	}); err != nil {
+		return err
+	}
+	_, err := db.Model(model).Where("network = ?", m.params.Network).Delete()
+	return err
+}
+
+// stageBalances - saves rebuilt token balances of contract to staging table
+func (m *Manager) stageBalances(db pg.DBI, balances []*tokenbalance.TokenBalance) error {
+	for start := 0; start < len(balances); start += stagingChunkSize {
+		end := start + stagingChunkSize
+		if end > len(balances) {
+			end = len(balances)
+		}
+		rows := make([]stagedBalance, 0, end-start)
+		for _, balance := range balances[start:end] {
+			rows = append(rows, newStagedBalance(balance))
+		}
+		if _, err := db.Model(&rows).Insert(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stagedBalances - returns staged token balances of contract
+func (m *Manager) stagedBalances(db pg.DBI, contract string) ([]*tokenbalance.TokenBalance, error) {
+	var rows []stagedBalance
+	if err := db.Model(&rows).
+		Where("network = ?", m.params.Network).
+		Where("contract = ?", contract).
+		Select(); err != nil {
+		return nil, err
+	}
+	balances := make([]*tokenbalance.TokenBalance, len(rows))
+	for i := range rows {
+		balances[i] = rows[i].tokenBalance()
+	}
+	return balances, nil
+}
+
+// stageStates - saves rebuilt big map states of contract to staging table
+func (m *Manager) stageStates(db pg.DBI, states []bigmapdiff.BigMapState) error {
+	for start := 0; start < len(states); start += stagingChunkSize {
+		end := start + stagingChunkSize
+		if end > len(states) {
+			end = len(states)
+		}
+		rows := make([]stagedState, 0, end-start)
+		for _, state := range states[start:end] {
+			state.ID = 0
+			rows = append(rows, stagedState{BigMapState: state})
+		}
+		if _, err := db.Model(&rows).Insert(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stagedStates - returns staged big map states of contract
+func (m *Manager) stagedStates(db pg.DBI, contract string) ([]bigmapdiff.BigMapState, error) {
+	var rows []stagedState
+	if err := db.Model(&rows).
+		Where("network = ?", m.params.Network).
+		Where("contract = ?", contract).
+		Select(); err != nil {
+		return nil, err
+	}
+	states := make([]bigmapdiff.BigMapState, len(rows))
+	for i := range rows {
+		states[i] = rows[i].BigMapState
+		states[i].ID = 0
+	}
+	return states, nil
+}
+
+// dropStaged - removes staged rows of contract after swap
+func (m *Manager) dropStaged(db pg.DBI, model interface{}, contract string) error {
+	_, err := db.Model(model).
+		Where("network = ?", m.params.Network).
+		Where("contract = ?", contract).
+		Delete()
+	return err
+}
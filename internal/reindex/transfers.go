@@ -0,0 +1,294 @@
+package reindex
+
+import (
+	"fmt"
+
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/bcd/tezerrors"
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/tokenbalance"
+	"github.com/baking-bad/bcdhub/internal/models/transfer"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/baking-bad/bcdhub/internal/parsers/ledger"
+	"github.com/baking-bad/bcdhub/internal/parsers/stacktrace"
+	transferParsers "github.com/baking-bad/bcdhub/internal/parsers/transfer"
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+)
+
+// operationTransfers - stored and parsed transfers of operation
+type operationTransfers struct {
+	operation operation.Operation
+	old       []*transfer.Transfer
+	new       []*transfer.Transfer
+}
+
+// transfersResult - operations which transfers differ from stored ones
+type transfersResult struct {
+	changed []operationTransfers
+}
+
+// added - returns parsed transfers of changed operations
+func (r *transfersResult) added() []*transfer.Transfer {
+	transfers := make([]*transfer.Transfer, 0)
+	for i := range r.changed {
+		transfers = append(transfers, r.changed[i].new...)
+	}
+	return transfers
+}
+
+// deltas - returns changes of token balances after replacing stored transfers by parsed ones
+func (r *transfersResult) deltas() map[string]*tokenbalance.TokenBalance {
+	deltas := make(map[string]*tokenbalance.TokenBalance)
+	merge := func(updates []*tokenbalance.TokenBalance, rollback bool) {
+		for _, update := range updates {
+			if rollback {
+				update.Balance = update.Balance.Neg()
+			}
+			id := tokenBalanceID(update)
+			if delta, ok := deltas[id]; ok {
+				delta.Balance = delta.Balance.Add(update.Balance)
+				if delta.AccountID == 0 {
+					delta.AccountID = update.AccountID
+				}
+				continue
+			}
+			deltas[id] = update
+		}
+	}
+	for i := range r.changed {
+		merge(transferParsers.UpdateTokenBalances(r.changed[i].old), true)
+		merge(transferParsers.UpdateTokenBalances(r.changed[i].new), false)
+	}
+	return deltas
+}
+
+func tokenBalanceID(tb *tokenbalance.TokenBalance) string {
+	return fmt.Sprintf("%s_%s_%d", tb.Account.Address, tb.Contract, tb.TokenID)
+}
+
+// parseTransfers - parses transfers of stored operations in the scope again
+func (m *Manager) parseTransfers(report *Report) (*transfersResult, error) {
+	rpc, err := m.ctx.GetRPC(m.params.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	db := m.ctx.StorageDB.DB
+	balances := newRunningBalances(db, m.ctx.Accounts, m.params.Network, m.level, m.operationIDs(db))
+	parser := transferParser{
+		Manager:  m,
+		rpc:      rpc,
+		balances: balances,
+		scripts:  make(map[string][]byte),
+	}
+
+	result := new(transfersResult)
+	var lastID int64
+	for {
+		var operations []operation.Operation
+		if err := db.Model(&operations).
+			Relation("Source").
+			Relation("Destination").
+			Relation("Initiator").
+			Apply(m.scope).
+			Where("operation.id > ?", lastID).
+			Order("operation.id asc").
+			Limit(operationsChunkSize).
+			Select(); err != nil {
+			return nil, err
+		}
+		if len(operations) == 0 {
+			break
+		}
+
+		ids := make([]int64, len(operations))
+		for i := range operations {
+			ids[i] = operations[i].ID
+		}
+		stored, err := m.ctx.Transfers.GetForOperations(ids...)
+		if err != nil {
+			return nil, err
+		}
+		old := make(map[int64][]*transfer.Transfer)
+		for i := range stored {
+			old[stored[i].OperationID] = append(old[stored[i].OperationID], &stored[i])
+		}
+
+		for i := range operations {
+			parsed, err := parser.parse(&operations[i])
+			if err != nil {
+				return nil, errors.Wrapf(err, "operation %d", operations[i].ID)
+			}
+			if err := balances.apply(parsed); err != nil {
+				return nil, err
+			}
+
+			if sameTransfers(old[operations[i].ID], parsed) {
+				continue
+			}
+			result.changed = append(result.changed, operationTransfers{
+				operation: operations[i],
+				old:       old[operations[i].ID],
+				new:       parsed,
+			})
+			report.Transfers = append(report.Transfers, newTransferChange(operations[i], old[operations[i].ID], parsed))
+		}
+
+		report.Operations += int64(len(operations))
+		lastID = operations[len(operations)-1].ID
+		logger.Info().Msgf("%d operations are parsed", report.Operations)
+	}
+	return result, nil
+}
+
+// transferParser - parses transfers of one operation like the indexer does
+type transferParser struct {
+	*Manager
+
+	rpc      noderpc.INode
+	balances *runningBalances
+	scripts  map[string][]byte
+
+	hash       string
+	stackTrace *stacktrace.StackTrace
+}
+
+func (p *transferParser) parse(op *operation.Operation) ([]*transfer.Transfer, error) {
+	if !bcd.IsContract(op.Destination.Address) || tezerrors.HasParametersError(op.Errors) {
+		return nil, nil
+	}
+
+	proto, err := p.ctx.Cache.ProtocolByID(op.Network, op.ProtocolID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s:%s", op.Destination.Address, proto.SymLink)
+	script, ok := p.scripts[key]
+	if !ok {
+		data, err := p.ctx.Contracts.Script(op.Network, op.Destination.Address, proto.SymLink)
+		if err != nil {
+			return nil, err
+		}
+		if script, err = data.Full(); err != nil {
+			return nil, err
+		}
+		p.scripts[key] = script
+	}
+	op.Script = script
+	op.AST, err = ast.NewScriptWithoutCode(script)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, err := p.ctx.BigMapDiffs.GetForOperation(op.ID)
+	if err != nil && !p.ctx.Storage.IsRecordNotFound(err) {
+		return nil, err
+	}
+	op.BigMapDiffs = make([]*bigmapdiff.BigMapDiff, len(diffs))
+	for i := range diffs {
+		op.BigMapDiffs[i] = &diffs[i]
+	}
+
+	if op.Hash != p.hash {
+		p.hash = op.Hash
+		p.stackTrace = stacktrace.New()
+		if err := p.stackTrace.Fill(p.ctx.Operations, *op); err != nil {
+			return nil, err
+		}
+	}
+
+	parser, err := transferParsers.NewParser(p.rpc, p.ctx.ContractMetadata, p.ctx.Blocks, p.balances, p.ctx.Accounts,
+		transferParsers.WithStackTrace(p.stackTrace),
+		transferParsers.WithNetwork(op.Network),
+		transferParsers.WithChainID(p.chainID),
+		transferParsers.WithGasLimit(proto.Constants.HardGasLimitPerOperation),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	op.Transfers = nil
+	if err := parser.Parse(op.BigMapDiffs, proto.Hash, op); err != nil {
+		if !errors.Is(err, noderpc.InvalidNodeResponse{}) {
+			return nil, err
+		}
+		logger.Warning().Err(err).Msg("transferParser.Parse")
+	}
+
+	if op.IsApplied() {
+		if _, err := ledger.New(p.balances, p.ctx.Accounts).Parse(op, p.stackTrace); err != nil {
+			return nil, err
+		}
+	}
+	return op.Transfers, nil
+}
+
+// replaceTransfers - deletes stored transfers of changed operations and saves parsed ones
+func (m *Manager) replaceTransfers(tx pg.DBI, transfers *transfersResult) error {
+	if len(transfers.changed) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(transfers.changed))
+	for i := range transfers.changed {
+		ids[i] = transfers.changed[i].operation.ID
+	}
+	if _, err := tx.Model((*transfer.Transfer)(nil)).
+		WhereIn("operation_id IN (?)", ids).
+		Delete(); err != nil {
+		return err
+	}
+
+	for _, t := range transfers.added() {
+		if !t.Initiator.IsEmpty() {
+			if err := t.Initiator.Save(tx); err != nil {
+				return err
+			}
+			t.InitiatorID = t.Initiator.ID
+		}
+		if !t.From.IsEmpty() {
+			if err := t.From.Save(tx); err != nil {
+				return err
+			}
+			t.FromID = t.From.ID
+		}
+		if !t.To.IsEmpty() {
+			if err := t.To.Save(tx); err != nil {
+				return err
+			}
+			t.ToID = t.To.ID
+		}
+		if err := t.Save(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameTransfers - compares transfers regardless of their order and identities
+func sameTransfers(old, new []*transfer.Transfer) bool {
+	if len(old) != len(new) {
+		return false
+	}
+	counter := make(map[string]int)
+	for i := range old {
+		counter[transferKey(old[i])]++
+	}
+	for i := range new {
+		key := transferKey(new[i])
+		if counter[key] == 0 {
+			return false
+		}
+		counter[key]--
+	}
+	return true
+}
+
+func transferKey(t *transfer.Transfer) string {
+	return fmt.Sprintf("%s|%s|%s|%d|%s|%d|%s", t.Contract, t.From.Address, t.To.Address, t.TokenID, t.Amount.String(), t.Status, t.Parent.String())
+}
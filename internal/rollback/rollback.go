@@ -14,10 +14,12 @@ import (
 	"github.com/baking-bad/bcdhub/internal/models/global_constant"
 	"github.com/baking-bad/bcdhub/internal/models/migration"
 	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/saplingdiff"
 	"github.com/baking-bad/bcdhub/internal/models/tokenbalance"
 	"github.com/baking-bad/bcdhub/internal/models/tokenmetadata"
 	"github.com/baking-bad/bcdhub/internal/models/transfer"
 	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/pubsub"
 	"github.com/baking-bad/bcdhub/internal/search"
 	"github.com/go-pg/pg/v10"
 	"github.com/pkg/errors"
@@ -77,7 +79,7 @@ func (rm Manager) Rollback(ctx context.Context, db pg.DBI, fromState block.Block
 			return err
 		}
 	}
-	return nil
+	return pubsub.Publish(db, pubsub.NewRollback(fromState.Network, toLevel))
 }
 
 func (rm Manager) rollbackTokenBalances(tx pg.DBI, network types.Network, level int64) error {
@@ -124,7 +126,7 @@ func (rm Manager) rollbackAll(tx pg.DBI, network types.Network, level int64) err
 		&block.Block{}, &contract.Contract{}, &bigmapdiff.BigMapDiff{},
 		&bigmapaction.BigMapAction{}, &cm.ContractMetadata{},
 		&transfer.Transfer{}, &tokenmetadata.TokenMetadata{},
-		&global_constant.GlobalConstant{},
+		&global_constant.GlobalConstant{}, &saplingdiff.SaplingDiff{},
 	} {
 		if _, err := tx.Model(index).
 			Where("network = ?", network).
@@ -144,3 +144,11 @@ func Parse(index string, highlight map[string][]string, data []byte) (interface{
 	}
 	return nil, errors.Errorf("Unknown index: %s", index)
 }
+
+// GetFields - returns searchable fields of index
+func GetFields(index string) ([]string, error) {
+	if s, ok := scorables[index]; ok {
+		return s.GetFields(), nil
+	}
+	return nil, errors.Errorf("Unknown index: %s", index)
+}
@@ -0,0 +1,314 @@
+package mempool
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/consts"
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/pkg/errors"
+)
+
+const (
+	monitorPath     = "chains/main/mempool/monitor_operations?applied=true&branch_delayed=true"
+	statusDelayed   = "branch_delayed"
+	reconnectPeriod = time.Second * 5
+)
+
+// Monitor - streams pending operations from node mempool to in-memory store.
+// Node closes the stream on every new block, so after reconnection operations included to new blocks are dropped from the store.
+// Operations which were not included during `ttl` blocks since they were seen are dropped too.
+type Monitor struct {
+	*Store
+
+	network  types.Network
+	url      string
+	selector func() (string, error)
+	rpc      noderpc.INode
+	client   *http.Client
+	ttl      int64
+	level    int64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// MonitorOption -
+type MonitorOption func(*Monitor)
+
+// WithStoreSize - sets max count of operation groups in store
+func WithStoreSize(size int) MonitorOption {
+	return func(m *Monitor) {
+		if size > 0 {
+			m.Store = NewStore(size)
+		}
+	}
+}
+
+// WithTTL - sets count of blocks after which pending operation is dropped
+func WithTTL(ttl int64) MonitorOption {
+	return func(m *Monitor) {
+		if ttl > 0 {
+			m.ttl = ttl
+		}
+	}
+}
+
+// WithNodeSelector - sets function which returns URL of node before every connection, e.g. the preferred node of RPC pool.
+// URL passed to `NewMonitor` is used if it's not set.
+func WithNodeSelector(selector func() (string, error)) MonitorOption {
+	return func(m *Monitor) {
+		m.selector = selector
+	}
+}
+
+// NewMonitor - creates monitor of `url` node mempool. `rpc` is used to receive head and included operations.
+func NewMonitor(network types.Network, url string, rpc noderpc.INode, opts ...MonitorOption) *Monitor {
+	m := &Monitor{
+		Store:   NewStore(DefaultStoreSize),
+		network: network,
+		url:     strings.TrimSuffix(url, "/"),
+		rpc:     rpc,
+		client:  &http.Client{},
+		ttl:     DefaultTTL,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Start -
+func (m *Monitor) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			if err := m.sync(); err != nil {
+				logger.Warning().Str("network", m.network.String()).Err(err).Msg("mempool sync")
+			}
+
+			if err := m.monitor(ctx); err != nil && ctx.Err() == nil {
+				logger.Warning().Str("network", m.network.String()).Err(err).Msg("mempool monitor")
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(reconnectPeriod):
+				}
+			}
+		}
+	}()
+}
+
+// Close -
+func (m *Monitor) Close() error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+	return nil
+}
+
+// sync - drops operations included to blocks since the last call and expired ones
+func (m *Monitor) sync() error {
+	head, err := m.rpc.GetHead()
+	if err != nil {
+		return err
+	}
+
+	if m.level > 0 && head.Level > m.level {
+		from := m.level + 1
+		if head.Level-from >= m.ttl {
+			from = head.Level - m.ttl + 1
+		}
+		for level := from; level <= head.Level; level++ {
+			opg, err := m.rpc.GetLightOPG(level)
+			if err != nil {
+				return err
+			}
+			hashes := make([]string, 0, len(opg))
+			for i := range opg {
+				hashes = append(hashes, opg[i].Hash)
+			}
+			m.Store.Remove(hashes...)
+		}
+	}
+
+	m.level = head.Level
+	m.Store.Expire(head.Level)
+	return nil
+}
+
+// monitor - reads stream of node until it's closed
+func (m *Monitor) monitor(ctx context.Context) error {
+	url, err := m.nodeURL()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/"+monitorPath, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("invalid status code: %d", resp.StatusCode)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var operations []nodeOperation
+		if err := decoder.Decode(&operations); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		m.add(operations)
+	}
+}
+
+func (m *Monitor) nodeURL() (string, error) {
+	if m.selector == nil {
+		return m.url, nil
+	}
+	url, err := m.selector()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(url, "/"), nil
+}
+
+func (m *Monitor) add(operations []nodeOperation) {
+	now := time.Now().Unix()
+	for i := range operations {
+		if operations[i].Hash == "" || m.Store.Has(operations[i].Hash) {
+			continue
+		}
+		ops, err := operations[i].pending(m.network, m.level, m.level+m.ttl, now)
+		if err != nil {
+			logger.Warning().Str("network", m.network.String()).Str("hash", operations[i].Hash).Err(err).Msg("mempool operation")
+			continue
+		}
+		if len(ops.Transactions)+len(ops.Originations) == 0 {
+			continue
+		}
+		m.Store.Add(operations[i].Hash, m.level+m.ttl, ops)
+	}
+}
+
+// nodeOperation - item of `monitor_operations` stream. `error` is set for operations which can not be applied yet.
+type nodeOperation struct {
+	Hash      string            `json:"hash"`
+	Protocol  string            `json:"protocol"`
+	Branch    string            `json:"branch"`
+	Signature string            `json:"signature"`
+	Contents  []json.RawMessage `json:"contents"`
+	Error     json.RawMessage   `json:"error,omitempty"`
+}
+
+func (op nodeOperation) pending(network types.Network, level, expirationLevel, timestamp int64) (result PendingOperations, err error) {
+	status := consts.Applied
+	if len(op.Error) > 0 {
+		status = statusDelayed
+	}
+
+	for _, raw := range op.Contents {
+		var content noderpc.Operation
+		if err = json.Unmarshal(raw, &content); err != nil {
+			return
+		}
+
+		switch content.Kind {
+		case consts.Transaction:
+			tx := PendingTransaction{
+				Amount:          json.Number("0"),
+				Branch:          op.Branch,
+				CreatedAt:       timestamp,
+				Errors:          op.Error,
+				ExpirationLevel: &expirationLevel,
+				Fee:             content.Fee,
+				GasLimit:        content.GasLimit,
+				Kind:            content.Kind,
+				Level:           level,
+				Parameters:      content.Parameters,
+				Signature:       op.Signature,
+				Source:          content.Source,
+				Status:          status,
+				StorageLimit:    content.StorageLimit,
+				UpdatedAt:       timestamp,
+				Network:         network.String(),
+				Hash:            op.Hash,
+				Counter:         content.Counter,
+				Raw:             raw,
+				Protocol:        op.Protocol,
+			}
+			if content.Amount != nil {
+				tx.Amount = json.Number(strconv.FormatInt(*content.Amount, 10))
+			}
+			if content.Destination != nil {
+				tx.Destination = *content.Destination
+			}
+			result.Transactions = append(result.Transactions, tx)
+		case consts.Origination:
+			origination := PendingOrigination{
+				Branch:          op.Branch,
+				CreatedAt:       timestamp,
+				Delegate:        content.Delegate,
+				Errors:          op.Error,
+				ExpirationLevel: expirationLevel,
+				Fee:             content.Fee,
+				GasLimit:        content.GasLimit,
+				Kind:            content.Kind,
+				Level:           level,
+				Signature:       op.Signature,
+				Source:          content.Source,
+				Status:          status,
+				StorageLimit:    content.StorageLimit,
+				UpdatedAt:       timestamp,
+				Network:         network.String(),
+				Hash:            op.Hash,
+				Counter:         content.Counter,
+				Raw:             raw,
+				Protocol:        op.Protocol,
+			}
+			if content.Balance != nil {
+				origination.Balance = strconv.FormatInt(*content.Balance, 10)
+			}
+			if len(content.Script) > 0 {
+				var script struct {
+					Storage json.RawMessage `json:"storage"`
+				}
+				if err = json.Unmarshal(content.Script, &script); err != nil {
+					return
+				}
+				origination.Storage = script.Storage
+			}
+			result.Originations = append(result.Originations, origination)
+		}
+	}
+	return
+}
@@ -0,0 +1,172 @@
+package mempool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	alice    = "tz1aSkwEot3L2kmUvcoxzjMomb9mvBNuzFK6"
+	bob      = "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb"
+	contract = "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn"
+)
+
+func pendingTx(hash, source, destination string, createdAt int64) PendingOperations {
+	return PendingOperations{
+		Transactions: []PendingTransaction{
+			{Hash: hash, Source: source, Destination: destination, CreatedAt: createdAt},
+		},
+	}
+}
+
+func TestStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		action  func(s *Store)
+		address string
+		want    []string
+	}{
+		{
+			name: "by source and destination",
+			action: func(s *Store) {
+				s.Add("oo1", 10, pendingTx("oo1", alice, contract, 1))
+				s.Add("oo2", 10, pendingTx("oo2", bob, contract, 2))
+				s.Add("oo3", 10, pendingTx("oo3", bob, alice, 3))
+			},
+			address: contract,
+			want:    []string{"oo2", "oo1"},
+		}, {
+			name: "bounded",
+			size: 2,
+			action: func(s *Store) {
+				s.Add("oo1", 10, pendingTx("oo1", alice, contract, 1))
+				s.Add("oo2", 10, pendingTx("oo2", alice, contract, 2))
+				s.Add("oo3", 10, pendingTx("oo3", alice, contract, 3))
+			},
+			address: alice,
+			want:    []string{"oo3", "oo2"},
+		}, {
+			name: "included",
+			action: func(s *Store) {
+				s.Add("oo1", 10, pendingTx("oo1", alice, contract, 1))
+				s.Add("oo2", 10, pendingTx("oo2", alice, contract, 2))
+				s.Remove("oo2", "oo4")
+			},
+			address: contract,
+			want:    []string{"oo1"},
+		}, {
+			name: "expired",
+			action: func(s *Store) {
+				s.Add("oo1", 10, pendingTx("oo1", alice, contract, 1))
+				s.Add("oo2", 12, pendingTx("oo2", alice, contract, 2))
+				s.Expire(11)
+			},
+			address: alice,
+			want:    []string{"oo2"},
+		}, {
+			name: "origination",
+			action: func(s *Store) {
+				s.Add("oo1", 10, PendingOperations{
+					Originations: []PendingOrigination{{Hash: "oo1", Source: alice}},
+				})
+			},
+			address: alice,
+			want:    []string{"oo1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore(tt.size)
+			tt.action(s)
+
+			res, err := s.Get(tt.address)
+			require.NoError(t, err)
+
+			hashes := make([]string, 0)
+			for _, tx := range res.Transactions {
+				hashes = append(hashes, tx.Hash)
+			}
+			for _, origination := range res.Originations {
+				hashes = append(hashes, origination.Hash)
+			}
+			assert.Equal(t, tt.want, hashes)
+			assert.Equal(t, len(s.byAddress[tt.address]), len(tt.want))
+		})
+	}
+}
+
+func TestMonitor_nodeURL(t *testing.T) {
+	m := NewMonitor(types.Hangzhounet, "http://first/", nil)
+	url, err := m.nodeURL()
+	require.NoError(t, err)
+	assert.Equal(t, "http://first", url)
+
+	nodes := []string{"http://second/", "http://third"}
+	m = NewMonitor(types.Hangzhounet, "http://first/", nil, WithNodeSelector(func() (string, error) {
+		url := nodes[0]
+		nodes = nodes[1:]
+		return url, nil
+	}))
+	for _, want := range []string{"http://second", "http://third"} {
+		url, err := m.nodeURL()
+		require.NoError(t, err)
+		assert.Equal(t, want, url)
+	}
+
+	errNoNodes := errors.New("no available nodes")
+	m = NewMonitor(types.Hangzhounet, "http://first/", nil, WithNodeSelector(func() (string, error) {
+		return "", errNoNodes
+	}))
+	assert.Equal(t, errNoNodes, m.monitor(context.Background()))
+}
+
+func TestMonitor_monitor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chains/main/mempool/monitor_operations", r.URL.Path)
+
+		fmt.Fprintf(w, `[{"hash":"oo1","protocol":"Psithaca2MLRFYargivpo7YvUr7wUDqyxrdhC5CQq78mRvimz6A","branch":"BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2","contents":[{"kind":"transaction","source":"%s","fee":"1000","counter":"10","gas_limit":"10000","storage_limit":"0","amount":"5","destination":"%s","parameters":{"entrypoint":"transfer","value":{"int":"1"}}}],"signature":"sig"}]`, alice, contract)
+		w.(http.Flusher).Flush()
+		fmt.Fprintf(w, `[{"hash":"oo2","protocol":"Psithaca2MLRFYargivpo7YvUr7wUDqyxrdhC5CQq78mRvimz6A","branch":"BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2","contents":[{"kind":"origination","source":"%s","fee":"1000","counter":"11","gas_limit":"10000","storage_limit":"500","balance":"0","script":{"code":[],"storage":{"int":"0"}}}],"signature":"sig","error":[{"kind":"temporary","id":"proto.012-Psithaca.contract.counter_in_the_future"}]},{"hash":"oo3","protocol":"Psithaca2MLRFYargivpo7YvUr7wUDqyxrdhC5CQq78mRvimz6A","branch":"BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2","contents":[{"kind":"reveal","source":"%s","fee":"1000","counter":"1","gas_limit":"10000","storage_limit":"0","public_key":"edpk"}],"signature":"sig"}]`, bob, bob)
+	}))
+	defer server.Close()
+
+	m := NewMonitor(types.Hangzhounet, server.URL+"/", nil)
+	m.level = 100
+	require.NoError(t, m.monitor(context.Background()))
+	assert.Equal(t, 2, m.Len())
+
+	res, err := m.Get(contract)
+	require.NoError(t, err)
+	if assert.Len(t, res.Transactions, 1) {
+		tx := res.Transactions[0]
+		assert.Equal(t, "oo1", tx.Hash)
+		assert.Equal(t, "applied", tx.Status)
+		assert.Equal(t, "5", tx.Amount.String())
+		assert.Equal(t, int64(10), tx.Counter)
+		assert.Equal(t, int64(100), tx.Level)
+		assert.Equal(t, int64(100+DefaultTTL), *tx.ExpirationLevel)
+		assert.JSONEq(t, `{"entrypoint":"transfer","value":{"int":"1"}}`, string(tx.Parameters))
+	}
+
+	res, err = m.GetByHash("oo2")
+	require.NoError(t, err)
+	if assert.Len(t, res.Originations, 1) {
+		origination := res.Originations[0]
+		assert.Equal(t, bob, origination.Source)
+		assert.Equal(t, "branch_delayed", origination.Status)
+		assert.Equal(t, "0", origination.Balance)
+		assert.JSONEq(t, `{"int":"0"}`, string(origination.Storage))
+		assert.NotEmpty(t, origination.Errors)
+	}
+
+	assert.False(t, m.Has("oo3"))
+}
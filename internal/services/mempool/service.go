@@ -0,0 +1,7 @@
+package mempool
+
+// Service - source of pending operations
+type Service interface {
+	Get(address string) (PendingOperations, error)
+	GetByHash(hash string) (PendingOperations, error)
+}
@@ -0,0 +1,197 @@
+package mempool
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+)
+
+// default values of store
+const (
+	DefaultStoreSize = 10000
+	DefaultTTL       = 120
+)
+
+type entry struct {
+	hash            string
+	expirationLevel int64
+	addresses       []string
+
+	transactions []PendingTransaction
+	originations []PendingOrigination
+}
+
+// Store - bounded in-memory storage of pending operation groups indexed by hash and by address.
+// The oldest group is dropped when store is full.
+type Store struct {
+	size int
+
+	items     map[string]*list.Element
+	order     *list.List
+	byAddress map[string]map[string]struct{}
+	mx        sync.RWMutex
+}
+
+// NewStore -
+func NewStore(size int) *Store {
+	if size <= 0 {
+		size = DefaultStoreSize
+	}
+	return &Store{
+		size:      size,
+		items:     make(map[string]*list.Element),
+		order:     list.New(),
+		byAddress: make(map[string]map[string]struct{}),
+	}
+}
+
+// Len - returns count of stored operation groups
+func (s *Store) Len() int {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return len(s.items)
+}
+
+// Has -
+func (s *Store) Has(hash string) bool {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	_, ok := s.items[hash]
+	return ok
+}
+
+// Add - stores operation group. Known groups are updated in place.
+func (s *Store) Add(hash string, expirationLevel int64, ops PendingOperations) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if _, ok := s.items[hash]; ok {
+		s.remove(hash)
+	}
+
+	e := &entry{
+		hash:            hash,
+		expirationLevel: expirationLevel,
+		transactions:    ops.Transactions,
+		originations:    ops.Originations,
+	}
+	for _, tx := range ops.Transactions {
+		e.addresses = append(e.addresses, tx.Source, tx.Destination)
+	}
+	for _, origination := range ops.Originations {
+		e.addresses = append(e.addresses, origination.Source)
+	}
+	for _, address := range e.addresses {
+		if address == "" {
+			continue
+		}
+		if _, ok := s.byAddress[address]; !ok {
+			s.byAddress[address] = make(map[string]struct{})
+		}
+		s.byAddress[address][hash] = struct{}{}
+	}
+	s.items[hash] = s.order.PushBack(e)
+
+	for s.order.Len() > s.size {
+		s.remove(s.order.Front().Value.(*entry).hash)
+	}
+}
+
+// Remove - drops operation groups, e.g. included to block
+func (s *Store) Remove(hashes ...string) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	for _, hash := range hashes {
+		s.remove(hash)
+	}
+}
+
+// Expire - drops operation groups which can not be included after `level`
+func (s *Store) Expire(level int64) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var expired []string
+	for el := s.order.Front(); el != nil; el = el.Next() {
+		if e := el.Value.(*entry); e.expirationLevel < level {
+			expired = append(expired, e.hash)
+		}
+	}
+	for _, hash := range expired {
+		s.remove(hash)
+	}
+}
+
+func (s *Store) remove(hash string) {
+	el, ok := s.items[hash]
+	if !ok {
+		return
+	}
+	e := el.Value.(*entry)
+	for _, address := range e.addresses {
+		if hashes, ok := s.byAddress[address]; ok {
+			delete(hashes, hash)
+			if len(hashes) == 0 {
+				delete(s.byAddress, address)
+			}
+		}
+	}
+	s.order.Remove(el)
+	delete(s.items, hash)
+}
+
+// Get - returns pending operations where `address` is source or destination. The newest operations are first.
+func (s *Store) Get(address string) (result PendingOperations, err error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	result = PendingOperations{
+		Transactions: make([]PendingTransaction, 0),
+		Originations: make([]PendingOrigination, 0),
+	}
+	for hash := range s.byAddress[address] {
+		e := s.items[hash].Value.(*entry)
+		for _, tx := range e.transactions {
+			if tx.Source == address || tx.Destination == address {
+				result.Transactions = append(result.Transactions, tx)
+			}
+		}
+		for _, origination := range e.originations {
+			if origination.Source == address {
+				result.Originations = append(result.Originations, origination)
+			}
+		}
+	}
+
+	sort.SliceStable(result.Transactions, func(i, j int) bool {
+		if result.Transactions[i].CreatedAt == result.Transactions[j].CreatedAt {
+			return result.Transactions[i].Counter > result.Transactions[j].Counter
+		}
+		return result.Transactions[i].CreatedAt > result.Transactions[j].CreatedAt
+	})
+	sort.SliceStable(result.Originations, func(i, j int) bool {
+		if result.Originations[i].CreatedAt == result.Originations[j].CreatedAt {
+			return result.Originations[i].Counter > result.Originations[j].Counter
+		}
+		return result.Originations[i].CreatedAt > result.Originations[j].CreatedAt
+	})
+	return
+}
+
+// GetByHash -
+func (s *Store) GetByHash(hash string) (result PendingOperations, err error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	result = PendingOperations{
+		Transactions: make([]PendingTransaction, 0),
+		Originations: make([]PendingOrigination, 0),
+	}
+	if el, ok := s.items[hash]; ok {
+		e := el.Value.(*entry)
+		result.Transactions = append(result.Transactions, e.transactions...)
+		result.Originations = append(result.Originations, e.originations...)
+	}
+	return
+}
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/models/bigmapdiff"
+	"github.com/baking-bad/bcdhub/internal/models/operation"
+	"github.com/baking-bad/bcdhub/internal/models/transfer"
+	"github.com/baking-bad/bcdhub/internal/models/webhook"
+)
+
+// Events
+const (
+	EventTransaction = "transaction"
+	EventTransfer    = "transfer"
+	EventBigMap      = "big_map"
+)
+
+// Payload - body of notification
+type Payload struct {
+	ID             string      `json:"id"`
+	Event          string      `json:"event"`
+	SubscriptionID int64       `json:"subscription_id"`
+	Network        string      `json:"network"`
+	Address        string      `json:"address"`
+	Level          int64       `json:"level"`
+	Timestamp      time.Time   `json:"timestamp"`
+	Hash           string      `json:"hash"`
+	Data           interface{} `json:"data"`
+}
+
+// Transaction -
+type Transaction struct {
+	Counter     int64           `json:"counter"`
+	Nonce       *int64          `json:"nonce,omitempty"`
+	Status      string          `json:"status"`
+	Source      string          `json:"source"`
+	Destination string          `json:"destination"`
+	Amount      int64           `json:"amount"`
+	Entrypoint  string          `json:"entrypoint,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// Transfer -
+type Transfer struct {
+	Contract string `json:"contract"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	TokenID  uint64 `json:"token_id"`
+	Amount   string `json:"amount"`
+}
+
+// BigMapUpdate -
+type BigMapUpdate struct {
+	Ptr     int64           `json:"ptr"`
+	KeyHash string          `json:"key_hash"`
+	Key     json.RawMessage `json:"key"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	Removed bool            `json:"removed"`
+}
+
+// NewTransactionPayload -
+func NewTransactionPayload(sub webhook.Subscription, op operation.Operation) Payload {
+	data := Transaction{
+		Counter:     op.Counter,
+		Nonce:       op.Nonce,
+		Status:      op.Status.String(),
+		Source:      op.Source.Address,
+		Destination: op.Destination.Address,
+		Amount:      op.Amount,
+		Entrypoint:  op.Entrypoint.String(),
+	}
+	if len(op.Parameters) > 0 {
+		data.Parameters = op.Parameters
+	}
+	return newPayload(sub, EventTransaction, op.ID, op.Level, op.Timestamp, op.Hash, data)
+}
+
+// NewTransferPayload -
+func NewTransferPayload(sub webhook.Subscription, t transfer.Transfer, hash string) Payload {
+	return newPayload(sub, EventTransfer, t.ID, t.Level, t.Timestamp, hash, Transfer{
+		Contract: t.Contract,
+		From:     t.From.Address,
+		To:       t.To.Address,
+		TokenID:  t.TokenID,
+		Amount:   t.Amount.String(),
+	})
+}
+
+// NewBigMapPayload -
+func NewBigMapPayload(sub webhook.Subscription, diff bigmapdiff.BigMapDiff, hash string) Payload {
+	data := BigMapUpdate{
+		Ptr:     diff.Ptr,
+		KeyHash: diff.KeyHash,
+		Key:     json.RawMessage(diff.Key),
+		Removed: len(diff.Value) == 0,
+	}
+	if !data.Removed {
+		data.Value = json.RawMessage(diff.Value)
+	}
+	return newPayload(sub, EventBigMap, diff.ID, diff.Level, diff.Timestamp, hash, data)
+}
+
+func newPayload(sub webhook.Subscription, event string, id, level int64, ts time.Time, hash string, data interface{}) Payload {
+	return Payload{
+		ID:             fmt.Sprintf("%d-%s-%d", sub.ID, event, id),
+		Event:          event,
+		SubscriptionID: sub.ID,
+		Network:        sub.Network.String(),
+		Address:        sub.Address,
+		Level:          level,
+		Timestamp:      ts.UTC(),
+		Hash:           hash,
+		Data:           data,
+	}
+}
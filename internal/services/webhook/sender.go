@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/pkg/errors"
+)
+
+// Headers of notification request
+const (
+	HeaderSignature = "X-BCD-Signature"
+	HeaderTimestamp = "X-BCD-Timestamp"
+	HeaderEvent     = "X-BCD-Event"
+	HeaderDelivery  = "X-BCD-Delivery"
+)
+
+// default values of sender
+const (
+	DefaultTimeout    = 10 * time.Second
+	DefaultMaxRetries = 5
+)
+
+// Sender - delivers notifications to subscribers
+type Sender struct {
+	client          *http.Client
+	maxRetries      uint64
+	initialInterval time.Duration
+	maxInterval     time.Duration
+}
+
+// SenderOption -
+type SenderOption func(*Sender)
+
+// WithTimeout -
+func WithTimeout(timeout time.Duration) SenderOption {
+	return func(s *Sender) {
+		if timeout > 0 {
+			s.client.Timeout = timeout
+		}
+	}
+}
+
+// WithMaxRetries -
+func WithMaxRetries(maxRetries uint64) SenderOption {
+	return func(s *Sender) {
+		s.maxRetries = maxRetries
+	}
+}
+
+// WithMaxInterval - sets maximum interval between retries
+func WithMaxInterval(interval time.Duration) SenderOption {
+	return func(s *Sender) {
+		if interval > 0 {
+			s.maxInterval = interval
+		}
+	}
+}
+
+// NewSender -
+func NewSender(opts ...SenderOption) *Sender {
+	s := &Sender{
+		client: &http.Client{
+			Timeout: DefaultTimeout,
+		},
+		maxRetries:      DefaultMaxRetries,
+		initialInterval: backoff.DefaultInitialInterval,
+		maxInterval:     time.Minute,
+	}
+	for i := range opts {
+		opts[i](s)
+	}
+	return s
+}
+
+// Sign - returns hex encoded HMAC-SHA256 of `timestamp.body` with `secret` key
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Post - makes single attempt to deliver `body` to `url`. Callers queue failed notifications and retry them after `RetryDelay`.
+// Client errors (4xx except 408 and 429) should not be retried, see `IsPermanent`.
+func (s *Sender) Post(ctx context.Context, url, secret string, payload Payload, body []byte) error {
+	return s.post(ctx, url, secret, payload, body)
+}
+
+// MaxRetries - returns count of retries after which notification is moved to dead letters
+func (s *Sender) MaxRetries() uint64 {
+	return s.maxRetries
+}
+
+// RetryDelay - returns delay before the next attempt if `attempts` were failed. Delay grows exponentially up to max interval.
+func (s *Sender) RetryDelay(attempts int64) time.Duration {
+	delay := s.initialInterval
+	for i := int64(1); i < attempts && delay < s.maxInterval; i++ {
+		delay *= 2
+	}
+	if delay > s.maxInterval {
+		delay = s.maxInterval
+	}
+	return delay
+}
+
+// IsPermanent - returns true if delivery failed with error which should not be retried
+func IsPermanent(err error) bool {
+	_, ok := err.(*backoff.PermanentError)
+	return ok
+}
+
+func (s *Sender) post(ctx context.Context, url, secret string, payload Payload, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bcdhub-webhooks")
+	req.Header.Set(HeaderEvent, payload.Event)
+	req.Header.Set(HeaderDelivery, payload.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(secret, timestamp, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests:
+		return errors.Errorf("subscriber responded with status %d", resp.StatusCode)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return backoff.Permanent(errors.Errorf("subscriber responded with status %d", resp.StatusCode))
+	default:
+		return errors.Errorf("subscriber responded with status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      string
+		want      string
+	}{
+		{
+			name:      "empty body",
+			secret:    "secret",
+			timestamp: 1640995200,
+			body:      "",
+			want:      "e83277df9af6a6050e50a5edde1f070ff16889a893931f29b8aaf75df5ab7a1e",
+		}, {
+			name:      "json body",
+			secret:    "secret",
+			timestamp: 1640995200,
+			body:      `{"id":"1"}`,
+			want:      "9625e5f87371d2aa9b2221e4cdb95f07ee8113467e1789c738bf69abc6f551ff",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sign(tt.secret, tt.timestamp, []byte(tt.body))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSender_Post(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		wantErr       bool
+		wantPermanent bool
+	}{
+		{
+			name:   "delivered",
+			status: http.StatusOK,
+		}, {
+			name:   "no content",
+			status: http.StatusNoContent,
+		}, {
+			name:    "server error",
+			status:  http.StatusBadGateway,
+			wantErr: true,
+		}, {
+			name:    "too many requests",
+			status:  http.StatusTooManyRequests,
+			wantErr: true,
+		}, {
+			name:          "client error is not retried",
+			status:        http.StatusNotFound,
+			wantErr:       true,
+			wantPermanent: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int64
+			body := []byte(`{"id":"1"}`)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt64(&calls, 1)
+
+				data, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, body, data)
+				assert.Equal(t, EventTransfer, r.Header.Get(HeaderEvent))
+				assert.Equal(t, "1", r.Header.Get(HeaderDelivery))
+
+				timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+				require.NoError(t, err)
+				assert.Equal(t, "sha256="+Sign("secret", timestamp, data), r.Header.Get(HeaderSignature))
+
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			sender := NewSender()
+			err := sender.Post(context.Background(), server.URL, "secret", Payload{ID: "1", Event: EventTransfer}, body)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantPermanent, IsPermanent(err))
+			assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+		})
+	}
+}
+
+func TestSender_RetryDelay(t *testing.T) {
+	sender := NewSender(WithMaxInterval(time.Second * 5))
+	sender.initialInterval = time.Second
+
+	tests := []struct {
+		attempts int64
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: time.Second * 2},
+		{attempts: 3, want: time.Second * 4},
+		{attempts: 4, want: time.Second * 5},
+		{attempts: 100, want: time.Second * 5},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.FormatInt(tt.attempts, 10), func(t *testing.T) {
+			assert.Equal(t, tt.want, sender.RetryDelay(tt.attempts))
+		})
+	}
+}
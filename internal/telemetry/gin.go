@@ -0,0 +1,44 @@
+package telemetry
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/cache/persistence"
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware - observes duration of requests by route template. Requests to unknown routes are grouped.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unknown"
+		}
+		RequestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Observe(time.Since(start).Seconds())
+	}
+}
+
+// CacheStore - wrapper of page cache store which counts hits and misses
+type CacheStore struct {
+	persistence.CacheStore
+}
+
+// NewCacheStore -
+func NewCacheStore(store persistence.CacheStore) *CacheStore {
+	return &CacheStore{store}
+}
+
+// Get -
+func (store *CacheStore) Get(key string, value interface{}) error {
+	err := store.CacheStore.Get(key, value)
+	if err == nil {
+		CacheRequests.WithLabelValues("hit").Inc()
+	} else {
+		CacheRequests.WithLabelValues("miss").Inc()
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/cache"
+	"github.com/gin-contrib/cache/persistence"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func observations(t *testing.T, labels ...string) uint64 {
+	var m dto.Metric
+	require.NoError(t, RequestDuration.WithLabelValues(labels...).(prometheus.Metric).Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Middleware())
+	store := NewCacheStore(persistence.NewInMemoryStore(time.Minute))
+	router.GET("/v1/contract/:network/:address", cache.CachePage(store, time.Minute, func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	}))
+
+	tests := []struct {
+		name   string
+		path   string
+		route  string
+		status string
+		hit    bool
+	}{
+		{
+			name:   "miss",
+			path:   "/v1/contract/mainnet/KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn",
+			route:  "/v1/contract/:network/:address",
+			status: "200",
+		}, {
+			name:   "hit",
+			path:   "/v1/contract/mainnet/KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn",
+			route:  "/v1/contract/:network/:address",
+			status: "200",
+			hit:    true,
+		}, {
+			name:   "unknown route",
+			path:   "/v2/unknown",
+			route:  "unknown",
+			status: "404",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := observations(t, http.MethodGet, tt.route, tt.status)
+			hits := testutil.ToFloat64(CacheRequests.WithLabelValues("hit"))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, requests+1, observations(t, http.MethodGet, tt.route, tt.status))
+
+			wantHits := hits
+			if tt.hit {
+				wantHits++
+			}
+			assert.Equal(t, wantHits, testutil.ToFloat64(CacheRequests.WithLabelValues("hit")))
+		})
+	}
+}
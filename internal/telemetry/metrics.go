@@ -0,0 +1,115 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "bcd"
+
+// Indexer stages
+const (
+	StageFetch = "fetch"
+	StageParse = "parse"
+	StageSave  = "save"
+)
+
+// Indexer metrics
+var (
+	HeadLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "head_lag_blocks",
+		Help:      "Count of blocks between node head and the last indexed block.",
+	}, []string{"network"})
+
+	BlocksParsed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "blocks_total",
+		Help:      "Count of indexed blocks.",
+	}, []string{"network"})
+
+	OperationsParsed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "operations_total",
+		Help:      "Count of indexed operations.",
+	}, []string{"network"})
+
+	StageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "stage_duration_seconds",
+		Help:      "Duration of block indexing stages: fetch from node, parse and save.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+	}, []string{"network", "stage"})
+
+	Rollbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "rollbacks_total",
+		Help:      "Count of rollbacks.",
+	}, []string{"network"})
+)
+
+// RPC metrics
+var (
+	RPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "call_duration_seconds",
+		Help:      "Duration of node RPC calls by method and node.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 13),
+	}, []string{"method", "node"})
+
+	RPCErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "errors_total",
+		Help:      "Count of failed node RPC calls by method and node.",
+	}, []string{"method", "node"})
+)
+
+// Metrics workers metrics
+var (
+	ServiceBacklog = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "metrics",
+		Name:      "service_backlog",
+		Help:      "Distance between the last processed ID of service and the max ID of its table.",
+	}, []string{"service"})
+)
+
+// API metrics
+var (
+	RequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of API requests by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "cache_requests_total",
+		Help:      "Count of requests to cached pages by result: hit or miss.",
+	}, []string{"result"})
+)
+
+// ObserveStage - observes duration of indexer stage started at `start`
+func ObserveStage(network, stage string, start time.Time) {
+	StageDuration.WithLabelValues(network, stage).Observe(time.Since(start).Seconds())
+}
+
+// ObserveRPC - observes duration and result of node call started at `start`
+func ObserveRPC(method, node string, start time.Time, err error) {
+	RPCDuration.WithLabelValues(method, node).Observe(time.Since(start).Seconds())
+	if err != nil {
+		RPCErrors.WithLabelValues(method, node).Inc()
+	}
+}
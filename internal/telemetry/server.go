@@ -0,0 +1,52 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path - path of metrics endpoint
+const Path = "/metrics"
+
+// Handler - returns handler which exposes metrics in Prometheus format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Server - HTTP server which exposes metrics on `/metrics`
+type Server struct {
+	server *http.Server
+}
+
+// NewServer -
+func NewServer(bind string) *Server {
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler())
+	return &Server{
+		server: &http.Server{
+			Addr:    bind,
+			Handler: mux,
+		},
+	}
+}
+
+// Start - starts listening in background
+func (s *Server) Start() {
+	go func() {
+		logger.Info().Msgf("Metrics are exposed on %s%s", s.server.Addr, Path)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Err(err)
+		}
+	}()
+}
+
+// Close -
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
@@ -0,0 +1,100 @@
+package views
+
+import (
+	"bytes"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+)
+
+// OnChainView - view which is declared in contract's script (`view` section)
+type OnChainView struct {
+	Parameter  []byte
+	Code       []byte
+	ReturnType []byte
+	Name       string
+}
+
+// NewOnChainView -
+func NewOnChainView(view ast.View) (*OnChainView, error) {
+	parameter, err := json.Marshal(view.Parameter[0])
+	if err != nil {
+		return nil, err
+	}
+	returnType, err := json.Marshal(view.ReturnType[0])
+	if err != nil {
+		return nil, err
+	}
+	code, err := json.Marshal(view.Code[0])
+	if err != nil {
+		return nil, err
+	}
+	return &OnChainView{
+		Parameter:  parameter,
+		ReturnType: returnType,
+		Code:       code,
+		Name:       view.Name,
+	}, nil
+}
+
+// GetCode - returns script which emulates view execution via `run_code`. View's code receives `pair parameter storage` as `CAR` of script's parameter.
+func (v *OnChainView) GetCode(storageType []byte) ([]byte, error) {
+	var script bytes.Buffer
+	script.WriteString(`[{"prim":"parameter","args":[{"prim":"pair","args":[`)
+	script.Write(v.Parameter)
+	script.WriteString(",")
+	if _, err := script.Write(storageType); err != nil {
+		return nil, err
+	}
+	script.WriteString(`]}]},{"prim":"storage","args":[{"prim":"option","args":[`)
+	script.Write(v.ReturnType)
+	script.WriteString(`]}]},{"prim":"code","args":[[{"prim":"CAR"},`)
+	script.Write(v.Code)
+	script.WriteString(`,{"prim":"SOME"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`)
+	return script.Bytes(), nil
+}
+
+// GetParameter -
+func (v *OnChainView) GetParameter(parameter string, storageValue []byte) ([]byte, error) {
+	var script bytes.Buffer
+	script.WriteString(`{"prim":"Pair","args":[`)
+	script.WriteString(parameter)
+	script.WriteString(",")
+	if _, err := script.Write(storageValue); err != nil {
+		return nil, err
+	}
+	script.WriteString(`]}`)
+	return script.Bytes(), nil
+}
+
+// Parse -
+func (v *OnChainView) Parse(response []byte, output interface{}) error {
+	return nil
+}
+
+// ExecuteOnChainView - executes on-chain view via `run_script_view` RPC. If node does not support the endpoint, view is emulated via `run_code`.
+// Returns Micheline value of view's return type.
+func ExecuteOnChainView(rpc noderpc.INode, view *OnChainView, ctx Context) ([]byte, error) {
+	response, err := rpc.RunScriptView(ctx.Contract, view.Name, []byte(ctx.Parameters), ctx.ChainID, ctx.Source, ctx.Initiator, ctx.HardGasLimitPerOperation)
+	switch {
+	case err == nil:
+		return response.Data, nil
+	case noderpc.IsUnknownRPC(err):
+	default:
+		return nil, err
+	}
+
+	storage, err := ExecuteWithoutParsing(rpc, view, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ast.UntypedAST
+	if err := json.Unmarshal(storage, &result); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 || len(result[0].Args) == 0 {
+		return nil, ErrNodeReturn
+	}
+	return json.Marshal(result[0].Args[0])
+}
@@ -0,0 +1,117 @@
+package views
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/baking-bad/bcdhub/internal/bcd/ast"
+	"github.com/baking-bad/bcdhub/internal/noderpc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const testViewScript = `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"nat"}]},{"prim":"code","args":[[{"prim":"CDR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]},{"prim":"view","args":[{"string":"add"},{"prim":"nat"},{"prim":"nat"},[{"prim":"UNPAIR"},{"prim":"ADD"}]]}]`
+
+func getTestOnChainView(t *testing.T) *OnChainView {
+	script, err := ast.NewScript([]byte(testViewScript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	views, err := script.GetViews()
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, err := NewOnChainView(views[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return view
+}
+
+func TestOnChainView_GetCode(t *testing.T) {
+	view := getTestOnChainView(t)
+	assert.Equal(t, "add", view.Name)
+
+	code, err := view.GetCode([]byte(`{"prim":"nat"}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `[{"prim":"parameter","args":[{"prim":"pair","args":[{"prim":"nat"},{"prim":"nat"}]}]},{"prim":"storage","args":[{"prim":"option","args":[{"prim":"nat"}]}]},{"prim":"code","args":[[{"prim":"CAR"},[{"prim":"UNPAIR"},{"prim":"ADD"}],{"prim":"SOME"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`, string(code))
+
+	parameter, err := view.GetParameter(`{"int":"1"}`, []byte(`{"int":"2"}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `{"prim":"Pair","args":[{"int":"1"},{"int":"2"}]}`, string(parameter))
+}
+
+func TestExecuteOnChainView(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rpc := noderpc.NewMockINode(ctrl)
+	view := getTestOnChainView(t)
+	ctx := Context{
+		Contract:   "KT1Jk8LRDoj6LkopYZwRq5ZEWBhYv8nVc6e6",
+		Parameters: `{"int":"1"}`,
+		ChainID:    "NetXnHfVqm9iesp",
+	}
+
+	t.Run("run_script_view", func(t *testing.T) {
+		rpc.EXPECT().
+			RunScriptView(ctx.Contract, "add", []byte(ctx.Parameters), ctx.ChainID, "", "", int64(0)).
+			Return(noderpc.RunScriptViewResponse{Data: []byte(`{"int":"3"}`)}, nil).
+			Times(1)
+
+		got, err := ExecuteOnChainView(rpc, view, ctx)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, `{"int":"3"}`, string(got))
+	})
+
+	t.Run("fallback to run_code", func(t *testing.T) {
+		script, err := ast.NewScript([]byte(testViewScript))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rpc.EXPECT().
+			RunScriptView(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(noderpc.RunScriptViewResponse{}, noderpc.InvalidStatusCodeError{Code: http.StatusNotFound}).
+			Times(1)
+		rpc.EXPECT().
+			GetScriptJSON(ctx.Contract, int64(0)).
+			Return(noderpc.Script{Code: script, Storage: []byte(`{"int":"2"}`)}, nil).
+			Times(1)
+		rpc.EXPECT().
+			RunCode(gomock.Any(), gomock.Any(), []byte(`{"prim":"Pair","args":[{"int":"1"},{"int":"2"}]}`), ctx.ChainID, "", "", "", "", int64(0), int64(0)).
+			Return(noderpc.RunCodeResponse{Storage: []byte(`{"prim":"Some","args":[{"int":"3"}]}`)}, nil).
+			Times(1)
+
+		got, err := ExecuteOnChainView(rpc, view, ctx)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, `{"int":"3"}`, string(got))
+	})
+
+	t.Run("view failure is not hidden by fallback", func(t *testing.T) {
+		rpc.EXPECT().
+			RunScriptView(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(noderpc.RunScriptViewResponse{}, noderpc.InvalidStatusCodeError{Code: http.StatusBadRequest}).
+			Times(1)
+
+		_, err := ExecuteOnChainView(rpc, view, ctx)
+		assert.ErrorIs(t, err, noderpc.ErrInvalidStatusCode)
+	})
+
+	t.Run("script failure", func(t *testing.T) {
+		rpc.EXPECT().
+			RunScriptView(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(noderpc.RunScriptViewResponse{}, noderpc.InvalidNodeResponse{}).
+			Times(1)
+
+		_, err := ExecuteOnChainView(rpc, view, ctx)
+		assert.Error(t, err)
+	})
+}
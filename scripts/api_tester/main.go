@@ -21,13 +21,18 @@ func main() {
 		return
 	}
 
-	ctx := config.NewContext(
+	ctx, err := config.NewContext(
 		config.WithStorage(cfg.Storage, "api_tester", 0, cfg.Scripts.Connections.Open, cfg.Scripts.Connections.Idle),
+		config.WithNetworks(cfg.Networks),
 		config.WithRPC(cfg.RPC),
 		config.WithSearch(cfg.Storage),
 		config.WithLoadErrorDescriptions(),
 		config.WithConfigCopy(cfg),
 	)
+	if err != nil {
+		logger.Err(err)
+		return
+	}
 	defer ctx.Close()
 
 	testGeneral(ctx)
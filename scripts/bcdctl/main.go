@@ -30,12 +30,17 @@ func main() {
 		Region:     cfg.Scripts.AWS.Region,
 	}
 
-	ctx = config.NewContext(
+	ctx, err = config.NewContext(
 		config.WithStorage(cfg.Storage, "bcdctl", 0, cfg.Scripts.Connections.Open, cfg.Scripts.Connections.Idle),
+		config.WithNetworks(cfg.Networks),
 		config.WithConfigCopy(cfg),
 		config.WithRPC(cfg.RPC),
 		config.WithSearch(cfg.Storage),
 	)
+	if err != nil {
+		logger.Err(err)
+		return
+	}
 	defer ctx.Close()
 
 	parser := flags.NewParser(nil, flags.Default)
@@ -96,6 +101,22 @@ func main() {
 		return
 	}
 
+	if _, err := parser.AddCommand("set_symlink",
+		"Set protocol symlink",
+		"Override symlink of protocol or add protocol which can't be detected automatically",
+		&setSymLinkCmd); err != nil {
+		logger.Err(err)
+		return
+	}
+
+	if _, err := parser.AddCommand("reindex",
+		"Reindex derived data",
+		"Rebuild transfers, token balances and big map states of contract or levels range from stored operations",
+		&reindexCmd); err != nil {
+		logger.Err(err)
+		return
+	}
+
 	if _, err := parser.Parse(); err != nil {
 		panic(err)
 	}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"github.com/baking-bad/bcdhub/internal/bcd"
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/models/protocol"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/pkg/errors"
+)
+
+type setSymLinkCommand struct {
+	Protocol string `short:"p" long:"protocol" description:"Protocol hash" required:"true"`
+	SymLink  string `short:"s" long:"symlink" description:"Symlink: alpha or babylon" required:"true"`
+	Network  string `short:"n" long:"network" description:"Network. Required if protocol is not indexed yet"`
+	Level    int64  `short:"l" long:"level" description:"Start level of protocol. Required if protocol is not indexed yet"`
+}
+
+var setSymLinkCmd setSymLinkCommand
+
+// Execute
+func (x *setSymLinkCommand) Execute(_ []string) error {
+	if len(x.Protocol) != 51 {
+		return errors.Errorf("invalid protocol hash: %s", x.Protocol)
+	}
+	if !bcd.IsValidSymLink(x.SymLink) {
+		return errors.Errorf("invalid symlink: %s", x.SymLink)
+	}
+
+	protocols, err := ctx.Protocols.GetAll()
+	if err != nil {
+		return err
+	}
+
+	var count int
+	for i := range protocols {
+		if protocols[i].Hash == x.Protocol {
+			count++
+		}
+	}
+
+	if count == 0 {
+		return x.create()
+	}
+
+	logger.Warning().Msgf("Do you want to set symlink '%s' for %d record(s) of protocol %s? Already indexed data is not migrated. (yes - continue. no - cancel)", x.SymLink, count, x.Protocol)
+	if !yes() {
+		logger.Info().Msg("Cancelled")
+		return nil
+	}
+
+	if _, err := ctx.StorageDB.DB.Model((*protocol.Protocol)(nil)).
+		Set("sym_link = ?", x.SymLink).
+		Where("hash = ?", x.Protocol).
+		Update(); err != nil {
+		return err
+	}
+	logger.Info().Msg("Done. Restart services to apply the symlink")
+	return nil
+}
+
+func (x *setSymLinkCommand) create() error {
+	network := types.NewNetwork(x.Network)
+	if network == types.Empty || x.Level < 1 {
+		return errors.Errorf("protocol %s is not indexed yet: network and start level are required", x.Protocol)
+	}
+
+	rpc, err := ctx.GetRPC(network)
+	if err != nil {
+		return err
+	}
+	constants, err := rpc.GetNetworkConstants(x.Level)
+	if err != nil {
+		return err
+	}
+
+	logger.Warning().Msgf("Do you want to create protocol %s with symlink '%s' in %s starting at %d? (yes - continue. no - cancel)", x.Protocol, x.SymLink, network, x.Level)
+	if !yes() {
+		logger.Info().Msg("Cancelled")
+		return nil
+	}
+
+	proto := protocol.Protocol{
+		Hash:       x.Protocol,
+		Network:    network,
+		StartLevel: x.Level,
+		SymLink:    x.SymLink,
+		Alias:      x.Protocol[:8],
+		Constants: &protocol.Constants{
+			CostPerByte:                  constants.CostPerByte,
+			HardGasLimitPerOperation:     constants.HardGasLimitPerOperation,
+			HardStorageLimitPerOperation: constants.HardStorageLimitPerOperation,
+			TimeBetweenBlocks:            constants.BlockDelay(),
+		},
+	}
+	if err := proto.Save(ctx.StorageDB.DB); err != nil {
+		return err
+	}
+	logger.Info().Msg("Done. Restart services to apply the symlink")
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/baking-bad/bcdhub/internal/logger"
+	"github.com/baking-bad/bcdhub/internal/models/types"
+	"github.com/baking-bad/bcdhub/internal/reindex"
+)
+
+type reindexCommand struct {
+	Network   string   `short:"n" long:"network" description:"Network" required:"true"`
+	Address   string   `short:"a" long:"address" description:"Contract address"`
+	FromLevel int64    `long:"from" description:"First level of range"`
+	ToLevel   int64    `long:"to" description:"Last level of range (default: current head)"`
+	Entities  []string `short:"e" long:"entity" description:"Derived entity to rebuild, can be repeated (default: all)" choice:"transfers" choice:"token_balances" choice:"big_map_states"`
+	DryRun    bool     `long:"dry-run" description:"Print differences without saving"`
+	CheckNode bool     `long:"check-node" description:"Check values of big map keys by the node's context at the last level: one request per key, the node has to keep the context"`
+}
+
+var reindexCmd reindexCommand
+
+// Execute
+func (x *reindexCommand) Execute(_ []string) error {
+	params := reindex.Params{
+		Network:   types.NewNetwork(x.Network),
+		Address:   x.Address,
+		FromLevel: x.FromLevel,
+		ToLevel:   x.ToLevel,
+		Entities:  x.Entities,
+		DryRun:    true,
+		CheckNode: x.CheckNode,
+	}
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	report, err := reindex.NewManager(ctx, params).Reindex(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := report.Write(os.Stdout); err != nil {
+		return err
+	}
+	if x.DryRun || report.Empty() {
+		return nil
+	}
+
+	logger.Warning().Msg("Do you want to apply the changes? (yes - continue. no - cancel)")
+	if !yes() {
+		logger.Info().Msg("Cancelled")
+		return nil
+	}
+
+	params.DryRun = false
+	if _, err := reindex.NewManager(ctx, params).Reindex(context.Background()); err != nil {
+		return err
+	}
+	logger.Info().Msg("Done")
+	return nil
+}
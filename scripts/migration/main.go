@@ -43,13 +43,18 @@ func main() {
 
 	start := time.Now()
 
-	ctx := config.NewContext(
+	ctx, err := config.NewContext(
 		config.WithStorage(cfg.Storage, "migrations", 0, cfg.Scripts.Connections.Open, cfg.Scripts.Connections.Idle),
+		config.WithNetworks(cfg.Networks),
 		config.WithRPC(cfg.RPC),
 		config.WithConfigCopy(cfg),
 		config.WithLoadErrorDescriptions(),
 		config.WithSearch(cfg.Storage),
 	)
+	if err != nil {
+		logger.Err(err)
+		return
+	}
 	defer ctx.Close()
 
 	logger.Info().Msgf("Starting %v migration...", migration.Key())
@@ -16,10 +16,15 @@ func main() {
 		return
 	}
 
-	ctx := config.NewContext(
+	ctx, err := config.NewContext(
 		config.WithStorage(cfg.Storage, "nginx", 0, cfg.Scripts.Connections.Open, cfg.Scripts.Connections.Idle),
+		config.WithNetworks(cfg.Networks),
 		config.WithConfigCopy(cfg),
 	)
+	if err != nil {
+		logger.Err(err)
+		return
+	}
 	defer ctx.Close()
 
 	dapps, err := ctx.DApps.All()